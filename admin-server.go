@@ -9,6 +9,9 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	router "github.com/gorilla/mux"
 	"github.com/journeymidnight/yig/api"
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/iam"
 	"github.com/journeymidnight/yig/iam/common"
@@ -46,6 +49,19 @@ type usageJson struct {
 	Usage int64
 }
 
+type quotaJson struct {
+	Quota meta.Quota
+	Usage meta.QuotaUsage
+}
+
+type publicAccessBlockJson struct {
+	PublicAccessBlock datatype.PublicAccessBlockConfiguration
+}
+
+type simulateJson struct {
+	Result api.AccessExplanation
+}
+
 var adminServer *adminServerConfig
 
 type handlerFunc func(http.Handler) http.Handler
@@ -127,6 +143,235 @@ func getCacheHitRatio(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// quotaOf returns kind and name of quota in claims, "bucket" or "uid"
+func quotaOf(claims jwt.MapClaims) (kind, name string, err error) {
+	if bucketName, ok := claims["bucket"].(string); ok && bucketName != "" {
+		return meta.QuotaKindBucket, bucketName, nil
+	}
+	if uid, ok := claims["uid"].(string); ok && uid != "" {
+		return meta.QuotaKindUser, uid, nil
+	}
+	return "", "", ErrInvalidRequestBody
+}
+
+func getQuota(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	kind, name, err := quotaOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	quota, err := adminServer.Yig.MetaStorage.GetQuota(kind, name)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	usage, err := adminServer.Yig.MetaStorage.GetQuotaUsage(kind, name)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	b, err := json.Marshal(quotaJson{Quota: quota, Usage: usage})
+	w.Write(b)
+	return
+}
+
+// setQuota sets "max_bytes" and "max_objects" in claims, negative for unlimited
+func setQuota(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	kind, name, err := quotaOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	quota := meta.UnlimitedQuota(kind, name)
+	// numbers in JSON claims are float64
+	if maxBytes, ok := claims["max_bytes"].(float64); ok {
+		quota.MaxBytes = int64(maxBytes)
+	}
+	if maxObjects, ok := claims["max_objects"].(float64); ok {
+		quota.MaxObjects = int64(maxObjects)
+	}
+
+	helper.Logger.Info("set quota:", kind, name, quota.MaxBytes, quota.MaxObjects)
+	err = adminServer.Yig.MetaStorage.PutQuota(quota)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	b, err := json.Marshal(quotaJson{Quota: quota})
+	w.Write(b)
+	return
+}
+
+func deleteQuota(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	kind, name, err := quotaOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	helper.Logger.Info("delete quota:", kind, name)
+	err = adminServer.Yig.MetaStorage.DeleteQuota(kind, name)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// uidOf returns "uid" in claims
+func uidOf(claims jwt.MapClaims) (uid string, err error) {
+	if uid, ok := claims["uid"].(string); ok && uid != "" {
+		return uid, nil
+	}
+	return "", ErrInvalidRequestBody
+}
+
+// getPublicAccessBlock returns account level Block Public Access settings of user,
+// which are enforced on all buckets owned by the user
+func getPublicAccessBlock(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	uid, err := uidOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	config, err := adminServer.Yig.MetaStorage.GetPublicAccessBlock(uid)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	b, err := json.Marshal(publicAccessBlockJson{PublicAccessBlock: config})
+	w.Write(b)
+	return
+}
+
+// setPublicAccessBlock sets "block_public_acls", "ignore_public_acls",
+// "block_public_policy" and "restrict_public_buckets" in claims, unset ones are disabled
+func setPublicAccessBlock(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	uid, err := uidOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	var config datatype.PublicAccessBlockConfiguration
+	config.BlockPublicAcls, _ = claims["block_public_acls"].(bool)
+	config.IgnorePublicAcls, _ = claims["ignore_public_acls"].(bool)
+	config.BlockPublicPolicy, _ = claims["block_public_policy"].(bool)
+	config.RestrictPublicBuckets, _ = claims["restrict_public_buckets"].(bool)
+
+	helper.Logger.Info("set public access block:", uid, config.BlockPublicAcls, config.IgnorePublicAcls,
+		config.BlockPublicPolicy, config.RestrictPublicBuckets)
+	err = adminServer.Yig.MetaStorage.PutPublicAccessBlock(uid, config)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	b, err := json.Marshal(publicAccessBlockJson{PublicAccessBlock: config})
+	w.Write(b)
+	return
+}
+
+func deletePublicAccessBlock(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	uid, err := uidOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	helper.Logger.Info("delete public access block:", uid)
+	err = adminServer.Yig.MetaStorage.DeletePublicAccessBlock(uid)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// conditionsOf returns "conditions" in claims, values could be a string or a list of strings
+func conditionsOf(claims jwt.MapClaims) (conditionValues map[string][]string, err error) {
+	conditionValues = make(map[string][]string)
+	if claims["conditions"] == nil {
+		return conditionValues, nil
+	}
+	conditions, ok := claims["conditions"].(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidRequestBody
+	}
+	for key, value := range conditions {
+		switch v := value.(type) {
+		case string:
+			conditionValues[key] = []string{v}
+		case []interface{}:
+			for _, s := range v {
+				str, ok := s.(string)
+				if !ok {
+					return nil, ErrInvalidRequestBody
+				}
+				conditionValues[key] = append(conditionValues[key], str)
+			}
+		default:
+			return nil, ErrInvalidRequestBody
+		}
+	}
+	return conditionValues, nil
+}
+
+// simulateAccess evaluates access of "uid" in claims, or anonymous users if empty,
+// to "bucket" and "object" with "action", under condition context "conditions",
+// and explains the decision
+func simulateAccess(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value("claims").(jwt.MapClaims)
+	uid, _ := claims["uid"].(string)
+	bucketName, _ := claims["bucket"].(string)
+	objectName, _ := claims["object"].(string)
+	actionName, _ := claims["action"].(string)
+	action := policy.Action(actionName)
+	if bucketName == "" || !action.IsValid() {
+		api.WriteErrorResponse(w, r, ErrInvalidRequestBody)
+		return
+	}
+	conditionValues, err := conditionsOf(claims)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	bucket, err := adminServer.Yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	config, err := adminServer.Yig.MetaStorage.GetEffectivePublicAccessBlock(bucket)
+	if err != nil {
+		api.WriteErrorResponse(w, r, err)
+		return
+	}
+	var object *meta.Object
+	if objectName != "" {
+		object, err = adminServer.Yig.MetaStorage.GetObject(bucketName, objectName, true)
+		if err != nil && err != ErrNoSuchKey {
+			api.WriteErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	helper.Logger.Info("simulate access:", uid, action, bucketName, objectName, conditionValues)
+	result := api.ExplainAccess(common.Credential{UserId: uid}, bucket, object, config,
+		action, objectName, conditionValues)
+	b, err := json.Marshal(simulateJson{Result: result})
+	w.Write(b)
+	return
+}
+
 var handlerFns = []handlerFunc{
 	//	SetJwtMiddlewareHandler,
 }
@@ -149,6 +394,13 @@ func configureAdminHandler() http.Handler {
 	admin.Methods("GET").Path("/bucket").HandlerFunc(SetJwtMiddlewareFunc(getBucketInfo))
 	admin.Methods("GET").Path("/object").HandlerFunc(SetJwtMiddlewareFunc(getObjectInfo))
 	admin.Methods("GET").Path("/cachehit").HandlerFunc(SetJwtMiddlewareFunc(getCacheHitRatio))
+	admin.Methods("GET").Path("/quota").HandlerFunc(SetJwtMiddlewareFunc(getQuota))
+	admin.Methods("PUT").Path("/quota").HandlerFunc(SetJwtMiddlewareFunc(setQuota))
+	admin.Methods("DELETE").Path("/quota").HandlerFunc(SetJwtMiddlewareFunc(deleteQuota))
+	admin.Methods("GET").Path("/publicaccessblock").HandlerFunc(SetJwtMiddlewareFunc(getPublicAccessBlock))
+	admin.Methods("PUT").Path("/publicaccessblock").HandlerFunc(SetJwtMiddlewareFunc(setPublicAccessBlock))
+	admin.Methods("DELETE").Path("/publicaccessblock").HandlerFunc(SetJwtMiddlewareFunc(deletePublicAccessBlock))
+	admin.Methods("GET").Path("/simulate").HandlerFunc(SetJwtMiddlewareFunc(simulateAccess))
 
 	metrics := NewMetrics("yig")
 	registry := prometheus.NewRegistry()
@@ -244,6 +244,7 @@ func startApiServer(c *ServerConfig) {
 
 	// Configure server.
 	apiServer := configureServer(c)
+	api.StartLogDelivery(c.ObjectLayer)
 
 	hosts, port := getListenIPs(apiServer.Server) // get listen ips and port.
 	tls := apiServer.Server.TLSConfig != nil      // 'true' if TLS is enabled.
@@ -268,4 +269,5 @@ func startApiServer(c *ServerConfig) {
 
 func stopApiServer() {
 	ApiServer.Stop()
+	api.StopLogDelivery()
 }
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+const (
+	// requests of admins in deny_reason_admins of config with this header set to "true"
+	// get reasons of denials in DenyReasonHeader
+	ExplainDenyHeader = "X-Yig-Explain-Deny"
+	DenyReasonHeader  = "X-Yig-Deny-Reason"
+)
+
+// AccessExplanation is the decision of access evaluation and its reason
+type AccessExplanation struct {
+	Allowed bool
+	Reason  string
+	// bucket policy statements deciding the result, if any
+	MatchedStatements []policy.Statement `json:",omitempty"`
+}
+
+// ExplainAccess evaluates access of credential to bucket, or object of bucket, with action
+// the same way as request authorization and storage, i.e. session policy, bucket policy,
+// Block Public Access settings, Object Ownership and canned ACLs in order, and explains
+// the decision. object is only used to evaluate its ACL, nil if not found.
+func ExplainAccess(credential common.Credential, bucket *meta.Bucket, object *meta.Object,
+	config datatype.PublicAccessBlockConfiguration, action policy.Action, objectName string,
+	conditionValues map[string][]string) AccessExplanation {
+
+	if bucket == nil {
+		return AccessExplanation{Reason: "bucket does not exist"}
+	}
+	if err := checkSessionPolicy(credential, bucket.Name, action, objectName, conditionValues); err != nil {
+		return AccessExplanation{Reason: "not allowed by session policy of temporary credential"}
+	}
+
+	// public ACLs are ignored for the bucket owner as well, as in RestrictOtherUserAccess
+	credential.IgnorePublicAcls = config.IgnorePublicAcls
+	// bucket policy is not evaluated for the bucket owner, as in isBucketPolicyAllowed
+	var reasons []string
+	var statements []policy.Statement
+	if bucket.OwnerId != credential.UserId {
+		var policyResult policy.IsPolicyAllowedResult
+		policyResult, statements = bucket.Policy.Explain(policy.Args{
+			AccountName:     credential.UserId,
+			Action:          action,
+			BucketName:      bucket.Name,
+			ConditionValues: conditionValues,
+			IsOwner:         false,
+			ObjectName:      objectName,
+		})
+		if policyResult == policy.PolicyDeny {
+			return AccessExplanation{
+				Reason:            "explicitly denied by bucket policy",
+				MatchedStatements: statements,
+			}
+		}
+		credential.AllowOtherUserAccess = policyResult == policy.PolicyAllow
+		err := RestrictOtherUserAccess(&credential, bucket, config)
+		if !credential.AllowOtherUserAccess {
+			if policyResult == policy.PolicyAllow {
+				reasons = append(reasons, "public bucket policy is restricted by RestrictPublicBuckets")
+			} else {
+				reasons = append(reasons, "not allowed by bucket policy")
+			}
+		}
+		if err != nil {
+			reasons = append(reasons, "ACLs are disabled by "+
+				datatype.ObjectOwnershipBucketOwnerEnforced+" Object Ownership")
+			// restricted statements are returned as well
+			return AccessExplanation{Reason: strings.Join(reasons, ", "), MatchedStatements: statements}
+		}
+	}
+
+	explanation := explainAcl(credential, bucket, object, action)
+	explanation.Reason = strings.Join(append(reasons, explanation.Reason), ", ")
+	explanation.MatchedStatements = statements
+	return explanation
+}
+
+// explanationOf explains the decision of ownership and ACL checks
+func explanationOf(access meta.Access) AccessExplanation {
+	return AccessExplanation{Allowed: access.Allowed, Reason: access.Reason}
+}
+
+// explainAcl explains the checks of storage for action, with the same functions,
+// after credential is authorized by policies
+func explainAcl(credential common.Credential, bucket *meta.Bucket, object *meta.Object,
+	action policy.Action) AccessExplanation {
+
+	switch action {
+	case policy.GetObjectAction, policy.GetObjectVersionAction:
+		if credential.AllowOtherUserAccess {
+			return AccessExplanation{Allowed: true, Reason: "allowed by bucket policy"}
+		}
+		if object == nil {
+			return AccessExplanation{Reason: "object is not found to check its ACL"}
+		}
+		return explanationOf(bucket.ObjectReadAccess(credential, object))
+	case policy.ListBucketAction, policy.ListBucketVersionsAction, policy.ListBucketMultipartUploadsAction:
+		if credential.AllowOtherUserAccess {
+			return AccessExplanation{Allowed: true, Reason: "allowed by bucket policy"}
+		}
+		return explanationOf(bucket.ReadAccess(credential))
+	case policy.PutObjectAction, policy.AbortMultipartUploadAction:
+		// writes are checked by bucket ACL only, whatever bucket policy allows
+		return explanationOf(bucket.WriteAccess(credential))
+	case policy.DeleteObjectAction, policy.DeleteObjectVersionAction:
+		if credential.AllowOtherUserAccess {
+			return AccessExplanation{Allowed: true, Reason: "allowed by bucket policy"}
+		}
+		return explanationOf(bucket.DeleteAccess(credential))
+	case policy.GetObjectTaggingAction, policy.PutObjectTaggingAction, policy.DeleteObjectTaggingAction,
+		policy.GetObjectVersionTaggingAction, policy.PutObjectVersionTaggingAction,
+		policy.DeleteObjectVersionTaggingAction, policy.GetObjectRetentionAction,
+		policy.PutObjectRetentionAction, policy.GetObjectLegalHoldAction, policy.PutObjectLegalHoldAction:
+		if credential.AllowOtherUserAccess {
+			return AccessExplanation{Allowed: true, Reason: "allowed by bucket policy"}
+		}
+		if object == nil {
+			return AccessExplanation{Reason: "object is not found to check its owner"}
+		}
+		return explanationOf(bucket.ObjectSubresourceAccess(credential, object))
+	}
+	if credential.AllowOtherUserAccess {
+		return AccessExplanation{Allowed: true, Reason: "allowed by bucket policy"}
+	}
+	if bucket.OwnerId == credential.UserId {
+		return AccessExplanation{Allowed: true, Reason: "requester owns the bucket"}
+	}
+	return AccessExplanation{Reason: fmt.Sprintf("ACLs never grant %s to other users", action)}
+}
+
+// accessRecord keeps the last authorization of request, which is explained
+// in DenyReasonHeader if the request is denied
+type accessRecord struct {
+	credential      common.Credential
+	bucket          *meta.Bucket
+	action          policy.Action
+	objectName      string
+	conditionValues map[string][]string
+}
+
+// recordAccess records authorization of request if it asks to explain denials
+func recordAccess(r *http.Request, credential common.Credential, bucket *meta.Bucket, action policy.Action,
+	objectName string, conditionValues map[string][]string) {
+
+	record := getRequestContext(r).accessRecord
+	if record == nil {
+		return
+	}
+	*record = accessRecord{
+		credential:      credential,
+		bucket:          bucket,
+		action:          action,
+		objectName:      objectName,
+		conditionValues: conditionValues,
+	}
+}
+
+// isExplainDenyRequested returns if request asks to explain denials and any admin could
+// get them, which is checked against the authenticated credential in setDenyReasonHeader
+func isExplainDenyRequested(r *http.Request) bool {
+	return len(helper.CONFIG.DenyReasonAdmins) > 0 &&
+		strings.ToLower(r.Header.Get(ExplainDenyHeader)) == "true"
+}
+
+// isDenyReasonAdmin returns if credential is authenticated as an admin in deny_reason_admins
+func isDenyReasonAdmin(credential common.Credential) bool {
+	if credential.UserId == "" {
+		return false
+	}
+	for _, uid := range helper.CONFIG.DenyReasonAdmins {
+		if uid == credential.UserId {
+			return true
+		}
+	}
+	return false
+}
+
+// setDenyReasonHeader explains the recorded authorization of denied request in DenyReasonHeader
+func setDenyReasonHeader(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	if ctx.accessRecord == nil || ctx.accessRecord.bucket == nil {
+		return
+	}
+	record := ctx.accessRecord
+	if !isDenyReasonAdmin(record.credential) {
+		return
+	}
+	var object *meta.Object
+	// only bucket settings are known for buckets other than the requested one, e.g. copy source
+	config := record.bucket.PublicAccessBlock
+	if ctx.BucketInfo != nil && record.bucket.Name == ctx.BucketInfo.Name {
+		config = ctx.PublicAccessBlock
+		if record.objectName == ctx.ObjectName {
+			object = ctx.ObjectInfo
+		}
+	}
+	explanation := ExplainAccess(record.credential, record.bucket, object, config,
+		record.action, record.objectName, record.conditionValues)
+	if !explanation.Allowed {
+		w.Header().Set(DenyReasonHeader, explanation.Reason)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+)
+
+func TestDenyReasonHeaderOfAdmins(t *testing.T) {
+	defer func(admins []string) { helper.CONFIG.DenyReasonAdmins = admins }(helper.CONFIG.DenyReasonAdmins)
+	bucket := newTestBucket(t, "b", "owner", "")
+	bucket.ACL.CannedAcl = "private"
+
+	helper.CONFIG.DenyReasonAdmins = nil
+	r := newTestRequest("GET", "/b/o")
+	r.Header.Set(ExplainDenyHeader, "true")
+	if isExplainDenyRequested(r) {
+		t.Error("Denials are explained without admins in config")
+	}
+
+	// anonymous requests are never explained, even if an empty uid is configured
+	helper.CONFIG.DenyReasonAdmins = []string{"admin", ""}
+	if !isExplainDenyRequested(r) {
+		t.Error("Denials are not explained to requests of admins")
+	}
+	testCases := []struct {
+		userId    string
+		explained bool
+	}{
+		{"admin", true},
+		{"user", false},
+		{"", false},
+	}
+	for _, testCase := range testCases {
+		w := httptest.NewRecorder()
+		r := newTestRequest("GET", "/b/o")
+		r = r.WithContext(context.WithValue(r.Context(), RequestContextKey, RequestContext{
+			BucketInfo: bucket,
+			ObjectName: "o",
+			accessRecord: &accessRecord{
+				credential: common.Credential{UserId: testCase.userId},
+				bucket:     bucket,
+				action:     policy.PutObjectAction,
+				objectName: "o",
+			},
+		}))
+		setDenyReasonHeader(w, r)
+		reason := w.Header().Get(DenyReasonHeader)
+		if testCase.explained && reason != "not allowed by bucket policy, not allowed by bucket ACL private" {
+			t.Errorf("Denial of %q is explained as %q", testCase.userId, reason)
+		}
+		if !testCase.explained && reason != "" {
+			t.Errorf("Denial of %q is explained as %q, expected no reason", testCase.userId, reason)
+		}
+	}
+}
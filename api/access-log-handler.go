@@ -1,14 +1,19 @@
 package api
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/journeymidnight/yig/helper"
-	bus "github.com/journeymidnight/yig/mq"
 	"github.com/journeymidnight/yig/meta"
+	bus "github.com/journeymidnight/yig/mq"
+	"github.com/journeymidnight/yig/signature"
 )
 
 type ResponseRecorder struct {
@@ -36,7 +41,9 @@ func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
 }
 
 func (r *ResponseRecorder) Flush() {
-	return
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
 }
 
 type AccessLogHandler struct {
@@ -65,6 +72,137 @@ func (a AccessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		elems["last_modified_time"] = objectLastModifiedTime
 	}
 	a.notify(elems)
+
+	if logDelivery != nil && ctx.BucketInfo != nil && ctx.BucketInfo.BucketLogging.Enabled() {
+		requester, ok := elems["requester_id"]
+		if !ok {
+			requester = newReplacer.Replace("{requester_id}")
+		}
+		logDelivery.Append(ctx.BucketInfo.Name, serverAccessLogRecord(r, a.responseRecorder, ctx, requester))
+	}
+}
+
+// serverAccessLogRecord formats a record in S3 server access log format, see
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html
+func serverAccessLogRecord(r *http.Request, rr *ResponseRecorder, ctx RequestContext, requester string) string {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	quoted := func(s string) string {
+		return "\"" + orDash(s) + "\""
+	}
+
+	remoteIP := r.Header.Get("X-Real-Ip")
+	if remoteIP == "" {
+		remoteIP, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	resource := "BUCKET"
+	key := "-"
+	if ctx.ObjectName != "" {
+		resource = "OBJECT"
+		key = url.QueryEscape(ctx.ObjectName)
+	}
+	query := r.URL.Query()
+	for subResource := range query {
+		if name, ok := logSubResources[subResource]; ok {
+			resource = name
+			break
+		}
+	}
+	if query.Get("partNumber") != "" && query.Get("uploadId") != "" {
+		resource = "PART"
+	}
+	var objectSize string
+	if ctx.ObjectInfo != nil {
+		objectSize = strconv.FormatInt(ctx.ObjectInfo.Size, 10)
+	}
+	var bytesSent string
+	if rr.size > 0 {
+		bytesSent = strconv.FormatInt(rr.size, 10)
+	}
+	var signatureVersion, authType string
+	switch ctx.AuthType {
+	case signature.AuthTypeSignedV4, signature.AuthTypeStreamingSigned:
+		signatureVersion, authType = "SigV4", "AuthHeader"
+	case signature.AuthTypePresignedV4:
+		signatureVersion, authType = "SigV4", "QueryString"
+	case signature.AuthTypeSignedV2:
+		signatureVersion, authType = "SigV2", "AuthHeader"
+	case signature.AuthTypePresignedV2:
+		signatureVersion, authType = "SigV2", "QueryString"
+	case signature.AuthTypePostPolicy:
+		authType = "HtmlForm"
+	}
+	var cipherSuite, tlsVersion string
+	if r.TLS != nil {
+		cipherSuite = tls.CipherSuiteName(r.TLS.CipherSuite)
+		tlsVersion = tlsVersionNames[r.TLS.Version]
+	}
+
+	fields := []string{
+		orDash(ctx.BucketInfo.OwnerId),
+		ctx.BucketInfo.Name,
+		time.Now().UTC().Format("[02/Jan/2006:15:04:05 -0700]"),
+		orDash(remoteIP),
+		orDash(requester),
+		orDash(ctx.RequestID),
+		"REST." + r.Method + "." + resource,
+		key,
+		quoted(r.Method + " " + r.URL.RequestURI() + " " + r.Proto),
+		strconv.Itoa(rr.status),
+		orDash(rr.errorCode),
+		orDash(bytesSent),
+		orDash(objectSize),
+		strconv.FormatInt(rr.requestTime.Nanoseconds()/1e6, 10),
+		"-", // turn-around time
+		quoted(r.Header.Get("Referer")),
+		quoted(r.Header.Get("User-Agent")),
+		orDash(query.Get("versionId")),
+		orDash(helper.CONFIG.InstanceId),
+		orDash(signatureVersion),
+		orDash(cipherSuite),
+		orDash(authType),
+		orDash(r.Host),
+		orDash(tlsVersion),
+	}
+	return strings.Join(fields, " ")
+}
+
+// sub-resources logged as resource type of operations, e.g. REST.PUT.ACL
+var logSubResources = map[string]string{
+	"acl":               "ACL",
+	"cors":              "CORS",
+	"lifecycle":         "LIFECYCLE",
+	"logging":           "LOGGING_STATUS",
+	"policy":            "BUCKETPOLICY",
+	"tagging":           "TAGGING",
+	"uploads":           "UPLOADS",
+	"uploadId":          "UPLOAD",
+	"versioning":        "VERSIONING",
+	"versions":          "BUCKETVERSIONS",
+	"website":           "WEBSITE",
+	"encryption":        "ENCRYPTION",
+	"notification":      "NOTIFICATION",
+	"replication":       "REPLICATION",
+	"inventory":         "INVENTORY",
+	"object-lock":       "OBJECT_LOCK_CONFIGURATION",
+	"retention":         "OBJECT_RETENTION",
+	"legal-hold":        "OBJECT_LEGAL_HOLD",
+	"restore":           "RESTORE",
+	"select":            "SELECT",
+	"attributes":        "OBJECT_ATTRIBUTES",
+	"publicAccessBlock": "PUBLIC_ACCESS_BLOCK",
+	"ownershipControls": "OWNERSHIP_CONTROLS",
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLSv1",
+	tls.VersionTLS11: "TLSv1.1",
+	tls.VersionTLS12: "TLSv1.2",
+	tls.VersionTLS13: "TLSv1.3",
 }
 
 func (a AccessLogHandler) notify(elems map[string]string) {
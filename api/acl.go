@@ -2,14 +2,35 @@ package api
 
 import (
 	. "github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
 	"net/http"
 )
 
-func getAclFromHeader(h http.Header) (acl Acl, err error) {
-	acl.CannedAcl = h.Get("x-amz-acl")
+// getAclFromHeader returns canned ACL in header "x-amz-acl", public ACLs are
+// rejected if blocked by Block Public Access settings of the bucket.
+// The header is ignored if ACLs are disabled by Object Ownership of the bucket.
+func getAclFromHeader(r *http.Request) (acl Acl, err error) {
+	if aclsDisabled(r) {
+		acl.CannedAcl = "private"
+		return
+	}
+	acl.CannedAcl = r.Header.Get("x-amz-acl")
 	if acl.CannedAcl == "" {
 		acl.CannedAcl = "private"
 	}
 	err = IsValidCannedAcl(acl)
+	if err != nil {
+		return
+	}
+	if getRequestContext(r).PublicAccessBlock.BlockPublicAcls && IsPublicAcl(acl) {
+		err = ErrPublicAclBlocked
+	}
 	return
 }
+
+// aclsDisabled returns if ACLs of the bucket in request are disabled by
+// BucketOwnerEnforced Object Ownership
+func aclsDisabled(r *http.Request) bool {
+	bucket := getRequestContext(r).BucketInfo
+	return bucket != nil && bucket.OwnershipControls.AclsDisabled()
+}
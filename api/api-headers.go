@@ -21,8 +21,10 @@ import (
 	"encoding/xml"
 	"net/http"
 	"strconv"
+	"time"
 
 	. "github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/crypto"
 	meta "github.com/journeymidnight/yig/meta/types"
 )
 
@@ -39,7 +41,26 @@ func EncodeResponse(response interface{}) []byte {
 	return bytesBuffer.Bytes()
 }
 
+// setSseKmsHeaders sets SSE-KMS response headers with key ID used to encrypt the object
+func setSseKmsHeaders(w http.ResponseWriter, keyId string) {
+	w.Header().Set(crypto.SSEHeader, crypto.SSEAlgorithmKMS)
+	if keyId != "" {
+		w.Header().Set(crypto.SSEKmsID, keyId)
+	}
+}
+
 // Write object header
+// setChecksumHeaders sets x-amz-checksum-* of additional checksum
+func setChecksumHeaders(w http.ResponseWriter, checksum Checksum) {
+	if !checksum.IsSet() {
+		return
+	}
+	w.Header().Set(ChecksumHeader(checksum.Algorithm), checksum.Value)
+	if checksum.Type != "" {
+		w.Header().Set("X-Amz-Checksum-Type", checksum.Type)
+	}
+}
+
 func SetObjectHeaders(w http.ResponseWriter, object *meta.Object, contentRange *HttpRange, statusCode int) {
 	// set object-related metadata headers
 	lastModified := object.LastModifiedTime.UTC().Format(http.TimeFormat)
@@ -65,6 +86,20 @@ func SetObjectHeaders(w http.ResponseWriter, object *meta.Object, contentRange *
 	if object.Type == meta.ObjectTypeAppendable {
 		w.Header().Set("X-Amz-Next-Append-Position", strconv.FormatInt(object.Size, 10))
 	}
+	if len(object.Tags) > 0 {
+		w.Header().Set("X-Amz-Tagging-Count", strconv.Itoa(len(object.Tags)))
+	}
+	if object.ReplicationStatus != "" {
+		w.Header().Set("X-Amz-Replication-Status", object.ReplicationStatus)
+	}
+	if object.ObjectLock.Mode != "" {
+		w.Header().Set("X-Amz-Object-Lock-Mode", object.ObjectLock.Mode)
+		w.Header().Set("X-Amz-Object-Lock-Retain-Until-Date",
+			object.ObjectLock.RetainUntilDate.UTC().Format(time.RFC3339))
+	}
+	if object.ObjectLock.LegalHold != "" {
+		w.Header().Set("X-Amz-Object-Lock-Legal-Hold", object.ObjectLock.LegalHold)
+	}
 
 	// for providing ranged content
 	if contentRange != nil && contentRange.OffsetBegin > -1 {
@@ -189,12 +189,14 @@ func GenerateInitiateMultipartUploadResponse(bucket, key, uploadID string) Initi
 }
 
 // GenerateCompleteMultipartUploadResponse
-func GenerateCompleteMultpartUploadResponse(bucket, key, location, etag string) CompleteMultipartUploadResponse {
+func GenerateCompleteMultpartUploadResponse(bucket, key, location, etag string,
+	checksum Checksum) CompleteMultipartUploadResponse {
 	return CompleteMultipartUploadResponse{
-		Location: location,
-		Bucket:   bucket,
-		Key:      key,
-		ETag:     etag,
+		Location:       location,
+		Bucket:         bucket,
+		Key:            key,
+		ETag:           etag,
+		ObjectChecksum: NewObjectChecksum(checksum),
 	}
 }
 
@@ -277,6 +279,10 @@ func WriteErrorResponseHeaders(w http.ResponseWriter, r *http.Request, err error
 	// ResponseRecorder
 	w.(*ResponseRecorder).status = status
 
+	if status == http.StatusForbidden {
+		setDenyReasonHeader(w, r)
+	}
+
 	// check website routing rules
 	if ctx.BucketInfo == nil {
 		w.WriteHeader(status)
@@ -31,6 +31,13 @@ func RegisterAPIRouter(mux *router.Router, api ObjectAPIHandlers) {
 	// API Router
 	apiRouter := mux.NewRoute().PathPrefix("/").Subrouter()
 
+	// Website router, matches bucket_name.website.domain.name/object_name
+	// Must be registered before S3 domains since they may share a suffix
+	for _, domain := range helper.CONFIG.WebsiteDomain {
+		website := apiRouter.Host("{bucket:.+}." + domain).Subrouter()
+		website.PathPrefix("/").HandlerFunc(api.WebsiteHandler)
+	}
+
 	var routers []*router.Router
 	for _, domain := range helper.CONFIG.S3Domain {
 		// Bucket router, matches domain.name/bucket_name/object_name
@@ -72,12 +79,39 @@ func RegisterAPIRouter(mux *router.Router, api ObjectAPIHandlers) {
 		// RestoreObject
 		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.RestoreObjectHandler).
 			Queries("restore", "")
+		// SelectObjectContent
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.SelectObjectContentHandler).
+			Queries("select", "", "select-type", "2")
 		// PutObjectACL
 		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectAclHandler).
 			Queries("acl", "")
 		// GetObjectAcl
 		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectAclHandler).
 			Queries("acl", "")
+		// PutObjectTagging
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectTaggingHandler).
+			Queries("tagging", "")
+		// GetObjectTagging
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectTaggingHandler).
+			Queries("tagging", "")
+		// DeleteObjectTagging
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(api.DeleteObjectTaggingHandler).
+			Queries("tagging", "")
+		// PutObjectRetention
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectRetentionHandler).
+			Queries("retention", "")
+		// GetObjectRetention
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectRetentionHandler).
+			Queries("retention", "")
+		// PutObjectLegalHold
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(api.PutObjectLegalHoldHandler).
+			Queries("legal-hold", "")
+		// GetObjectLegalHold
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectLegalHoldHandler).
+			Queries("legal-hold", "")
+		// GetObjectAttributes
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(api.GetObjectAttributesHandler).
+			Queries("attributes", "")
 
 		// AppendObject
 		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.AppendObjectHandler).Queries("append", "")
@@ -143,6 +177,46 @@ func RegisterAPIRouter(mux *router.Router, api ObjectAPIHandlers) {
 		bucket.Methods("GET").HandlerFunc(api.GetBucketEncryption).Queries("encryption", "")
 		//
 		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketEncryption).Queries("encryption", "")
+		// PutBucketTagging
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketTaggingHandler).Queries("tagging", "")
+		// GetBucketTagging
+		bucket.Methods("GET").HandlerFunc(api.GetBucketTaggingHandler).Queries("tagging", "")
+		// DeleteBucketTagging
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketTaggingHandler).Queries("tagging", "")
+		// PutBucketNotification
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketNotificationHandler).Queries("notification", "")
+		// GetBucketNotification
+		bucket.Methods("GET").HandlerFunc(api.GetBucketNotificationHandler).Queries("notification", "")
+		// PutBucketReplication
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketReplicationHandler).Queries("replication", "")
+		// GetBucketReplication
+		bucket.Methods("GET").HandlerFunc(api.GetBucketReplicationHandler).Queries("replication", "")
+		// DeleteBucketReplication
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketReplicationHandler).Queries("replication", "")
+		// PutBucketInventoryConfiguration
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketInventoryHandler).Queries("inventory", "", "id", "{id:.*}")
+		// GetBucketInventoryConfiguration
+		bucket.Methods("GET").HandlerFunc(api.GetBucketInventoryHandler).Queries("inventory", "", "id", "{id:.*}")
+		// DeleteBucketInventoryConfiguration
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketInventoryHandler).Queries("inventory", "", "id", "{id:.*}")
+		// ListBucketInventoryConfigurations
+		bucket.Methods("GET").HandlerFunc(api.ListBucketInventoryHandler).Queries("inventory", "")
+		// PutBucketObjectLockConfig
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketObjectLockConfigHandler).Queries("object-lock", "")
+		// GetBucketObjectLockConfig
+		bucket.Methods("GET").HandlerFunc(api.GetBucketObjectLockConfigHandler).Queries("object-lock", "")
+		// PutPublicAccessBlock
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketPublicAccessBlockHandler).Queries("publicAccessBlock", "")
+		// GetPublicAccessBlock
+		bucket.Methods("GET").HandlerFunc(api.GetBucketPublicAccessBlockHandler).Queries("publicAccessBlock", "")
+		// DeletePublicAccessBlock
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketPublicAccessBlockHandler).Queries("publicAccessBlock", "")
+		// PutBucketOwnershipControls
+		bucket.Methods("PUT").HandlerFunc(api.PutBucketOwnershipControlsHandler).Queries("ownershipControls", "")
+		// GetBucketOwnershipControls
+		bucket.Methods("GET").HandlerFunc(api.GetBucketOwnershipControlsHandler).Queries("ownershipControls", "")
+		// DeleteBucketOwnershipControls
+		bucket.Methods("DELETE").HandlerFunc(api.DeleteBucketOwnershipControlsHandler).Queries("ownershipControls", "")
 
 		// HeadBucket
 		bucket.Methods("HEAD").HandlerFunc(api.HeadBucketHandler)
@@ -157,6 +231,8 @@ func RegisterAPIRouter(mux *router.Router, api ObjectAPIHandlers) {
 	}
 	/// Root operation
 
+	// STS AssumeRole and GetSessionToken
+	apiRouter.Methods("POST").Path("/").HandlerFunc(api.StsHandler)
 	// ListBuckets
 	apiRouter.Methods("GET").HandlerFunc(api.ListBucketsHandler)
 }
@@ -4,8 +4,11 @@ import (
 	"net"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/journeymidnight/yig/api/datatype"
 	"github.com/journeymidnight/yig/api/datatype/policy"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
@@ -20,6 +23,14 @@ import (
 //   for authenticated requests validates IAM policies.
 // returns APIErrorCode if any to be replied to the client.
 func checkRequestAuth(r *http.Request, action policy.Action) (c common.Credential, err error) {
+	return checkRequestAuthWithConditions(r, action, nil)
+}
+
+// checkRequestAuthWithConditions works like checkRequestAuth, but evaluates bucket policy
+// with extra condition values which could not be extracted from request headers,
+// e.g. tags in request body of PutObjectTagging.
+func checkRequestAuthWithConditions(r *http.Request, action policy.Action,
+	conditions map[string][]string) (c common.Credential, err error) {
 	// TODO:Location constraint
 	ctx := getRequestContext(r)
 	logger := ctx.Logger
@@ -37,31 +48,54 @@ func checkRequestAuth(r *http.Request, action policy.Action) (c common.Credentia
 		} else {
 			helper.Logger.Info("Credential:", c)
 			// check bucket policy
-			isAllow, err := IsBucketPolicyAllowed(c.UserId, ctx.BucketInfo, r, action, ctx.ObjectName)
+			isAllow, err := isBucketPolicyAllowed(c, ctx.BucketInfo, r, action, ctx.ObjectName, conditions)
+			if err != nil {
+				return c, err
+			}
 			c.AllowOtherUserAccess = isAllow
+			err = RestrictOtherUserAccess(&c, ctx.BucketInfo, ctx.PublicAccessBlock)
 			return c, err
 		}
 	case signature.AuthTypeAnonymous:
-		isAllow, err := IsBucketPolicyAllowed(c.UserId, ctx.BucketInfo, r, action, ctx.ObjectName)
+		isAllow, err := isBucketPolicyAllowed(c, ctx.BucketInfo, r, action, ctx.ObjectName, conditions)
+		if err != nil {
+			return c, err
+		}
 		c.AllowOtherUserAccess = isAllow
+		err = RestrictOtherUserAccess(&c, ctx.BucketInfo, ctx.PublicAccessBlock)
 		return c, err
 	}
 	return c, ErrAccessDenied
 }
 
-func IsBucketPolicyAllowed(userId string, bucket *meta.Bucket, r *http.Request, action policy.Action, objectName string) (allow bool, err error) {
+func IsBucketPolicyAllowed(credential common.Credential, bucket *meta.Bucket, r *http.Request, action policy.Action, objectName string) (allow bool, err error) {
+	return isBucketPolicyAllowed(credential, bucket, r, action, objectName, nil)
+}
+
+// isBucketPolicyAllowed evaluates bucket policy, temporary credentials are
+// further limited by their session policies
+func isBucketPolicyAllowed(credential common.Credential, bucket *meta.Bucket, r *http.Request, action policy.Action,
+	objectName string, conditions map[string][]string) (allow bool, err error) {
 	if bucket == nil {
 		return false, ErrAccessDenied
 	}
-	if bucket.OwnerId == userId {
+	conditionValues := getConditionValues(r, "")
+	for key, values := range conditions {
+		conditionValues[key] = values
+	}
+	recordAccess(r, credential, bucket, action, objectName, conditionValues)
+	if err = checkSessionPolicy(credential, bucket.Name, action, objectName, conditionValues); err != nil {
+		return false, err
+	}
+	if bucket.OwnerId == credential.UserId {
 		return false, nil
 	}
 	policyResult := bucket.Policy.IsAllowed(policy.Args{
 		// TODO: Add IAM policy. Current account name is always useless.
-		AccountName:     userId,
+		AccountName:     credential.UserId,
 		Action:          action,
 		BucketName:      bucket.Name,
-		ConditionValues: getConditionValues(r, ""),
+		ConditionValues: conditionValues,
 		IsOwner:         false,
 		ObjectName:      objectName,
 	})
@@ -75,6 +109,102 @@ func IsBucketPolicyAllowed(userId string, bucket *meta.Bucket, r *http.Request,
 
 }
 
+// checkSessionPolicy denies temporary credentials whose session policies
+// don't allow action, credentials without session policy are not limited
+func checkSessionPolicy(credential common.Credential, bucketName string, action policy.Action,
+	objectName string, conditionValues map[string][]string) error {
+
+	if credential.SessionPolicy == "" {
+		return nil
+	}
+	sessionPolicy, err := policy.ParseSessionPolicy(credential.SessionPolicy)
+	if err != nil {
+		helper.Logger.Error("Invalid session policy of", credential.AccessKeyID, "err:", err)
+		return ErrAccessDenied
+	}
+	if !sessionPolicy.IsSessionAllowed(policy.Args{
+		AccountName:     credential.UserId,
+		Action:          action,
+		BucketName:      bucketName,
+		ConditionValues: conditionValues,
+		ObjectName:      objectName,
+	}) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// checkRequestPolicy evaluates session policy and bucket policy of action for handlers
+// which authenticate requests by themselves, other users are permitted in credential
+// if bucket policy allows. Bucket owner and ACL are further checked in storage.
+func checkRequestPolicy(r *http.Request, credential *common.Credential, action policy.Action,
+	bucketName, objectName string) error {
+
+	ctx := getRequestContext(r)
+	if ctx.BucketInfo == nil || ctx.BucketInfo.Name != bucketName {
+		// leave ErrNoSuchBucket to handlers
+		return checkSessionPolicy(*credential, bucketName, action, objectName, getConditionValues(r, ""))
+	}
+	isAllow, err := isBucketPolicyAllowed(*credential, ctx.BucketInfo, r, action, objectName, nil)
+	if err != nil {
+		return err
+	}
+	credential.AllowOtherUserAccess = isAllow
+	return RestrictOtherUserAccess(credential, ctx.BucketInfo, ctx.PublicAccessBlock)
+}
+
+// RestrictOtherUserAccess enforces Block Public Access settings and Object Ownership
+// of bucket on other users.
+// With RestrictPublicBuckets, a public bucket policy grants nothing to other users.
+// With IgnorePublicAcls, public ACLs grant nothing, which is checked with ACLs in storage,
+// and with BucketOwnerEnforced Object Ownership, ACLs are disabled, so other users are
+// only permitted by bucket policy.
+func RestrictOtherUserAccess(credential *common.Credential, bucket *meta.Bucket,
+	config datatype.PublicAccessBlockConfiguration) error {
+
+	if bucket == nil {
+		return nil
+	}
+	credential.IgnorePublicAcls = config.IgnorePublicAcls
+	if bucket.OwnerId == credential.UserId {
+		return nil
+	}
+	if credential.AllowOtherUserAccess && config.RestrictPublicBuckets && bucket.Policy.IsPublic() {
+		credential.AllowOtherUserAccess = false
+	}
+	if !credential.AllowOtherUserAccess && bucket.OwnershipControls.AclsDisabled() {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// versionedAction returns version specific action of request with versionId,
+// e.g. s3:GetObjectVersion for s3:GetObject
+func versionedAction(r *http.Request, action, versionAction policy.Action) policy.Action {
+	if r.URL.Query().Get("versionId") != "" {
+		return versionAction
+	}
+	return action
+}
+
+// canBypassGovernance returns if the request asks to bypass GOVERNANCE retention
+// with header "x-amz-bypass-governance-retention", and is permitted to by bucket policy.
+// Bucket owner is permitted unless explicitly denied.
+func canBypassGovernance(r *http.Request, userId string, bucket *meta.Bucket, objectName string) bool {
+	if strings.ToLower(r.Header.Get("X-Amz-Bypass-Governance-Retention")) != "true" || bucket == nil {
+		return false
+	}
+	policyResult := bucket.Policy.IsAllowed(policy.Args{
+		AccountName:     userId,
+		Action:          policy.BypassGovernanceRetentionAction,
+		BucketName:      bucket.Name,
+		ConditionValues: getConditionValues(r, ""),
+		IsOwner:         bucket.OwnerId == userId,
+		ObjectName:      objectName,
+	})
+	return policyResult == policy.PolicyAllow
+}
+
 func getConditionValues(request *http.Request, locationConstraint string) map[string][]string {
 	args := make(map[string][]string)
 
@@ -87,6 +217,10 @@ func getConditionValues(request *http.Request, locationConstraint string) map[st
 	}
 
 	for key, values := range request.URL.Query() {
+		// keys of x-amz-* headers are never taken from query parameters
+		if strings.HasPrefix(strings.ToLower(key), "x-amz-") {
+			continue
+		}
 		if existingValues, found := args[key]; found {
 			args[key] = append(existingValues, values...)
 		} else {
@@ -95,11 +229,63 @@ func getConditionValues(request *http.Request, locationConstraint string) map[st
 	}
 
 	args["SourceIp"] = []string{GetSourceIP(request)}
+	// absent headers are left absent for Null and ...IfExists conditions
+	if referer := request.Referer(); referer != "" {
+		args["Referer"] = []string{referer}
+	}
+	if userAgent := request.UserAgent(); userAgent != "" {
+		args["UserAgent"] = []string{userAgent}
+	}
+	args["SecureTransport"] = []string{strconv.FormatBool(isSecureTransport(request))}
+	now := time.Now().UTC()
+	args["CurrentTime"] = []string{now.Format(time.RFC3339)}
+	args["EpochTime"] = []string{strconv.FormatInt(now.Unix(), 10)}
 
 	if locationConstraint != "" {
 		args["LocationConstraint"] = []string{locationConstraint}
 	}
 
+	ctx := getRequestContext(request)
+	switch ctx.AuthType {
+	case signature.AuthTypeSignedV2, signature.AuthTypePresignedV2, signature.AuthTypePostPolicy:
+		args["signatureversion"] = []string{"AWS"}
+	case signature.AuthTypeSignedV4, signature.AuthTypePresignedV4, signature.AuthTypeStreamingSigned:
+		args["signatureversion"] = []string{"AWS4-HMAC-SHA256"}
+	}
+	if ctx.ObjectInfo != nil {
+		for key, values := range getTagConditionValues("ExistingObjectTag", ctx.ObjectInfo.Tags) {
+			args[key] = values
+		}
+	}
+	// Tags are validated by handlers, ignore malformed ones here
+	if tags, err := datatype.ParseTaggingHeader(request.Header.Get("X-Amz-Tagging")); err == nil {
+		for key, values := range getRequestTagConditionValues(tags) {
+			args[key] = values
+		}
+	}
+
+	return args
+}
+
+// getTagConditionValues returns condition values of tags, with keys like "ExistingObjectTag/<tag-key>"
+func getTagConditionValues(name string, tags map[string]string) map[string][]string {
+	args := make(map[string][]string, len(tags))
+	for key, value := range tags {
+		args[name+"/"+key] = []string{value}
+	}
+	return args
+}
+
+// getRequestTagConditionValues returns condition values of s3:RequestObjectTag and s3:RequestObjectTagKeys
+func getRequestTagConditionValues(tags map[string]string) map[string][]string {
+	args := getTagConditionValues("RequestObjectTag", tags)
+	if len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		args["RequestObjectTagKeys"] = keys
+	}
 	return args
 }
 
@@ -117,9 +303,55 @@ var (
 	forRegex = regexp.MustCompile(`(?i)(?:for=)([^(;|,| )]+)(.*)`)
 	// Allows for a sub-match for the first instance of scheme (http|https)
 	// prefixed by 'proto='. The match is case-insensitive.
-
+	protoRegex      = regexp.MustCompile(`(?i)(?:proto=)(https|http)`)
+	xForwardedProto = http.CanonicalHeaderKey("X-Forwarded-Proto")
 )
 
+// isSecureTransport returns if request is sent by TLS, to yig or to a trusted proxy.
+// Forwarded schemes are set by clients unless they're from trusted proxies.
+func isSecureTransport(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return isTrustedProxy(r.RemoteAddr) && GetSourceScheme(r) == "https"
+}
+
+// isTrustedProxy returns if remote address is one of trusted_proxies in config
+func isTrustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range helper.CONFIG.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSourceScheme retrieves the scheme from the X-Forwarded-Proto and RFC7239
+// Forwarded headers (in that order).
+func GetSourceScheme(r *http.Request) string {
+	if proto := r.Header.Get(xForwardedProto); proto != "" {
+		return strings.ToLower(proto)
+	}
+	if fwd := r.Header.Get(forwarded); fwd != "" {
+		if match := protoRegex.FindStringSubmatch(fwd); len(match) > 1 {
+			return strings.ToLower(match[1])
+		}
+	}
+	return ""
+}
+
 // GetSourceIP retrieves the IP from the X-Forwarded-For, X-Real-IP and RFC7239
 // Forwarded headers (in that order), falls back to r.RemoteAddr when all
 // else fails.
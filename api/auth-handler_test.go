@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/log"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+func newTestRequest(method, target string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	return r.WithContext(context.WithValue(r.Context(), RequestContextKey, RequestContext{}))
+}
+
+func newTestBucket(t *testing.T, name, owner, bucketPolicy string) *meta.Bucket {
+	bucket := &meta.Bucket{Name: name, OwnerId: owner}
+	if bucketPolicy != "" {
+		p, err := policy.ParseConfig(strings.NewReader(bucketPolicy), name)
+		if err != nil {
+			t.Fatal("Invalid bucket policy:", err)
+		}
+		bucket.Policy = *p
+	}
+	return bucket
+}
+
+func TestSessionPolicyIntersection(t *testing.T) {
+	helper.Logger = log.NewLogger(os.Stderr, log.ParseLevel("error"))
+	publicRead := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*",` +
+		`"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`
+	getObject := `{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`
+	putObject := `{"Statement":[{"Effect":"Allow","Action":"s3:PutObject","Resource":"arn:aws:s3:::b/*"}]}`
+	denyPrivate := `{"Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::*"},` +
+		`{"Effect":"Deny","Action":"s3:*","Resource":"arn:aws:s3:::b/private/*"}]}`
+
+	testCases := []struct {
+		description   string
+		owner         string
+		bucketPolicy  string
+		sessionPolicy string
+		action        policy.Action
+		objectName    string
+		allow         bool
+		err           error
+	}{
+		{"owner without session policy", "user", "", "", policy.PutObjectAction, "o", false, nil},
+		{"owner within session policy", "user", "", getObject, policy.GetObjectAction, "o", false, nil},
+		{"owner out of session policy", "user", "", getObject, policy.PutObjectAction, "o", false, ErrAccessDenied},
+		{"denied by session policy", "user", "", denyPrivate, policy.GetObjectAction, "private/o", false,
+			ErrAccessDenied},
+		{"allowed by both", "owner", publicRead, getObject, policy.GetObjectAction, "o", true, nil},
+		{"allowed by bucket policy only", "owner", publicRead, putObject, policy.GetObjectAction, "o", false,
+			ErrAccessDenied},
+		// session policies never grant anything by themselves
+		{"allowed by session policy only", "owner", "", getObject, policy.GetObjectAction, "o", false, nil},
+		{"invalid session policy", "user", "", "{", policy.GetObjectAction, "o", false, ErrAccessDenied},
+	}
+	for _, testCase := range testCases {
+		bucket := newTestBucket(t, "b", testCase.owner, testCase.bucketPolicy)
+		credential := common.Credential{UserId: "user", AccessKeyID: "ASIAKEY", SessionPolicy: testCase.sessionPolicy}
+		if testCase.sessionPolicy != "" {
+			credential.SessionToken = "token"
+		}
+		allow, err := isBucketPolicyAllowed(credential, bucket, newTestRequest("GET", "/b/o"),
+			testCase.action, testCase.objectName, nil)
+		if allow != testCase.allow || err != testCase.err {
+			t.Errorf("%s: allow %v, error %v, expected %v, %v", testCase.description,
+				allow, err, testCase.allow, testCase.err)
+		}
+	}
+}
+
+func TestSessionPolicyOfOtherBuckets(t *testing.T) {
+	helper.Logger = log.NewLogger(os.Stderr, log.ParseLevel("error"))
+	credential := common.Credential{UserId: "user", AccessKeyID: "ASIAKEY", SessionToken: "token",
+		SessionPolicy: `{"Statement":[{"Effect":"Allow","Action":"s3:ListBucket","Resource":"arn:aws:s3:::b"}]}`}
+
+	err := checkSessionPolicy(credential, "b", policy.ListBucketAction, "", nil)
+	if err != nil {
+		t.Error("ListBucket of b is denied:", err)
+	}
+	err = checkSessionPolicy(credential, "c", policy.ListBucketAction, "", nil)
+	if err != ErrAccessDenied {
+		t.Error("ListBucket of c is not denied:", err)
+	}
+	err = checkSessionPolicy(credential, "b", policy.GetObjectAction, "o", nil)
+	if err != ErrAccessDenied {
+		t.Error("GetObject of b is not denied:", err)
+	}
+}
+
+func TestVersionedAction(t *testing.T) {
+	testCases := []struct {
+		target string
+		action policy.Action
+	}{
+		{"/b/o", policy.GetObjectAction},
+		{"/b/o?versionId=", policy.GetObjectAction},
+		{"/b/o?versionId=v", policy.GetObjectVersionAction},
+		{"/b/o?acl&versionId=null", policy.GetObjectVersionAction},
+	}
+	for _, testCase := range testCases {
+		r := newTestRequest("GET", testCase.target)
+		action := versionedAction(r, policy.GetObjectAction, policy.GetObjectVersionAction)
+		if action != testCase.action {
+			t.Errorf("Action of %s is %s, expected %s", testCase.target, action, testCase.action)
+		}
+	}
+}
+
+func TestCheckRequestPolicyAction(t *testing.T) {
+	helper.Logger = log.NewLogger(os.Stderr, log.ParseLevel("error"))
+	bucket := newTestBucket(t, "b", "owner", `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Principal":"*","Action":"s3:GetObjectAcl","Resource":"arn:aws:s3:::b/*"},`+
+		`{"Effect":"Deny","Principal":"*","Action":"s3:PutObjectAcl","Resource":"arn:aws:s3:::b/*"}]}`)
+	testCases := []struct {
+		action policy.Action
+		allow  bool
+		err    error
+	}{
+		{policy.GetObjectAclAction, true, nil},
+		// other actions are not granted by specific actions
+		{policy.GetObjectVersionAclAction, false, nil},
+		{policy.GetObjectAction, false, nil},
+		{policy.PutObjectAclAction, false, ErrAccessDenied},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest("GET", "/b/o?acl", nil)
+		r = r.WithContext(context.WithValue(r.Context(), RequestContextKey, RequestContext{BucketInfo: bucket}))
+		credential := common.Credential{UserId: "user"}
+		err := checkRequestPolicy(r, &credential, testCase.action, "b", "o")
+		if credential.AllowOtherUserAccess != testCase.allow || err != testCase.err {
+			t.Errorf("%s: allow %v, error %v, expected %v, %v", testCase.action,
+				credential.AllowOtherUserAccess, err, testCase.allow, testCase.err)
+		}
+	}
+}
+
+func TestSecureTransport(t *testing.T) {
+	trustedProxies := helper.CONFIG.TrustedProxies
+	helper.CONFIG.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	defer func() { helper.CONFIG.TrustedProxies = trustedProxies }()
+
+	testCases := []struct {
+		remoteAddr string
+		header     map[string]string
+		tls        bool
+		secure     bool
+	}{
+		{"1.2.3.4:1234", nil, false, false},
+		{"1.2.3.4:1234", nil, true, true},
+		// forwarded schemes of clients are not trusted
+		{"1.2.3.4:1234", map[string]string{"X-Forwarded-Proto": "https"}, false, false},
+		{"1.2.3.4:1234", map[string]string{"Forwarded": "for=1.2.3.4;proto=https"}, false, false},
+		{"10.1.2.3:1234", map[string]string{"X-Forwarded-Proto": "https"}, false, true},
+		{"192.168.1.1:1234", map[string]string{"Forwarded": "for=1.2.3.4;proto=https"}, false, true},
+		{"192.168.1.2:1234", map[string]string{"X-Forwarded-Proto": "https"}, false, false},
+		{"10.1.2.3:1234", map[string]string{"X-Forwarded-Proto": "http"}, false, false},
+		{"10.1.2.3:1234", nil, false, false},
+	}
+	for i, testCase := range testCases {
+		r := newTestRequest("GET", "/b/o")
+		r.RemoteAddr = testCase.remoteAddr
+		for k, v := range testCase.header {
+			r.Header.Set(k, v)
+		}
+		if testCase.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		values := getConditionValues(r, "")
+		if secure := values["SecureTransport"][0] == "true"; secure != testCase.secure {
+			t.Errorf("Case %d: SecureTransport %v, expected %v", i, secure, testCase.secure)
+		}
+	}
+}
+
+func TestAbsentRefererCondition(t *testing.T) {
+	bucketPolicy := `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*",` +
+		`"Condition":{"StringLikeIfExists":{"aws:Referer":"http://example.com/*"}}},` +
+		`{"Effect":"Allow","Principal":"*","Action":"s3:ListBucket","Resource":"arn:aws:s3:::b",` +
+		`"Condition":{"Null":{"aws:Referer":"true","aws:UserAgent":"true"}}}]}`
+	bucket := newTestBucket(t, "b", "owner", bucketPolicy)
+
+	testCases := []struct {
+		referer string
+		action  policy.Action
+		allowed bool
+	}{
+		{"", policy.GetObjectAction, true},
+		{"http://example.com/page", policy.GetObjectAction, true},
+		{"http://other.com/page", policy.GetObjectAction, false},
+		{"", policy.ListBucketAction, true},
+		{"http://example.com/page", policy.ListBucketAction, false},
+	}
+	for _, testCase := range testCases {
+		r := newTestRequest("GET", "/b/o")
+		if testCase.referer != "" {
+			r.Header.Set("Referer", testCase.referer)
+		}
+		values := getConditionValues(r, "")
+		if _, found := values["UserAgent"]; found {
+			t.Errorf("UserAgent is present without User-Agent header: %v", values["UserAgent"])
+		}
+		objectName := "o"
+		if testCase.action == policy.ListBucketAction {
+			objectName = ""
+		}
+		allowed, err := isBucketPolicyAllowed(common.Credential{UserId: "user"}, bucket, r,
+			testCase.action, objectName, nil)
+		if err != nil || allowed != testCase.allowed {
+			t.Errorf("%s with Referer %q is allowed %v, err %v, expected %v", testCase.action,
+				testCase.referer, allowed, err, testCase.allowed)
+		}
+	}
+}
+
+func TestRestrictOtherUserAccess(t *testing.T) {
+	publicPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*",` +
+		`"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`
+	userPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"user"},` +
+		`"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`
+	enforced := datatype.OwnershipControls{Rules: []datatype.OwnershipControlsRule{
+		{ObjectOwnership: datatype.ObjectOwnershipBucketOwnerEnforced}}}
+
+	testCases := []struct {
+		description  string
+		bucketPolicy string
+		ownership    datatype.OwnershipControls
+		config       datatype.PublicAccessBlockConfiguration
+		allowed      bool
+		allow        bool
+		err          error
+	}{
+		{"public policy", publicPolicy, datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{}, true, true, nil},
+		{"restricted public policy", publicPolicy, datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{RestrictPublicBuckets: true}, true, false, nil},
+		{"restricted public policy without ACLs", publicPolicy, datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{RestrictPublicBuckets: true, IgnorePublicAcls: true},
+			true, false, nil},
+		{"non public policy", userPolicy, datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{RestrictPublicBuckets: true, IgnorePublicAcls: true},
+			true, true, nil},
+		// ACLs are left to storage, where public ACLs are ignored by credential
+		{"no policy", "", datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{}, false, false, nil},
+		{"public ACLs ignored", "", datatype.OwnershipControls{},
+			datatype.PublicAccessBlockConfiguration{IgnorePublicAcls: true}, false, false, nil},
+		{"ACLs disabled", "", enforced,
+			datatype.PublicAccessBlockConfiguration{}, false, false, ErrAccessDenied},
+	}
+	for _, testCase := range testCases {
+		bucket := newTestBucket(t, "b", "owner", testCase.bucketPolicy)
+		bucket.OwnershipControls = testCase.ownership
+		credential := common.Credential{UserId: "user", AllowOtherUserAccess: testCase.allowed}
+		err := RestrictOtherUserAccess(&credential, bucket, testCase.config)
+		if credential.AllowOtherUserAccess != testCase.allow || err != testCase.err {
+			t.Errorf("%s: allow %v, error %v, expected %v, %v", testCase.description,
+				credential.AllowOtherUserAccess, err, testCase.allow, testCase.err)
+		}
+		if credential.IgnorePublicAcls != testCase.config.IgnorePublicAcls {
+			t.Errorf("%s: public ACLs ignored %v, expected %v", testCase.description,
+				credential.IgnorePublicAcls, testCase.config.IgnorePublicAcls)
+		}
+	}
+
+	// bucket owners are never restricted
+	bucket := newTestBucket(t, "b", "user", publicPolicy)
+	bucket.OwnershipControls = enforced
+	credential := common.Credential{UserId: "user"}
+	err := RestrictOtherUserAccess(&credential, bucket, datatype.PublicAccessBlockConfiguration{
+		IgnorePublicAcls: true, RestrictPublicBuckets: true})
+	if err != nil {
+		t.Error("Bucket owner is restricted:", err)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/iam/common"
 	"github.com/journeymidnight/yig/signature"
@@ -26,6 +27,10 @@ func (api ObjectAPIHandlers) PutBucketEncryption(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutEncryptionConfigurationAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -78,6 +83,10 @@ func (api ObjectAPIHandlers) GetBucketEncryption(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetEncryptionConfigurationAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -125,6 +134,10 @@ func (api ObjectAPIHandlers) DeleteBucketEncryption(w http.ResponseWriter, r *ht
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutEncryptionConfigurationAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/gorilla/mux"
 	. "github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/iam/common"
@@ -56,6 +57,10 @@ func (api ObjectAPIHandlers) GetBucketLocationHandler(w http.ResponseWriter, r *
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketLocationAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if _, err = api.ObjectAPI.GetBucketInfo(bucketName, credential); err != nil {
 		logger.Error("Unable to fetch bucket info:", err)
@@ -101,6 +106,10 @@ func (api ObjectAPIHandlers) ListMultipartUploadsHandler(w http.ResponseWriter,
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListBucketMultipartUploadsAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	request, err := parseListUploadsQuery(r.URL.Query())
 	if err != nil {
@@ -153,6 +162,10 @@ func (api ObjectAPIHandlers) ListObjectsHandler(w http.ResponseWriter, r *http.R
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListBucketAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	request, err := parseListObjectsQuery(r.URL.Query())
 	if err != nil {
@@ -198,6 +211,10 @@ func (api ObjectAPIHandlers) ListVersionedObjectsHandler(w http.ResponseWriter,
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListBucketVersionsAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	request, err := parseListObjectsQuery(r.URL.Query())
 	if err != nil {
@@ -236,6 +253,10 @@ func (api ObjectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListAllMyBucketsAction, "", ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	bucketsInfo, err := api.ObjectAPI.ListBuckets(credential)
 	if err != nil {
@@ -314,9 +335,20 @@ func (api ObjectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 	var deleteErrors []DeleteError
 	var deletedObjects []ObjectIdentifier
 	// Loop through all the objects and delete them sequentially.
+	bucketInfo := getRequestContext(r).BucketInfo
 	for _, object := range deleteObjects.Objects {
-		result, err := api.ObjectAPI.DeleteObject(bucket, object.ObjectName,
-			object.VersionId, credential)
+		var action policy.Action = policy.DeleteObjectAction
+		if object.VersionId != "" {
+			action = policy.DeleteObjectVersionAction
+		}
+		var result DeleteObjectResult
+		objectCredential := credential
+		err = checkRequestPolicy(r, &objectCredential, action, bucket, object.ObjectName)
+		if err == nil {
+			bypassGovernance := canBypassGovernance(r, credential.UserId, bucketInfo, object.ObjectName)
+			result, err = api.ObjectAPI.DeleteObject(bucket, object.ObjectName,
+				object.VersionId, bypassGovernance, objectCredential)
+		}
 		if err == nil {
 			deletedObjects = append(deletedObjects, ObjectIdentifier{
 				ObjectName:   object.ObjectName,
@@ -371,6 +403,10 @@ func (api ObjectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.CreateBucketAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if len(r.Header.Get("Content-Length")) == 0 {
 		logger.Info("Content Length is null")
@@ -378,7 +414,7 @@ func (api ObjectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	acl, err := getAclFromHeader(r.Header)
+	acl, err := getAclFromHeader(r)
 	if err != nil {
 		WriteErrorResponse(w, r, err)
 		return
@@ -386,8 +422,10 @@ func (api ObjectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 
 	// TODO:the location value in the request body should match the Region in serverConfig.
 
+	objectLockEnabled := strings.ToLower(r.Header.Get("X-Amz-Bucket-Object-Lock-Enabled")) == "true"
+
 	// Make bucket.
-	err = api.ObjectAPI.MakeBucket(bucketName, acl, credential)
+	err = api.ObjectAPI.MakeBucket(bucketName, acl, objectLockEnabled, credential)
 	if err != nil {
 		logger.Error("Unable to create bucket", bucketName, "error:", err)
 		WriteErrorResponse(w, r, err)
@@ -411,6 +449,10 @@ func (api ObjectAPIHandlers) PutBucketLoggingHandler(w http.ResponseWriter, r *h
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketLoggingAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	var bl BucketLoggingStatus
 	blBuffer, err := ioutil.ReadAll(io.LimitReader(r.Body, 4096))
@@ -468,6 +510,10 @@ func (api ObjectAPIHandlers) GetBucketLoggingHandler(w http.ResponseWriter, r *h
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketLoggingAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -513,23 +559,19 @@ func (api ObjectAPIHandlers) PutBucketLifeCycleHandler(w http.ResponseWriter, r
 		WriteErrorResponse(w, r, err)
 		return
 	}
-
-	var lc Lifecycle
-	lcBuffer, err := ioutil.ReadAll(io.LimitReader(r.Body, 4096))
-	if err != nil {
-		logger.Error("Unable to read lifecycle body:", err)
-		WriteErrorResponse(w, r, ErrInvalidLc)
+	if err = checkRequestPolicy(r, &credential, policy.PutLifecycleConfigurationAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
 		return
 	}
-	err = xml.Unmarshal(lcBuffer, &lc)
+
+	lc, err := ParseLifecycleConfig(r.Body)
 	if err != nil {
-		logger.Error("Unable to parse lifecycle XML body:", err)
-		WriteErrorResponse(w, r, ErrInternalError)
+		WriteErrorResponse(w, r, err)
 		return
 	}
 
 	logger.Info("Setting lifecycle:", lc)
-	err = api.ObjectAPI.SetBucketLifecycle(bucket, lc, credential)
+	err = api.ObjectAPI.SetBucketLifecycle(bucket, *lc, credential)
 	if err != nil {
 		logger.Error(err, "Unable to set lifecycle for bucket:", err)
 		WriteErrorResponse(w, r, err)
@@ -561,6 +603,10 @@ func (api ObjectAPIHandlers) GetBucketLifeCycleHandler(w http.ResponseWriter, r
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetLifecycleConfigurationAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	lc, err := api.ObjectAPI.GetBucketLifecycle(bucketName, credential)
 	if err != nil {
@@ -595,6 +641,10 @@ func (api ObjectAPIHandlers) DelBucketLifeCycleHandler(w http.ResponseWriter, r
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutLifecycleConfigurationAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	err = api.ObjectAPI.DelBucketLifecycle(bucketName, credential)
 	if err != nil {
@@ -618,12 +668,19 @@ func (api ObjectAPIHandlers) PutBucketAclHandler(w http.ResponseWriter, r *http.
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketAclAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	var acl Acl
 	var policy AccessControlPolicy
 	if _, ok := r.Header["X-Amz-Acl"]; ok {
-		acl, err = getAclFromHeader(r.Header)
-		if err != nil {
+		acl, err = getAclFromHeader(r)
+		if err == ErrPublicAclBlocked {
+			WriteErrorResponse(w, r, err)
+			return
+		} else if err != nil {
 			logger.Error("Unable to read canned ACLs:", err)
 			WriteErrorResponse(w, r, ErrInvalidAcl)
 			return
@@ -675,6 +732,10 @@ func (api ObjectAPIHandlers) GetBucketAclHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketAclAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	policy, err := api.ObjectAPI.GetBucketAcl(bucketName, credential)
 	if err != nil {
@@ -709,6 +770,10 @@ func (api ObjectAPIHandlers) PutBucketCorsHandler(w http.ResponseWriter, r *http
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketCORSAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// If Content-Length is unknown or zero, deny the request.
 	if !contains(r.TransferEncoding, "chunked") {
@@ -755,6 +820,10 @@ func (api ObjectAPIHandlers) DeleteBucketCorsHandler(w http.ResponseWriter, r *h
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketCORSAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	err = api.ObjectAPI.DeleteBucketCors(bucketName, credential)
 	if err != nil {
@@ -777,6 +846,10 @@ func (api ObjectAPIHandlers) GetBucketCorsHandler(w http.ResponseWriter, r *http
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketCORSAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	cors, err := api.ObjectAPI.GetBucketCors(bucketName, credential)
 	if err != nil {
@@ -809,6 +882,10 @@ func (api ObjectAPIHandlers) GetBucketVersioningHandler(w http.ResponseWriter, r
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketVersioningAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	versioning, err := api.ObjectAPI.GetBucketVersioning(bucketName, credential)
 	if err != nil {
@@ -841,6 +918,10 @@ func (api ObjectAPIHandlers) PutBucketVersioningHandler(w http.ResponseWriter, r
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketVersioningAction, bucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// If Content-Length is unknown or zero, deny the request.
 	if !contains(r.TransferEncoding, "chunked") {
@@ -952,6 +1033,10 @@ func (api ObjectAPIHandlers) HeadBucketHandler(w http.ResponseWriter, r *http.Re
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListBucketAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if _, err = api.ObjectAPI.GetBucketInfo(bucket, credential); err != nil {
 		logger.Error("Unable to fetch bucket info:", err)
@@ -975,6 +1060,10 @@ func (api ObjectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.DeleteBucketAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if err = api.ObjectAPI.DeleteBucket(bucket, credential); err != nil {
 		logger.Error("Unable to delete a bucket:", err)
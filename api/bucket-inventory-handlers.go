@@ -0,0 +1,168 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/signature"
+)
+
+// checkBucketOwner authenticates the request, and only allows bucket owner
+// if action is not denied by bucket policy and session policy
+func checkBucketOwner(r *http.Request, action policy.Action) error {
+	ctx := getRequestContext(r)
+	var credential common.Credential
+	var err error
+	switch ctx.AuthType {
+	default:
+		// For all unknown auth types return error.
+		return ErrAccessDenied
+	case signature.AuthTypePresignedV4, signature.AuthTypeSignedV4,
+		signature.AuthTypePresignedV2, signature.AuthTypeSignedV2:
+		if credential, err = signature.IsReqAuthenticated(r); err != nil {
+			return err
+		}
+	}
+
+	if ctx.BucketInfo == nil {
+		return ErrNoSuchBucket
+	}
+	if err = checkRequestPolicy(r, &credential, action, ctx.BucketName, ""); err != nil {
+		return err
+	}
+	if credential.UserId != ctx.BucketInfo.OwnerId {
+		return ErrBucketAccessForbidden
+	}
+	return nil
+}
+
+// PutBucketInventoryHandler - PUT Bucket inventory
+// ----------
+// Inventory reports of the bucket are exported to destination bucket
+// daily or weekly by tools/inventory.
+func (api ObjectAPIHandlers) PutBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	if err := checkBucketOwner(r, policy.PutInventoryConfigurationAction); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	// PutBucketInventory always needs Content-Length.
+	if r.ContentLength <= 0 {
+		WriteErrorResponse(w, r, ErrMissingContentLength)
+		return
+	}
+
+	config, err := datatype.ParseInventoryConfig(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if config.Id != r.URL.Query().Get("id") {
+		WriteErrorResponse(w, r, ErrInvalidInventoryConfiguration)
+		return
+	}
+
+	err = api.ObjectAPI.SetBucketInventory(ctx.BucketInfo, *config)
+	if err != nil {
+		logger.Error("Unable to set inventory for bucket:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "PutBucketInventoryConfiguration"
+	WriteSuccessResponse(w, nil)
+}
+
+// GetBucketInventoryHandler - GET Bucket inventory
+func (api ObjectAPIHandlers) GetBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	if err := checkBucketOwner(r, policy.GetInventoryConfigurationAction); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	config, err := api.ObjectAPI.GetBucketInventory(ctx.BucketName, r.URL.Query().Get("id"))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	config.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	encodedSuccessResponse, err := xmlFormat(config)
+	if err != nil {
+		logger.Error("Failed to marshal inventory XML for bucket", ctx.BucketName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+
+	setXmlHeader(w)
+	//ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetBucketInventoryConfiguration"
+	// Write to client.
+	WriteSuccessResponse(w, encodedSuccessResponse)
+}
+
+// ListBucketInventoryHandler - GET Bucket inventory list
+// ----------
+// At most 100 configurations are returned in a page, ordered by id.
+func (api ObjectAPIHandlers) ListBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	if err := checkBucketOwner(r, policy.GetInventoryConfigurationAction); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	result, err := api.ObjectAPI.ListBucketInventory(ctx.BucketName,
+		r.URL.Query().Get("continuation-token"))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	result.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	encodedSuccessResponse, err := xmlFormat(result)
+	if err != nil {
+		logger.Error("Failed to marshal inventory list XML for bucket", ctx.BucketName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+
+	setXmlHeader(w)
+	//ResponseRecorder
+	w.(*ResponseRecorder).operationName = "ListBucketInventoryConfigurations"
+	// Write to client.
+	WriteSuccessResponse(w, encodedSuccessResponse)
+}
+
+// DeleteBucketInventoryHandler - DELETE Bucket inventory
+// Reports already exported are kept in destination bucket.
+func (api ObjectAPIHandlers) DeleteBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+
+	if err := checkBucketOwner(r, policy.PutInventoryConfigurationAction); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if err := api.ObjectAPI.DeleteBucketInventory(ctx.BucketInfo, r.URL.Query().Get("id")); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "DeleteBucketInventoryConfiguration"
+	// Success.
+	WriteSuccessNoContent(w)
+}
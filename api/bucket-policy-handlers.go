@@ -60,6 +60,10 @@ func (api ObjectAPIHandlers) PutBucketPolicyHandler(w http.ResponseWriter, r *ht
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketPolicyAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// Error out if Content-Length is missing.
 	// PutBucketPolicy always needs Content-Length.
@@ -117,6 +121,10 @@ func (api ObjectAPIHandlers) DeleteBucketPolicyHandler(w http.ResponseWriter, r
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.DeleteBucketPolicyAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if err := api.ObjectAPI.DeleteBucketPolicy(credential, bucket); err != nil {
 		WriteErrorResponse(w, r, err)
@@ -149,6 +157,10 @@ func (api ObjectAPIHandlers) GetBucketPolicyHandler(w http.ResponseWriter, r *ht
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketPolicyAction, bucket, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// Read bucket access policy.
 	bucketPolicy, err := api.ObjectAPI.GetBucketPolicy(credential, bucket)
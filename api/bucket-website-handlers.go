@@ -3,6 +3,7 @@ package api
 import (
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/journeymidnight/yig/api/datatype"
@@ -11,6 +12,7 @@ import (
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
 	"github.com/journeymidnight/yig/signature"
 )
 
@@ -34,6 +36,10 @@ func (api ObjectAPIHandlers) PutBucketWebsiteHandler(w http.ResponseWriter, r *h
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketWebsiteAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -85,6 +91,10 @@ func (api ObjectAPIHandlers) GetBucketWebsiteHandler(w http.ResponseWriter, r *h
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketWebsiteAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -134,6 +144,10 @@ func (api ObjectAPIHandlers) DeleteBucketWebsiteHandler(w http.ResponseWriter, r
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.DeleteBucketWebsiteAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
@@ -154,7 +168,6 @@ func (api ObjectAPIHandlers) DeleteBucketWebsiteHandler(w http.ResponseWriter, r
 
 func (api ObjectAPIHandlers) HandledByWebsite(w http.ResponseWriter, r *http.Request) (handled bool) {
 	ctx := getRequestContext(r)
-	logger := ctx.Logger
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
 		return true
@@ -170,11 +183,7 @@ func (api ObjectAPIHandlers) HandledByWebsite(w http.ResponseWriter, r *http.Req
 			WriteErrorResponse(w, r, ErrSecondLevelDomainForbidden)
 			return true
 		}
-		protocol := redirect.Protocol
-		if protocol == "" {
-			protocol = helper.Ternary(r.URL.Scheme == "", "http", r.URL.Scheme).(string)
-		}
-		http.Redirect(w, r, protocol+"://"+redirect.HostName+r.RequestURI, http.StatusFound)
+		redirectAllRequests(w, r, redirect)
 		return true
 	}
 
@@ -185,27 +194,14 @@ func (api ObjectAPIHandlers) HandledByWebsite(w http.ResponseWriter, r *http.Req
 		}
 
 		// match routing rules
-		if len(website.RoutingRules) != 0 {
-			for _, rule := range website.RoutingRules {
-				// If the condition matches, handle redirect
-				if rule.Match(ctx.ObjectName, "") {
-					rule.DoRedirect(w, r, ctx.ObjectName)
-					return true
-				}
-			}
+		if matchRoutingRules(w, r, website.RoutingRules, ctx.ObjectName) {
+			return true
 		}
 
 		// handle IndexDocument
 		if strings.HasSuffix(ctx.ObjectName, "/") || ctx.ObjectName == "" {
 			indexName := ctx.ObjectName + id.Suffix
-			credential := common.Credential{}
-			isAllow, err := IsBucketPolicyAllowed(credential.UserId, ctx.BucketInfo, r, policy.GetObjectAction, indexName)
-			if err != nil {
-				WriteErrorResponse(w, r, err)
-				return true
-			}
-			credential.AllowOtherUserAccess = isAllow
-			index, err := api.ObjectAPI.GetObjectInfo(ctx.BucketName, indexName, "", credential)
+			index, credential, err := api.getWebsiteObjectInfo(r, indexName)
 			if err != nil {
 				if err == ErrNoSuchKey {
 					api.errAllowableObjectNotFound(w, r, credential)
@@ -214,25 +210,7 @@ func (api ObjectAPIHandlers) HandledByWebsite(w http.ResponseWriter, r *http.Req
 				WriteErrorResponse(w, r, err)
 				return true
 			}
-			writer := newGetObjectResponseWriter(w, r, index, nil, http.StatusOK, "")
-			// Reads the object at startOffset and writes to mw.
-			if err := api.ObjectAPI.GetObject(index, 0, index.Size, writer, datatype.SseRequest{}); err != nil {
-				logger.Error("Unable to write to client:", err)
-				if !writer.dataWritten {
-					// Error response only if no data has been written to client yet. i.e if
-					// partial data has already been written before an error
-					// occurred then no point in setting StatusCode and
-					// sending error XML.
-					WriteErrorResponse(w, r, err)
-				}
-				return true
-			}
-			if !writer.dataWritten {
-				// If ObjectAPI.GetObject did not return error and no data has
-				// been written it would mean that it is a 0-byte object.
-				// call wrter.Write(nil) to set appropriate headers.
-				writer.Write(nil)
-			}
+			api.writeWebsiteObject(w, r, index, http.StatusOK)
 			return true
 		}
 
@@ -240,50 +218,183 @@ func (api ObjectAPIHandlers) HandledByWebsite(w http.ResponseWriter, r *http.Req
 	return false
 }
 
+// WebsiteHandler - serves requests sent to the website endpoint
+// ----------
+// Website endpoints only support GET and HEAD, and all requests are
+// treated as anonymous. Objects must be publicly readable to be served.
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/dev/WebsiteEndpoints.html
+func (api ObjectAPIHandlers) WebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteErrorResponse(w, r, ErrMethodNotAllowed)
+		return
+	}
+	if ctx.BucketInfo == nil {
+		WriteErrorResponse(w, r, ErrNoSuchBucket)
+		return
+	}
+
+	website := ctx.BucketInfo.Website
+	if redirect := website.RedirectAllRequestsTo; redirect != nil && redirect.HostName != "" {
+		redirectAllRequests(w, r, redirect)
+		return
+	}
+	id := website.IndexDocument
+	if id == nil || id.Suffix == "" {
+		WriteErrorResponse(w, r, ErrNoSuchWebsiteConfiguration)
+		return
+	}
+
+	// rules with KeyPrefixEquals only are applied before looking up the object,
+	// rules with HttpErrorCodeReturnedEquals are applied in writeWebsiteErrorResponse
+	if matchRoutingRules(w, r, website.RoutingRules, ctx.ObjectName) {
+		return
+	}
+
+	objectName := ctx.ObjectName
+	if objectName == "" || strings.HasSuffix(objectName, "/") {
+		objectName += id.Suffix
+	}
+	object, credential, err := api.getWebsiteObjectInfo(r, objectName)
+	if err == ErrNoSuchKey && objectName == ctx.ObjectName {
+		// Request for "dir" is redirected to "dir/" if "dir/" has an index document
+		if _, _, e := api.getWebsiteObjectInfo(r, objectName+"/"+id.Suffix); e == nil {
+			http.Redirect(w, r, "/"+objectName+"/", http.StatusFound)
+			return
+		}
+	}
+	if err == nil && object.DeleteMarker {
+		err = ErrNoSuchKey
+	}
+	if err != nil {
+		logger.Info("Unable to fetch website object", objectName, "error:", err)
+		if err == ErrNoSuchKey {
+			api.errAllowableObjectNotFound(w, r, credential)
+			return
+		}
+		api.writeWebsiteErrorResponse(w, r, err)
+		return
+	}
+	api.writeWebsiteObject(w, r, object, http.StatusOK)
+}
+
 func (api ObjectAPIHandlers) ReturnWebsiteErrorDocument(w http.ResponseWriter, r *http.Request, statusCode int) (handled bool) {
 	w.(*ResponseRecorder).operationName = "GetObject"
 	ctx := getRequestContext(r)
-	logger := ctx.Logger
 	if ctx.BucketInfo == nil {
 		WriteErrorResponse(w, r, ErrNoSuchBucket)
 		return true
 	}
 	website := ctx.BucketInfo.Website
 	if ed := website.ErrorDocument; ed != nil && ed.Key != "" {
-		indexName := ed.Key
-		credential := common.Credential{}
-		isAllow, err := IsBucketPolicyAllowed(credential.UserId, ctx.BucketInfo, r, policy.GetObjectAction, indexName)
+		index, _, err := api.getWebsiteObjectInfo(r, ed.Key)
 		if err != nil {
 			WriteErrorResponse(w, r, err)
 			return true
 		}
-		credential.AllowOtherUserAccess = isAllow
-		index, err := api.ObjectAPI.GetObjectInfo(ctx.BucketName, indexName, "", credential)
-		if err != nil {
+		api.writeWebsiteObject(w, r, index, statusCode)
+		return true
+	} else {
+		return false
+	}
+}
+
+// writeWebsiteErrorResponse applies routing rules matching the http status code of err,
+// then returns the error document if configured, or the error itself otherwise.
+func (api ObjectAPIHandlers) writeWebsiteErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := getRequestContext(r)
+	if ctx.BucketInfo == nil {
+		WriteErrorResponse(w, r, ErrNoSuchBucket)
+		return
+	}
+	var status int
+	website := ctx.BucketInfo.Website
+	apiErrorCode, ok := err.(ApiError)
+	if ok {
+		status = apiErrorCode.HttpStatusCode()
+	} else {
+		status = http.StatusInternalServerError
+	}
+	// match routing rules
+	for _, rule := range website.RoutingRules {
+		// If the condition matches, handle redirect
+		if rule.Match(ctx.ObjectName, strconv.Itoa(status)) {
+			rule.DoRedirect(w, r, ctx.ObjectName)
+			return
+		}
+	}
+	if api.ReturnWebsiteErrorDocument(w, r, status) {
+		return
+	}
+	WriteErrorResponse(w, r, err)
+}
+
+// getWebsiteObjectInfo fetches object info on behalf of an anonymous user
+func (api ObjectAPIHandlers) getWebsiteObjectInfo(r *http.Request,
+	objectName string) (object *meta.Object, credential common.Credential, err error) {
+
+	ctx := getRequestContext(r)
+	isAllow, err := IsBucketPolicyAllowed(credential, ctx.BucketInfo, r, policy.GetObjectAction, objectName)
+	if err != nil {
+		return
+	}
+	credential.AllowOtherUserAccess = isAllow
+	if err = RestrictOtherUserAccess(&credential, ctx.BucketInfo, ctx.PublicAccessBlock); err != nil {
+		return
+	}
+	object, err = api.ObjectAPI.GetObjectInfo(ctx.BucketName, objectName, "", credential)
+	return
+}
+
+func (api ObjectAPIHandlers) writeWebsiteObject(w http.ResponseWriter, r *http.Request,
+	object *meta.Object, statusCode int) {
+
+	logger := getRequestContext(r).Logger
+	if r.Method == http.MethodHead {
+		w.(*ResponseRecorder).operationName = "HeadObject"
+		SetObjectHeaders(w, object, nil, statusCode)
+		return
+	}
+	w.(*ResponseRecorder).operationName = "GetObject"
+	writer := newGetObjectResponseWriter(w, r, object, nil, statusCode, "")
+	// Reads the object at startOffset and writes to mw.
+	if err := api.ObjectAPI.GetObject(object, 0, object.Size, writer, datatype.SseRequest{}); err != nil {
+		logger.Error("Unable to write to client:", err)
+		if !writer.dataWritten {
+			// Error response only if no data has been written to client yet. i.e if
+			// partial data has already been written before an error
+			// occurred then no point in setting StatusCode and
+			// sending error XML.
 			WriteErrorResponse(w, r, err)
-			return true
 		}
-		writer := newGetObjectResponseWriter(w, r, index, nil, http.StatusNotFound, "")
-		// Reads the object at startOffset and writes to mw.
-		if err := api.ObjectAPI.GetObject(index, 0, index.Size, writer, datatype.SseRequest{}); err != nil {
-			logger.Error("Unable to write to client:", err)
-			if !writer.dataWritten {
-				// Error response only if no data has been written to client yet. i.e if
-				// partial data has already been written before an error
-				// occurred then no point in setting StatusCode and
-				// sending error XML.
-				WriteErrorResponse(w, r, err)
-			}
+		return
+	}
+	if !writer.dataWritten {
+		// If ObjectAPI.GetObject did not return error and no data has
+		// been written it would mean that it is a 0-byte object.
+		// call wrter.Write(nil) to set appropriate headers.
+		writer.Write(nil)
+	}
+}
+
+func redirectAllRequests(w http.ResponseWriter, r *http.Request, redirect *RedirectAllRequestsTo) {
+	protocol := redirect.Protocol
+	if protocol == "" {
+		protocol = helper.Ternary(r.URL.Scheme == "", "http", r.URL.Scheme).(string)
+	}
+	http.Redirect(w, r, protocol+"://"+redirect.HostName+r.RequestURI, http.StatusFound)
+}
+
+// matchRoutingRules redirects the request if any of the rules matches
+// objectName without an error code
+func matchRoutingRules(w http.ResponseWriter, r *http.Request, rules []RoutingRule, objectName string) bool {
+	for _, rule := range rules {
+		// If the condition matches, handle redirect
+		if rule.Match(objectName, "") {
+			rule.DoRedirect(w, r, objectName)
 			return true
 		}
-		if !writer.dataWritten {
-			// If ObjectAPI.GetObject did not return error and no data has
-			// been written it would mean that it is a 0-byte object.
-			// call wrter.Write(nil) to set appropriate headers.
-			writer.Write(nil)
-		}
-		return true
-	} else {
-		return false
 	}
+	return false
 }
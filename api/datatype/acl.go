@@ -13,7 +13,8 @@ var ValidCannedAcl = []string{
 	"aws-exec-read",
 	"authenticated-read",
 	"bucket-owner-read",
-	"bucket-owner-full-controll",
+	"bucket-owner-full-control",
+	"log-delivery-write",
 }
 
 const (
@@ -24,6 +25,7 @@ const (
 	CANNEDACL_AUTHENTICATED_READ         = 4
 	CANNEDACL_BUCKET_OWNER_READ          = 5
 	CANNEDACL_BUCKET_OWNER_FULL_CONTROLL = 6
+	CANNEDACL_LOG_DELIVERY_WRITE         = 7
 )
 
 const (
@@ -39,6 +41,7 @@ const (
 const (
 	ACL_GROUP_TYPE_ALL_USERS           = "http://acs.amazonaws.com/groups/global/AllUsers"
 	ACL_GROUP_TYPE_AUTHENTICATED_USERS = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	ACL_GROUP_TYPE_LOG_DELIVERY        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
 )
 
 const (
@@ -245,6 +248,15 @@ func CreatePolicyFromCanned(owner Owner, bucketOwner Owner, acl Acl) (
 		if bucketOwner.ID != owner.ID {
 			policy.AccessControlList = append(policy.AccessControlList, grant)
 		}
+	case "log-delivery-write":
+		owner := Owner{}
+		for _, perm := range []string{ACL_PERM_WRITE, ACL_PERM_READ_ACP} {
+			grant, err := createGrant(ACL_TYPE_GROUP, owner, perm, ACL_GROUP_TYPE_LOG_DELIVERY)
+			if err != nil {
+				return policy, err
+			}
+			policy.AccessControlList = append(policy.AccessControlList, grant)
+		}
 	default:
 		return policy, ErrUnsupportedAcl
 	}
@@ -118,6 +118,7 @@ type Part struct {
 	ETag         string
 	LastModified string
 	Size         int64
+	ObjectChecksum
 }
 
 // ListPartsResponse - format for list parts response.
@@ -135,6 +136,8 @@ type ListPartsResponse struct {
 	// The class of storage used to store the object.
 	StorageClass string
 
+	ChecksumAlgorithm string `xml:"ChecksumAlgorithm,omitempty"`
+
 	PartNumberMarker     int
 	NextPartNumberMarker int
 	MaxParts             int
@@ -266,6 +269,7 @@ type CompleteMultipartUploadResponse struct {
 	Bucket   string
 	Key      string
 	ETag     string
+	ObjectChecksum
 }
 
 // PostResponse container for completed post upload response
@@ -314,6 +318,9 @@ type PutObjectResult struct {
 	Md5          string
 	VersionId    string
 	LastModified time.Time
+	// key ID of SSE-KMS objects, the default key of KMS is used if not specified in request
+	SseAwsKmsKeyId string
+	Checksum       Checksum
 }
 
 type RenameObjectResult struct {
@@ -333,28 +340,29 @@ type DeleteObjectResult struct {
 type PutObjectPartResult struct {
 	ETag                    string
 	SseType                 string
-	SseAwsKmsKeyIdBase64    string
+	SseAwsKmsKeyId          string
 	SseCustomerAlgorithm    string
 	SseCustomerKeyMd5Base64 string
+	Checksum                Checksum
 }
 
 type CompleteMultipartResult struct {
 	ETag                    string
 	VersionId               string
 	SseType                 string
-	SseAwsKmsKeyIdBase64    string
+	SseAwsKmsKeyId          string
 	SseCustomerAlgorithm    string
 	SseCustomerKeyMd5Base64 string
+	Checksum                Checksum
 }
 
 type SseRequest struct {
-	// type of Server Side Encryption, could be "SSE-KMS", "SSE-S3", "SSE-C"(custom), or ""(none),
-	// KMS is not implemented yet
+	// type of Server Side Encryption, could be "SSE-KMS", "SSE-S3", "SSE-C"(custom), or ""(none)
 	Type string
 
 	// AWS-managed specific(KMS and S3)
 	SseAwsKmsKeyId string
-	SseContext     string
+	SseContext     string // base64 encoded JSON of SSE-KMS encryption context
 
 	// customer-provided specific(SSE-C)
 	SseCustomerAlgorithm string
@@ -1,10 +1,16 @@
 package datatype
 
 type BucketLoggingStatus struct {
-	LoggingEnabled    BucketLoggingRule `xml:"LoggingEnabled"`
+	LoggingEnabled BucketLoggingRule `xml:"LoggingEnabled"`
 }
 
 type BucketLoggingRule struct {
-	TargetBucket     string `xml:"TargetBucket"`
-	TargetPrefix     string `xml:"TargetPrefix"`
-}
\ No newline at end of file
+	TargetBucket string `xml:"TargetBucket"`
+	TargetPrefix string `xml:"TargetPrefix"`
+}
+
+// Enabled returns if access logs should be delivered to TargetBucket,
+// logging is disabled by an empty BucketLoggingStatus
+func (s BucketLoggingStatus) Enabled() bool {
+	return s.LoggingEnabled.TargetBucket != ""
+}
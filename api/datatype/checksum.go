@@ -0,0 +1,229 @@
+package datatype
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"hash"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+const (
+	ChecksumCRC32  = "CRC32"
+	ChecksumCRC32C = "CRC32C"
+	ChecksumSHA1   = "SHA1"
+	ChecksumSHA256 = "SHA256"
+
+	ChecksumTypeFullObject = "FULL_OBJECT"
+	ChecksumTypeComposite  = "COMPOSITE"
+
+	ChecksumModeEnabled = "ENABLED"
+)
+
+var ChecksumAlgorithms = []string{ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256}
+
+// Checksum is the additional checksum of an object or a part, Value is base64 encoded,
+// checksums of multipart objects are checksums of part checksums, suffixed with "-<parts count>"
+type Checksum struct {
+	Algorithm string `json:",omitempty"`
+	Value     string `json:",omitempty"`
+	Type      string `json:",omitempty"`
+}
+
+func (c Checksum) IsSet() bool {
+	return c.Algorithm != "" && c.Value != ""
+}
+
+// ChecksumHeader returns header name of algorithm, e.g "X-Amz-Checksum-Crc32c"
+func ChecksumHeader(algorithm string) string {
+	return http.CanonicalHeaderKey("X-Amz-Checksum-" + strings.ToLower(algorithm))
+}
+
+func IsValidChecksumAlgorithm(algorithm string) bool {
+	for _, a := range ChecksumAlgorithms {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func NewChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case ChecksumSHA1:
+		return sha1.New()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// ParseChecksumHeaders returns algorithm and expected value of the request,
+// value is empty if only x-amz-sdk-checksum-algorithm is sent
+func ParseChecksumHeaders(header http.Header) (checksum Checksum, err error) {
+	for _, algorithm := range ChecksumAlgorithms {
+		value := header.Get(ChecksumHeader(algorithm))
+		if value == "" {
+			continue
+		}
+		if checksum.Algorithm != "" {
+			// only one checksum is allowed
+			return checksum, ErrInvalidChecksum
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(decoded) != NewChecksumHash(algorithm).Size() {
+			return checksum, ErrInvalidChecksum
+		}
+		checksum.Algorithm = algorithm
+		checksum.Value = value
+	}
+	sdkAlgorithm := strings.ToUpper(header.Get("X-Amz-Sdk-Checksum-Algorithm"))
+	if sdkAlgorithm != "" {
+		if !IsValidChecksumAlgorithm(sdkAlgorithm) {
+			return checksum, ErrInvalidChecksum
+		}
+		if checksum.Algorithm != "" && checksum.Algorithm != sdkAlgorithm {
+			return checksum, ErrInvalidChecksum
+		}
+		checksum.Algorithm = sdkAlgorithm
+	}
+	if checksum.Algorithm != "" {
+		checksum.Type = ChecksumTypeFullObject
+	}
+	return checksum, nil
+}
+
+// ParseChecksumAlgorithmHeader parses x-amz-checksum-algorithm of CreateMultipartUpload
+// and CopyObject, returns empty string if not set
+func ParseChecksumAlgorithmHeader(header http.Header) (string, error) {
+	algorithm := strings.ToUpper(header.Get("X-Amz-Checksum-Algorithm"))
+	if algorithm != "" && !IsValidChecksumAlgorithm(algorithm) {
+		return "", ErrInvalidChecksum
+	}
+	return algorithm, nil
+}
+
+// CompositeChecksum calculates checksum of multipart object from checksums of parts,
+// which is checksum of concatenated binary part checksums
+func CompositeChecksum(algorithm string, partChecksums []string) (checksum Checksum, err error) {
+	h := NewChecksumHash(algorithm)
+	if h == nil {
+		return checksum, ErrInvalidChecksum
+	}
+	for _, partChecksum := range partChecksums {
+		decoded, err := base64.StdEncoding.DecodeString(partChecksum)
+		if err != nil {
+			return checksum, ErrInvalidChecksum
+		}
+		h.Write(decoded)
+	}
+	return Checksum{
+		Algorithm: algorithm,
+		Value: base64.StdEncoding.EncodeToString(h.Sum(nil)) + "-" +
+			strconv.Itoa(len(partChecksums)),
+		Type: ChecksumTypeComposite,
+	}, nil
+}
+
+// ObjectChecksum is the Checksum element of GetObjectAttributes and CompleteMultipartUpload
+type ObjectChecksum struct {
+	ChecksumCRC32  string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumSHA1   string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256,omitempty"`
+}
+
+func NewObjectChecksum(checksum Checksum) (c ObjectChecksum) {
+	switch checksum.Algorithm {
+	case ChecksumCRC32:
+		c.ChecksumCRC32 = checksum.Value
+	case ChecksumCRC32C:
+		c.ChecksumCRC32C = checksum.Value
+	case ChecksumSHA1:
+		c.ChecksumSHA1 = checksum.Value
+	case ChecksumSHA256:
+		c.ChecksumSHA256 = checksum.Value
+	}
+	return
+}
+
+// Value returns checksum of algorithm
+func (c ObjectChecksum) Value(algorithm string) string {
+	switch algorithm {
+	case ChecksumCRC32:
+		return c.ChecksumCRC32
+	case ChecksumCRC32C:
+		return c.ChecksumCRC32C
+	case ChecksumSHA1:
+		return c.ChecksumSHA1
+	case ChecksumSHA256:
+		return c.ChecksumSHA256
+	}
+	return ""
+}
+
+const (
+	ObjectAttributeETag         = "ETag"
+	ObjectAttributeChecksum     = "Checksum"
+	ObjectAttributeObjectParts  = "ObjectParts"
+	ObjectAttributeStorageClass = "StorageClass"
+	ObjectAttributeObjectSize   = "ObjectSize"
+
+	MaxObjectAttributesParts = 1000
+)
+
+// ParseObjectAttributesHeader parses x-amz-object-attributes, which is required
+func ParseObjectAttributesHeader(header http.Header) (attributes map[string]bool, err error) {
+	attributes = make(map[string]bool)
+	for _, value := range header["X-Amz-Object-Attributes"] {
+		for _, attribute := range strings.Split(value, ",") {
+			attribute = strings.TrimSpace(attribute)
+			switch attribute {
+			case ObjectAttributeETag, ObjectAttributeChecksum, ObjectAttributeObjectParts,
+				ObjectAttributeStorageClass, ObjectAttributeObjectSize:
+				attributes[attribute] = true
+			default:
+				return nil, ErrInvalidObjectAttributes
+			}
+		}
+	}
+	if len(attributes) == 0 {
+		return nil, ErrInvalidObjectAttributes
+	}
+	return attributes, nil
+}
+
+type ObjectAttributesPart struct {
+	PartNumber int   `xml:"PartNumber"`
+	Size       int64 `xml:"Size"`
+	ObjectChecksum
+}
+
+type ObjectAttributesParts struct {
+	TotalPartsCount      int                    `xml:"TotalPartsCount"`
+	PartNumberMarker     int                    `xml:"PartNumberMarker"`
+	NextPartNumberMarker int                    `xml:"NextPartNumberMarker"`
+	MaxParts             int                    `xml:"MaxParts"`
+	IsTruncated          bool                   `xml:"IsTruncated"`
+	Parts                []ObjectAttributesPart `xml:"Part"`
+}
+
+type GetObjectAttributesResponse struct {
+	XMLName      xml.Name               `xml:"GetObjectAttributesResponse"`
+	Xmlns        string                 `xml:"xmlns,attr,omitempty"`
+	ETag         string                 `xml:"ETag,omitempty"`
+	Checksum     *ObjectChecksum        `xml:"Checksum,omitempty"`
+	ObjectParts  *ObjectAttributesParts `xml:"ObjectParts,omitempty"`
+	StorageClass string                 `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64                 `xml:"ObjectSize,omitempty"`
+}
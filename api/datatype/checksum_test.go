@@ -0,0 +1,93 @@
+package datatype
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/journeymidnight/yig/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChecksumHeaders(t *testing.T) {
+	cases := []struct {
+		name     string
+		headers  map[string]string
+		checksum Checksum
+		err      error
+	}{
+		{"none", nil, Checksum{}, nil},
+		{"crc32", map[string]string{"X-Amz-Checksum-Crc32": "DUoRhQ=="},
+			Checksum{ChecksumCRC32, "DUoRhQ==", ChecksumTypeFullObject}, nil},
+		{"crc32c", map[string]string{"X-Amz-Checksum-Crc32c": "yZRlqg=="},
+			Checksum{ChecksumCRC32C, "yZRlqg==", ChecksumTypeFullObject}, nil},
+		{"sha1", map[string]string{"X-Amz-Checksum-Sha1": "Kq5sNclPz7QV2+lfQIuc6R7oRu0="},
+			Checksum{ChecksumSHA1, "Kq5sNclPz7QV2+lfQIuc6R7oRu0=", ChecksumTypeFullObject}, nil},
+		{"sha256 with sdk algorithm", map[string]string{
+			"X-Amz-Checksum-Sha256":        "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+			"X-Amz-Sdk-Checksum-Algorithm": "sha256"},
+			Checksum{ChecksumSHA256, "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", ChecksumTypeFullObject}, nil},
+		// value is calculated by trailer or not sent at all
+		{"sdk algorithm only", map[string]string{"X-Amz-Sdk-Checksum-Algorithm": "CRC32"},
+			Checksum{Algorithm: ChecksumCRC32, Type: ChecksumTypeFullObject}, nil},
+		{"two checksums", map[string]string{"X-Amz-Checksum-Crc32": "DUoRhQ==",
+			"X-Amz-Checksum-Crc32c": "yZRlqg=="}, Checksum{}, ErrInvalidChecksum},
+		{"wrong length", map[string]string{"X-Amz-Checksum-Crc32": "Kq5sNclPz7QV2+lfQIuc6R7oRu0="},
+			Checksum{}, ErrInvalidChecksum},
+		{"not base64", map[string]string{"X-Amz-Checksum-Crc32": "not base64"}, Checksum{}, ErrInvalidChecksum},
+		{"unknown sdk algorithm", map[string]string{"X-Amz-Sdk-Checksum-Algorithm": "MD5"},
+			Checksum{}, ErrInvalidChecksum},
+		{"sdk algorithm mismatch", map[string]string{"X-Amz-Checksum-Crc32": "DUoRhQ==",
+			"X-Amz-Sdk-Checksum-Algorithm": "SHA1"}, Checksum{}, ErrInvalidChecksum},
+	}
+	for _, c := range cases {
+		header := make(http.Header)
+		for k, v := range c.headers {
+			header.Set(k, v)
+		}
+		checksum, err := ParseChecksumHeaders(header)
+		assert.Equal(t, c.err, err, c.name)
+		if err == nil {
+			assert.Equal(t, c.checksum, checksum, c.name)
+		}
+	}
+}
+
+func TestNewChecksumHash(t *testing.T) {
+	for _, algorithm := range ChecksumAlgorithms {
+		assert.NotNil(t, NewChecksumHash(algorithm), algorithm)
+	}
+	assert.Nil(t, NewChecksumHash("MD5"))
+	assert.Equal(t, "X-Amz-Checksum-Crc32c", ChecksumHeader(ChecksumCRC32C))
+}
+
+func TestCompositeChecksum(t *testing.T) {
+	// SHA256 of "hello " and "world"
+	checksum, err := CompositeChecksum(ChecksumSHA256, []string{
+		"XjI1qDRuWkWF+MWFYvUFK4/iajuxIuHpbHZ4SWTfxGE=",
+		"SG6kYiTRu0+2gPNPfJrZao8k7Ii+c+qOWmxlJg6cuKc=",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Checksum{ChecksumSHA256, "Zhie15keHg/OBlOZxcoF/BXCgYZaeimRvdZnwUZqkaQ=-2",
+		ChecksumTypeComposite}, checksum)
+
+	_, err = CompositeChecksum(ChecksumSHA256, []string{"not base64"})
+	assert.Equal(t, ErrInvalidChecksum, err)
+	_, err = CompositeChecksum("MD5", nil)
+	assert.Equal(t, ErrInvalidChecksum, err)
+}
+
+func TestParseChecksumAlgorithmHeader(t *testing.T) {
+	header := make(http.Header)
+	algorithm, err := ParseChecksumAlgorithmHeader(header)
+	assert.Nil(t, err)
+	assert.Equal(t, "", algorithm)
+
+	header.Set("X-Amz-Checksum-Algorithm", "crc32c")
+	algorithm, err = ParseChecksumAlgorithmHeader(header)
+	assert.Nil(t, err)
+	assert.Equal(t, ChecksumCRC32C, algorithm)
+
+	header.Set("X-Amz-Checksum-Algorithm", "md5")
+	_, err = ParseChecksumAlgorithmHeader(header)
+	assert.Equal(t, ErrInvalidChecksum, err)
+}
@@ -0,0 +1,53 @@
+package datatype
+
+import (
+	"net/http"
+	"strings"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+// WriteCondition is the If-Match and If-None-Match headers of PutObject
+// and CompleteMultipartUpload, which are checked in the transaction
+// that puts the object
+type WriteCondition struct {
+	// ETag the current object must have, "*" for any existing object
+	IfMatch string
+	// If-None-Match: *, object is created only if absent
+	IfNoneMatch bool
+}
+
+func ParseWriteCondition(header http.Header) (condition WriteCondition, err error) {
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		// only "*" is supported for writes
+		if ifNoneMatch != "*" {
+			return condition, ErrNotImplemented
+		}
+		condition.IfNoneMatch = true
+	}
+	if ifMatch := header.Get("If-Match"); ifMatch != "" {
+		condition.IfMatch = strings.TrimSuffix(strings.TrimPrefix(ifMatch, "\""), "\"")
+	}
+	return condition, nil
+}
+
+func (c WriteCondition) IsSet() bool {
+	return c.IfNoneMatch || c.IfMatch != ""
+}
+
+// Satisfied returns if the condition holds for current object,
+// a delete marker is the same as no object
+func (c WriteCondition) Satisfied(exists bool, etag string) bool {
+	if c.IfNoneMatch && exists {
+		return false
+	}
+	if c.IfMatch != "" {
+		if !exists {
+			return false
+		}
+		if c.IfMatch != "*" && c.IfMatch != etag {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package datatype
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+func TestParseWriteCondition(t *testing.T) {
+	var testcase = []struct {
+		header    map[string]string
+		condition WriteCondition
+		err       error
+	}{
+		{nil, WriteCondition{}, nil},
+		{map[string]string{"If-Match": "etag"}, WriteCondition{IfMatch: "etag"}, nil},
+		{map[string]string{"If-Match": "\"etag\""}, WriteCondition{IfMatch: "etag"}, nil},
+		{map[string]string{"If-Match": "*"}, WriteCondition{IfMatch: "*"}, nil},
+		{map[string]string{"If-None-Match": "*"}, WriteCondition{IfNoneMatch: true}, nil},
+		{map[string]string{"If-None-Match": "\"etag\""}, WriteCondition{}, ErrNotImplemented},
+	}
+	for _, c := range testcase {
+		header := http.Header{}
+		for k, v := range c.header {
+			header.Set(k, v)
+		}
+		condition, err := ParseWriteCondition(header)
+		if err != c.err {
+			t.Errorf("ParseWriteCondition(%v) error: %v, expected: %v", c.header, err, c.err)
+			continue
+		}
+		if err == nil && condition != c.condition {
+			t.Errorf("ParseWriteCondition(%v) = %+v, expected: %+v", c.header, condition, c.condition)
+		}
+		if err == nil && condition.IsSet() != (len(c.header) != 0) {
+			t.Errorf("IsSet of %+v = %v", condition, condition.IsSet())
+		}
+	}
+}
+
+func TestWriteConditionSatisfied(t *testing.T) {
+	var testcase = []struct {
+		condition WriteCondition
+		exists    bool
+		etag      string
+		satisfied bool
+	}{
+		{WriteCondition{}, false, "", true},
+		{WriteCondition{}, true, "etag", true},
+		{WriteCondition{IfMatch: "etag"}, true, "etag", true},
+		{WriteCondition{IfMatch: "etag"}, true, "other", false},
+		// absent object, or a delete marker
+		{WriteCondition{IfMatch: "etag"}, false, "", false},
+		{WriteCondition{IfMatch: "*"}, true, "other", true},
+		{WriteCondition{IfMatch: "*"}, false, "", false},
+		{WriteCondition{IfNoneMatch: true}, false, "", true},
+		{WriteCondition{IfNoneMatch: true}, true, "etag", false},
+		{WriteCondition{IfMatch: "etag", IfNoneMatch: true}, true, "etag", false},
+	}
+	for _, c := range testcase {
+		satisfied := c.condition.Satisfied(c.exists, c.etag)
+		if satisfied != c.satisfied {
+			t.Errorf("Satisfied of %+v for exists: %v, etag: %s = %v, expected: %v",
+				c.condition, c.exists, c.etag, satisfied, c.satisfied)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package datatype
 import (
 	"encoding/xml"
 	"github.com/dustin/go-humanize"
+	"github.com/journeymidnight/yig/crypto"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"io"
@@ -26,7 +27,7 @@ type Rule struct {
 
 type ApplyServerSideEncryptionByDefault struct {
 	XMLName        xml.Name `xml:"ApplyServerSideEncryptionByDefault"`
-	KMSMasterKeyID string   `xml:"KMSMasterKeyID"`
+	KMSMasterKeyID string   `xml:"KMSMasterKeyID,omitempty"`
 	SSEAlgorithm   string   `xml:"SSEAlgorithm"`
 }
 
@@ -50,6 +51,16 @@ func (e *EncryptionConfiguration) Validate() (error error) {
 					return ErrMissingSSEAlgorithmOrKMSMasterKeyIDInEncryptionRule
 				}
 			}
+			switch sseAlgorithm {
+			case crypto.SSEAlgorithmAES256:
+				// KMSMasterKeyID is only allowed with aws:kms
+				if masterKeyID != "" {
+					return ErrInvalidEncryptionMethod
+				}
+			case crypto.SSEAlgorithmKMS:
+			default:
+				return ErrInvalidEncryptionMethod
+			}
 		}
 	} else {
 		return ErrMissingRuleInEncryption
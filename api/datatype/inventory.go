@@ -0,0 +1,217 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxInventoryConfigurations = 1000
+	MaxInventoryListSize       = 100
+	MaxInventorySize           = 20 * humanize.KiByte
+
+	InventoryFrequencyDaily  = "Daily"
+	InventoryFrequencyWeekly = "Weekly"
+
+	InventoryVersionsAll     = "All"
+	InventoryVersionsCurrent = "Current"
+
+	InventoryFormatCSV = "CSV"
+)
+
+// Optional fields of inventory reports, in the order of columns.
+// ObjectType is not in AWS, it's "Normal", "Appendable" or "Multipart"
+var InventoryFields = []string{
+	"Size",
+	"LastModifiedDate",
+	"StorageClass",
+	"ETag",
+	"IsMultipartUploaded",
+	"ReplicationStatus",
+	"EncryptionStatus",
+	"ObjectLockRetainUntilDate",
+	"ObjectLockMode",
+	"ObjectLockLegalHoldStatus",
+	"ObjectType",
+}
+
+var inventoryIdPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+type InventoryConfiguration struct {
+	XMLName                xml.Name                 `xml:"InventoryConfiguration"`
+	Xmlns                  string                   `xml:"xmlns,attr,omitempty"`
+	Id                     string                   `xml:"Id"`
+	IsEnabled              bool                     `xml:"IsEnabled"`
+	Filter                 *InventoryFilter         `xml:"Filter,omitempty"`
+	Destination            InventoryDestination     `xml:"Destination"`
+	Schedule               InventorySchedule        `xml:"Schedule"`
+	IncludedObjectVersions string                   `xml:"IncludedObjectVersions"`
+	OptionalFields         *InventoryOptionalFields `xml:"OptionalFields,omitempty"`
+}
+
+type InventoryFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type InventoryDestination struct {
+	S3BucketDestination InventoryS3BucketDestination `xml:"S3BucketDestination"`
+}
+
+type InventoryS3BucketDestination struct {
+	AccountId string `xml:"AccountId,omitempty"`
+	// ARN of destination bucket, "arn:aws:s3:::<bucket>"
+	Bucket     string               `xml:"Bucket"`
+	Format     string               `xml:"Format"`
+	Prefix     string               `xml:"Prefix,omitempty"`
+	Encryption *InventoryEncryption `xml:"Encryption,omitempty"`
+}
+
+type InventoryEncryption struct {
+	SSES3  *InventorySSES3  `xml:"SSE-S3,omitempty"`
+	SSEKMS *InventorySSEKMS `xml:"SSE-KMS,omitempty"`
+}
+
+type InventorySSES3 struct{}
+
+type InventorySSEKMS struct {
+	KeyId string `xml:"KeyId"`
+}
+
+type InventorySchedule struct {
+	Frequency string `xml:"Frequency"`
+}
+
+type InventoryOptionalFields struct {
+	Fields []string `xml:"Field"`
+}
+
+type ListInventoryConfigurationsResult struct {
+	XMLName                xml.Name                 `xml:"ListInventoryConfigurationsResult"`
+	Xmlns                  string                   `xml:"xmlns,attr,omitempty"`
+	InventoryConfiguration []InventoryConfiguration `xml:"InventoryConfiguration"`
+	IsTruncated            bool                     `xml:"IsTruncated"`
+	ContinuationToken      string                   `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken  string                   `xml:"NextContinuationToken,omitempty"`
+}
+
+func (c InventoryConfiguration) GetPrefix() string {
+	if c.Filter != nil {
+		return c.Filter.Prefix
+	}
+	return ""
+}
+
+// DestinationBucket parses ARN of destination bucket
+func (d InventoryS3BucketDestination) DestinationBucket() string {
+	parts := strings.Split(d.Bucket, ":")
+	return parts[len(parts)-1]
+}
+
+// Fields returns optional fields of configuration, in the order of columns
+func (c InventoryConfiguration) Fields() (fields []string) {
+	if c.OptionalFields == nil {
+		return nil
+	}
+	for _, field := range InventoryFields {
+		for _, f := range c.OptionalFields.Fields {
+			if f == field {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// SseRequest returns server side encryption of report files
+func (d InventoryS3BucketDestination) SseRequest() (sse SseRequest) {
+	if d.Encryption == nil {
+		return
+	}
+	if d.Encryption.SSEKMS != nil {
+		sse.Type = "SSE-KMS"
+		sse.SseAwsKmsKeyId = d.Encryption.SSEKMS.KeyId
+	} else if d.Encryption.SSES3 != nil {
+		sse.Type = "SSE-S3"
+	}
+	return
+}
+
+func (d InventoryS3BucketDestination) validate() error {
+	// arn:partition:service:region:account-id:resource
+	parts := strings.Split(d.Bucket, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "s3" || parts[5] == "" ||
+		strings.Contains(parts[5], "/") {
+		return ErrInvalidInventoryDestination
+	}
+	if d.Format != InventoryFormatCSV {
+		// ORC and Parquet
+		return ErrNotImplemented
+	}
+	if d.Encryption != nil {
+		if (d.Encryption.SSES3 == nil) == (d.Encryption.SSEKMS == nil) {
+			return ErrInvalidInventoryConfiguration
+		}
+	}
+	return nil
+}
+
+func (c InventoryConfiguration) Validate() error {
+	if !inventoryIdPattern.MatchString(c.Id) {
+		return ErrInvalidInventoryConfiguration
+	}
+	switch c.Schedule.Frequency {
+	case InventoryFrequencyDaily, InventoryFrequencyWeekly:
+	default:
+		return ErrInvalidInventoryConfiguration
+	}
+	switch c.IncludedObjectVersions {
+	case InventoryVersionsAll, InventoryVersionsCurrent:
+	default:
+		return ErrInvalidInventoryConfiguration
+	}
+	if c.OptionalFields != nil {
+		for _, f := range c.OptionalFields.Fields {
+			valid := false
+			for _, field := range InventoryFields {
+				if f == field {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return ErrInvalidInventoryConfiguration
+			}
+		}
+	}
+	return c.Destination.S3BucketDestination.validate()
+}
+
+func ParseInventoryConfig(reader io.Reader) (*InventoryConfiguration, error) {
+	inventoryConfig := new(InventoryConfiguration)
+	inventoryBuffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxInventorySize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read inventory body:", err)
+		return nil, err
+	}
+	if len(inventoryBuffer) > MaxInventorySize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(inventoryBuffer, inventoryConfig)
+	if err != nil {
+		helper.Logger.Error("Unable to parse inventory XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = inventoryConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return inventoryConfig, nil
+}
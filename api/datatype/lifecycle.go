@@ -1,19 +1,479 @@
 package datatype
 
 import (
+	"encoding/json"
 	"encoding/xml"
-	//	. "github.com/journeymidnight/yig/error"
-	//	"github.com/journeymidnight/yig/helper"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxLifecycleRulesCount = 1000
+	MaxLifecycleRuleIdLen  = 255
+	MaxLifecycleSize       = 20 * humanize.KiByte
+
+	MaxNewerNoncurrentVersions = 100
+
+	LifecycleRuleEnabled  = "Enabled"
+	LifecycleRuleDisabled = "Disabled"
 )
 
+// storage classes objects could be transitioned to, objects are only
+// transitioned to storage classes of higher rank
+var transitionStorageClasses = map[string]int{
+	"STANDARD_IA":  1,
+	"GLACIER":      2,
+	"DEEP_ARCHIVE": 3,
+}
+
+// LifecycleRule applies to objects matching Filter, or Prefix in old format
 type LifecycleRule struct {
-	ID         string `xml:"ID"`
-	Prefix     string `xml:"Prefix"`
-	Status     string `xml:"Status"`
-	Expiration string `xml:"Expiration>Days"`
+	ID                             string                          `xml:"ID,omitempty"`
+	Prefix                         string                          `xml:"Prefix,omitempty"`
+	Filter                         *LifecycleFilter                `xml:"Filter,omitempty"`
+	Status                         string                          `xml:"Status"`
+	Expiration                     *LifecycleExpiration            `xml:"Expiration,omitempty"`
+	Transition                     []LifecycleTransition           `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	NoncurrentVersionTransition    []NoncurrentVersionTransition   `xml:"NoncurrentVersionTransition,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleFilter contains only one of the conditions, conditions are combined with And
+type LifecycleFilter struct {
+	Prefix                string        `xml:"Prefix,omitempty"`
+	Tag                   *Tag          `xml:"Tag,omitempty"`
+	ObjectSizeGreaterThan int64         `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64         `xml:"ObjectSizeLessThan,omitempty"`
+	And                   *LifecycleAnd `xml:"And,omitempty"`
+}
+
+type LifecycleAnd struct {
+	Prefix                string `xml:"Prefix,omitempty"`
+	Tags                  []Tag  `xml:"Tag,omitempty"`
+	ObjectSizeGreaterThan int64  `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64  `xml:"ObjectSizeLessThan,omitempty"`
+}
+
+// AbortIncompleteMultipartUpload aborts multipart uploads not completed
+// DaysAfterInitiation days after initiated
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// LifecycleExpiration expires current versions Days after created or since Date,
+// ExpiredObjectDeleteMarker removes delete markers with no noncurrent versions
+type LifecycleExpiration struct {
+	Days                      int    `xml:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+// Expiration was stored as a string of days in older versions
+func (e *LifecycleExpiration) UnmarshalJSON(data []byte) (err error) {
+	var days string
+	if json.Unmarshal(data, &days) == nil {
+		if days != "" {
+			e.Days, err = strconv.Atoi(days)
+		}
+		return err
+	}
+	type expiration LifecycleExpiration
+	return json.Unmarshal(data, (*expiration)(e))
+}
+
+// LifecycleTransition moves objects to StorageClass after Days since
+// created, or since Date which is midnight UTC in ISO 8601 format
+type LifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty"`
+	Date         string `xml:"Date,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// NoncurrentVersionExpiration removes versions NoncurrentDays after they become
+// noncurrent, except the NewerNoncurrentVersions newest noncurrent versions
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
+}
+
+// NoncurrentVersionTransition moves versions to StorageClass NoncurrentDays after
+// they become noncurrent, except the NewerNoncurrentVersions newest noncurrent versions
+type NoncurrentVersionTransition struct {
+	NoncurrentDays          int    `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int    `xml:"NewerNoncurrentVersions,omitempty"`
+	StorageClass            string `xml:"StorageClass"`
 }
 
 type Lifecycle struct {
 	XMLName xml.Name        `xml:"LifecycleConfiguration"`
 	Rule    []LifecycleRule `xml:"Rule"`
 }
+
+// StorageClassRank returns how cold a storage class is, 0 for STANDARD
+func StorageClassRank(storageClass string) int {
+	return transitionStorageClasses[storageClass]
+}
+
+// dates in lifecycle rules must be midnight UTC in ISO 8601 format
+func parseLifecycleDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return date, ErrInvalidLc
+	}
+	return date.UTC(), nil
+}
+
+func validateLifecycleDate(value string) error {
+	date, err := parseLifecycleDate(value)
+	if err != nil {
+		return err
+	}
+	if !date.Equal(date.Truncate(24 * time.Hour)) {
+		return ErrInvalidLc
+	}
+	return nil
+}
+
+func (t LifecycleTransition) GetDate() (time.Time, error) {
+	return parseLifecycleDate(t.Date)
+}
+
+func (t LifecycleTransition) validate() error {
+	if (t.Days == 0) == (t.Date == "") {
+		return ErrInvalidLc
+	}
+	if t.Days < 0 {
+		return ErrInvalidLc
+	}
+	if t.Date != "" {
+		if err := validateLifecycleDate(t.Date); err != nil {
+			return err
+		}
+	}
+	if _, ok := transitionStorageClasses[t.StorageClass]; !ok {
+		return ErrInvalidStorageClass
+	}
+	return nil
+}
+
+func (r LifecycleRule) IsEnabled() bool {
+	return r.Status == LifecycleRuleEnabled
+}
+
+// GetPrefix returns the prefix objects must have to match the rule
+func (r LifecycleRule) GetPrefix() string {
+	if r.Filter == nil {
+		return r.Prefix
+	}
+	if r.Filter.And != nil {
+		return r.Filter.And.Prefix
+	}
+	return r.Filter.Prefix
+}
+
+func (r LifecycleRule) getTags() []Tag {
+	if r.Filter == nil {
+		return nil
+	}
+	if r.Filter.And != nil {
+		return r.Filter.And.Tags
+	}
+	if r.Filter.Tag != nil {
+		return []Tag{*r.Filter.Tag}
+	}
+	return nil
+}
+
+func (r LifecycleRule) getSizeRange() (greaterThan, lessThan int64) {
+	if r.Filter == nil {
+		return 0, 0
+	}
+	if r.Filter.And != nil {
+		return r.Filter.And.ObjectSizeGreaterThan, r.Filter.And.ObjectSizeLessThan
+	}
+	return r.Filter.ObjectSizeGreaterThan, r.Filter.ObjectSizeLessThan
+}
+
+// Match checks if an object version of size and tags matches the rule
+func (r LifecycleRule) Match(name string, size int64, tags map[string]string) bool {
+	if !strings.HasPrefix(name, r.GetPrefix()) {
+		return false
+	}
+	for _, tag := range r.getTags() {
+		if value, ok := tags[tag.Key]; !ok || value != tag.Value {
+			return false
+		}
+	}
+	greaterThan, lessThan := r.getSizeRange()
+	if greaterThan > 0 && size <= greaterThan {
+		return false
+	}
+	if lessThan > 0 && size >= lessThan {
+		return false
+	}
+	return true
+}
+
+// MatchUpload checks if a multipart upload matches the rule, which is filtered only by prefix
+func (r LifecycleRule) MatchUpload(name string) bool {
+	return strings.HasPrefix(name, r.GetPrefix())
+}
+
+// HasExpiration returns if current versions are expired by the rule
+func (r LifecycleRule) HasExpiration() bool {
+	return r.Expiration != nil && (r.Expiration.Days != 0 || r.Expiration.Date != "")
+}
+
+// ExpiresDeleteMarker returns if delete markers with no noncurrent versions are removed by the rule
+func (r LifecycleRule) ExpiresDeleteMarker() bool {
+	return r.Expiration != nil && r.Expiration.ExpiredObjectDeleteMarker
+}
+
+func (e LifecycleExpiration) GetDate() (time.Time, error) {
+	return parseLifecycleDate(e.Date)
+}
+
+func (e LifecycleExpiration) validate() error {
+	conditions := 0
+	if e.Days != 0 {
+		conditions++
+	}
+	if e.Date != "" {
+		conditions++
+	}
+	if e.ExpiredObjectDeleteMarker {
+		conditions++
+	}
+	// delete markers are expired along with Days or Date
+	if conditions != 1 {
+		return ErrMalformedXML
+	}
+	if e.Days < 0 {
+		return ErrInvalidLc
+	}
+	if e.Date != "" {
+		return validateLifecycleDate(e.Date)
+	}
+	return nil
+}
+
+func validateLifecycleSizeRange(greaterThan, lessThan int64) error {
+	if greaterThan < 0 || lessThan < 0 {
+		return ErrInvalidLc
+	}
+	if greaterThan > 0 && lessThan > 0 && greaterThan >= lessThan {
+		return ErrInvalidLc
+	}
+	return nil
+}
+
+func validateLifecycleTags(tags []Tag) error {
+	keys := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if err := validateTag(tag.Key, tag.Value); err != nil {
+			return err
+		}
+		if keys[tag.Key] {
+			return ErrInvalidTag
+		}
+		keys[tag.Key] = true
+	}
+	return nil
+}
+
+func (f LifecycleFilter) validate() error {
+	conditions := 0
+	if f.Prefix != "" {
+		conditions++
+	}
+	if f.Tag != nil {
+		conditions++
+		if err := validateLifecycleTags([]Tag{*f.Tag}); err != nil {
+			return err
+		}
+	}
+	if f.ObjectSizeGreaterThan != 0 {
+		conditions++
+	}
+	if f.ObjectSizeLessThan != 0 {
+		conditions++
+	}
+	if f.And != nil {
+		conditions++
+		if err := validateLifecycleTags(f.And.Tags); err != nil {
+			return err
+		}
+		if err := validateLifecycleSizeRange(f.And.ObjectSizeGreaterThan, f.And.ObjectSizeLessThan); err != nil {
+			return err
+		}
+	}
+	if conditions > 1 {
+		return ErrMalformedXML
+	}
+	return validateLifecycleSizeRange(f.ObjectSizeGreaterThan, f.ObjectSizeLessThan)
+}
+
+func (e NoncurrentVersionExpiration) validate() error {
+	if e.NoncurrentDays <= 0 {
+		return ErrInvalidLc
+	}
+	if e.NewerNoncurrentVersions < 0 || e.NewerNoncurrentVersions > MaxNewerNoncurrentVersions {
+		return ErrInvalidLc
+	}
+	return nil
+}
+
+func (t NoncurrentVersionTransition) validate() error {
+	if t.NoncurrentDays <= 0 {
+		return ErrInvalidLc
+	}
+	if t.NewerNoncurrentVersions < 0 || t.NewerNoncurrentVersions > MaxNewerNoncurrentVersions {
+		return ErrInvalidLc
+	}
+	if _, ok := transitionStorageClasses[t.StorageClass]; !ok {
+		return ErrInvalidStorageClass
+	}
+	return nil
+}
+
+func (r LifecycleRule) validateNoncurrent() error {
+	expirationDays := 0
+	if r.NoncurrentVersionExpiration != nil {
+		if err := r.NoncurrentVersionExpiration.validate(); err != nil {
+			return err
+		}
+		expirationDays = r.NoncurrentVersionExpiration.NoncurrentDays
+	}
+	for i, transition := range r.NoncurrentVersionTransition {
+		if err := transition.validate(); err != nil {
+			return err
+		}
+		if expirationDays != 0 && transition.NoncurrentDays >= expirationDays {
+			return ErrInvalidLc
+		}
+		for _, other := range r.NoncurrentVersionTransition[:i] {
+			rank, otherRank := StorageClassRank(transition.StorageClass), StorageClassRank(other.StorageClass)
+			if rank == otherRank {
+				return ErrInvalidLc
+			}
+			if (transition.NoncurrentDays > other.NoncurrentDays) != (rank > otherRank) {
+				return ErrInvalidLc
+			}
+		}
+	}
+	return nil
+}
+
+func (r LifecycleRule) validate() error {
+	if len(r.ID) > MaxLifecycleRuleIdLen {
+		return ErrInvalidLc
+	}
+	if r.Status != LifecycleRuleEnabled && r.Status != LifecycleRuleDisabled {
+		return ErrMalformedXML
+	}
+	if r.Prefix != "" && r.Filter != nil {
+		return ErrMalformedXML
+	}
+	if r.Filter != nil {
+		if err := r.Filter.validate(); err != nil {
+			return err
+		}
+	}
+	if r.Expiration == nil && len(r.Transition) == 0 && r.NoncurrentVersionExpiration == nil &&
+		len(r.NoncurrentVersionTransition) == 0 && r.AbortIncompleteMultipartUpload == nil {
+		return ErrInvalidLc
+	}
+	expirationDays := 0
+	if r.Expiration != nil {
+		if err := r.Expiration.validate(); err != nil {
+			return err
+		}
+		expirationDays = r.Expiration.Days
+	}
+	// delete markers and multipart uploads have no tags or size
+	greaterThan, lessThan := r.getSizeRange()
+	filtered := len(r.getTags()) > 0 || greaterThan > 0 || lessThan > 0
+	if filtered && (r.ExpiresDeleteMarker() || r.AbortIncompleteMultipartUpload != nil) {
+		return ErrInvalidLc
+	}
+	if r.AbortIncompleteMultipartUpload != nil && r.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+		return ErrInvalidLc
+	}
+	// transitions are either all by days or all by date, and colder
+	// storage classes must come later
+	var byDays, byDate bool
+	for i, transition := range r.Transition {
+		if err := transition.validate(); err != nil {
+			return err
+		}
+		byDays = byDays || transition.Days != 0
+		byDate = byDate || transition.Date != ""
+		if expirationDays != 0 && transition.Days >= expirationDays {
+			return ErrInvalidLc
+		}
+		for _, other := range r.Transition[:i] {
+			rank, otherRank := StorageClassRank(transition.StorageClass), StorageClassRank(other.StorageClass)
+			if rank == otherRank {
+				return ErrInvalidLc
+			}
+			later := transition.Days > other.Days
+			if transition.Date != "" && other.Date != "" {
+				date, _ := transition.GetDate()
+				otherDate, _ := other.GetDate()
+				later = date.After(otherDate)
+			}
+			if later != (rank > otherRank) {
+				return ErrInvalidLc
+			}
+		}
+	}
+	if byDays && byDate {
+		return ErrInvalidLc
+	}
+	return r.validateNoncurrent()
+}
+
+func (lc Lifecycle) Validate() error {
+	if len(lc.Rule) == 0 || len(lc.Rule) > MaxLifecycleRulesCount {
+		return ErrMalformedXML
+	}
+	ids := make(map[string]bool, len(lc.Rule))
+	for _, rule := range lc.Rule {
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return ErrInvalidLc
+			}
+			ids[rule.ID] = true
+		}
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ParseLifecycleConfig(reader io.Reader) (*Lifecycle, error) {
+	lc := new(Lifecycle)
+	lcBuffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxLifecycleSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read lifecycle body:", err)
+		return nil, err
+	}
+	if len(lcBuffer) > MaxLifecycleSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(lcBuffer, lc)
+	if err != nil {
+		helper.Logger.Error("Unable to parse lifecycle XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	return lc, nil
+}
@@ -0,0 +1,209 @@
+package datatype
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/journeymidnight/yig/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLifecycleTransition(t *testing.T) {
+	const header = `<LifecycleConfiguration><Rule><ID>rule</ID><Status>Enabled</Status>`
+	const footer = `</Rule></LifecycleConfiguration>`
+	cases := []struct {
+		name  string
+		rule  string
+		valid error
+	}{
+		{"days", `<Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>`, nil},
+		{"date", `<Transition><Date>2020-01-01T00:00:00Z</Date><StorageClass>GLACIER</StorageClass></Transition>`, nil},
+		{"colder later", `<Transition><Days>30</Days><StorageClass>STANDARD_IA</StorageClass></Transition>` +
+			`<Transition><Days>60</Days><StorageClass>GLACIER</StorageClass></Transition>`, nil},
+		{"before expiration", `<Expiration><Days>60</Days></Expiration>` +
+			`<Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>`, nil},
+		{"noncurrent", `<NoncurrentVersionTransition><NoncurrentDays>30</NoncurrentDays>` +
+			`<StorageClass>GLACIER</StorageClass></NoncurrentVersionTransition>`, nil},
+		{"no days or date", `<Transition><StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"days and date", `<Transition><Days>30</Days><Date>2020-01-01T00:00:00Z</Date>` +
+			`<StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"negative days", `<Transition><Days>-1</Days><StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"date not midnight", `<Transition><Date>2020-01-01T08:00:00Z</Date>` +
+			`<StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"standard", `<Transition><Days>30</Days><StorageClass>STANDARD</StorageClass></Transition>`,
+			ErrInvalidStorageClass},
+		{"same storage class", `<Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>` +
+			`<Transition><Days>60</Days><StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"colder earlier", `<Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>` +
+			`<Transition><Days>60</Days><StorageClass>STANDARD_IA</StorageClass></Transition>`, ErrInvalidLc},
+		{"days and date mixed", `<Transition><Days>30</Days><StorageClass>STANDARD_IA</StorageClass></Transition>` +
+			`<Transition><Date>2020-01-01T00:00:00Z</Date><StorageClass>GLACIER</StorageClass></Transition>`,
+			ErrInvalidLc},
+		{"after expiration", `<Expiration><Days>30</Days></Expiration>` +
+			`<Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition>`, ErrInvalidLc},
+		{"noncurrent no days", `<NoncurrentVersionTransition><StorageClass>GLACIER</StorageClass>` +
+			`</NoncurrentVersionTransition>`, ErrInvalidLc},
+		{"noncurrent after expiration", `<NoncurrentVersionExpiration><NoncurrentDays>30</NoncurrentDays>` +
+			`</NoncurrentVersionExpiration><NoncurrentVersionTransition><NoncurrentDays>30</NoncurrentDays>` +
+			`<StorageClass>GLACIER</StorageClass></NoncurrentVersionTransition>`, ErrInvalidLc},
+	}
+	for _, c := range cases {
+		lc, err := ParseLifecycleConfig(strings.NewReader(header + c.rule + footer))
+		if !assert.Nil(t, err, c.name) {
+			continue
+		}
+		assert.Equal(t, c.valid, lc.Validate(), c.name)
+	}
+}
+
+func TestParseLifecycleTransitionFields(t *testing.T) {
+	lc, err := ParseLifecycleConfig(strings.NewReader(`<LifecycleConfiguration><Rule>` +
+		`<Status>Enabled</Status><Filter><Prefix>logs/</Prefix></Filter>` +
+		`<Transition><Date>2020-01-01T00:00:00Z</Date><StorageClass>GLACIER</StorageClass></Transition>` +
+		`</Rule></LifecycleConfiguration>`))
+	assert.Nil(t, err)
+	assert.Nil(t, lc.Validate())
+	transition := lc.Rule[0].Transition[0]
+	assert.Equal(t, "GLACIER", transition.StorageClass)
+	date, err := transition.GetDate()
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-01-01T00:00:00Z", date.Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, 2, StorageClassRank(transition.StorageClass))
+	assert.Equal(t, 0, StorageClassRank("STANDARD"))
+}
+
+// validateRule parses and validates lifecycle configuration of a rule
+func validateRule(t *testing.T, rule string) error {
+	lc, err := ParseLifecycleConfig(strings.NewReader(`<LifecycleConfiguration><Rule>` +
+		`<Status>Enabled</Status>` + rule + `</Rule></LifecycleConfiguration>`))
+	if !assert.Nil(t, err, rule) {
+		return err
+	}
+	return lc.Validate()
+}
+
+func TestValidateLifecycleNoncurrent(t *testing.T) {
+	cases := []struct {
+		rule  string
+		valid error
+	}{
+		{`<NoncurrentVersionExpiration><NoncurrentDays>30</NoncurrentDays></NoncurrentVersionExpiration>`, nil},
+		{`<NoncurrentVersionExpiration><NoncurrentDays>30</NoncurrentDays>` +
+			`<NewerNoncurrentVersions>5</NewerNoncurrentVersions></NoncurrentVersionExpiration>`, nil},
+		{`<NoncurrentVersionExpiration><NoncurrentDays>0</NoncurrentDays></NoncurrentVersionExpiration>`,
+			ErrInvalidLc},
+		{`<NoncurrentVersionExpiration><NoncurrentDays>30</NoncurrentDays>` +
+			`<NewerNoncurrentVersions>101</NewerNoncurrentVersions></NoncurrentVersionExpiration>`, ErrInvalidLc},
+		{`<Expiration><ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker></Expiration>`, nil},
+		// delete markers are expired along with Days
+		{`<Expiration><Days>30</Days><ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker></Expiration>`,
+			ErrMalformedXML},
+		{`<Expiration></Expiration>`, ErrMalformedXML},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.valid, validateRule(t, c.rule), c.rule)
+	}
+}
+
+func TestLifecycleRuleExpiresDeleteMarker(t *testing.T) {
+	rule := LifecycleRule{Expiration: &LifecycleExpiration{ExpiredObjectDeleteMarker: true}}
+	assert.True(t, rule.ExpiresDeleteMarker())
+	assert.False(t, rule.HasExpiration())
+
+	rule = LifecycleRule{Expiration: &LifecycleExpiration{Days: 30}}
+	assert.False(t, rule.ExpiresDeleteMarker())
+	assert.True(t, rule.HasExpiration())
+
+	rule = LifecycleRule{NoncurrentVersionExpiration: &NoncurrentVersionExpiration{NoncurrentDays: 30}}
+	assert.False(t, rule.ExpiresDeleteMarker())
+	assert.False(t, rule.HasExpiration())
+}
+
+func TestLifecycleExpirationUnmarshalJSON(t *testing.T) {
+	// expiration was stored as a string of days
+	var rule LifecycleRule
+	assert.Nil(t, json.Unmarshal([]byte(`{"Expiration":"30"}`), &rule))
+	assert.Equal(t, 30, rule.Expiration.Days)
+
+	rule = LifecycleRule{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"Expiration":{"ExpiredObjectDeleteMarker":true}}`), &rule))
+	assert.True(t, rule.ExpiresDeleteMarker())
+}
+
+func TestValidateLifecycleFilter(t *testing.T) {
+	cases := []struct {
+		rule  string
+		valid error
+	}{
+		{`<Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>1</Days></Expiration>`, nil},
+		{`<Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter><Expiration><Days>1</Days></Expiration>`, nil},
+		{`<Filter><And><Prefix>logs/</Prefix><Tag><Key>a</Key><Value>1</Value></Tag>` +
+			`<Tag><Key>b</Key><Value>2</Value></Tag><ObjectSizeGreaterThan>10</ObjectSizeGreaterThan>` +
+			`</And></Filter><Expiration><Days>1</Days></Expiration>`, nil},
+		{`<Filter></Filter><Expiration><Date>2020-01-01T00:00:00Z</Date></Expiration>`, nil},
+		{`<AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation>` +
+			`</AbortIncompleteMultipartUpload>`, nil},
+		// conditions must be combined with And
+		{`<Filter><Prefix>logs/</Prefix><Tag><Key>k</Key><Value>v</Value></Tag></Filter>` +
+			`<Expiration><Days>1</Days></Expiration>`, ErrMalformedXML},
+		{`<Prefix>logs/</Prefix><Filter><Prefix>logs/</Prefix></Filter>` +
+			`<Expiration><Days>1</Days></Expiration>`, ErrMalformedXML},
+		{`<Filter><And><Tag><Key>a</Key><Value>1</Value></Tag><Tag><Key>a</Key><Value>2</Value></Tag>` +
+			`</And></Filter><Expiration><Days>1</Days></Expiration>`, ErrInvalidTag},
+		{`<Filter><And><ObjectSizeGreaterThan>10</ObjectSizeGreaterThan>` +
+			`<ObjectSizeLessThan>10</ObjectSizeLessThan></And></Filter>` +
+			`<Expiration><Days>1</Days></Expiration>`, ErrInvalidLc},
+		{`<Filter><ObjectSizeLessThan>-1</ObjectSizeLessThan></Filter>` +
+			`<Expiration><Days>1</Days></Expiration>`, ErrInvalidLc},
+		// multipart uploads have no tags
+		{`<Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter><AbortIncompleteMultipartUpload>` +
+			`<DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload>`, ErrInvalidLc},
+		{`<AbortIncompleteMultipartUpload><DaysAfterInitiation>0</DaysAfterInitiation>` +
+			`</AbortIncompleteMultipartUpload>`, ErrInvalidLc},
+		{`<Expiration><Date>2020-01-01T12:00:00Z</Date></Expiration>`, ErrInvalidLc},
+		{`<Expiration><Date>2020-01-01</Date></Expiration>`, ErrInvalidLc},
+		{`<Expiration><Days>1</Days><Date>2020-01-01T00:00:00Z</Date></Expiration>`, ErrMalformedXML},
+		{`<Filter><Prefix>logs/</Prefix></Filter>`, ErrInvalidLc},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.valid, validateRule(t, c.rule), c.rule)
+	}
+}
+
+func TestLifecycleRuleMatch(t *testing.T) {
+	rule := LifecycleRule{Filter: &LifecycleFilter{And: &LifecycleAnd{
+		Prefix:                "logs/",
+		Tags:                  []Tag{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		ObjectSizeGreaterThan: 10,
+		ObjectSizeLessThan:    100,
+	}}}
+	tags := map[string]string{"a": "1", "b": "2", "c": "3"}
+	assert.True(t, rule.Match("logs/1", 50, tags))
+	assert.False(t, rule.Match("data/1", 50, tags))
+	assert.False(t, rule.Match("logs/1", 10, tags))
+	assert.False(t, rule.Match("logs/1", 100, tags))
+	assert.False(t, rule.Match("logs/1", 50, map[string]string{"a": "1"}))
+	assert.False(t, rule.Match("logs/1", 50, map[string]string{"a": "1", "b": "3"}))
+	// multipart uploads are only filtered by prefix
+	assert.True(t, rule.MatchUpload("logs/1"))
+	assert.False(t, rule.MatchUpload("data/1"))
+
+	rule = LifecycleRule{Prefix: "logs/"}
+	assert.Equal(t, "logs/", rule.GetPrefix())
+	assert.True(t, rule.Match("logs/1", 0, nil))
+
+	rule = LifecycleRule{Filter: &LifecycleFilter{Tag: &Tag{Key: "a", Value: "1"}}}
+	assert.Equal(t, "", rule.GetPrefix())
+	assert.True(t, rule.Match("any", 0, tags))
+	assert.False(t, rule.Match("any", 0, nil))
+}
+
+func TestLifecycleExpirationDate(t *testing.T) {
+	expiration := LifecycleExpiration{Date: "2020-01-01T00:00:00+08:00"}
+	date, err := expiration.GetDate()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, 12, 31, 16, 0, 0, 0, time.UTC), date)
+	assert.Equal(t, ErrInvalidLc, expiration.validate())
+}
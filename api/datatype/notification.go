@@ -0,0 +1,300 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxNotificationSize        = 20 * humanize.KiByte
+	MaxNotificationFilterValue = 1024
+
+	NotificationQueueService = "sqs"
+	NotificationTopicService = "sns"
+)
+
+// Event names in S3 event records, configured with prefix "s3:" in notification configuration
+const (
+	ObjectCreatedPut                     = "ObjectCreated:Put"
+	ObjectCreatedPost                    = "ObjectCreated:Post"
+	ObjectCreatedCopy                    = "ObjectCreated:Copy"
+	ObjectCreatedCompleteMultipartUpload = "ObjectCreated:CompleteMultipartUpload"
+	ObjectRemovedDelete                  = "ObjectRemoved:Delete"
+	ObjectRemovedDeleteMarkerCreated     = "ObjectRemoved:DeleteMarkerCreated"
+	ObjectRestoreCompleted               = "ObjectRestore:Completed"
+)
+
+var supportedNotificationEvents = map[string]bool{
+	"s3:ObjectCreated:*":                         true,
+	"s3:" + ObjectCreatedPut:                     true,
+	"s3:" + ObjectCreatedPost:                    true,
+	"s3:" + ObjectCreatedCopy:                    true,
+	"s3:" + ObjectCreatedCompleteMultipartUpload: true,
+	"s3:ObjectRemoved:*":                         true,
+	"s3:" + ObjectRemovedDelete:                  true,
+	"s3:" + ObjectRemovedDeleteMarkerCreated:     true,
+	"s3:ObjectRestore:*":                         true,
+	"s3:" + ObjectRestoreCompleted:               true,
+}
+
+type NotificationConfiguration struct {
+	XMLName                     xml.Name                     `xml:"NotificationConfiguration"`
+	Xmlns                       string                       `xml:"xmlns,attr,omitempty"`
+	QueueConfigurations         []QueueConfiguration         `xml:"QueueConfiguration,omitempty"`
+	TopicConfigurations         []TopicConfiguration         `xml:"TopicConfiguration,omitempty"`
+	CloudFunctionConfigurations []CloudFunctionConfiguration `xml:"CloudFunctionConfiguration,omitempty" json:"-"`
+}
+
+type QueueConfiguration struct {
+	Id     string              `xml:"Id,omitempty"`
+	Queue  string              `xml:"Queue"`
+	Events []string            `xml:"Event"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+type TopicConfiguration struct {
+	Id     string              `xml:"Id,omitempty"`
+	Topic  string              `xml:"Topic"`
+	Events []string            `xml:"Event"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+// Lambda functions are not supported, only used to reject such configurations
+type CloudFunctionConfiguration struct {
+	Id string `xml:"Id,omitempty"`
+}
+
+type NotificationFilter struct {
+	S3Key S3KeyFilter `xml:"S3Key"`
+}
+
+type S3KeyFilter struct {
+	FilterRules []FilterRule `xml:"FilterRule"`
+}
+
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// NotificationTarget is the common view of queue and topic configurations
+type NotificationTarget struct {
+	Id     string
+	Arn    string
+	Events []string
+	Filter *NotificationFilter
+}
+
+func (n NotificationConfiguration) Targets() []NotificationTarget {
+	targets := make([]NotificationTarget, 0, len(n.QueueConfigurations)+len(n.TopicConfigurations))
+	for _, q := range n.QueueConfigurations {
+		targets = append(targets, NotificationTarget{Id: q.Id, Arn: q.Queue, Events: q.Events, Filter: q.Filter})
+	}
+	for _, t := range n.TopicConfigurations {
+		targets = append(targets, NotificationTarget{Id: t.Id, Arn: t.Topic, Events: t.Events, Filter: t.Filter})
+	}
+	return targets
+}
+
+// MatchedTargets returns targets which subscribe event of objectName
+func (n NotificationConfiguration) MatchedTargets(eventName, objectName string) (targets []NotificationTarget) {
+	for _, target := range n.Targets() {
+		if target.matchEvent(eventName) && target.Filter.match(objectName) {
+			targets = append(targets, target)
+		}
+	}
+	return
+}
+
+func (t NotificationTarget) matchEvent(eventName string) bool {
+	for _, e := range t.Events {
+		if e == "s3:"+eventName {
+			return true
+		}
+		if strings.HasSuffix(e, ":*") && strings.HasPrefix("s3:"+eventName, strings.TrimSuffix(e, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginName returns name of the MQ plugin which events are sent to,
+// i.e. the resource part of target ARN "arn:aws:sqs:<region>:<account>:<plugin-name>"
+func (t NotificationTarget) PluginName() string {
+	return t.Arn[strings.LastIndex(t.Arn, ":")+1:]
+}
+
+func (f *NotificationFilter) match(objectName string) bool {
+	if f == nil {
+		return true
+	}
+	for _, rule := range f.S3Key.FilterRules {
+		switch strings.ToLower(rule.Name) {
+		case "prefix":
+			if !strings.HasPrefix(objectName, rule.Value) {
+				return false
+			}
+		case "suffix":
+			if !strings.HasSuffix(objectName, rule.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f *NotificationFilter) validate() error {
+	if f == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, rule := range f.S3Key.FilterRules {
+		name := strings.ToLower(rule.Name)
+		if name != "prefix" && name != "suffix" {
+			return ErrFilterNameInvalid
+		}
+		if names[name] {
+			return ErrFilterNameInvalid
+		}
+		names[name] = true
+		if len(rule.Value) > MaxNotificationFilterValue {
+			return ErrFilterValueInvalid
+		}
+	}
+	return nil
+}
+
+func validateNotificationArn(arn, service string) error {
+	// arn:partition:service:region:account-id:resource
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != service || parts[5] == "" {
+		return ErrARNNotification
+	}
+	return nil
+}
+
+func (t NotificationTarget) validate(service string) error {
+	if err := validateNotificationArn(t.Arn, service); err != nil {
+		return err
+	}
+	if len(t.Events) == 0 {
+		return ErrEventNotification
+	}
+	for _, e := range t.Events {
+		if !supportedNotificationEvents[e] {
+			return ErrEventNotification
+		}
+	}
+	return t.Filter.validate()
+}
+
+func (n NotificationConfiguration) Validate() error {
+	if len(n.CloudFunctionConfigurations) > 0 {
+		return ErrNotImplemented
+	}
+	ids := make(map[string]bool)
+	for _, q := range n.QueueConfigurations {
+		target := NotificationTarget{Id: q.Id, Arn: q.Queue, Events: q.Events, Filter: q.Filter}
+		if err := target.validate(NotificationQueueService); err != nil {
+			return err
+		}
+	}
+	for _, t := range n.TopicConfigurations {
+		target := NotificationTarget{Id: t.Id, Arn: t.Topic, Events: t.Events, Filter: t.Filter}
+		if err := target.validate(NotificationTopicService); err != nil {
+			return err
+		}
+	}
+	for _, target := range n.Targets() {
+		if target.Id == "" {
+			continue
+		}
+		if ids[target.Id] {
+			return ErrOverlappingConfigs
+		}
+		ids[target.Id] = true
+	}
+	return nil
+}
+
+func ParseNotificationConfig(reader io.Reader) (*NotificationConfiguration, error) {
+	notificationConfig := new(NotificationConfiguration)
+	notificationBuffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxNotificationSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read notification body:", err)
+		return nil, err
+	}
+	if len(notificationBuffer) > MaxNotificationSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(notificationBuffer, notificationConfig)
+	if err != nil {
+		helper.Logger.Error("Unable to parse notification XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = notificationConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
+	// Assign an ID if not provided, as AWS does
+	for i := range notificationConfig.QueueConfigurations {
+		if notificationConfig.QueueConfigurations[i].Id == "" {
+			notificationConfig.QueueConfigurations[i].Id = string(helper.GenerateRandomId())
+		}
+	}
+	for i := range notificationConfig.TopicConfigurations {
+		if notificationConfig.TopicConfigurations[i].Id == "" {
+			notificationConfig.TopicConfigurations[i].Id = string(helper.GenerateRandomId())
+		}
+	}
+	return notificationConfig, nil
+}
+
+// S3 event message structure, see
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/notification-content-structure.html
+type EventRecords struct {
+	Records []EventRecord `json:"Records"`
+}
+
+type EventRecord struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AwsRegion         string            `json:"awsRegion"`
+	EventTime         string            `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      EventIdentity     `json:"userIdentity"`
+	RequestParameters map[string]string `json:"requestParameters,omitempty"`
+	ResponseElements  map[string]string `json:"responseElements,omitempty"`
+	S3                EventS3           `json:"s3"`
+}
+
+type EventIdentity struct {
+	PrincipalId string `json:"principalId"`
+}
+
+type EventS3 struct {
+	SchemaVersion   string      `json:"s3SchemaVersion"`
+	ConfigurationId string      `json:"configurationId"`
+	Bucket          EventBucket `json:"bucket"`
+	Object          EventObject `json:"object"`
+}
+
+type EventBucket struct {
+	Name          string        `json:"name"`
+	OwnerIdentity EventIdentity `json:"ownerIdentity"`
+	Arn           string        `json:"arn"`
+}
+
+type EventObject struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionId string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
@@ -0,0 +1,237 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxObjectLockSize = 4 * humanize.KiByte
+
+	ObjectLockEnabled = "Enabled"
+
+	RetentionModeGovernance = "GOVERNANCE"
+	RetentionModeCompliance = "COMPLIANCE"
+
+	LegalHoldOn  = "ON"
+	LegalHoldOff = "OFF"
+)
+
+// ObjectLockConfiguration is the bucket level Object Lock configuration,
+// retention of rule is applied to new object versions without retention headers
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name        `xml:"ObjectLockConfiguration"`
+	Xmlns             string          `xml:"xmlns,attr,omitempty"`
+	ObjectLockEnabled string          `xml:"ObjectLockEnabled,omitempty"`
+	Rule              *ObjectLockRule `xml:"Rule,omitempty"`
+}
+
+type ObjectLockRule struct {
+	DefaultRetention DefaultRetention `xml:"DefaultRetention"`
+}
+
+type DefaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+// ObjectRetention is the request and response body of "?retention"
+type ObjectRetention struct {
+	XMLName         xml.Name   `xml:"Retention"`
+	Xmlns           string     `xml:"xmlns,attr,omitempty"`
+	Mode            string     `xml:"Mode,omitempty"`
+	RetainUntilDate *time.Time `xml:"RetainUntilDate,omitempty"`
+}
+
+// ObjectLegalHold is the request and response body of "?legal-hold"
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
+
+// ObjectLock is the retention and legal hold of an object version
+type ObjectLock struct {
+	Mode            string    `json:",omitempty"`
+	RetainUntilDate time.Time `json:",omitempty"`
+	LegalHold       string    `json:",omitempty"`
+}
+
+func (c ObjectLockConfiguration) Enabled() bool {
+	return c.ObjectLockEnabled == ObjectLockEnabled
+}
+
+// DefaultObjectLock returns retention of new object versions created at now,
+// according to default retention of the rule
+func (c ObjectLockConfiguration) DefaultObjectLock(now time.Time) (lock ObjectLock) {
+	if !c.Enabled() || c.Rule == nil {
+		return
+	}
+	retention := c.Rule.DefaultRetention
+	lock.Mode = retention.Mode
+	lock.RetainUntilDate = now.AddDate(retention.Years, 0, retention.Days).UTC()
+	return
+}
+
+func (c ObjectLockConfiguration) Validate() error {
+	if c.ObjectLockEnabled != ObjectLockEnabled {
+		return ErrMalformedXML
+	}
+	if c.Rule == nil {
+		return nil
+	}
+	retention := c.Rule.DefaultRetention
+	if !validRetentionMode(retention.Mode) {
+		return ErrMalformedXML
+	}
+	if retention.Days != 0 && retention.Years != 0 {
+		return ErrMalformedXML
+	}
+	if retention.Days <= 0 && retention.Years <= 0 {
+		return ErrInvalidRetentionPeriod
+	}
+	return nil
+}
+
+func validRetentionMode(mode string) bool {
+	return mode == RetentionModeGovernance || mode == RetentionModeCompliance
+}
+
+// IsRetained returns if the version could not be deleted or overwritten
+// because of retention, at time now
+func (l ObjectLock) IsRetained(now time.Time) bool {
+	return l.Mode != "" && l.RetainUntilDate.After(now)
+}
+
+// IsSet returns if retention or legal hold is specified
+func (l ObjectLock) IsSet() bool {
+	return l.Mode != "" || l.LegalHold != ""
+}
+
+func (l ObjectLock) IsLegalHoldOn() bool {
+	return l.LegalHold == LegalHoldOn
+}
+
+func (l ObjectLock) Retention() ObjectRetention {
+	retention := ObjectRetention{Mode: l.Mode}
+	if l.Mode != "" {
+		date := l.RetainUntilDate
+		retention.RetainUntilDate = &date
+	}
+	return retention
+}
+
+func ParseObjectLockConfig(reader io.Reader) (*ObjectLockConfiguration, error) {
+	config := new(ObjectLockConfiguration)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxObjectLockSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read object lock body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxObjectLockSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, config)
+	if err != nil {
+		helper.Logger.Error("Unable to parse object lock XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ParseObjectRetention parses body of PUT "?retention", an empty retention
+// removes retention of the version
+func ParseObjectRetention(reader io.Reader) (*ObjectRetention, error) {
+	retention := new(ObjectRetention)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxObjectLockSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read retention body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxObjectLockSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, retention)
+	if err != nil {
+		helper.Logger.Error("Unable to parse retention XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	if retention.Mode == "" && retention.RetainUntilDate == nil {
+		return retention, nil
+	}
+	if retention.Mode == "" || retention.RetainUntilDate == nil {
+		return nil, ErrMalformedXML
+	}
+	if !validRetentionMode(retention.Mode) {
+		return nil, ErrUnknownWormModeDirective
+	}
+	if !retention.RetainUntilDate.After(time.Now()) {
+		return nil, ErrPastObjectLockRetainDate
+	}
+	return retention, nil
+}
+
+func ParseObjectLegalHold(reader io.Reader) (*ObjectLegalHold, error) {
+	legalHold := new(ObjectLegalHold)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxObjectLockSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read legal hold body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxObjectLockSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, legalHold)
+	if err != nil {
+		helper.Logger.Error("Unable to parse legal hold XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	if legalHold.Status != LegalHoldOn && legalHold.Status != LegalHoldOff {
+		return nil, ErrInvalidLegalHoldStatus
+	}
+	return legalHold, nil
+}
+
+// ParseObjectLockHeaders parses retention and legal hold headers of
+// PUT/POST/COPY object and initiate multipart upload
+func ParseObjectLockHeaders(header http.Header) (lock ObjectLock, err error) {
+	mode := header.Get("X-Amz-Object-Lock-Mode")
+	date := header.Get("X-Amz-Object-Lock-Retain-Until-Date")
+	if (mode == "") != (date == "") {
+		return lock, ErrObjectLockInvalidHeaders
+	}
+	if mode != "" {
+		if !validRetentionMode(mode) {
+			return lock, ErrUnknownWormModeDirective
+		}
+		lock.RetainUntilDate, err = time.Parse(time.RFC3339, date)
+		if err != nil {
+			return lock, ErrMalformedDate
+		}
+		if !lock.RetainUntilDate.After(time.Now()) {
+			return lock, ErrPastObjectLockRetainDate
+		}
+		lock.Mode = mode
+		lock.RetainUntilDate = lock.RetainUntilDate.UTC()
+	}
+	switch legalHold := header.Get("X-Amz-Object-Lock-Legal-Hold"); legalHold {
+	case "", LegalHoldOff:
+	case LegalHoldOn:
+		lock.LegalHold = legalHold
+	default:
+		return lock, ErrInvalidLegalHoldStatus
+	}
+	return lock, nil
+}
@@ -0,0 +1,84 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxOwnershipControlsSize = 4 * humanize.KiByte
+
+	// ACLs are disabled, the bucket owner owns all objects
+	ObjectOwnershipBucketOwnerEnforced = "BucketOwnerEnforced"
+	// the bucket owner owns new objects uploaded with canned ACL "bucket-owner-full-control"
+	ObjectOwnershipBucketOwnerPreferred = "BucketOwnerPreferred"
+	// the uploader owns new objects, which is the default
+	ObjectOwnershipObjectWriter = "ObjectWriter"
+)
+
+// OwnershipControls is the Object Ownership setting of a bucket
+type OwnershipControls struct {
+	XMLName xml.Name                `xml:"OwnershipControls"`
+	Xmlns   string                  `xml:"xmlns,attr,omitempty"`
+	Rules   []OwnershipControlsRule `xml:"Rule"`
+}
+
+type OwnershipControlsRule struct {
+	ObjectOwnership string `xml:"ObjectOwnership"`
+}
+
+// IsSet returns if Object Ownership is specified
+func (c OwnershipControls) IsSet() bool {
+	return len(c.Rules) > 0
+}
+
+// ObjectOwnership returns Object Ownership of the bucket, ObjectWriter if not specified
+func (c OwnershipControls) ObjectOwnership() string {
+	if !c.IsSet() {
+		return ObjectOwnershipObjectWriter
+	}
+	return c.Rules[0].ObjectOwnership
+}
+
+// AclsDisabled returns if ACLs of the bucket and its objects are disabled
+func (c OwnershipControls) AclsDisabled() bool {
+	return c.ObjectOwnership() == ObjectOwnershipBucketOwnerEnforced
+}
+
+func (c OwnershipControls) Validate() error {
+	if len(c.Rules) != 1 {
+		return ErrMalformedXML
+	}
+	switch c.Rules[0].ObjectOwnership {
+	case ObjectOwnershipBucketOwnerEnforced, ObjectOwnershipBucketOwnerPreferred, ObjectOwnershipObjectWriter:
+		return nil
+	}
+	return ErrMalformedXML
+}
+
+func ParseOwnershipControls(reader io.Reader) (*OwnershipControls, error) {
+	controls := new(OwnershipControls)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxOwnershipControlsSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read ownership controls body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxOwnershipControlsSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, controls)
+	if err != nil {
+		helper.Logger.Error("Unable to parse ownership controls XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = controls.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return controls, nil
+}
@@ -19,8 +19,10 @@ package policy
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/journeymidnight/yig/api/datatype/policy/condition"
+	"github.com/journeymidnight/yig/api/datatype/policy/utils"
 )
 
 // Action - policy action.
@@ -83,40 +85,351 @@ const (
 
 	// PutObjectAction - PutObject Rest API action.
 	PutObjectAction = "s3:PutObject"
+
+	// GetObjectTaggingAction - GetObjectTagging Rest API action.
+	GetObjectTaggingAction = "s3:GetObjectTagging"
+
+	// PutObjectTaggingAction - PutObjectTagging Rest API action.
+	PutObjectTaggingAction = "s3:PutObjectTagging"
+
+	// DeleteObjectTaggingAction - DeleteObjectTagging Rest API action.
+	DeleteObjectTaggingAction = "s3:DeleteObjectTagging"
+
+	// GetObjectRetentionAction - GetObjectRetention Rest API action.
+	GetObjectRetentionAction = "s3:GetObjectRetention"
+
+	// PutObjectRetentionAction - PutObjectRetention Rest API action.
+	PutObjectRetentionAction = "s3:PutObjectRetention"
+
+	// GetObjectLegalHoldAction - GetObjectLegalHold Rest API action.
+	GetObjectLegalHoldAction = "s3:GetObjectLegalHold"
+
+	// PutObjectLegalHoldAction - PutObjectLegalHold Rest API action.
+	PutObjectLegalHoldAction = "s3:PutObjectLegalHold"
+
+	// BypassGovernanceRetentionAction - permission to delete versions or shorten
+	// retention in GOVERNANCE mode, with header x-amz-bypass-governance-retention.
+	BypassGovernanceRetentionAction = "s3:BypassGovernanceRetention"
+
+	// GetObjectVersionAction - GetObject Rest API action with versionId.
+	GetObjectVersionAction = "s3:GetObjectVersion"
+
+	// DeleteObjectVersionAction - DeleteObject Rest API action with versionId.
+	DeleteObjectVersionAction = "s3:DeleteObjectVersion"
+
+	// GetObjectAclAction - GetObjectAcl Rest API action.
+	GetObjectAclAction = "s3:GetObjectAcl"
+
+	// PutObjectAclAction - PutObjectAcl Rest API action.
+	PutObjectAclAction = "s3:PutObjectAcl"
+
+	// GetObjectVersionAclAction - GetObjectAcl Rest API action with versionId.
+	GetObjectVersionAclAction = "s3:GetObjectVersionAcl"
+
+	// PutObjectVersionAclAction - PutObjectAcl Rest API action with versionId.
+	PutObjectVersionAclAction = "s3:PutObjectVersionAcl"
+
+	// GetObjectVersionTaggingAction - GetObjectTagging Rest API action with versionId.
+	GetObjectVersionTaggingAction = "s3:GetObjectVersionTagging"
+
+	// PutObjectVersionTaggingAction - PutObjectTagging Rest API action with versionId.
+	PutObjectVersionTaggingAction = "s3:PutObjectVersionTagging"
+
+	// DeleteObjectVersionTaggingAction - DeleteObjectTagging Rest API action with versionId.
+	DeleteObjectVersionTaggingAction = "s3:DeleteObjectVersionTagging"
+
+	// GetObjectAttributesAction - GetObjectAttributes Rest API action.
+	GetObjectAttributesAction = "s3:GetObjectAttributes"
+
+	// GetObjectVersionAttributesAction - GetObjectAttributes Rest API action with versionId.
+	GetObjectVersionAttributesAction = "s3:GetObjectVersionAttributes"
+
+	// GetObjectTorrentAction - GetObjectTorrent Rest API action.
+	GetObjectTorrentAction = "s3:GetObjectTorrent"
+
+	// GetObjectVersionTorrentAction - GetObjectTorrent Rest API action with versionId.
+	GetObjectVersionTorrentAction = "s3:GetObjectVersionTorrent"
+
+	// RestoreObjectAction - RestoreObject Rest API action.
+	RestoreObjectAction = "s3:RestoreObject"
+
+	// GetObjectVersionForReplicationAction - permission to read objects for replication.
+	GetObjectVersionForReplicationAction = "s3:GetObjectVersionForReplication"
+
+	// ReplicateObjectAction - permission to replicate objects to destination bucket.
+	ReplicateObjectAction = "s3:ReplicateObject"
+
+	// ReplicateDeleteAction - permission to replicate delete markers to destination bucket.
+	ReplicateDeleteAction = "s3:ReplicateDelete"
+
+	// ReplicateTagsAction - permission to replicate object tags to destination bucket.
+	ReplicateTagsAction = "s3:ReplicateTags"
+
+	// ObjectOwnerOverrideToBucketOwnerAction - permission to change replica ownership.
+	ObjectOwnerOverrideToBucketOwnerAction = "s3:ObjectOwnerOverrideToBucketOwner"
+
+	// GetBucketAclAction - GetBucketAcl Rest API action.
+	GetBucketAclAction = "s3:GetBucketAcl"
+
+	// PutBucketAclAction - PutBucketAcl Rest API action.
+	PutBucketAclAction = "s3:PutBucketAcl"
+
+	// GetBucketCORSAction - GetBucketCors Rest API action.
+	GetBucketCORSAction = "s3:GetBucketCORS"
+
+	// PutBucketCORSAction - PutBucketCors and DeleteBucketCors Rest API action.
+	PutBucketCORSAction = "s3:PutBucketCORS"
+
+	// GetBucketVersioningAction - GetBucketVersioning Rest API action.
+	GetBucketVersioningAction = "s3:GetBucketVersioning"
+
+	// PutBucketVersioningAction - PutBucketVersioning Rest API action.
+	PutBucketVersioningAction = "s3:PutBucketVersioning"
+
+	// ListBucketVersionsAction - ListObjectVersions Rest API action.
+	ListBucketVersionsAction = "s3:ListBucketVersions"
+
+	// GetBucketLoggingAction - GetBucketLogging Rest API action.
+	GetBucketLoggingAction = "s3:GetBucketLogging"
+
+	// PutBucketLoggingAction - PutBucketLogging Rest API action.
+	PutBucketLoggingAction = "s3:PutBucketLogging"
+
+	// GetLifecycleConfigurationAction - GetBucketLifecycle Rest API action.
+	GetLifecycleConfigurationAction = "s3:GetLifecycleConfiguration"
+
+	// PutLifecycleConfigurationAction - PutBucketLifecycle and DeleteBucketLifecycle Rest API action.
+	PutLifecycleConfigurationAction = "s3:PutLifecycleConfiguration"
+
+	// GetBucketWebsiteAction - GetBucketWebsite Rest API action.
+	GetBucketWebsiteAction = "s3:GetBucketWebsite"
+
+	// PutBucketWebsiteAction - PutBucketWebsite Rest API action.
+	PutBucketWebsiteAction = "s3:PutBucketWebsite"
+
+	// DeleteBucketWebsiteAction - DeleteBucketWebsite Rest API action.
+	DeleteBucketWebsiteAction = "s3:DeleteBucketWebsite"
+
+	// GetBucketTaggingAction - GetBucketTagging Rest API action.
+	GetBucketTaggingAction = "s3:GetBucketTagging"
+
+	// PutBucketTaggingAction - PutBucketTagging and DeleteBucketTagging Rest API action.
+	PutBucketTaggingAction = "s3:PutBucketTagging"
+
+	// GetEncryptionConfigurationAction - GetBucketEncryption Rest API action.
+	GetEncryptionConfigurationAction = "s3:GetEncryptionConfiguration"
+
+	// PutEncryptionConfigurationAction - PutBucketEncryption and DeleteBucketEncryption Rest API action.
+	PutEncryptionConfigurationAction = "s3:PutEncryptionConfiguration"
+
+	// GetReplicationConfigurationAction - GetBucketReplication Rest API action.
+	GetReplicationConfigurationAction = "s3:GetReplicationConfiguration"
+
+	// PutReplicationConfigurationAction - PutBucketReplication and DeleteBucketReplication Rest API action.
+	PutReplicationConfigurationAction = "s3:PutReplicationConfiguration"
+
+	// GetInventoryConfigurationAction - GetBucketInventoryConfiguration and ListBucketInventoryConfigurations Rest API action.
+	GetInventoryConfigurationAction = "s3:GetInventoryConfiguration"
+
+	// PutInventoryConfigurationAction - PutBucketInventoryConfiguration and DeleteBucketInventoryConfiguration Rest API action.
+	PutInventoryConfigurationAction = "s3:PutInventoryConfiguration"
+
+	// GetBucketObjectLockConfigurationAction - GetObjectLockConfiguration Rest API action.
+	GetBucketObjectLockConfigurationAction = "s3:GetBucketObjectLockConfiguration"
+
+	// PutBucketObjectLockConfigurationAction - PutObjectLockConfiguration Rest API action.
+	PutBucketObjectLockConfigurationAction = "s3:PutBucketObjectLockConfiguration"
+
+	// GetBucketPolicyStatusAction - GetBucketPolicyStatus Rest API action.
+	GetBucketPolicyStatusAction = "s3:GetBucketPolicyStatus"
+
+	// GetBucketPublicAccessBlockAction - GetPublicAccessBlock Rest API action.
+	GetBucketPublicAccessBlockAction = "s3:GetBucketPublicAccessBlock"
+
+	// PutBucketPublicAccessBlockAction - PutPublicAccessBlock and DeletePublicAccessBlock Rest API action.
+	PutBucketPublicAccessBlockAction = "s3:PutBucketPublicAccessBlock"
+
+	// GetBucketOwnershipControlsAction - GetBucketOwnershipControls Rest API action.
+	GetBucketOwnershipControlsAction = "s3:GetBucketOwnershipControls"
+
+	// PutBucketOwnershipControlsAction - PutBucketOwnershipControls and DeleteBucketOwnershipControls Rest API action.
+	PutBucketOwnershipControlsAction = "s3:PutBucketOwnershipControls"
+
+	// GetBucketRequestPaymentAction - GetBucketRequestPayment Rest API action.
+	GetBucketRequestPaymentAction = "s3:GetBucketRequestPayment"
+
+	// PutBucketRequestPaymentAction - PutBucketRequestPayment Rest API action.
+	PutBucketRequestPaymentAction = "s3:PutBucketRequestPayment"
+
+	// GetAccelerateConfigurationAction - GetBucketAccelerateConfiguration Rest API action.
+	GetAccelerateConfigurationAction = "s3:GetAccelerateConfiguration"
+
+	// PutAccelerateConfigurationAction - PutBucketAccelerateConfiguration Rest API action.
+	PutAccelerateConfigurationAction = "s3:PutAccelerateConfiguration"
+
+	// GetAnalyticsConfigurationAction - GetBucketAnalyticsConfiguration Rest API action.
+	GetAnalyticsConfigurationAction = "s3:GetAnalyticsConfiguration"
+
+	// PutAnalyticsConfigurationAction - PutBucketAnalyticsConfiguration Rest API action.
+	PutAnalyticsConfigurationAction = "s3:PutAnalyticsConfiguration"
+
+	// GetMetricsConfigurationAction - GetBucketMetricsConfiguration Rest API action.
+	GetMetricsConfigurationAction = "s3:GetMetricsConfiguration"
+
+	// PutMetricsConfigurationAction - PutBucketMetricsConfiguration Rest API action.
+	PutMetricsConfigurationAction = "s3:PutMetricsConfiguration"
+
+	// GetIntelligentTieringConfigurationAction - GetBucketIntelligentTieringConfiguration Rest API action.
+	GetIntelligentTieringConfigurationAction = "s3:GetIntelligentTieringConfiguration"
+
+	// PutIntelligentTieringConfigurationAction - PutBucketIntelligentTieringConfiguration Rest API action.
+	PutIntelligentTieringConfigurationAction = "s3:PutIntelligentTieringConfiguration"
+
+	// GetAccountPublicAccessBlockAction - GetPublicAccessBlock Rest API action of account.
+	GetAccountPublicAccessBlockAction = "s3:GetAccountPublicAccessBlock"
+
+	// PutAccountPublicAccessBlockAction - PutPublicAccessBlock Rest API action of account.
+	PutAccountPublicAccessBlockAction = "s3:PutAccountPublicAccessBlock"
 )
 
-// isObjectAction - returns whether action is object type or not.
-func (action Action) isObjectAction() bool {
-	switch action {
-	case AbortMultipartUploadAction, DeleteObjectAction, GetObjectAction:
-		fallthrough
-	case ListMultipartUploadPartsAction, PutObjectAction:
-		return true
+// supportedObjectActions - actions whose resources are objects.
+var supportedObjectActions = NewActionSet(
+	AbortMultipartUploadAction,
+	DeleteObjectAction,
+	DeleteObjectVersionAction,
+	GetObjectAction,
+	GetObjectVersionAction,
+	GetObjectAclAction,
+	PutObjectAclAction,
+	GetObjectVersionAclAction,
+	PutObjectVersionAclAction,
+	GetObjectTaggingAction,
+	PutObjectTaggingAction,
+	DeleteObjectTaggingAction,
+	GetObjectVersionTaggingAction,
+	PutObjectVersionTaggingAction,
+	DeleteObjectVersionTaggingAction,
+	GetObjectAttributesAction,
+	GetObjectVersionAttributesAction,
+	GetObjectTorrentAction,
+	GetObjectVersionTorrentAction,
+	GetObjectRetentionAction,
+	PutObjectRetentionAction,
+	GetObjectLegalHoldAction,
+	PutObjectLegalHoldAction,
+	BypassGovernanceRetentionAction,
+	ListMultipartUploadPartsAction,
+	PutObjectAction,
+	RestoreObjectAction,
+	GetObjectVersionForReplicationAction,
+	ReplicateObjectAction,
+	ReplicateDeleteAction,
+	ReplicateTagsAction,
+	ObjectOwnerOverrideToBucketOwnerAction,
+)
+
+// supportedBucketActions - actions whose resources are buckets. Actions of account
+// are also put here, since there're only bucket and object resources in bucket policy.
+var supportedBucketActions = NewActionSet(
+	CreateBucketAction,
+	DeleteBucketAction,
+	HeadBucketAction,
+	ListAllMyBucketsAction,
+	ListBucketAction,
+	ListBucketVersionsAction,
+	ListBucketMultipartUploadsAction,
+	GetBucketLocationAction,
+	GetBucketPolicyAction,
+	PutBucketPolicyAction,
+	DeleteBucketPolicyAction,
+	GetBucketPolicyStatusAction,
+	GetBucketAclAction,
+	PutBucketAclAction,
+	GetBucketCORSAction,
+	PutBucketCORSAction,
+	GetBucketVersioningAction,
+	PutBucketVersioningAction,
+	GetBucketLoggingAction,
+	PutBucketLoggingAction,
+	GetLifecycleConfigurationAction,
+	PutLifecycleConfigurationAction,
+	GetBucketWebsiteAction,
+	PutBucketWebsiteAction,
+	DeleteBucketWebsiteAction,
+	GetBucketTaggingAction,
+	PutBucketTaggingAction,
+	GetBucketNotificationAction,
+	PutBucketNotificationAction,
+	ListenBucketNotificationAction,
+	GetEncryptionConfigurationAction,
+	PutEncryptionConfigurationAction,
+	GetReplicationConfigurationAction,
+	PutReplicationConfigurationAction,
+	GetInventoryConfigurationAction,
+	PutInventoryConfigurationAction,
+	GetBucketObjectLockConfigurationAction,
+	PutBucketObjectLockConfigurationAction,
+	GetBucketPublicAccessBlockAction,
+	PutBucketPublicAccessBlockAction,
+	GetBucketOwnershipControlsAction,
+	PutBucketOwnershipControlsAction,
+	GetBucketRequestPaymentAction,
+	PutBucketRequestPaymentAction,
+	GetAccelerateConfigurationAction,
+	PutAccelerateConfigurationAction,
+	GetAnalyticsConfigurationAction,
+	PutAnalyticsConfigurationAction,
+	GetMetricsConfigurationAction,
+	PutMetricsConfigurationAction,
+	GetIntelligentTieringConfigurationAction,
+	PutIntelligentTieringConfigurationAction,
+	GetAccountPublicAccessBlockAction,
+	PutAccountPublicAccessBlockAction,
+)
+
+// isPattern - returns whether action contains wildcards, e.g. "s3:Get*" or "s3:*".
+func (action Action) isPattern() bool {
+	return strings.ContainsAny(string(action), "*?")
+}
+
+// Match - matches action against action pattern, action names are case insensitive.
+func (action Action) Match(a Action) bool {
+	if !action.isPattern() {
+		return strings.EqualFold(string(action), string(a))
 	}
+	return utils.Match(strings.ToLower(string(action)), strings.ToLower(string(a)))
+}
 
-	return false
+// expand - returns supported actions matched by action pattern.
+func (action Action) expand() ActionSet {
+	actions := NewActionSet()
+	for _, supported := range []ActionSet{supportedObjectActions, supportedBucketActions} {
+		for a := range supported {
+			if action.Match(a) {
+				actions.Add(a)
+			}
+		}
+	}
+	return actions
 }
 
-// IsValid - checks if action is valid or not.
+// isObjectAction - returns whether action is object type or not.
+func (action Action) isObjectAction() bool {
+	_, found := supportedObjectActions[action]
+	return found
+}
+
+// IsValid - checks if action is valid or not, action patterns are valid
+// if they match any supported action.
 func (action Action) IsValid() bool {
-	switch action {
-	case AbortMultipartUploadAction, CreateBucketAction, DeleteBucketAction:
-		fallthrough
-	case DeleteBucketPolicyAction, DeleteObjectAction, GetBucketLocationAction:
-		fallthrough
-	case GetBucketNotificationAction, GetBucketPolicyAction, GetObjectAction:
-		fallthrough
-	case HeadBucketAction, ListAllMyBucketsAction, ListBucketAction:
-		fallthrough
-	case ListBucketMultipartUploadsAction, ListenBucketNotificationAction:
-		fallthrough
-	case ListMultipartUploadPartsAction, PutBucketNotificationAction:
-		fallthrough
-	case PutBucketPolicyAction, PutObjectAction:
+	if action.isPattern() {
+		return len(action.expand()) > 0
+	}
+	if _, found := supportedObjectActions[action]; found {
 		return true
 	}
-
-	return false
+	_, found := supportedBucketActions[action]
+	return found
 }
 
 // MarshalJSON - encodes Action to JSON data.
@@ -136,8 +449,8 @@ func (action *Action) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	a := Action(s)
-	if !a.IsValid() {
+	a, err := parseAction(s)
+	if err != nil {
 		return fmt.Errorf("invalid action '%v'", s)
 	}
 
@@ -153,6 +466,13 @@ func parseAction(s string) (Action, error) {
 		return action, nil
 	}
 
+	// action names are case insensitive, e.g. "s3:getobject" is "s3:GetObject"
+	if expanded := action.expand(); len(expanded) == 1 {
+		for a := range expanded {
+			return a, nil
+		}
+	}
+
 	return action, fmt.Errorf("unsupported action '%v'", s)
 }
 
@@ -178,6 +498,12 @@ var actionConditionKeyMap = map[Action]condition.KeySet{
 		condition.AWSSourceIP,
 	),
 
+	DeleteObjectTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
 	GetBucketLocationAction: condition.NewKeySet(
 		condition.AWSReferer,
 		condition.AWSSourceIP,
@@ -197,6 +523,13 @@ var actionConditionKeyMap = map[Action]condition.KeySet{
 		condition.S3XAmzServerSideEncryption,
 		condition.S3XAmzServerSideEncryptionAwsKMSKeyID,
 		condition.S3XAmzStorageClass,
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
 		condition.AWSReferer,
 		condition.AWSSourceIP,
 	),
@@ -250,7 +583,162 @@ var actionConditionKeyMap = map[Action]condition.KeySet{
 		condition.S3XAmzServerSideEncryptionAwsKMSKeyID,
 		condition.S3XAmzMetadataDirective,
 		condition.S3XAmzStorageClass,
+		condition.S3RequestObjectTag,
+		condition.S3RequestObjectTagKeys,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.S3RequestObjectTag,
+		condition.S3RequestObjectTagKeys,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectRetentionAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectRetentionAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectLegalHoldAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectLegalHoldAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	BypassGovernanceRetentionAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectVersionAction: condition.NewKeySet(
+		condition.S3XAmzServerSideEncryption,
+		condition.S3XAmzServerSideEncryptionAwsKMSKeyID,
+		condition.S3XAmzStorageClass,
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	DeleteObjectVersionAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectAclAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectAclAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
 		condition.AWSReferer,
 		condition.AWSSourceIP,
 	),
+
+	GetObjectVersionAclAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectVersionAclAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectVersionTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	PutObjectVersionTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.S3RequestObjectTag,
+		condition.S3RequestObjectTagKeys,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	DeleteObjectVersionTaggingAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectAttributesAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	GetObjectVersionAttributesAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	RestoreObjectAction: condition.NewKeySet(
+		condition.S3ExistingObjectTag,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+
+	ListBucketVersionsAction: condition.NewKeySet(
+		condition.S3Prefix,
+		condition.S3Delimiter,
+		condition.S3MaxKeys,
+		condition.AWSReferer,
+		condition.AWSSourceIP,
+	),
+}
+
+// commonConditionKeys - condition keys supported by all actions.
+var commonConditionKeys = condition.NewKeySet(
+	condition.AWSReferer,
+	condition.AWSSourceIP,
+	condition.AWSCurrentTime,
+	condition.AWSEpochTime,
+	condition.AWSSecureTransport,
+	condition.AWSUserAgent,
+	condition.S3SignatureVersion,
+)
+
+// conditionKeysOf - returns supported condition keys of action, including common keys.
+// Action patterns support condition keys of all actions they match.
+func conditionKeysOf(action Action) condition.KeySet {
+	keys := condition.NewKeySet()
+	for key := range commonConditionKeys {
+		keys.Add(key)
+	}
+	actions := NewActionSet(action)
+	if action.isPattern() {
+		actions = action.expand()
+	}
+	for a := range actions {
+		for key := range actionConditionKeyMap[a] {
+			keys.Add(key)
+		}
+	}
+	return keys
 }
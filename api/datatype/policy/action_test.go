@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype/policy/condition"
+)
+
+func TestParseAction(t *testing.T) {
+	testCases := []struct {
+		s         string
+		action    Action
+		expectErr bool
+	}{
+		{"s3:GetObject", GetObjectAction, false},
+		{"s3:GetObjectVersionAcl", GetObjectVersionAclAction, false},
+		{"s3:PutBucketOwnershipControls", PutBucketOwnershipControlsAction, false},
+		{"s3:RestoreObject", RestoreObjectAction, false},
+		// action names are case insensitive
+		{"s3:getobject", GetObjectAction, false},
+		{"S3:LISTBUCKETVERSIONS", ListBucketVersionsAction, false},
+		{"s3:*", "s3:*", false},
+		{"s3:Get*", "s3:Get*", false},
+		{"s3:*Acl", "s3:*Acl", false},
+		{"s3:Get?bject", "s3:Get?bject", false},
+		{"s3:Unknown", "", true},
+		{"s3:Unknown*", "", true},
+		{"ec2:*", "", true},
+		{"", "", true},
+	}
+	for _, testCase := range testCases {
+		action, err := parseAction(testCase.s)
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("parseAction(%q) error: %v, expected error: %v", testCase.s, err, testCase.expectErr)
+			continue
+		}
+		if err == nil && action != testCase.action {
+			t.Errorf("parseAction(%q) = %q, expected: %q", testCase.s, action, testCase.action)
+		}
+	}
+}
+
+func TestActionMatch(t *testing.T) {
+	testCases := []struct {
+		pattern Action
+		action  Action
+		matched bool
+	}{
+		{GetObjectAction, GetObjectAction, true},
+		{"s3:getobject", GetObjectAction, true},
+		// specific actions are not implied by others
+		{GetObjectAction, GetObjectVersionAction, false},
+		{GetObjectAction, GetObjectAclAction, false},
+		{PutObjectAction, PutObjectAclAction, false},
+		{ListBucketAction, ListBucketVersionsAction, false},
+		{"s3:*", DeleteBucketAction, true},
+		{"s3:Get*", GetBucketPolicyStatusAction, true},
+		{"s3:Get*", PutObjectAction, false},
+		{"s3:*Acl", PutObjectVersionAclAction, true},
+		{"s3:*Acl", GetObjectAction, false},
+		{"s3:GetObject*", GetObjectVersionTaggingAction, true},
+	}
+	for _, testCase := range testCases {
+		if matched := testCase.pattern.Match(testCase.action); matched != testCase.matched {
+			t.Errorf("%q.Match(%q) = %v, expected: %v", testCase.pattern, testCase.action,
+				matched, testCase.matched)
+		}
+	}
+}
+
+func TestActionType(t *testing.T) {
+	objectActions := []Action{GetObjectAction, GetObjectVersionAction, GetObjectAclAction,
+		PutObjectVersionAclAction, RestoreObjectAction, GetObjectAttributesAction, DeleteObjectVersionAction,
+		AbortMultipartUploadAction, ListMultipartUploadPartsAction, BypassGovernanceRetentionAction}
+	bucketActions := []Action{ListBucketAction, ListBucketVersionsAction, GetBucketAclAction,
+		PutBucketLoggingAction, GetLifecycleConfigurationAction, PutBucketOwnershipControlsAction,
+		GetBucketPolicyStatusAction, DeleteBucketWebsiteAction}
+	for _, action := range objectActions {
+		if !action.IsValid() || !action.isObjectAction() {
+			t.Errorf("%q is not a valid object action", action)
+		}
+	}
+	for _, action := range bucketActions {
+		if !action.IsValid() || action.isObjectAction() {
+			t.Errorf("%q is not a valid bucket action", action)
+		}
+	}
+
+	expanded := Action("s3:*VersionAcl").expand()
+	if len(expanded) != 2 || !expanded.Contains(GetObjectVersionAclAction) ||
+		!expanded.Contains(PutObjectVersionAclAction) {
+		t.Errorf("s3:*VersionAcl is expanded to %v", expanded)
+	}
+}
+
+func TestConditionKeysOf(t *testing.T) {
+	testCases := []struct {
+		action    Action
+		key       condition.Key
+		supported bool
+	}{
+		{ListBucketAction, condition.S3Prefix, true},
+		{GetObjectAction, condition.S3Prefix, false},
+		{GetObjectVersionAction, condition.S3ExistingObjectTag, true},
+		// common keys are supported by all actions
+		{GetBucketAclAction, condition.AWSSecureTransport, true},
+		// patterns support keys of all actions they match
+		{"s3:List*", condition.S3Prefix, true},
+		{"s3:*", condition.S3ExistingObjectTag, true},
+		{"s3:*Acl", condition.S3Prefix, false},
+	}
+	for _, testCase := range testCases {
+		_, supported := conditionKeysOf(testCase.action)[testCase.key]
+		if supported != testCase.supported {
+			t.Errorf("%q supports %q: %v, expected: %v", testCase.action, testCase.key,
+				supported, testCase.supported)
+		}
+	}
+}
+
+func TestStatementResourceOfAction(t *testing.T) {
+	testCases := []struct {
+		data      string
+		expectErr bool
+	}{
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:GetObjectVersion","Resource":"arn:aws:s3:::b/*"}`, false},
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:GetObjectVersion","Resource":"arn:aws:s3:::b"}`, true},
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:ListBucketVersions","Resource":"arn:aws:s3:::b"}`, false},
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:ListBucketVersions","Resource":"arn:aws:s3:::b/*"}`, true},
+		// patterns match both object and bucket actions
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:Get*","Resource":"arn:aws:s3:::b"}`, false},
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:Get*","Resource":"arn:aws:s3:::b/*"}`, false},
+		{`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject*","Resource":"arn:aws:s3:::b"}`, true},
+	}
+	for _, testCase := range testCases {
+		var statement Statement
+		err := json.Unmarshal([]byte(testCase.data), &statement)
+		if err == nil {
+			err = statement.isValid()
+		}
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("Statement %s error: %v, expected error: %v", testCase.data, err, testCase.expectErr)
+		}
+	}
+}
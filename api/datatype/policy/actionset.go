@@ -32,10 +32,18 @@ func (actionSet ActionSet) Add(action Action) {
 	actionSet[action] = struct{}{}
 }
 
-// Contains - checks given action exists in the action set.
+// Contains - checks given action exists in the action set,
+// or matches any action pattern in the set.
 func (actionSet ActionSet) Contains(action Action) bool {
-	_, found := actionSet[action]
-	return found
+	if _, found := actionSet[action]; found {
+		return true
+	}
+	for a := range actionSet {
+		if a.isPattern() && a.Match(action) {
+			return true
+		}
+	}
+	return false
 }
 
 // Intersection - returns actions available in both ActionSet.
@@ -0,0 +1,118 @@
+package condition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/journeymidnight/yig/api/datatype/policy/utils"
+)
+
+func toArnFuncString(n name, key Key, values utils.StringSet) string {
+	valueStrings := values.ToSlice()
+	sort.Strings(valueStrings)
+
+	return fmt.Sprintf("%v:%v:%v", n, key, valueStrings)
+}
+
+// arnMatch - matches ARN against ARN pattern component by component, wildcards
+// of a component never match across colons.
+func arnMatch(pattern, arn string) bool {
+	patterns := strings.SplitN(pattern, ":", 6)
+	arns := strings.SplitN(arn, ":", 6)
+	if len(patterns) != 6 || len(arns) != 6 {
+		return false
+	}
+	for i := range patterns {
+		if !utils.Match(patterns[i], arns[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// arnFunc - ARN functions. ArnEquals and ArnLike behave the same, they check whether
+// ARN by Key in given values map matches any of condition values with wildcards.
+// For example,
+//   - if values = ["arn:aws:s3:::mybucket/*"], at evaluate() it returns whether ARN
+//     in value map for Key is an object of mybucket.
+type arnFunc struct {
+	n      name
+	k      Key
+	values utils.StringSet
+}
+
+// evaluate() - evaluates to check whether ARN by Key in given values matches
+// condition values, results are negated for ArnNotEquals and ArnNotLike.
+func (f arnFunc) evaluate(values map[string][]string) bool {
+	matched := false
+	for _, v := range f.k.requestValues(values) {
+		if !f.values.FuncMatch(arnMatch, v).IsEmpty() {
+			matched = true
+			break
+		}
+	}
+	if f.n == arnNotEquals || f.n == arnNotLike {
+		return !matched
+	}
+
+	return matched
+}
+
+// key() - returns condition key which is used by this condition function.
+func (f arnFunc) key() Key {
+	return f.k
+}
+
+// name() - returns condition name of this function, e.g. "ArnLike".
+func (f arnFunc) name() name {
+	return f.n
+}
+
+func (f arnFunc) String() string {
+	return toArnFuncString(f.n, f.k, f.values)
+}
+
+// toMap - returns map representation of this function.
+func (f arnFunc) toMap() map[Key]ValueSet {
+	if !f.k.IsValid() {
+		return nil
+	}
+
+	values := NewValueSet()
+	for _, value := range f.values.ToSlice() {
+		values.Add(NewStringValue(value))
+	}
+
+	return map[Key]ValueSet{
+		f.k: values,
+	}
+}
+
+// newArnFunc - returns new ARN function of name n.
+func newArnFunc(n name, key Key, values ValueSet) (Function, error) {
+	valueStrings, err := valuesToStringSlice(n, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewArnFunc(n, key, valueStrings...)
+}
+
+// NewArnFunc - returns new ARN function of name n, e.g. "ArnEquals".
+func NewArnFunc(n name, key Key, values ...string) (Function, error) {
+	switch n {
+	case arnEquals, arnNotEquals, arnLike, arnNotLike:
+	default:
+		return nil, fmt.Errorf("%v is not an ARN condition", n)
+	}
+	sset := utils.CreateStringSet(values...)
+	for _, s := range sset.ToSlice() {
+		if len(strings.SplitN(s, ":", 6)) != 6 {
+			return nil, fmt.Errorf("invalid ARN '%v' for %v condition", s, n)
+		}
+	}
+
+	return &arnFunc{n, key, sset}, nil
+}
@@ -0,0 +1,89 @@
+package condition
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/journeymidnight/yig/api/datatype/policy/utils"
+)
+
+// binaryEqualsFunc - BinaryEquals function. It checks whether value by Key in given
+// values map equals any of condition values, which are base64 encoded.
+// For example,
+//   - if values = ["QmluYXJ5VmFsdWU="], at evaluate() it returns whether value
+//     in value map for Key is "BinaryValue".
+type binaryEqualsFunc struct {
+	k      Key
+	values utils.StringSet
+}
+
+// evaluate() - evaluates to check whether value by Key in given values equals
+// decoded condition values.
+func (f binaryEqualsFunc) evaluate(values map[string][]string) bool {
+	for _, v := range f.k.requestValues(values) {
+		if f.values.Contains(base64.StdEncoding.EncodeToString([]byte(v))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key() - returns condition key which is used by this condition function.
+func (f binaryEqualsFunc) key() Key {
+	return f.k
+}
+
+// name() - returns "BinaryEquals" condition name.
+func (f binaryEqualsFunc) name() name {
+	return binaryEquals
+}
+
+func (f binaryEqualsFunc) String() string {
+	valueStrings := f.values.ToSlice()
+	sort.Strings(valueStrings)
+
+	return fmt.Sprintf("%v:%v:%v", binaryEquals, f.k, valueStrings)
+}
+
+// toMap - returns map representation of this function.
+func (f binaryEqualsFunc) toMap() map[Key]ValueSet {
+	if !f.k.IsValid() {
+		return nil
+	}
+
+	values := NewValueSet()
+	for _, value := range f.values.ToSlice() {
+		values.Add(NewStringValue(value))
+	}
+
+	return map[Key]ValueSet{
+		f.k: values,
+	}
+}
+
+// newBinaryEqualsFunc - returns new BinaryEquals function.
+func newBinaryEqualsFunc(key Key, values ValueSet) (Function, error) {
+	valueStrings, err := valuesToStringSlice(binaryEquals, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBinaryEqualsFunc(key, valueStrings...)
+}
+
+// NewBinaryEqualsFunc - returns new BinaryEquals function, values are base64 encoded.
+func NewBinaryEqualsFunc(key Key, values ...string) (Function, error) {
+	sset := utils.NewStringSet()
+	for _, s := range values {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("value '%v' must be base64 encoded for %v condition", s, binaryEquals)
+		}
+		// normalize encoding for comparison
+		sset.Add(base64.StdEncoding.EncodeToString(b))
+	}
+
+	return &binaryEqualsFunc{key, sset}, nil
+}
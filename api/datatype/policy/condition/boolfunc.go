@@ -0,0 +1,83 @@
+package condition
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// booleanFunc - Bool condition function. It checks whether boolean value by Key
+// in given values map equals condition value.
+// For example,
+//   - if Key = aws:SecureTransport and value = false, at evaluate() it returns
+//     whether the request is NOT sent by TLS.
+type booleanFunc struct {
+	k     Key
+	value bool
+}
+
+// evaluate() - evaluates to check whether boolean value by Key in given values
+// equals condition value.
+func (f booleanFunc) evaluate(values map[string][]string) bool {
+	for _, s := range f.k.requestValues(values) {
+		if b, err := strconv.ParseBool(s); err == nil && b == f.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key() - returns condition key which is used by this condition function.
+func (f booleanFunc) key() Key {
+	return f.k
+}
+
+// name() - returns "Bool" condition name.
+func (f booleanFunc) name() name {
+	return boolean
+}
+
+func (f booleanFunc) String() string {
+	return fmt.Sprintf("%v:%v:%v", boolean, f.k, f.value)
+}
+
+// toMap - returns map representation of this function.
+func (f booleanFunc) toMap() map[Key]ValueSet {
+	if !f.k.IsValid() {
+		return nil
+	}
+
+	return map[Key]ValueSet{
+		f.k: NewValueSet(NewStringValue(strconv.FormatBool(f.value))),
+	}
+}
+
+func newBooleanFunc(key Key, values ValueSet) (Function, error) {
+	if len(values) != 1 {
+		return nil, fmt.Errorf("only one value is allowed for Bool condition")
+	}
+
+	var value bool
+	for v := range values {
+		switch v.GetType() {
+		case reflect.Bool:
+			value, _ = v.GetBool()
+		case reflect.String:
+			var err error
+			s, _ := v.GetString()
+			if value, err = strconv.ParseBool(s); err != nil {
+				return nil, fmt.Errorf("value must be a boolean string for Bool condition")
+			}
+		default:
+			return nil, fmt.Errorf("value must be a boolean for Bool condition")
+		}
+	}
+
+	return &booleanFunc{key, value}, nil
+}
+
+// NewBooleanFunc - returns new Bool function.
+func NewBooleanFunc(key Key, value bool) (Function, error) {
+	return &booleanFunc{key, value}, nil
+}
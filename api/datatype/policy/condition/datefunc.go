@@ -0,0 +1,128 @@
+package condition
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype/policy/utils"
+)
+
+// date formats of ISO 8601 accepted by date conditions, epoch seconds are also accepted
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+func parseDate(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date '%v'", s)
+}
+
+var dateOperators = map[name]compareOperator{
+	dateEquals:            equals,
+	dateNotEquals:         notEquals,
+	dateLessThan:          lessThan,
+	dateLessThanEquals:    lessThanEquals,
+	dateGreaterThan:       greaterThan,
+	dateGreaterThanEquals: greaterThanEquals,
+}
+
+// dateFunc - Date functions. They compare date value by Key in given values map
+// with condition values.
+// For example,
+//   - if n = DateLessThan, Key = aws:CurrentTime and values = ["2020-01-01T00:00:00Z"],
+//     at evaluate() it returns whether the request is sent before 2020.
+type dateFunc struct {
+	n      name
+	k      Key
+	values utils.StringSet
+	dates  []time.Time
+}
+
+// evaluate() - evaluates to check whether date value by Key in given values
+// satisfies the condition. Values which are not dates never satisfy.
+func (f dateFunc) evaluate(values map[string][]string) bool {
+	requestDates := []time.Time{}
+	for _, s := range f.k.requestValues(values) {
+		if date, err := parseDate(s); err == nil {
+			requestDates = append(requestDates, date)
+		}
+	}
+
+	return compareValues(dateOperators[f.n], len(requestDates), len(f.dates), func(i, j int) int {
+		switch {
+		case requestDates[i].Before(f.dates[j]):
+			return -1
+		case requestDates[i].After(f.dates[j]):
+			return 1
+		}
+		return 0
+	})
+}
+
+// key() - returns condition key which is used by this condition function.
+func (f dateFunc) key() Key {
+	return f.k
+}
+
+// name() - returns condition name of this function, e.g. "DateLessThan".
+func (f dateFunc) name() name {
+	return f.n
+}
+
+func (f dateFunc) String() string {
+	return toCompareFuncString(f.n, f.k, f.values)
+}
+
+// toMap - returns map representation of this function.
+func (f dateFunc) toMap() map[Key]ValueSet {
+	if !f.k.IsValid() {
+		return nil
+	}
+
+	values := NewValueSet()
+	for _, value := range f.values.ToSlice() {
+		values.Add(NewStringValue(value))
+	}
+
+	return map[Key]ValueSet{
+		f.k: values,
+	}
+}
+
+// newDateFunc - returns new date function of name n.
+func newDateFunc(n name, key Key, values ValueSet) (Function, error) {
+	return NewDateFunc(n, key, valuesToStrings(values).ToSlice()...)
+}
+
+// NewDateFunc - returns new date function of name n, e.g. "DateGreaterThan".
+func NewDateFunc(n name, key Key, values ...string) (Function, error) {
+	if _, found := dateOperators[n]; !found {
+		return nil, fmt.Errorf("%v is not a date condition", n)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty values for %v condition", n)
+	}
+
+	f := dateFunc{n: n, k: key, values: utils.CreateStringSet(values...)}
+	for _, s := range f.values.ToSlice() {
+		date, err := parseDate(s)
+		if err != nil {
+			return nil, fmt.Errorf("value '%v' must be a date for %v condition", s, n)
+		}
+		f.dates = append(f.dates, date)
+	}
+
+	return &f, nil
+}
@@ -66,12 +66,30 @@ func (functions Functions) Keys() KeySet {
 	return keySet
 }
 
+// IsFixedSourceIP - returns whether any function restricts aws:SourceIp of
+// requests to fixed IP ranges by "IpAddress" condition.
+func (functions Functions) IsFixedSourceIP() bool {
+	for _, f := range functions {
+		if ipFunc, ok := f.(*ipAddressFunc); ok && ipFunc.isFixed() {
+			return true
+		}
+	}
+
+	return false
+}
+
 // MarshalJSON - encodes Functions to  JSON data.
 func (functions Functions) MarshalJSON() ([]byte, error) {
 	nm := make(map[name]map[Key]ValueSet)
 
 	for _, f := range functions {
-		nm[f.name()] = f.toMap()
+		// functions of the same name are merged, e.g. StringEquals of different keys
+		if _, found := nm[f.name()]; !found {
+			nm[f.name()] = make(map[Key]ValueSet)
+		}
+		for key, values := range f.toMap() {
+			nm[f.name()][key] = values
+		}
 	}
 
 	return json.Marshal(nm)
@@ -120,7 +138,8 @@ func (functions *Functions) UnmarshalJSON(data []byte) error {
 			}
 
 			var f Function
-			switch n {
+			_, base, _ := n.split()
+			switch base {
 			case stringEquals:
 				if f, err = newStringEqualsFunc(key, values); err != nil {
 					return err
@@ -149,11 +168,33 @@ func (functions *Functions) UnmarshalJSON(data []byte) error {
 				if f, err = newNullFunc(key, values); err != nil {
 					return err
 				}
+			case numericEquals, numericNotEquals, numericLessThan, numericLessThanEquals,
+				numericGreaterThan, numericGreaterThanEquals:
+				if f, err = newNumericFunc(base, key, values); err != nil {
+					return err
+				}
+			case dateEquals, dateNotEquals, dateLessThan, dateLessThanEquals,
+				dateGreaterThan, dateGreaterThanEquals:
+				if f, err = newDateFunc(base, key, values); err != nil {
+					return err
+				}
+			case boolean:
+				if f, err = newBooleanFunc(key, values); err != nil {
+					return err
+				}
+			case arnEquals, arnNotEquals, arnLike, arnNotLike:
+				if f, err = newArnFunc(base, key, values); err != nil {
+					return err
+				}
+			case binaryEquals:
+				if f, err = newBinaryEqualsFunc(key, values); err != nil {
+					return err
+				}
 			default:
 				return fmt.Errorf("%v is not handled", n)
 			}
 
-			funcs = append(funcs, f)
+			funcs = append(funcs, newQualifiedFunc(n, f))
 		}
 	}
 
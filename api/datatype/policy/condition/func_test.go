@@ -0,0 +1,218 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func parseFunctions(t *testing.T, data string) Functions {
+	var functions Functions
+	if err := json.Unmarshal([]byte(data), &functions); err != nil {
+		t.Fatalf("Invalid condition %s: %v", data, err)
+	}
+	return functions
+}
+
+type evaluateCase struct {
+	condition string
+	values    map[string][]string
+	result    bool
+}
+
+func testEvaluate(t *testing.T, testCases []evaluateCase) {
+	for i, testCase := range testCases {
+		functions := parseFunctions(t, testCase.condition)
+		if result := functions.Evaluate(testCase.values); result != testCase.result {
+			t.Errorf("Case %d: %s with %v = %v, expected %v", i, testCase.condition,
+				testCase.values, result, testCase.result)
+		}
+	}
+}
+
+func TestStringFunctions(t *testing.T) {
+	testEvaluate(t, []evaluateCase{
+		{`{"StringEquals":{"s3:prefix":["a","b"]}}`, map[string][]string{"prefix": {"a"}}, true},
+		{`{"StringEquals":{"s3:prefix":["a","b"]}}`, map[string][]string{"prefix": {"c"}}, false},
+		{`{"StringEquals":{"s3:prefix":"a"}}`, map[string][]string{}, false},
+		{`{"StringNotEquals":{"s3:prefix":["a","b"]}}`, map[string][]string{"prefix": {"c"}}, true},
+		{`{"StringNotEquals":{"s3:prefix":["a","b"]}}`, map[string][]string{"prefix": {"b"}}, false},
+		{`{"StringLike":{"aws:Referer":"http://*.example.com/*"}}`,
+			map[string][]string{"Referer": {"http://www.example.com/index.html"}}, true},
+		{`{"StringLike":{"aws:Referer":"http://*.example.com/*"}}`,
+			map[string][]string{"Referer": {"http://example.org/"}}, false},
+		{`{"StringNotLike":{"aws:UserAgent":"curl/*"}}`, map[string][]string{"UserAgent": {"aws-cli/2"}}, true},
+		{`{"StringNotLike":{"aws:UserAgent":"curl/*"}}`, map[string][]string{"UserAgent": {"curl/7.1"}}, false},
+		{`{"StringEquals":{"s3:x-amz-server-side-encryption":"AES256"}}`,
+			map[string][]string{"X-Amz-Server-Side-Encryption": {"AES256"}}, true},
+		{`{"StringEquals":{"s3:ExistingObjectTag/team":"dev"}}`,
+			map[string][]string{"ExistingObjectTag/team": {"dev"}}, true},
+	})
+}
+
+func TestIPAddressAndNullFunctions(t *testing.T) {
+	testEvaluate(t, []evaluateCase{
+		{`{"IpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`, map[string][]string{"SourceIp": {"192.168.1.10"}}, true},
+		{`{"IpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`, map[string][]string{"SourceIp": {"192.168.2.10"}}, false},
+		{`{"NotIpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`, map[string][]string{"SourceIp": {"192.168.2.10"}}, true},
+		{`{"NotIpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`, map[string][]string{"SourceIp": {"192.168.1.10"}}, false},
+		{`{"Null":{"s3:x-amz-server-side-encryption":"true"}}`, map[string][]string{}, true},
+		{`{"Null":{"s3:x-amz-server-side-encryption":"true"}}`,
+			map[string][]string{"X-Amz-Server-Side-Encryption": {"AES256"}}, false},
+		{`{"Null":{"s3:x-amz-server-side-encryption":"false"}}`,
+			map[string][]string{"X-Amz-Server-Side-Encryption": {"AES256"}}, true},
+	})
+}
+
+func TestNumericFunctions(t *testing.T) {
+	values := map[string][]string{"max-keys": {"100"}}
+	testEvaluate(t, []evaluateCase{
+		{`{"NumericEquals":{"s3:max-keys":"100"}}`, values, true},
+		{`{"NumericEquals":{"s3:max-keys":["10","100"]}}`, values, true},
+		{`{"NumericEquals":{"s3:max-keys":"10"}}`, values, false},
+		{`{"NumericNotEquals":{"s3:max-keys":"10"}}`, values, true},
+		{`{"NumericNotEquals":{"s3:max-keys":["10","100"]}}`, values, false},
+		{`{"NumericLessThan":{"s3:max-keys":"100"}}`, values, false},
+		{`{"NumericLessThan":{"s3:max-keys":"100.5"}}`, values, true},
+		{`{"NumericLessThanEquals":{"s3:max-keys":"100"}}`, values, true},
+		{`{"NumericGreaterThan":{"s3:max-keys":"100"}}`, values, false},
+		{`{"NumericGreaterThan":{"s3:max-keys":"99"}}`, values, true},
+		{`{"NumericGreaterThanEquals":{"s3:max-keys":"100"}}`, values, true},
+		// values which are not numbers, or absent, never satisfy
+		{`{"NumericLessThan":{"s3:max-keys":"100"}}`, map[string][]string{"max-keys": {"ten"}}, false},
+		{`{"NumericNotEquals":{"s3:max-keys":"100"}}`, map[string][]string{}, false},
+	})
+}
+
+func TestDateFunctions(t *testing.T) {
+	values := map[string][]string{"CurrentTime": {"2020-06-01T00:00:00Z"}, "EpochTime": {"1590969600"}}
+	testEvaluate(t, []evaluateCase{
+		{`{"DateEquals":{"aws:CurrentTime":"2020-06-01T00:00:00Z"}}`, values, true},
+		{`{"DateEquals":{"aws:EpochTime":"2020-06-01T00:00:00Z"}}`, values, true},
+		{`{"DateNotEquals":{"aws:CurrentTime":"2020-06-01T00:00:00Z"}}`, values, false},
+		{`{"DateNotEquals":{"aws:CurrentTime":"2020-01-01T00:00:00Z"}}`, values, true},
+		{`{"DateLessThan":{"aws:CurrentTime":"2021-01-01T00:00:00Z"}}`, values, true},
+		{`{"DateLessThan":{"aws:CurrentTime":"2020-06-01T00:00:00Z"}}`, values, false},
+		{`{"DateLessThanEquals":{"aws:CurrentTime":"2020-06-01T00:00:00Z"}}`, values, true},
+		{`{"DateGreaterThan":{"aws:CurrentTime":"2020-01-01T00:00:00Z"}}`, values, true},
+		{`{"DateGreaterThan":{"aws:EpochTime":"1590969600"}}`, values, false},
+		{`{"DateGreaterThanEquals":{"aws:EpochTime":"1590969600"}}`, values, true},
+		{`{"DateLessThan":{"aws:CurrentTime":"2021-01-01T00:00:00Z"}}`,
+			map[string][]string{"CurrentTime": {"yesterday"}}, false},
+	})
+}
+
+func TestBoolFunctions(t *testing.T) {
+	testEvaluate(t, []evaluateCase{
+		{`{"Bool":{"aws:SecureTransport":"true"}}`, map[string][]string{"SecureTransport": {"true"}}, true},
+		{`{"Bool":{"aws:SecureTransport":true}}`, map[string][]string{"SecureTransport": {"true"}}, true},
+		{`{"Bool":{"aws:SecureTransport":"false"}}`, map[string][]string{"SecureTransport": {"true"}}, false},
+		{`{"Bool":{"aws:SecureTransport":"false"}}`, map[string][]string{"SecureTransport": {"false"}}, true},
+		{`{"Bool":{"aws:SecureTransport":"false"}}`, map[string][]string{}, false},
+	})
+}
+
+func TestArnFunctions(t *testing.T) {
+	values := map[string][]string{"X-Amz-Copy-Source": {"arn:aws:s3:::bucket/dir/object"}}
+	testEvaluate(t, []evaluateCase{
+		{`{"ArnEquals":{"s3:x-amz-copy-source":"arn:aws:s3:::bucket/dir/object"}}`, values, true},
+		{`{"ArnEquals":{"s3:x-amz-copy-source":"arn:aws:s3:::bucket/*"}}`, values, true},
+		{`{"ArnLike":{"s3:x-amz-copy-source":"arn:aws:s3:::bucket/*"}}`, values, true},
+		{`{"ArnLike":{"s3:x-amz-copy-source":"arn:aws:s3:::other/*"}}`, values, false},
+		// ARNs are matched component by component
+		{`{"ArnLike":{"s3:x-amz-copy-source":"arn:aws:s3:region::bucket/*"}}`, values, false},
+		{`{"ArnLike":{"s3:x-amz-copy-source":"arn:*:s3:*::bucket/dir/object"}}`, values, true},
+		{`{"ArnNotEquals":{"s3:x-amz-copy-source":"arn:aws:s3:::other/*"}}`, values, true},
+		{`{"ArnNotLike":{"s3:x-amz-copy-source":"arn:aws:s3:::bucket/*"}}`, values, false},
+		{`{"ArnNotLike":{"s3:x-amz-copy-source":"arn:aws:s3:::bucket/*"}}`, map[string][]string{}, true},
+	})
+}
+
+func TestBinaryFunctions(t *testing.T) {
+	testEvaluate(t, []evaluateCase{
+		// "aGVsbG8=" is base64 of "hello"
+		{`{"BinaryEquals":{"aws:UserAgent":"aGVsbG8="}}`, map[string][]string{"UserAgent": {"hello"}}, true},
+		{`{"BinaryEquals":{"aws:UserAgent":"aGVsbG8="}}`, map[string][]string{"UserAgent": {"hello!"}}, false},
+		{`{"BinaryEquals":{"aws:UserAgent":"aGVsbG8="}}`, map[string][]string{}, false},
+	})
+}
+
+func TestQualifiedFunctions(t *testing.T) {
+	tagKeys := map[string][]string{"RequestObjectTagKeys": {"team", "project"}}
+	testEvaluate(t, []evaluateCase{
+		{`{"ForAnyValue:StringEquals":{"s3:RequestObjectTagKeys":["team","owner"]}}`, tagKeys, true},
+		{`{"ForAnyValue:StringEquals":{"s3:RequestObjectTagKeys":["owner"]}}`, tagKeys, false},
+		{`{"ForAnyValue:StringEquals":{"s3:RequestObjectTagKeys":["owner"]}}`, map[string][]string{}, false},
+		{`{"ForAllValues:StringEquals":{"s3:RequestObjectTagKeys":["team","project","owner"]}}`, tagKeys, true},
+		{`{"ForAllValues:StringEquals":{"s3:RequestObjectTagKeys":["team"]}}`, tagKeys, false},
+		// ForAllValues is satisfied if key is absent
+		{`{"ForAllValues:StringEquals":{"s3:RequestObjectTagKeys":["team"]}}`, map[string][]string{}, true},
+		{`{"ForAnyValue:StringLike":{"s3:RequestObjectTagKeys":"pro*"}}`, tagKeys, true},
+		{`{"ForAllValues:StringLike":{"s3:RequestObjectTagKeys":"pro*"}}`, tagKeys, false},
+		{`{"StringEqualsIfExists":{"s3:x-amz-server-side-encryption":"AES256"}}`, map[string][]string{}, true},
+		{`{"StringEqualsIfExists":{"s3:x-amz-server-side-encryption":"AES256"}}`,
+			map[string][]string{"X-Amz-Server-Side-Encryption": {"aws:kms"}}, false},
+		{`{"NumericLessThanEqualsIfExists":{"s3:max-keys":"10"}}`, map[string][]string{}, true},
+		{`{"NumericLessThanEqualsIfExists":{"s3:max-keys":"10"}}`, map[string][]string{"max-keys": {"100"}}, false},
+		{`{"ForAnyValue:StringEqualsIfExists":{"s3:RequestObjectTagKeys":"team"}}`, tagKeys, true},
+		{`{"ForAnyValue:StringEqualsIfExists":{"s3:RequestObjectTagKeys":"team"}}`, map[string][]string{}, true},
+	})
+}
+
+func TestInvalidFunctions(t *testing.T) {
+	testCases := []string{
+		`{}`,
+		`{"StringEquals":{"s3:unknown":"a"}}`,
+		`{"UnknownCondition":{"s3:prefix":"a"}}`,
+		`{"ForSomeValues:StringEquals":{"s3:prefix":"a"}}`,
+		`{"ForAnyValue:Null":{"s3:prefix":"true"}}`,
+		`{"NullIfExists":{"s3:prefix":"true"}}`,
+		`{"NumericEquals":{"s3:max-keys":"ten"}}`,
+		`{"DateEquals":{"aws:CurrentTime":"yesterday"}}`,
+		`{"Bool":{"aws:SecureTransport":"yes"}}`,
+		`{"Bool":{"aws:SecureTransport":["true","false"]}}`,
+		`{"ArnEquals":{"s3:x-amz-copy-source":"bucket/object"}}`,
+		`{"BinaryEquals":{"aws:UserAgent":"not base64!"}}`,
+		`{"IpAddress":{"aws:Referer":"192.168.1.0/24"}}`,
+	}
+	for _, testCase := range testCases {
+		var functions Functions
+		if err := json.Unmarshal([]byte(testCase), &functions); err == nil {
+			t.Errorf("Invalid condition %s is parsed", testCase)
+		}
+	}
+}
+
+func TestFunctionsMarshalJSON(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		// functions of the same name are merged
+		{`{"StringEquals":{"s3:prefix":"a","aws:Referer":"r"}}`,
+			`{"StringEquals":{"aws:Referer":["r"],"s3:prefix":["a"]}}`},
+		{`{"ForAnyValue:StringLike":{"s3:RequestObjectTagKeys":"pro*"},"StringLike":{"s3:prefix":"a*"}}`,
+			`{"ForAnyValue:StringLike":{"s3:RequestObjectTagKeys":["pro*"]},"StringLike":{"s3:prefix":["a*"]}}`},
+		{`{"StringEqualsIfExists":{"s3:x-amz-server-side-encryption":"AES256"}}`,
+			`{"StringEqualsIfExists":{"s3:x-amz-server-side-encryption":["AES256"]}}`},
+		{`{"NumericLessThan":{"s3:max-keys":"10"},"DateGreaterThan":{"aws:CurrentTime":"2020-01-01T00:00:00Z"}}`,
+			`{"DateGreaterThan":{"aws:CurrentTime":["2020-01-01T00:00:00Z"]},"NumericLessThan":{"s3:max-keys":["10"]}}`},
+		{`{"Bool":{"aws:SecureTransport":"true"}}`, `{"Bool":{"aws:SecureTransport":["true"]}}`},
+		{`{"ForAllValues:StringLikeIfExists":{"s3:RequestObjectTagKeys":"pro*"}}`,
+			`{"ForAllValues:StringLikeIfExists":{"s3:RequestObjectTagKeys":["pro*"]}}`},
+	}
+	for _, testCase := range testCases {
+		functions := parseFunctions(t, testCase.data)
+		data, err := json.Marshal(functions)
+		if err != nil {
+			t.Errorf("Marshal %s error: %v", testCase.data, err)
+			continue
+		}
+		if string(data) != testCase.expected {
+			t.Errorf("Marshal %s = %s, expected %s", testCase.data, data, testCase.expected)
+		}
+		// marshaled functions are parsed back the same
+		if parsed := parseFunctions(t, string(data)); parsed.String() != functions.String() {
+			t.Errorf("Parsed %s = %v, expected %v", data, parsed, functions)
+		}
+	}
+}
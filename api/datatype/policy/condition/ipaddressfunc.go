@@ -46,7 +46,7 @@ type ipAddressFunc struct {
 // falls in one of network or not.
 func (f ipAddressFunc) evaluate(values map[string][]string) bool {
 	IPs := []net.IP{}
-	for _, s := range values[f.k.Name()] {
+	for _, s := range f.k.requestValues(values) {
 		IP := net.ParseIP(s)
 		if IP == nil {
 			panic(fmt.Errorf("invalid IP address '%v'", s))
@@ -97,6 +97,19 @@ func (f ipAddressFunc) toMap() map[Key]ValueSet {
 	}
 }
 
+// isFixed() - returns whether all networks are fixed IP ranges, i.e. no broader
+// than /8 for IPv4 and /32 for IPv6.
+func (f ipAddressFunc) isFixed() bool {
+	for _, IPNet := range f.values {
+		ones, bits := IPNet.Mask.Size()
+		if (bits == 8*net.IPv4len && ones < 8) || (bits == 8*net.IPv6len && ones < 32) {
+			return false
+		}
+	}
+
+	return len(f.values) > 0
+}
+
 // notIPAddressFunc - Not IP address function. It checks whether value by Key in given
 // values is NOT in IP network.  Here Key must be AWSSourceIP.
 // For example,
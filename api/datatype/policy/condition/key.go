@@ -19,6 +19,7 @@ package condition
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -68,17 +69,63 @@ const (
 
 	// AWSSourceIP - key representing client's IP address (not intermittent proxies) of any API.
 	AWSSourceIP = "aws:SourceIp"
+
+	// S3ExistingObjectTag - key representing tags of the existing object, used as
+	// "s3:ExistingObjectTag/<tag-key>" in policies.
+	S3ExistingObjectTag = "s3:ExistingObjectTag"
+
+	// S3RequestObjectTag - key representing tags in the request, i.e. header x-amz-tagging of
+	// PutObject API or body of PutObjectTagging API, used as "s3:RequestObjectTag/<tag-key>" in policies.
+	S3RequestObjectTag = "s3:RequestObjectTag"
+
+	// S3RequestObjectTagKeys - key representing tag keys in the request.
+	S3RequestObjectTagKeys = "s3:RequestObjectTagKeys"
+
+	// S3SignatureVersion - key representing signature version of the request, "AWS" for
+	// signature V2 and "AWS4-HMAC-SHA256" for signature V4.
+	S3SignatureVersion = "s3:signatureversion"
+
+	// AWSCurrentTime - key representing date and time of the request in ISO 8601 of any API.
+	AWSCurrentTime = "aws:CurrentTime"
+
+	// AWSEpochTime - key representing date and time of the request in epoch seconds of any API.
+	AWSEpochTime = "aws:EpochTime"
+
+	// AWSSecureTransport - key representing whether the request is sent by TLS of any API.
+	AWSSecureTransport = "aws:SecureTransport"
+
+	// AWSUserAgent - key representing User-Agent header of any API.
+	AWSUserAgent = "aws:UserAgent"
 )
 
+// base - returns key without tag key suffix, e.g. "s3:ExistingObjectTag/<tag-key>"
+// returns "s3:ExistingObjectTag".
+func (key Key) base() Key {
+	keyString := string(key)
+	for _, prefix := range []string{S3ExistingObjectTag, S3RequestObjectTag} {
+		if strings.HasPrefix(keyString, prefix+"/") && len(keyString) > len(prefix)+1 {
+			return Key(prefix)
+		}
+	}
+	return key
+}
+
 // IsValid - checks if key is valid or not.
 func (key Key) IsValid() bool {
-	switch key {
+	switch key.base() {
 	case S3XAmzCopySource, S3XAmzServerSideEncryption, S3XAmzServerSideEncryptionAwsKMSKeyID:
 		fallthrough
 	case S3XAmzMetadataDirective, S3XAmzStorageClass, S3LocationConstraint, S3Prefix:
 		fallthrough
 	case S3Delimiter, S3MaxKeys, AWSReferer, AWSSourceIP:
+		fallthrough
+	case S3SignatureVersion, AWSCurrentTime, AWSEpochTime, AWSSecureTransport, AWSUserAgent:
 		return true
+	case S3RequestObjectTagKeys:
+		return true
+	case S3ExistingObjectTag, S3RequestObjectTag:
+		// tag keys must be given as suffix
+		return key != key.base()
 	}
 
 	return false
@@ -104,6 +151,22 @@ func (key Key) Name() string {
 	return strings.TrimPrefix(keyString, "s3:")
 }
 
+// valueName - returns name of key in request values. Keys of HTTP headers, e.g.
+// "s3:x-amz-copy-source", are named by canonical header names like "X-Amz-Copy-Source".
+func (key Key) valueName() string {
+	keyName := key.Name()
+	if strings.HasPrefix(keyName, "x-amz-") {
+		return http.CanonicalHeaderKey(keyName)
+	}
+
+	return keyName
+}
+
+// requestValues - returns values of key in request values.
+func (key Key) requestValues(values map[string][]string) []string {
+	return values[key.valueName()]
+}
+
 // UnmarshalJSON - decodes JSON data to Key.
 func (key *Key) UnmarshalJSON(data []byte) error {
 	var s string
@@ -139,6 +202,7 @@ func (set KeySet) Add(key Key) {
 }
 
 // Difference - returns a key set contains difference of two keys.
+// Keys with tag key suffix are compared by their base keys.
 // Example:
 //     keySet1 := ["one", "two", "three"]
 //     keySet2 := ["two", "four", "three"]
@@ -147,7 +211,7 @@ func (set KeySet) Difference(sset KeySet) KeySet {
 	nset := make(KeySet)
 
 	for k := range set {
-		if _, ok := sset[k]; !ok {
+		if _, ok := sset[k.base()]; !ok {
 			nset.Add(k)
 		}
 	}
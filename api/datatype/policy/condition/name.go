@@ -19,24 +19,79 @@ package condition
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type name string
 
 const (
-	stringEquals    name = "StringEquals"
-	stringNotEquals      = "StringNotEquals"
-	stringLike           = "StringLike"
-	stringNotLike        = "StringNotLike"
-	ipAddress            = "IpAddress"
-	notIPAddress         = "NotIpAddress"
-	null                 = "Null"
+	stringEquals             name = "StringEquals"
+	stringNotEquals               = "StringNotEquals"
+	stringLike                    = "StringLike"
+	stringNotLike                 = "StringNotLike"
+	ipAddress                     = "IpAddress"
+	notIPAddress                  = "NotIpAddress"
+	null                          = "Null"
+	numericEquals                 = "NumericEquals"
+	numericNotEquals              = "NumericNotEquals"
+	numericLessThan               = "NumericLessThan"
+	numericLessThanEquals         = "NumericLessThanEquals"
+	numericGreaterThan            = "NumericGreaterThan"
+	numericGreaterThanEquals      = "NumericGreaterThanEquals"
+	dateEquals                    = "DateEquals"
+	dateNotEquals                 = "DateNotEquals"
+	dateLessThan                  = "DateLessThan"
+	dateLessThanEquals            = "DateLessThanEquals"
+	dateGreaterThan               = "DateGreaterThan"
+	dateGreaterThanEquals         = "DateGreaterThanEquals"
+	boolean                       = "Bool"
+	arnEquals                     = "ArnEquals"
+	arnNotEquals                  = "ArnNotEquals"
+	arnLike                       = "ArnLike"
+	arnNotLike                    = "ArnNotLike"
+	binaryEquals                  = "BinaryEquals"
 )
 
+// Set qualifiers and suffix of condition names, e.g. "ForAnyValue:StringLike"
+// and "StringEqualsIfExists".
+const (
+	forAnyValue  = "ForAnyValue:"
+	forAllValues = "ForAllValues:"
+	ifExists     = "IfExists"
+)
+
+// split - returns set qualifier, condition name without qualifier and suffix,
+// and whether name has suffix "IfExists".
+func (n name) split() (qualifier string, base name, isIfExists bool) {
+	s := string(n)
+	for _, q := range []string{forAnyValue, forAllValues} {
+		if strings.HasPrefix(s, q) {
+			qualifier, s = q, strings.TrimPrefix(s, q)
+			break
+		}
+	}
+	if strings.HasSuffix(s, ifExists) {
+		isIfExists, s = true, strings.TrimSuffix(s, ifExists)
+	}
+	return qualifier, name(s), isIfExists
+}
+
 // IsValid - checks if name is valid or not.
 func (n name) IsValid() bool {
-	switch n {
-	case stringEquals, stringNotEquals, stringLike, stringNotLike, ipAddress, notIPAddress, null:
+	qualifier, base, isIfExists := n.split()
+	switch base {
+	case null:
+		// Null checks existence of keys, so it's never qualified
+		return qualifier == "" && !isIfExists
+	case stringEquals, stringNotEquals, stringLike, stringNotLike, ipAddress, notIPAddress:
+		fallthrough
+	case numericEquals, numericNotEquals, numericLessThan, numericLessThanEquals:
+		fallthrough
+	case numericGreaterThan, numericGreaterThanEquals, dateEquals, dateNotEquals:
+		fallthrough
+	case dateLessThan, dateLessThanEquals, dateGreaterThan, dateGreaterThanEquals:
+		fallthrough
+	case boolean, arnEquals, arnNotEquals, arnLike, arnNotLike, binaryEquals:
 		return true
 	}
 
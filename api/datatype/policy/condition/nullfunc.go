@@ -37,7 +37,7 @@ type nullFunc struct {
 // evaluate() - evaluates to check whether Key is present in given values or not.
 // Depending on condition boolean value, this function returns true or false.
 func (f nullFunc) evaluate(values map[string][]string) bool {
-	requestValue := values[f.k.Name()]
+	requestValue := f.k.requestValues(values)
 
 	if f.value {
 		return len(requestValue) == 0
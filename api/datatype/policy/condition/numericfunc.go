@@ -0,0 +1,177 @@
+package condition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/journeymidnight/yig/api/datatype/policy/utils"
+)
+
+// compareOperator - operator of numeric and date condition functions.
+type compareOperator int
+
+const (
+	equals compareOperator = iota
+	notEquals
+	lessThan
+	lessThanEquals
+	greaterThan
+	greaterThanEquals
+)
+
+// test - checks result of comparing request value with condition value,
+// which is negative, zero or positive like strings.Compare.
+func (op compareOperator) test(result int) bool {
+	switch op {
+	case equals:
+		return result == 0
+	case notEquals:
+		return result != 0
+	case lessThan:
+		return result < 0
+	case lessThanEquals:
+		return result <= 0
+	case greaterThan:
+		return result > 0
+	case greaterThanEquals:
+		return result >= 0
+	}
+
+	return false
+}
+
+// compareValues - checks whether any request value satisfies op with any condition value.
+// "NotEquals" is satisfied only if request value equals none of condition values.
+func compareValues(op compareOperator, requestCount, conditionCount int, compare func(i, j int) int) bool {
+	for i := 0; i < requestCount; i++ {
+		matched := op == notEquals
+		for j := 0; j < conditionCount; j++ {
+			if op == notEquals {
+				matched = matched && op.test(compare(i, j))
+			} else if op.test(compare(i, j)) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func valuesToStrings(values ValueSet) utils.StringSet {
+	set := utils.NewStringSet()
+	for value := range values {
+		set.Add(value.String())
+	}
+
+	return set
+}
+
+func toCompareFuncString(n name, key Key, values utils.StringSet) string {
+	valueStrings := values.ToSlice()
+	sort.Strings(valueStrings)
+
+	return fmt.Sprintf("%v:%v:%v", n, key, valueStrings)
+}
+
+var numericOperators = map[name]compareOperator{
+	numericEquals:            equals,
+	numericNotEquals:         notEquals,
+	numericLessThan:          lessThan,
+	numericLessThanEquals:    lessThanEquals,
+	numericGreaterThan:       greaterThan,
+	numericGreaterThanEquals: greaterThanEquals,
+}
+
+// numericFunc - Numeric functions. They compare numeric value by Key in given values
+// map with condition values.
+// For example,
+//   - if n = NumericLessThanEquals, Key = s3:max-keys and values = ["100"], at evaluate()
+//     it returns whether max-keys in value map is no more than 100.
+type numericFunc struct {
+	n       name
+	k       Key
+	values  utils.StringSet
+	numbers []float64
+}
+
+// evaluate() - evaluates to check whether numeric value by Key in given values
+// satisfies the condition. Values which are not numbers never satisfy.
+func (f numericFunc) evaluate(values map[string][]string) bool {
+	requestNumbers := []float64{}
+	for _, s := range f.k.requestValues(values) {
+		if number, err := strconv.ParseFloat(s, 64); err == nil {
+			requestNumbers = append(requestNumbers, number)
+		}
+	}
+
+	return compareValues(numericOperators[f.n], len(requestNumbers), len(f.numbers), func(i, j int) int {
+		switch {
+		case requestNumbers[i] < f.numbers[j]:
+			return -1
+		case requestNumbers[i] > f.numbers[j]:
+			return 1
+		}
+		return 0
+	})
+}
+
+// key() - returns condition key which is used by this condition function.
+func (f numericFunc) key() Key {
+	return f.k
+}
+
+// name() - returns condition name of this function, e.g. "NumericEquals".
+func (f numericFunc) name() name {
+	return f.n
+}
+
+func (f numericFunc) String() string {
+	return toCompareFuncString(f.n, f.k, f.values)
+}
+
+// toMap - returns map representation of this function.
+func (f numericFunc) toMap() map[Key]ValueSet {
+	if !f.k.IsValid() {
+		return nil
+	}
+
+	values := NewValueSet()
+	for _, value := range f.values.ToSlice() {
+		values.Add(NewStringValue(value))
+	}
+
+	return map[Key]ValueSet{
+		f.k: values,
+	}
+}
+
+// newNumericFunc - returns new numeric function of name n.
+func newNumericFunc(n name, key Key, values ValueSet) (Function, error) {
+	return NewNumericFunc(n, key, valuesToStrings(values).ToSlice()...)
+}
+
+// NewNumericFunc - returns new numeric function of name n, e.g. "NumericLessThan".
+func NewNumericFunc(n name, key Key, values ...string) (Function, error) {
+	if _, found := numericOperators[n]; !found {
+		return nil, fmt.Errorf("%v is not a numeric condition", n)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty values for %v condition", n)
+	}
+
+	f := numericFunc{n: n, k: key, values: utils.CreateStringSet(values...)}
+	for _, s := range f.values.ToSlice() {
+		number, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value '%v' must be a number for %v condition", s, n)
+		}
+		f.numbers = append(f.numbers, number)
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,88 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// qualifiedString - returns string representation of qualified function f,
+// which replaces condition name of the base function.
+func qualifiedString(n name, f Function) string {
+	return fmt.Sprintf("%v%v", n, strings.TrimPrefix(f.String(), string(f.name())))
+}
+
+// ifExistsFunc - "...IfExists" condition function. It evaluates the base function
+// only if Key is present in given values, and is satisfied otherwise.
+// For example,
+//   - "StringEqualsIfExists" with Key = s3:x-amz-server-side-encryption and values = ["AES256"]
+//     is satisfied by requests with header x-amz-server-side-encryption: AES256, or without it.
+type ifExistsFunc struct {
+	Function
+}
+
+// evaluate() - evaluates the base function if Key is present in given values.
+func (f ifExistsFunc) evaluate(values map[string][]string) bool {
+	if len(f.key().requestValues(values)) == 0 {
+		return true
+	}
+
+	return f.Function.evaluate(values)
+}
+
+// name() - returns condition name with suffix "IfExists".
+func (f ifExistsFunc) name() name {
+	return f.Function.name() + ifExists
+}
+
+func (f ifExistsFunc) String() string {
+	return qualifiedString(f.name(), f.Function)
+}
+
+// setFunc - set qualified condition function, e.g. "ForAnyValue:StringLike". The base
+// function is evaluated with each value by Key in given values.
+//   - ForAnyValue is satisfied if any value satisfies the base function.
+//   - ForAllValues is satisfied if every value satisfies the base function,
+//     or Key is not present in given values.
+type setFunc struct {
+	Function
+	qualifier string
+}
+
+// evaluate() - evaluates the base function with each value by Key in given values.
+func (f setFunc) evaluate(values map[string][]string) bool {
+	keyName := f.key().valueName()
+	for _, v := range f.key().requestValues(values) {
+		matched := f.Function.evaluate(map[string][]string{keyName: {v}})
+		if f.qualifier == forAnyValue && matched {
+			return true
+		}
+		if f.qualifier == forAllValues && !matched {
+			return false
+		}
+	}
+
+	return f.qualifier == forAllValues
+}
+
+// name() - returns condition name with set qualifier.
+func (f setFunc) name() name {
+	return name(f.qualifier) + f.Function.name()
+}
+
+func (f setFunc) String() string {
+	return qualifiedString(f.name(), f.Function)
+}
+
+// newQualifiedFunc - qualifies base function f by set qualifier and "IfExists" of name n.
+// "IfExists" applies to the qualified function, so absent Key satisfies it either way.
+func newQualifiedFunc(n name, f Function) Function {
+	qualifier, _, isIfExists := n.split()
+	if qualifier != "" {
+		f = &setFunc{f, qualifier}
+	}
+	if isIfExists {
+		f = &ifExistsFunc{f}
+	}
+
+	return f
+}
@@ -44,7 +44,7 @@ type stringEqualsFunc struct {
 // evaluate() - evaluates to check whether value by Key in given values is in
 // condition values.
 func (f stringEqualsFunc) evaluate(values map[string][]string) bool {
-	requestValue := values[f.k.Name()]
+	requestValue := f.k.requestValues(values)
 	return !f.values.Intersection(utils.CreateStringSet(requestValue...)).IsEmpty()
 }
 
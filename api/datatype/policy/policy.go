@@ -79,6 +79,53 @@ func (policy Policy) IsAllowed(args Args) IsPolicyAllowedResult {
 	return NoPolicy
 }
 
+// Explain - works like IsAllowed, and also returns statements deciding the result,
+// i.e. all matched deny statements for PolicyDeny, or all matched allow statements
+// for PolicyAllow of other users.
+func (policy Policy) Explain(args Args) (IsPolicyAllowedResult, []Statement) {
+	var denied []Statement
+	for _, statement := range policy.Statements {
+		if statement.Effect == Deny {
+			if !statement.IsAllowed(args) {
+				denied = append(denied, statement)
+			}
+		}
+	}
+	if len(denied) != 0 {
+		return PolicyDeny, denied
+	}
+
+	if args.IsOwner {
+		return PolicyAllow, nil
+	}
+
+	var allowed []Statement
+	for _, statement := range policy.Statements {
+		if statement.Effect == Allow {
+			if statement.IsAllowed(args) {
+				allowed = append(allowed, statement)
+			}
+		}
+	}
+	if len(allowed) != 0 {
+		return PolicyAllow, allowed
+	}
+
+	return NoPolicy, nil
+}
+
+// IsPublic - returns whether any statement of policy grants access to everyone,
+// which is blocked or restricted by public access block settings.
+func (policy Policy) IsPublic() bool {
+	for _, statement := range policy.Statements {
+		if statement.isPublic() {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsEmpty - returns whether policy is empty or not.
 func (policy Policy) IsEmpty() bool {
 	return len(policy.Statements) == 0
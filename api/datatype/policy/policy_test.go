@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyIsPublic(t *testing.T) {
+	statement := func(effect, principal, condition string) string {
+		s := `{"Effect":"` + effect + `","Principal":` + principal +
+			`,"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"`
+		if condition != "" {
+			s += `,"Condition":` + condition
+		}
+		return s + "}"
+	}
+	testCases := []struct {
+		statements []string
+		public     bool
+	}{
+		{[]string{statement("Allow", `"*"`, "")}, true},
+		{[]string{statement("Allow", `{"AWS":"*"}`, "")}, true},
+		{[]string{statement("Allow", `{"AWS":["user","*"]}`, "")}, true},
+		{[]string{statement("Allow", `{"AWS":"user"}`, "")}, false},
+		{[]string{statement("Deny", `"*"`, "")}, false},
+		{[]string{statement("Allow", `{"Service":"logging.s3.amazonaws.com"}`, "")}, false},
+		{[]string{statement("Allow", `{"AWS":"user"}`, ""),
+			statement("Allow", `"*"`, `{"StringLike":{"aws:Referer":"http://example.com/*"}}`)}, true},
+		// fixed source IP ranges are not public
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`)}, false},
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}`)}, false},
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":"2001:db8::/32"}}`)}, false},
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":"2001:db8::/31"}}`)}, true},
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":"0.0.0.0/0"}}`)}, true},
+		{[]string{statement("Allow", `"*"`, `{"IpAddress":{"aws:SourceIp":["192.168.1.0/24","8.0.0.0/7"]}}`)}, true},
+		{[]string{statement("Allow", `"*"`, `{"NotIpAddress":{"aws:SourceIp":"192.168.1.0/24"}}`)}, true},
+		{[]string{statement("Allow", `"*"`,
+			`{"IpAddress":{"aws:SourceIp":"192.168.1.0/24"},"StringEquals":{"s3:x-amz-server-side-encryption":"AES256"}}`)},
+			false},
+	}
+	for i, testCase := range testCases {
+		data := `{"Version":"2012-10-17","Statement":[` + strings.Join(testCase.statements, ",") + `]}`
+		policy, err := ParseConfig(strings.NewReader(data), "b")
+		if err != nil {
+			t.Errorf("Case %d: invalid policy %s: %v", i, data, err)
+			continue
+		}
+		if public := policy.IsPublic(); public != testCase.public {
+			t.Errorf("Case %d: IsPublic of %s = %v, expected %v", i, data, public, testCase.public)
+		}
+	}
+}
@@ -22,19 +22,23 @@ import (
 	"github.com/journeymidnight/yig/api/datatype/policy/utils"
 )
 
-// Principal - policy principal.
+// LogDeliveryService - service principal delivering server access logs.
+const LogDeliveryService = "logging.s3.amazonaws.com"
+
+// Principal - policy principal, accounts in AWS, or services in Service.
 type Principal struct {
-	AWS utils.StringSet
+	AWS     utils.StringSet `json:",omitempty"`
+	Service utils.StringSet `json:",omitempty"`
 }
 
 // IsValid - checks whether Principal is valid or not.
 func (p Principal) IsValid() bool {
-	return len(p.AWS) != 0
+	return len(p.AWS) != 0 || len(p.Service) != 0
 }
 
 // Intersection - returns principals available in both Principal.
 func (p Principal) Intersection(principal Principal) utils.StringSet {
-	return p.AWS.Intersection(principal.AWS)
+	return p.AWS.Intersection(principal.AWS).Union(p.Service.Intersection(principal.Service))
 }
 
 // MarshalJSON - encodes Principal to JSON data.
@@ -57,7 +61,7 @@ func (p Principal) Match(principal string) bool {
 		}
 	}
 
-	return false
+	return p.Service.Contains(principal)
 }
 
 // UnmarshalJSON - decodes JSON data to Principal.
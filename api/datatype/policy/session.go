@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxSessionPolicySize is the max length of inline session policy of STS
+const MaxSessionPolicySize = 2048
+
+// ParseSessionPolicy parses inline session policy of temporary credentials.
+// Session policies have no Principal, their statements apply to the session itself.
+func ParseSessionPolicy(data string) (*Policy, error) {
+	if len(data) > MaxSessionPolicySize {
+		return nil, fmt.Errorf("session policy is larger than %d", MaxSessionPolicySize)
+	}
+	var raw struct {
+		ID         ID `json:"ID,omitempty"`
+		Version    string
+		Statements []map[string]json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, err
+	}
+	for _, statement := range raw.Statements {
+		if _, ok := statement["Principal"]; !ok {
+			statement["Principal"] = json.RawMessage(`"*"`)
+		}
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var policy Policy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return nil, err
+	}
+	if policy.IsEmpty() {
+		return nil, fmt.Errorf("session policy has no statement")
+	}
+	return &policy, nil
+}
+
+// IsSessionAllowed returns if session policy allows args,
+// only explicitly allowed actions are permitted
+func (policy Policy) IsSessionAllowed(args Args) bool {
+	args.IsOwner = false
+	return policy.IsAllowed(args) == PolicyAllow
+}
@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSessionPolicy(t *testing.T) {
+	testCases := []struct {
+		data       string
+		expectErr  bool
+		principals int
+	}{
+		{`{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`, false, 1},
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["a","b"]},` +
+			`"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`, false, 2},
+		{`{"Statement":[]}`, true, 0},
+		{`{"Statement":[{"Effect":"Allow","Action":"s3:Unknown","Resource":"arn:aws:s3:::b/*"}]}`, true, 0},
+		{`{"Statement":`, true, 0},
+		{`{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::` +
+			strings.Repeat("b", MaxSessionPolicySize) + `"}]}`, true, 0},
+	}
+	for i, testCase := range testCases {
+		policy, err := ParseSessionPolicy(testCase.data)
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("Case %d: error %v, expected error: %v", i, err, testCase.expectErr)
+			continue
+		}
+		if err == nil && len(policy.Statements[0].Principal.AWS) != testCase.principals {
+			t.Errorf("Case %d: principals %v, expected %d", i,
+				policy.Statements[0].Principal.AWS, testCase.principals)
+		}
+	}
+}
+
+func TestIsSessionAllowed(t *testing.T) {
+	policy, err := ParseSessionPolicy(`{"Statement":[` +
+		`{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":"arn:aws:s3:::b/*"},` +
+		`{"Effect":"Deny","Action":"s3:PutObject","Resource":"arn:aws:s3:::b/readonly/*"}]}`)
+	if err != nil {
+		t.Fatal("Invalid session policy:", err)
+	}
+	testCases := []struct {
+		args    Args
+		allowed bool
+	}{
+		{Args{Action: GetObjectAction, BucketName: "b", ObjectName: "o"}, true},
+		{Args{Action: PutObjectAction, BucketName: "b", ObjectName: "o"}, true},
+		{Args{Action: PutObjectAction, BucketName: "b", ObjectName: "readonly/o"}, false},
+		{Args{Action: GetObjectAction, BucketName: "c", ObjectName: "o"}, false},
+		{Args{Action: DeleteObjectAction, BucketName: "b", ObjectName: "o"}, false},
+		// bucket owners are limited by session policies as well
+		{Args{Action: DeleteObjectAction, BucketName: "b", ObjectName: "o", IsOwner: true}, false},
+	}
+	for i, testCase := range testCases {
+		if allowed := policy.IsSessionAllowed(testCase.args); allowed != testCase.allowed {
+			t.Errorf("Case %d: allowed %v, expected %v", i, allowed, testCase.allowed)
+		}
+	}
+}
@@ -83,18 +83,12 @@ func (statement Statement) isValid() error {
 	}
 
 	for action := range statement.Actions {
-		if action.isObjectAction() {
-			if !statement.Resources.objectResourceExists() {
-				return fmt.Errorf("unsupported Resource found %v for action %v", statement.Resources, action)
-			}
-		} else {
-			if !statement.Resources.bucketResourceExists() {
-				return fmt.Errorf("unsupported Resource found %v for action %v", statement.Resources, action)
-			}
+		if !statement.isResourceValid(action) {
+			return fmt.Errorf("unsupported Resource found %v for action %v", statement.Resources, action)
 		}
 
 		keys := statement.Conditions.Keys()
-		keyDiff := keys.Difference(actionConditionKeyMap[action])
+		keyDiff := keys.Difference(conditionKeysOf(action))
 		if !keyDiff.IsEmpty() {
 			return fmt.Errorf("unsupported condition keys '%v' used for action '%v'", keyDiff, action)
 		}
@@ -103,6 +97,33 @@ func (statement Statement) isValid() error {
 	return nil
 }
 
+// isResourceValid - checks whether resources match the type of action. Action patterns
+// may match both object and bucket actions, either type of resources is accepted.
+func (statement Statement) isResourceValid(action Action) bool {
+	actions := NewActionSet(action)
+	if action.isPattern() {
+		actions = action.expand()
+	}
+	for a := range actions {
+		if a.isObjectAction() {
+			if statement.Resources.objectResourceExists() {
+				return true
+			}
+		} else if statement.Resources.bucketResourceExists() {
+			return true
+		}
+	}
+	return false
+}
+
+// isPublic - checks whether statement allows everyone, i.e. wildcard principal
+// without restricting requests to fixed source IP ranges.
+func (statement Statement) isPublic() bool {
+	return statement.Effect == Allow &&
+		statement.Principal.AWS.Contains("*") &&
+		!statement.Conditions.IsFixedSourceIP()
+}
+
 // MarshalJSON - encodes JSON data to Statement.
 func (statement Statement) MarshalJSON() ([]byte, error) {
 	if err := statement.isValid(); err != nil {
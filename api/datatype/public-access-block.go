@@ -0,0 +1,71 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const MaxPublicAccessBlockSize = 4 * humanize.KiByte
+
+// PublicAccessBlockConfiguration is the Block Public Access settings of a bucket,
+// or the default of all buckets owned by an account
+type PublicAccessBlockConfiguration struct {
+	XMLName xml.Name `xml:"PublicAccessBlockConfiguration" json:"-"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty" json:"-"`
+	// reject requests setting public ACLs
+	BlockPublicAcls bool `xml:"BlockPublicAcls"`
+	// public ACLs grant nothing to other users
+	IgnorePublicAcls bool `xml:"IgnorePublicAcls"`
+	// reject bucket policies granting public access
+	BlockPublicPolicy bool `xml:"BlockPublicPolicy"`
+	// public bucket policies grant nothing to other users
+	RestrictPublicBuckets bool `xml:"RestrictPublicBuckets"`
+}
+
+// IsSet returns if any setting is enabled
+func (c PublicAccessBlockConfiguration) IsSet() bool {
+	return c.BlockPublicAcls || c.IgnorePublicAcls || c.BlockPublicPolicy || c.RestrictPublicBuckets
+}
+
+// Merge returns the most restrictive combination of both configurations,
+// as bucket settings and account settings are both enforced
+func (c PublicAccessBlockConfiguration) Merge(other PublicAccessBlockConfiguration) PublicAccessBlockConfiguration {
+	return PublicAccessBlockConfiguration{
+		BlockPublicAcls:       c.BlockPublicAcls || other.BlockPublicAcls,
+		IgnorePublicAcls:      c.IgnorePublicAcls || other.IgnorePublicAcls,
+		BlockPublicPolicy:     c.BlockPublicPolicy || other.BlockPublicPolicy,
+		RestrictPublicBuckets: c.RestrictPublicBuckets || other.RestrictPublicBuckets,
+	}
+}
+
+// IsPublicAcl returns if the ACL grants access to all users or authenticated users
+func IsPublicAcl(acl Acl) bool {
+	switch acl.CannedAcl {
+	case "public-read", "public-read-write", "authenticated-read":
+		return true
+	}
+	return false
+}
+
+func ParsePublicAccessBlockConfig(reader io.Reader) (*PublicAccessBlockConfiguration, error) {
+	config := new(PublicAccessBlockConfiguration)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxPublicAccessBlockSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read public access block body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxPublicAccessBlockSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, config)
+	if err != nil {
+		helper.Logger.Error("Unable to parse public access block XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	return config, nil
+}
@@ -0,0 +1,42 @@
+package datatype
+
+import (
+	"testing"
+)
+
+func TestIsPublicAcl(t *testing.T) {
+	var testcase = []struct {
+		acl    string
+		public bool
+	}{
+		{"private", false},
+		{"public-read", true},
+		{"public-read-write", true},
+		{"aws-exec-read", false},
+		{"authenticated-read", true},
+		{"bucket-owner-read", false},
+		{"bucket-owner-full-control", false},
+		// log delivery group is not public
+		{"log-delivery-write", false},
+		{"", false},
+	}
+	for _, c := range testcase {
+		if public := IsPublicAcl(Acl{CannedAcl: c.acl}); public != c.public {
+			t.Errorf("IsPublicAcl(%q) = %v, expected: %v", c.acl, public, c.public)
+		}
+	}
+}
+
+func TestPublicAccessBlockMerge(t *testing.T) {
+	bucket := PublicAccessBlockConfiguration{BlockPublicAcls: true, RestrictPublicBuckets: true}
+	account := PublicAccessBlockConfiguration{IgnorePublicAcls: true}
+	merged := bucket.Merge(account)
+	expected := PublicAccessBlockConfiguration{BlockPublicAcls: true, IgnorePublicAcls: true,
+		RestrictPublicBuckets: true}
+	if merged != expected {
+		t.Errorf("Merge of %+v and %+v = %+v, expected: %+v", bucket, account, merged, expected)
+	}
+	if (PublicAccessBlockConfiguration{}).IsSet() || !merged.IsSet() {
+		t.Error("IsSet of empty or merged configuration is wrong")
+	}
+}
@@ -0,0 +1,177 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxReplicationRulesCount = 1000
+	MaxReplicationRuleIdLen  = 255
+	MaxReplicationSize       = 20 * humanize.KiByte
+
+	ReplicationRuleEnabled  = "Enabled"
+	ReplicationRuleDisabled = "Disabled"
+)
+
+type ReplicationConfiguration struct {
+	XMLName xml.Name          `xml:"ReplicationConfiguration"`
+	Xmlns   string            `xml:"xmlns,attr,omitempty"`
+	Role    string            `xml:"Role,omitempty"`
+	Rules   []ReplicationRule `xml:"Rule"`
+}
+
+type ReplicationRule struct {
+	ID                      string                   `xml:"ID,omitempty"`
+	Priority                int                      `xml:"Priority,omitempty"`
+	Status                  string                   `xml:"Status"`
+	Prefix                  *string                  `xml:"Prefix,omitempty"`
+	Filter                  *ReplicationFilter       `xml:"Filter,omitempty"`
+	Destination             ReplicationDestination   `xml:"Destination"`
+	DeleteMarkerReplication *DeleteMarkerReplication `xml:"DeleteMarkerReplication,omitempty"`
+}
+
+type ReplicationFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type ReplicationDestination struct {
+	// ARN of destination bucket, "arn:aws:s3:<region>::<bucket>",
+	// region is the key of "replication_endpoints" in config and
+	// defaults to region of this deployment if empty
+	Bucket       string `xml:"Bucket"`
+	StorageClass string `xml:"StorageClass,omitempty"`
+}
+
+type DeleteMarkerReplication struct {
+	Status string `xml:"Status"`
+}
+
+func (r ReplicationRule) GetPrefix() string {
+	if r.Filter != nil {
+		return r.Filter.Prefix
+	}
+	if r.Prefix != nil {
+		return *r.Prefix
+	}
+	return ""
+}
+
+func (r ReplicationRule) ReplicateDeleteMarker() bool {
+	return r.DeleteMarkerReplication != nil && r.DeleteMarkerReplication.Status == ReplicationRuleEnabled
+}
+
+// DestinationRegion and DestinationBucket parse ARN of destination bucket
+func (d ReplicationDestination) DestinationRegion() string {
+	parts := strings.Split(d.Bucket, ":")
+	if len(parts) != 6 || parts[3] == "" {
+		return helper.CONFIG.Region
+	}
+	return parts[3]
+}
+
+func (d ReplicationDestination) DestinationBucket() string {
+	parts := strings.Split(d.Bucket, ":")
+	return parts[len(parts)-1]
+}
+
+func (d ReplicationDestination) validate() error {
+	// arn:partition:service:region:account-id:resource
+	parts := strings.Split(d.Bucket, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "s3" || parts[5] == "" ||
+		strings.Contains(parts[5], "/") {
+		return ErrInvalidReplicationDestination
+	}
+	if _, ok := helper.CONFIG.ReplicationEndpoints[d.DestinationRegion()]; !ok {
+		return ErrInvalidReplicationDestination
+	}
+	switch d.StorageClass {
+	case "", "STANDARD", "STANDARD_IA", "GLACIER":
+	default:
+		return ErrInvalidStorageClass
+	}
+	return nil
+}
+
+// MatchRule returns the enabled rule with the highest priority which
+// object name matches, nil if none
+func (c ReplicationConfiguration) MatchRule(objectName string) *ReplicationRule {
+	var matched *ReplicationRule
+	for i, rule := range c.Rules {
+		if rule.Status != ReplicationRuleEnabled || !strings.HasPrefix(objectName, rule.GetPrefix()) {
+			continue
+		}
+		if matched == nil || rule.Priority > matched.Priority {
+			matched = &c.Rules[i]
+		}
+	}
+	return matched
+}
+
+func (c ReplicationConfiguration) Validate() error {
+	if len(c.Rules) == 0 || len(c.Rules) > MaxReplicationRulesCount {
+		return ErrMalformedXML
+	}
+	ids := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if len(rule.ID) > MaxReplicationRuleIdLen {
+			return ErrInvalidReplicationRule
+		}
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return ErrInvalidReplicationRule
+			}
+			ids[rule.ID] = true
+		}
+		if rule.Status != ReplicationRuleEnabled && rule.Status != ReplicationRuleDisabled {
+			return ErrMalformedXML
+		}
+		if rule.Prefix != nil && rule.Filter != nil {
+			return ErrMalformedXML
+		}
+		if rule.DeleteMarkerReplication != nil {
+			status := rule.DeleteMarkerReplication.Status
+			if status != ReplicationRuleEnabled && status != ReplicationRuleDisabled {
+				return ErrMalformedXML
+			}
+		}
+		if err := rule.Destination.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ParseReplicationConfig(reader io.Reader) (*ReplicationConfiguration, error) {
+	replicationConfig := new(ReplicationConfiguration)
+	replicationBuffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxReplicationSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read replication body:", err)
+		return nil, err
+	}
+	if len(replicationBuffer) > MaxReplicationSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(replicationBuffer, replicationConfig)
+	if err != nil {
+		helper.Logger.Error("Unable to parse replication XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = replicationConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
+	// Assign an ID if not provided, as AWS does
+	for i := range replicationConfig.Rules {
+		if replicationConfig.Rules[i].ID == "" {
+			replicationConfig.Rules[i].ID = string(helper.GenerateRandomId())
+		}
+	}
+	return replicationConfig, nil
+}
@@ -0,0 +1,217 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxSelectRequestSize = 256 << 10 // 256K, SQL expression is limited to 256K by S3
+
+	SelectCompressionNone  = "NONE"
+	SelectCompressionGzip  = "GZIP"
+	SelectCompressionBzip2 = "BZIP2"
+
+	CsvFileHeaderUse    = "USE"
+	CsvFileHeaderIgnore = "IGNORE"
+	CsvFileHeaderNone   = "NONE"
+
+	JsonTypeDocument = "DOCUMENT"
+	JsonTypeLines    = "LINES"
+
+	CsvQuoteFieldsAlways   = "ALWAYS"
+	CsvQuoteFieldsAsNeeded = "ASNEEDED"
+)
+
+// SelectObjectContentRequest is body of POST "?select&select-type=2"
+type SelectObjectContentRequest struct {
+	XMLName             xml.Name            `xml:"SelectObjectContentRequest"`
+	Expression          string              `xml:"Expression"`
+	ExpressionType      string              `xml:"ExpressionType"`
+	RequestProgress     SelectProgress      `xml:"RequestProgress"`
+	InputSerialization  InputSerialization  `xml:"InputSerialization"`
+	OutputSerialization OutputSerialization `xml:"OutputSerialization"`
+	ScanRange           *SelectScanRange    `xml:"ScanRange"`
+}
+
+type SelectProgress struct {
+	Enabled bool `xml:"Enabled"`
+}
+
+type SelectScanRange struct {
+	Start *int64 `xml:"Start"`
+	End   *int64 `xml:"End"`
+}
+
+type InputSerialization struct {
+	CompressionType string        `xml:"CompressionType"`
+	CSV             *CSVInput     `xml:"CSV"`
+	JSON            *JSONInput    `xml:"JSON"`
+	Parquet         *ParquetInput `xml:"Parquet"`
+}
+
+type CSVInput struct {
+	FileHeaderInfo             string `xml:"FileHeaderInfo"`
+	Comments                   string `xml:"Comments"`
+	QuoteEscapeCharacter       string `xml:"QuoteEscapeCharacter"`
+	RecordDelimiter            string `xml:"RecordDelimiter"`
+	FieldDelimiter             string `xml:"FieldDelimiter"`
+	QuoteCharacter             string `xml:"QuoteCharacter"`
+	AllowQuotedRecordDelimiter bool   `xml:"AllowQuotedRecordDelimiter"`
+}
+
+type JSONInput struct {
+	Type string `xml:"Type"`
+}
+
+type ParquetInput struct{}
+
+type OutputSerialization struct {
+	CSV  *CSVOutput  `xml:"CSV"`
+	JSON *JSONOutput `xml:"JSON"`
+}
+
+type CSVOutput struct {
+	QuoteFields          string `xml:"QuoteFields"`
+	QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter"`
+	RecordDelimiter      string `xml:"RecordDelimiter"`
+	FieldDelimiter       string `xml:"FieldDelimiter"`
+	QuoteCharacter       string `xml:"QuoteCharacter"`
+}
+
+type JSONOutput struct {
+	RecordDelimiter string `xml:"RecordDelimiter"`
+}
+
+// SelectStats is payload of Stats and Progress events
+type SelectStats struct {
+	BytesScanned   int64 `xml:"BytesScanned"`
+	BytesProcessed int64 `xml:"BytesProcessed"`
+	BytesReturned  int64 `xml:"BytesReturned"`
+}
+
+// Validate checks the request and fills default values of serializations
+func (r *SelectObjectContentRequest) Validate() error {
+	if strings.TrimSpace(r.Expression) == "" {
+		return ErrSelectParseFailure
+	}
+	if r.ExpressionType != "SQL" {
+		return ErrInvalidExpressionType
+	}
+	if r.ScanRange != nil {
+		return ErrNotImplemented
+	}
+
+	input := &r.InputSerialization
+	switch strings.ToUpper(input.CompressionType) {
+	case "":
+		input.CompressionType = SelectCompressionNone
+	case SelectCompressionNone, SelectCompressionGzip, SelectCompressionBzip2:
+		input.CompressionType = strings.ToUpper(input.CompressionType)
+	default:
+		return ErrInvalidCompressionFormat
+	}
+	formats := 0
+	if input.CSV != nil {
+		formats++
+		csv := input.CSV
+		switch strings.ToUpper(csv.FileHeaderInfo) {
+		case "":
+			csv.FileHeaderInfo = CsvFileHeaderNone
+		case CsvFileHeaderUse, CsvFileHeaderIgnore, CsvFileHeaderNone:
+			csv.FileHeaderInfo = strings.ToUpper(csv.FileHeaderInfo)
+		default:
+			return ErrInvalidFileHeaderInfo
+		}
+		csv.FieldDelimiter = defaultString(csv.FieldDelimiter, ",")
+		csv.RecordDelimiter = defaultString(csv.RecordDelimiter, "\n")
+		csv.QuoteCharacter = defaultString(csv.QuoteCharacter, "\"")
+		csv.QuoteEscapeCharacter = defaultString(csv.QuoteEscapeCharacter, csv.QuoteCharacter)
+		if len(csv.FieldDelimiter) != 1 || len(csv.QuoteCharacter) != 1 ||
+			len(csv.QuoteEscapeCharacter) != 1 || len(csv.Comments) > 1 ||
+			len(csv.RecordDelimiter) < 1 || len(csv.RecordDelimiter) > 2 {
+			return ErrInvalidSelectRequestParameter
+		}
+	}
+	if input.JSON != nil {
+		formats++
+		switch strings.ToUpper(input.JSON.Type) {
+		case JsonTypeDocument, JsonTypeLines:
+			input.JSON.Type = strings.ToUpper(input.JSON.Type)
+		default:
+			return ErrInvalidJsonType
+		}
+	}
+	if input.Parquet != nil {
+		return ErrNotImplemented
+	}
+	if formats != 1 {
+		return ErrInvalidSelectRequestParameter
+	}
+
+	output := &r.OutputSerialization
+	formats = 0
+	if output.CSV != nil {
+		formats++
+		csv := output.CSV
+		switch strings.ToUpper(csv.QuoteFields) {
+		case "":
+			csv.QuoteFields = CsvQuoteFieldsAsNeeded
+		case CsvQuoteFieldsAlways, CsvQuoteFieldsAsNeeded:
+			csv.QuoteFields = strings.ToUpper(csv.QuoteFields)
+		default:
+			return ErrInvalidQuoteFields
+		}
+		csv.FieldDelimiter = defaultString(csv.FieldDelimiter, ",")
+		csv.RecordDelimiter = defaultString(csv.RecordDelimiter, "\n")
+		csv.QuoteCharacter = defaultString(csv.QuoteCharacter, "\"")
+		csv.QuoteEscapeCharacter = defaultString(csv.QuoteEscapeCharacter, csv.QuoteCharacter)
+		if len(csv.FieldDelimiter) != 1 || len(csv.QuoteCharacter) != 1 ||
+			len(csv.QuoteEscapeCharacter) != 1 ||
+			len(csv.RecordDelimiter) < 1 || len(csv.RecordDelimiter) > 2 {
+			return ErrInvalidSelectRequestParameter
+		}
+	}
+	if output.JSON != nil {
+		formats++
+		output.JSON.RecordDelimiter = defaultString(output.JSON.RecordDelimiter, "\n")
+	}
+	if formats != 1 {
+		return ErrInvalidSelectRequestParameter
+	}
+	return nil
+}
+
+func defaultString(s, defaultValue string) string {
+	if s == "" {
+		return defaultValue
+	}
+	return s
+}
+
+func ParseSelectRequest(reader io.Reader) (*SelectObjectContentRequest, error) {
+	request := new(SelectObjectContentRequest)
+	buffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxSelectRequestSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read select request body:", err)
+		return nil, err
+	}
+	if len(buffer) > MaxSelectRequestSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(buffer, request)
+	if err != nil {
+		helper.Logger.Error("Unable to parse select request XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
@@ -0,0 +1,89 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+const (
+	StsXmlns = "https://sts.amazonaws.com/doc/2011-06-15/"
+
+	// durations of temporary credentials in seconds
+	StsMinDuration              = 900
+	AssumeRoleDefaultDuration   = 3600
+	AssumeRoleMaxDuration       = 43200
+	SessionTokenDefaultDuration = 43200
+	SessionTokenMaxDuration     = 129600
+)
+
+var roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+type StsCredentials struct {
+	AccessKeyId     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+type AssumedRoleUser struct {
+	Arn           string `xml:"Arn"`
+	AssumedRoleId string `xml:"AssumedRoleId"`
+}
+
+type StsResponseMetadata struct {
+	RequestId string `xml:"RequestId"`
+}
+
+type AssumeRoleResult struct {
+	Credentials     StsCredentials  `xml:"Credentials"`
+	AssumedRoleUser AssumedRoleUser `xml:"AssumedRoleUser"`
+}
+
+type AssumeRoleResponse struct {
+	XMLName          xml.Name            `xml:"AssumeRoleResponse"`
+	Xmlns            string              `xml:"xmlns,attr,omitempty"`
+	Result           AssumeRoleResult    `xml:"AssumeRoleResult"`
+	ResponseMetadata StsResponseMetadata `xml:"ResponseMetadata"`
+}
+
+type GetSessionTokenResult struct {
+	Credentials StsCredentials `xml:"Credentials"`
+}
+
+type GetSessionTokenResponse struct {
+	XMLName          xml.Name              `xml:"GetSessionTokenResponse"`
+	Xmlns            string                `xml:"xmlns,attr,omitempty"`
+	Result           GetSessionTokenResult `xml:"GetSessionTokenResult"`
+	ResponseMetadata StsResponseMetadata   `xml:"ResponseMetadata"`
+}
+
+// ParseStsDuration parses DurationSeconds of STS requests
+func ParseStsDuration(value string, defaultSeconds, maxSeconds int) (time.Duration, error) {
+	if value == "" {
+		return time.Duration(defaultSeconds) * time.Second, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < StsMinDuration || seconds > maxSeconds {
+		return 0, ErrInvalidStsParameter
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// ParseRoleArn parses "arn:aws:iam::<account>:role/<name>", account could be empty
+func ParseRoleArn(arn string) (account, role string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" ||
+		!strings.HasPrefix(parts[5], "role/") || len(parts[5]) == len("role/") {
+		return "", "", ErrInvalidStsParameter
+	}
+	return parts[4], strings.TrimPrefix(parts[5], "role/"), nil
+}
+
+func IsValidRoleSessionName(name string) bool {
+	return roleSessionNamePattern.MatchString(name)
+}
@@ -0,0 +1,135 @@
+package datatype
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+)
+
+const (
+	MaxObjectTagsCount      = 10
+	MaxBucketTagsCount      = 50
+	MaxTagKeyLength         = 128
+	MaxTagValueLength       = 256
+	MaxTaggingSize          = 20 * humanize.KiByte
+	TaggingDirectiveCopy    = "COPY"
+	TaggingDirectiveReplace = "REPLACE"
+)
+
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+type Tag struct {
+	XMLName xml.Name `xml:"Tag"`
+	Key     string   `xml:"Key"`
+	Value   string   `xml:"Value"`
+}
+
+// NewTagging builds a Tagging response from stored tags, sorted by key
+func NewTagging(tags map[string]string) Tagging {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tagging := Tagging{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		TagSet: make([]Tag, 0, len(keys)),
+	}
+	for _, k := range keys {
+		tagging.TagSet = append(tagging.TagSet, Tag{Key: k, Value: tags[k]})
+	}
+	return tagging
+}
+
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/dev/object-tagging.html
+func (t Tagging) Validate(maxTags int) error {
+	if len(t.TagSet) > maxTags {
+		return ErrTooManyTags
+	}
+	keys := make(map[string]bool, len(t.TagSet))
+	for _, tag := range t.TagSet {
+		if err := validateTag(tag.Key, tag.Value); err != nil {
+			return err
+		}
+		if keys[tag.Key] {
+			return ErrInvalidTag
+		}
+		keys[tag.Key] = true
+	}
+	return nil
+}
+
+func (t Tagging) ToMap() map[string]string {
+	tags := make(map[string]string, len(t.TagSet))
+	for _, tag := range t.TagSet {
+		tags[tag.Key] = tag.Value
+	}
+	return tags
+}
+
+func validateTag(key, value string) error {
+	if key == "" || utf8.RuneCountInString(key) > MaxTagKeyLength ||
+		utf8.RuneCountInString(value) > MaxTagValueLength {
+		return ErrInvalidTag
+	}
+	return nil
+}
+
+func ParseTagging(reader io.Reader, maxTags int) (map[string]string, error) {
+	tagging := new(Tagging)
+	taggingBuffer, err := ioutil.ReadAll(io.LimitReader(reader, MaxTaggingSize+1))
+	if err != nil {
+		helper.Logger.Error("Unable to read tagging body:", err)
+		return nil, err
+	}
+	if len(taggingBuffer) > MaxTaggingSize {
+		return nil, ErrEntityTooLarge
+	}
+	err = xml.Unmarshal(taggingBuffer, tagging)
+	if err != nil {
+		helper.Logger.Error("Unable to parse tagging XML body:", err)
+		return nil, ErrMalformedXML
+	}
+	err = tagging.Validate(maxTags)
+	if err != nil {
+		return nil, err
+	}
+	return tagging.ToMap(), nil
+}
+
+// ParseTaggingHeader parses tags from header "x-amz-tagging",
+// which is URL query encoded, e.g. "Key1=Value1&Key2=Value2"
+func ParseTaggingHeader(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(header)
+	if err != nil {
+		return nil, ErrInvalidTag
+	}
+	if len(values) > MaxObjectTagsCount {
+		return nil, ErrTooManyTags
+	}
+	tags := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) != 1 {
+			return nil, ErrInvalidTag
+		}
+		if err := validateTag(k, v[0]); err != nil {
+			return nil, err
+		}
+		tags[k] = v[0]
+	}
+	return tags, nil
+}
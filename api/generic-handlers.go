@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/journeymidnight/yig/api/datatype"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/log"
@@ -114,6 +115,11 @@ func (h resourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := getRequestContext(r)
 	logger := ctx.Logger
 	bucketName, objectName := ctx.BucketName, ctx.ObjectName
+	// Website endpoints ignore resource queries
+	if ctx.IsWebsiteDomain {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
 	// If bucketName is present and not objectName check for bucket
 	// level resource queries.
 	if bucketName != "" && objectName == "" {
@@ -170,16 +176,12 @@ func ignoreNotImplementedObjectResources(req *http.Request) bool {
 
 // List of not implemented bucket queries
 var notImplementedBucketResourceNames = map[string]bool{
-	"notification":   true,
-	"replication":    true,
-	"tagging":        true,
 	"requestPayment": true,
 }
 
 // List of not implemented object queries
 var notImplementedObjectResourceNames = map[string]bool{
 	"torrent": true,
-	"tagging": true,
 }
 
 func ContextLogger(r *http.Request) log.Logger {
@@ -213,16 +215,24 @@ type GenerateContextHandler struct {
 func (h GenerateContextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var bucketInfo *types.Bucket
 	var objectInfo *types.Object
+	var publicAccessBlock datatype.PublicAccessBlockConfiguration
 	var err error
 	requestId := r.Context().Value(RequestIdKey).(string)
 	logger := r.Context().Value(ContextLoggerKey).(log.Logger)
-	bucketName, objectName, isBucketDomain := GetBucketAndObjectInfoFromRequest(r)
+	bucketName, objectName, isBucketDomain, isWebsiteDomain := GetBucketAndObjectInfoFromRequest(r)
 	if bucketName != "" {
 		bucketInfo, err = h.meta.GetBucket(bucketName, true)
 		if err != nil && err != ErrNoSuchBucket {
 			WriteErrorResponse(w, r, err)
 			return
 		}
+		if bucketInfo != nil {
+			publicAccessBlock, err = h.meta.GetEffectivePublicAccessBlock(bucketInfo)
+			if err != nil {
+				WriteErrorResponse(w, r, err)
+				return
+			}
+		}
 		if bucketInfo != nil && objectName != "" {
 			objectInfo, err = h.meta.GetObject(bucketInfo.Name, objectName, true)
 			if err != nil && err != ErrNoSuchKey {
@@ -237,19 +247,27 @@ func (h GenerateContextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		WriteErrorResponse(w, r, ErrSignatureVersionNotSupported)
 		return
 	}
+	var record *accessRecord
+	if isExplainDenyRequested(r) {
+		record = new(accessRecord)
+	}
 
 	ctx := context.WithValue(
 		r.Context(),
 		RequestContextKey,
 		RequestContext{
-			RequestID:      requestId,
-			Logger:         logger,
-			BucketName:     bucketName,
-			ObjectName:     objectName,
-			BucketInfo:     bucketInfo,
-			ObjectInfo:     objectInfo,
-			AuthType:       authType,
-			IsBucketDomain: isBucketDomain,
+			RequestID:       requestId,
+			Logger:          logger,
+			BucketName:      bucketName,
+			ObjectName:      objectName,
+			BucketInfo:      bucketInfo,
+			ObjectInfo:      objectInfo,
+			AuthType:        authType,
+			IsBucketDomain:  isBucketDomain,
+			IsWebsiteDomain: isWebsiteDomain,
+
+			PublicAccessBlock: publicAccessBlock,
+			accessRecord:      record,
 		})
 	logger.Info(fmt.Sprintf("BucketName: %s, ObjectName: %s, BucketInfo: %+v, ObjectInfo: %+v, AuthType: %d",
 		bucketName, objectName, bucketInfo, objectInfo, authType))
@@ -276,11 +294,20 @@ func InReservedOrigins(origin string) bool {
 
 //// helpers
 
-func GetBucketAndObjectInfoFromRequest(r *http.Request) (bucketName string, objectName string, isBucketDomain bool) {
+func GetBucketAndObjectInfoFromRequest(r *http.Request) (bucketName string, objectName string,
+	isBucketDomain bool, isWebsiteDomain bool) {
+
 	splits := strings.SplitN(r.URL.Path[1:], "/", 2)
 	v := strings.Split(r.Host, ":")
 	hostWithOutPort := v[0]
-	isBucketDomain, bucketName = helper.HasBucketInDomain(hostWithOutPort, ".", helper.CONFIG.S3Domain)
+	// Website endpoints only support virtual hosted-style requests,
+	// check them first in case a website domain is a subdomain of a S3 domain
+	isWebsiteDomain, bucketName = helper.HasBucketInDomain(hostWithOutPort, ".", helper.CONFIG.WebsiteDomain)
+	if isWebsiteDomain {
+		isBucketDomain = true
+	} else {
+		isBucketDomain, bucketName = helper.HasBucketInDomain(hostWithOutPort, ".", helper.CONFIG.S3Domain)
+	}
 	if isBucketDomain {
 		objectName = r.URL.Path[1:]
 	} else {
@@ -292,7 +319,7 @@ func GetBucketAndObjectInfoFromRequest(r *http.Request) (bucketName string, obje
 			objectName = splits[1]
 		}
 	}
-	return bucketName, objectName, isBucketDomain
+	return bucketName, objectName, isBucketDomain, isWebsiteDomain
 }
 
 func getRequestContext(r *http.Request) RequestContext {
@@ -89,17 +89,28 @@ func extractMetadataFromHeader(header http.Header) map[string]string {
 	return metadata
 }
 
+// bucketSseRequest returns SSE request of default encryption of the bucket
+func bucketSseRequest(configuration *ApplyServerSideEncryptionByDefault) (request SseRequest) {
+	switch configuration.SSEAlgorithm {
+	case crypto.SSEAlgorithmAES256:
+		request.Type = crypto.S3.String()
+	case crypto.SSEAlgorithmKMS:
+		request.Type = crypto.S3KMS.String()
+		request.SseAwsKmsKeyId = configuration.KMSMasterKeyID
+	}
+	return
+}
+
 func parseSseHeader(header http.Header) (request SseRequest, err error) {
 	// sse three options are mutually exclusive
-	if crypto.S3.IsRequested(header) && crypto.SSEC.IsRequested(header) {
+	if (crypto.S3.IsRequested(header) || crypto.S3KMS.IsRequested(header)) && crypto.SSEC.IsRequested(header) {
 		return request, ErrIncompatibleEncryptionMethod
 	}
 
 	if sse := header.Get(crypto.SSEHeader); sse != "" {
 		switch sse {
 		case crypto.SSEAlgorithmKMS:
-			err = ErrNotImplemented
-			return request, err
+			request.Type = crypto.S3KMS.String()
 		case crypto.SSEAlgorithmAES256:
 			request.Type = crypto.S3.String()
 		default:
@@ -117,10 +128,21 @@ func parseSseHeader(header http.Header) (request SseRequest, err error) {
 		}
 	}
 
+	// key ID and encryption context are only meaningful for SSE-KMS
+	if request.Type != crypto.S3KMS.String() &&
+		(header.Get(crypto.SSEKmsID) != "" || header.Get(crypto.SSEKmsContext) != "") {
+		return request, ErrInvalidSseHeader
+	}
+
 	switch request.Type {
 	case crypto.S3KMS.String():
-		// Not implemented yet
-		return request, ErrNotImplemented
+		// key ID is resolved by storage if not specified, context is bound to the data key
+		request.SseAwsKmsKeyId = header.Get(crypto.SSEKmsID)
+		request.SseContext = header.Get(crypto.SSEKmsContext)
+		if _, err = crypto.S3KMS.ParseContext(request.SseContext); err != nil {
+			return request, ErrInvalidSseHeader
+		}
+		return request, nil
 	case crypto.S3.String():
 		// encrypt key will retrieve from kms now
 		return request, nil
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+const (
+	spoolSuffix    = ".log"      // records being appended, one file per source bucket
+	deliverySuffix = ".delivery" // records waiting to be delivered
+)
+
+// LogDelivery spools server access log records of buckets with logging enabled,
+// and delivers them as objects under TargetPrefix of target buckets periodically.
+// Records are appended to local files, so they are delivered after YIG restarts.
+type LogDelivery struct {
+	objectLayer ObjectLayer
+	dir         string
+	interval    time.Duration
+
+	mutex  sync.Mutex
+	spools map[string]*os.File // keyed by source bucket
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+var logDelivery *LogDelivery
+
+// StartLogDelivery starts delivering spooled access logs, records are spooled
+// only after it is started
+func StartLogDelivery(objectLayer ObjectLayer) {
+	dir := helper.CONFIG.LogDeliverySpoolDir
+	err := os.MkdirAll(dir, 0700)
+	helper.PanicOnError(err, "Unable to create log delivery spool directory.")
+	logDelivery = &LogDelivery{
+		objectLayer: objectLayer,
+		dir:         dir,
+		interval:    time.Duration(helper.CONFIG.LogDeliveryInterval) * time.Second,
+		spools:      make(map[string]*os.File),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go logDelivery.run()
+}
+
+// StopLogDelivery closes spool files, records not delivered yet are delivered after restart
+func StopLogDelivery() {
+	if logDelivery == nil {
+		return
+	}
+	close(logDelivery.stop)
+	<-logDelivery.done
+	logDelivery.mutex.Lock()
+	defer logDelivery.mutex.Unlock()
+	for bucketName, f := range logDelivery.spools {
+		f.Close()
+		delete(logDelivery.spools, bucketName)
+	}
+}
+
+// Append spools a record of source bucket
+func (d *LogDelivery) Append(bucketName, record string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	f, ok := d.spools[bucketName]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(filepath.Join(d.dir, bucketName+spoolSuffix),
+			os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			helper.Logger.Error("Failed to open log spool of bucket", bucketName, "err:", err)
+			return
+		}
+		d.spools[bucketName] = f
+	}
+	_, err := f.WriteString(record + "\n")
+	if err == nil {
+		// records are kept if YIG crashes
+		err = f.Sync()
+	}
+	if err != nil {
+		helper.Logger.Error("Failed to spool access log of bucket", bucketName, "err:", err)
+	}
+}
+
+func (d *LogDelivery) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		// spool files left by last run are delivered at start
+		d.rotate()
+		d.deliverAll()
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rotate renames spool files to delivery files, so new records are appended to new spool files
+func (d *LogDelivery) rotate() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for bucketName, f := range d.spools {
+		f.Close()
+		delete(d.spools, bucketName)
+	}
+	spools, err := filepath.Glob(filepath.Join(d.dir, "*"+spoolSuffix))
+	if err != nil {
+		helper.Logger.Error("Failed to list log spools, err:", err)
+		return
+	}
+	now := time.Now().UnixNano()
+	for _, spool := range spools {
+		bucketName := strings.TrimSuffix(filepath.Base(spool), spoolSuffix)
+		delivery := filepath.Join(d.dir, fmt.Sprintf("%s.%d%s", bucketName, now, deliverySuffix))
+		err = os.Rename(spool, delivery)
+		if err != nil {
+			helper.Logger.Error("Failed to rotate log spool", spool, "err:", err)
+		}
+	}
+}
+
+func (d *LogDelivery) deliverAll() {
+	deliveries, err := filepath.Glob(filepath.Join(d.dir, "*"+deliverySuffix))
+	if err != nil {
+		helper.Logger.Error("Failed to list log deliveries, err:", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+		// file name is <bucket>.<rotated time>.delivery
+		name := strings.TrimSuffix(filepath.Base(delivery), deliverySuffix)
+		bucketName := name[:strings.LastIndex(name, ".")]
+		err = d.deliver(bucketName, delivery)
+		if err != nil {
+			// kept and retried in the next round
+			helper.Logger.Warn("Failed to deliver access logs of bucket", bucketName, "err:", err)
+			continue
+		}
+		os.Remove(delivery)
+	}
+}
+
+// deliver puts records in file as an object of target bucket, records are dropped
+// if logging is disabled or the target bucket is no longer valid
+func (d *LogDelivery) deliver(bucketName, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	bucket, err := d.objectLayer.GetBucket(bucketName)
+	if err == ErrNoSuchBucket {
+		helper.Logger.Warn("Access logs of deleted bucket", bucketName, "are dropped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bucket.BucketLogging.Enabled() {
+		helper.Logger.Warn("Logging of bucket", bucketName, "is disabled, access logs are dropped")
+		return nil
+	}
+	rule := bucket.BucketLogging.LoggingEnabled
+	target, err := d.objectLayer.GetBucket(rule.TargetBucket)
+	if err == ErrNoSuchBucket {
+		helper.Logger.Warn("Target bucket", rule.TargetBucket, "of bucket", bucketName,
+			"does not exist, access logs are dropped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	objectName, err := logObjectName(rule.TargetPrefix, time.Now())
+	if err != nil {
+		return err
+	}
+	config, err := d.objectLayer.GetEffectivePublicAccessBlock(target)
+	if err != nil {
+		return err
+	}
+	if !target.AllowsLogDelivery(bucket.OwnerId, objectName, config) {
+		helper.Logger.Warn("Target bucket", rule.TargetBucket, "does not permit log delivery of bucket",
+			bucketName, "access logs are dropped")
+		return nil
+	}
+	// log objects are owned by owner of the target bucket
+	credential := common.Credential{UserId: target.OwnerId}
+	metadata := map[string]string{"Content-Type": "text/plain"}
+	_, err = d.objectLayer.PutObject(target.Name, objectName, credential, info.Size(),
+		f, metadata, datatype.Acl{CannedAcl: "private"}, nil,
+		datatype.ObjectLock{}, datatype.SseRequest{}, meta.ObjectStorageClassStandard,
+		datatype.WriteCondition{})
+	if err != nil {
+		return err
+	}
+	helper.Logger.Info("Access logs of bucket", bucketName, "delivered to", target.Name, objectName)
+	return nil
+}
+
+// logObjectName returns key of log objects, i.e. TargetPrefixYYYY-mm-DD-HH-MM-SS-UniqueString
+func logObjectName(prefix string, t time.Time) (string, error) {
+	unique := make([]byte, 8)
+	_, err := rand.Read(unique)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s-%X", prefix, t.UTC().Format("2006-01-02-15-04-05"), unique), nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	. "github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+// GetObjectAttributesHandler - GET Object attributes
+// ----------
+// Returns attributes of x-amz-object-attributes without the object data,
+// parts are listed by "max-parts" and "part-number-marker" headers.
+func (api ObjectAPIHandlers) GetObjectAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	credential, err := checkRequestAuth(r, versionedAction(r, policy.GetObjectAttributesAction,
+		policy.GetObjectVersionAttributesAction))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	attributes, err := ParseObjectAttributesHeader(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	maxParts := MaxObjectAttributesParts
+	if maxPartsString := r.Header.Get("X-Amz-Max-Parts"); maxPartsString != "" {
+		maxParts, err = strconv.Atoi(maxPartsString)
+		if err != nil || maxParts < 0 || maxParts > MaxObjectAttributesParts {
+			WriteErrorResponse(w, r, ErrInvalidMaxParts)
+			return
+		}
+	}
+	partNumberMarker := 0
+	if markerString := r.Header.Get("X-Amz-Part-Number-Marker"); markerString != "" {
+		partNumberMarker, err = strconv.Atoi(markerString)
+		if err != nil || partNumberMarker < 0 {
+			WriteErrorResponse(w, r, ErrInvalidPartNumberMarker)
+			return
+		}
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.GetObjectInfoByCtx(ctx, version, credential)
+	if err != nil {
+		logger.Error("Unable to fetch object info:", err)
+		if err == ErrNoSuchKey {
+			api.errAllowableObjectNotFound(w, r, credential)
+			return
+		}
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if object.DeleteMarker {
+		w.Header().Set("x-amz-delete-marker", "true")
+		WriteErrorResponse(w, r, ErrNoSuchKey)
+		return
+	}
+
+	response := GetObjectAttributesResponse{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+	}
+	if attributes[ObjectAttributeETag] {
+		response.ETag = object.Etag
+	}
+	if attributes[ObjectAttributeChecksum] && object.Checksum.IsSet() {
+		checksum := NewObjectChecksum(object.Checksum)
+		response.Checksum = &checksum
+	}
+	if attributes[ObjectAttributeObjectParts] && len(object.Parts) > 0 {
+		response.ObjectParts = objectAttributesParts(object, partNumberMarker, maxParts)
+	}
+	if attributes[ObjectAttributeStorageClass] {
+		response.StorageClass = object.StorageClass.ToString()
+	}
+	if attributes[ObjectAttributeObjectSize] {
+		response.ObjectSize = &object.Size
+	}
+
+	encodedSuccessResponse, err := xmlFormat(response)
+	if err != nil {
+		logger.Error("Failed to marshal object attributes XML for object", object.Name,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	w.Header().Set("Last-Modified", object.LastModifiedTime.UTC().Format(http.TimeFormat))
+	setSubresourceVersionHeader(w, object, version)
+	setXmlHeader(w)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetObjectAttributes"
+	WriteSuccessResponse(w, encodedSuccessResponse)
+}
+
+// objectAttributesParts lists at most maxParts parts after partNumberMarker
+func objectAttributesParts(object *meta.Object, partNumberMarker, maxParts int) *ObjectAttributesParts {
+	parts := &ObjectAttributesParts{
+		TotalPartsCount:  len(object.Parts),
+		PartNumberMarker: partNumberMarker,
+		MaxParts:         maxParts,
+	}
+	// checksums of parts are of the algorithm of the object
+	algorithm := object.Checksum.Algorithm
+	for i := partNumberMarker + 1; i <= len(object.Parts); i++ {
+		p, ok := object.Parts[i]
+		if !ok {
+			continue
+		}
+		if len(parts.Parts) == maxParts {
+			parts.IsTruncated = true
+			break
+		}
+		parts.Parts = append(parts.Parts, ObjectAttributesPart{
+			PartNumber: p.PartNumber,
+			Size:       p.Size,
+			ObjectChecksum: NewObjectChecksum(Checksum{
+				Algorithm: algorithm,
+				Value:     p.Checksum,
+			}),
+		})
+		parts.NextPartNumberMarker = p.PartNumber
+	}
+	return parts
+}
@@ -35,6 +35,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // supportedGetReqParams - supported request parameters for GET presigned request.
@@ -103,9 +104,13 @@ func (api ObjectAPIHandlers) errAllowableObjectNotFound(w http.ResponseWriter, r
 	} else {
 		switch ctx.BucketInfo.ACL.CannedAcl {
 		case "public-read", "public-read-write":
-			err = ErrNoSuchKey
+			if credential.IgnorePublicAcls {
+				err = ErrAccessDenied
+			} else {
+				err = ErrNoSuchKey
+			}
 		case "authenticated-read":
-			if credential.AccessKeyID != "" {
+			if credential.AccessKeyID != "" && !credential.IgnorePublicAcls {
 				err = ErrNoSuchKey
 			} else {
 				err = ErrAccessDenied
@@ -118,28 +123,7 @@ func (api ObjectAPIHandlers) errAllowableObjectNotFound(w http.ResponseWriter, r
 			}
 		}
 	}
-	var status int
-	website := ctx.BucketInfo.Website
-	apiErrorCode, ok := err.(ApiError)
-	if ok {
-		status = apiErrorCode.HttpStatusCode()
-	} else {
-		status = http.StatusInternalServerError
-	}
-	// match routing rules
-	if len(website.RoutingRules) != 0 {
-		for _, rule := range website.RoutingRules {
-			// If the condition matches, handle redirect
-			if rule.Match(ctx.ObjectName, strconv.Itoa(status)) {
-				rule.DoRedirect(w, r, ctx.ObjectName)
-				return
-			}
-		}
-	}
-	if api.ReturnWebsiteErrorDocument(w, r, status) {
-		return
-	}
-	WriteErrorResponse(w, r, err)
+	api.writeWebsiteErrorResponse(w, r, err)
 }
 
 type GetObjectResponseWriter struct {
@@ -193,7 +177,7 @@ func (api ObjectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if credential, err = checkRequestAuth(r, policy.GetObjectAction); err != nil {
+	if credential, err = checkRequestAuth(r, versionedAction(r, policy.GetObjectAction, policy.GetObjectVersionAction)); err != nil {
 		WriteErrorResponse(w, r, err)
 		return
 	}
@@ -300,8 +284,7 @@ func (api ObjectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 	case "":
 		break
 	case crypto.S3KMS.String():
-		w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
-		// TODO: not implemented yet
+		setSseKmsHeaders(w, object.SseKmsKeyId)
 	case crypto.S3.String():
 		w.Header().Set("X-Amz-Server-Side-Encryption", "AES256")
 	case crypto.SSEC.String():
@@ -309,6 +292,10 @@ func (api ObjectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		w.Header().Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5",
 			r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
 	}
+	// checksum is of the whole object, not returned for ranges
+	if r.Header.Get("X-Amz-Checksum-Mode") == ChecksumModeEnabled && hrange == nil {
+		setChecksumHeaders(w, object.Checksum)
+	}
 
 	//ResponseRecorder
 	w.(*ResponseRecorder).operationName = "GetObject"
@@ -343,7 +330,7 @@ func (api ObjectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 	logger := ctx.Logger
 	var credential common.Credential
 	var err error
-	if credential, err = checkRequestAuth(r, policy.GetObjectAction); err != nil {
+	if credential, err = checkRequestAuth(r, versionedAction(r, policy.GetObjectAction, policy.GetObjectVersionAction)); err != nil {
 		WriteErrorResponse(w, r, err)
 		return
 	}
@@ -422,8 +409,7 @@ func (api ObjectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 	case "":
 		break
 	case crypto.S3KMS.String():
-		w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
-		// TODO not implemented yet
+		setSseKmsHeaders(w, object.SseKmsKeyId)
 	case crypto.S3.String():
 		w.Header().Set("X-Amz-Server-Side-Encryption", "AES256")
 	case crypto.SSEC.String():
@@ -431,6 +417,9 @@ func (api ObjectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		w.Header().Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5",
 			r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
 	}
+	if r.Header.Get("X-Amz-Checksum-Mode") == ChecksumModeEnabled && rangeHeader == "" {
+		setChecksumHeaders(w, object.Checksum)
+	}
 
 	//ResponseRecorder
 	w.(*ResponseRecorder).operationName = "HeadObject"
@@ -505,8 +494,14 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	logger.Info("Copying object from", sourceBucketName, sourceObjectName,
 		sourceVersion, "to", targetBucketName, targetObjectName)
 
+	sourceCredential, err := api.getCopySourceCredential(r, credential,
+		sourceBucketName, sourceObjectName, sourceVersion)
+	if err != nil {
+		WriteErrorResponseWithResource(w, r, err, copySource)
+		return
+	}
 	sourceObject, err := api.ObjectAPI.GetObjectInfo(sourceBucketName, sourceObjectName,
-		sourceVersion, credential)
+		sourceVersion, sourceCredential)
 	if err != nil {
 		logger.Error("Unable to fetch object info:", err)
 		WriteErrorResponseWithResource(w, r, err, copySource)
@@ -520,14 +515,15 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	}
 	if sseRequest.Type == "" {
 		if configuration, ok := api.ObjectAPI.CheckBucketEncryption(targetBucketName); ok {
-			if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 {
-				sseRequest.Type = crypto.S3.String()
-			}
-			//TODO:add kms
+			sseRequest = bucketSseRequest(configuration)
 		}
 	}
 	if sseRequest.Type == "" {
 		sseRequest.Type = sourceObject.SseType
+		if sourceObject.SseType == crypto.S3KMS.String() {
+			sseRequest.SseAwsKmsKeyId = sourceObject.SseKmsKeyId
+			sseRequest.SseContext = sourceObject.SseContext
+		}
 	}
 
 	// Verify before x-amz-copy-source preconditions before continuing with CopyObject.
@@ -600,7 +596,7 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		pipeWriter.Close()
 	}()
 
-	targetACL, err := getAclFromHeader(r.Header)
+	targetACL, err := getAclFromHeader(r)
 	if err != nil {
 		WriteErrorResponse(w, r, err)
 		return
@@ -614,6 +610,7 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	targetObject.Size = sourceObject.Size
 	targetObject.Etag = sourceObject.Etag
 	targetObject.Parts = sourceObject.Parts
+	targetObject.Checksum = sourceObject.Checksum
 	targetObject.Type = sourceObject.Type
 	targetObject.ObjectId = sourceObject.ObjectId
 	targetObject.Pool = sourceObject.Pool
@@ -637,6 +634,27 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	taggingDirective := r.Header.Get("X-Amz-Tagging-Directive")
+	if taggingDirective == TaggingDirectiveCopy || taggingDirective == "" {
+		targetObject.Tags = sourceObject.Tags
+	} else if taggingDirective == TaggingDirectiveReplace {
+		targetObject.Tags, err = ParseTaggingHeader(r.Header.Get("X-Amz-Tagging"))
+		if err != nil {
+			WriteErrorResponse(w, r, err)
+			return
+		}
+	} else {
+		WriteErrorResponse(w, r, ErrInvalidCopyRequest)
+		return
+	}
+
+	// Retention and legal hold are never copied from source object
+	targetObject.ObjectLock, err = ParseObjectLockHeaders(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
 	var isMetadataOnly bool
 	isMetadataOnly = false
 	if sourceBucketName == targetBucketName && sourceObjectName == targetObjectName {
@@ -678,6 +696,9 @@ func (api ObjectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 			w.Header().Set(headerName, header)
 		}
 	}
+	if result.SseAwsKmsKeyId != "" {
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
+	}
 
 	//ResponseRecorder
 	w.(*ResponseRecorder).operationName = "CopyObject"
@@ -771,6 +792,45 @@ func (api ObjectAPIHandlers) RenameObjectHandler(w http.ResponseWriter, r *http.
 	WriteSuccessResponse(w, encodedSuccessResponse)
 }
 
+// getCopySourceCredential evaluates bucket policy of copy source with s3:GetObject,
+// or s3:GetObjectVersion if source version is given. Permissions granted by bucket
+// policy of target bucket don't apply to source object.
+func (api ObjectAPIHandlers) getCopySourceCredential(r *http.Request, credential common.Credential,
+	sourceBucketName, sourceObjectName, sourceVersion string) (common.Credential, error) {
+
+	var action policy.Action = policy.GetObjectAction
+	if sourceVersion != "" {
+		action = policy.GetObjectVersionAction
+	}
+	credential.AllowOtherUserAccess = false
+	sourceBucket, err := api.ObjectAPI.GetBucket(sourceBucketName)
+	if err != nil {
+		return credential, err
+	}
+	credential.AllowOtherUserAccess, err = IsBucketPolicyAllowed(credential, sourceBucket, r,
+		action, sourceObjectName)
+	if err != nil {
+		return credential, err
+	}
+	publicAccessBlock, err := api.ObjectAPI.GetEffectivePublicAccessBlock(sourceBucket)
+	if err != nil {
+		return credential, err
+	}
+	err = RestrictOtherUserAccess(&credential, sourceBucket, publicAccessBlock)
+	return credential, err
+}
+
+// checkUploadPolicy evaluates bucket policy and Block Public Access settings of the bucket for uploads
+func checkUploadPolicy(r *http.Request, credential *common.Credential, objectName string) (err error) {
+	ctx := getRequestContext(r)
+	credential.AllowOtherUserAccess, err = IsBucketPolicyAllowed(*credential, ctx.BucketInfo, r,
+		policy.PutObjectAction, objectName)
+	if err != nil {
+		return err
+	}
+	return RestrictOtherUserAccess(credential, ctx.BucketInfo, ctx.PublicAccessBlock)
+}
+
 // PutObjectHandler - PUT Object
 // ----------
 // This implementation of the PUT operation adds an object to a bucket.
@@ -868,7 +928,7 @@ func (api ObjectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Parse SSE related headers
-	// Support SSE-S3 and SSE-C now
+	// Support SSE-S3, SSE-KMS and SSE-C now
 	var sseRequest SseRequest
 
 	if hasServerSideEncryptionHeader(r.Header) && !hasSuffix(objectName, "/") { // handle SSE requests
@@ -878,13 +938,28 @@ func (api ObjectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			return
 		}
 	} else if configuration, ok := api.ObjectAPI.CheckBucketEncryption(bucketName); ok {
-		if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 {
-			sseRequest.Type = crypto.S3.String()
-		}
-		//TODO:add kms
+		sseRequest = bucketSseRequest(configuration)
+	}
+
+	acl, err := getAclFromHeader(r)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
 	}
 
-	acl, err := getAclFromHeader(r.Header)
+	tags, err := ParseTaggingHeader(r.Header.Get("X-Amz-Tagging"))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	lock, err := ParseObjectLockHeaders(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	condition, err := ParseWriteCondition(r.Header)
 	if err != nil {
 		WriteErrorResponse(w, r, err)
 		return
@@ -896,9 +971,15 @@ func (api ObjectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Bucket policy may deny uploads by s3:RequestObjectTag
+	if err = checkUploadPolicy(r, &credential, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
 	var result PutObjectResult
 	result, err = api.ObjectAPI.PutObject(bucketName, objectName, credential, size, dataReadCloser,
-		metadata, acl, sseRequest, storageClass)
+		metadata, acl, tags, lock, sseRequest, storageClass, condition)
 	if err != nil {
 		logger.Error("Unable to create object", objectName, "error:", err)
 		WriteErrorResponse(w, r, err)
@@ -911,6 +992,7 @@ func (api ObjectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	if result.VersionId != "" {
 		w.Header().Set("x-amz-version-id", result.VersionId)
 	}
+	setChecksumHeaders(w, result.Checksum)
 	// Set SSE related headers
 	for _, headerName := range []string{
 		"X-Amz-Server-Side-Encryption",
@@ -922,6 +1004,9 @@ func (api ObjectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 			w.Header().Set(headerName, header)
 		}
 	}
+	if result.SseAwsKmsKeyId != "" {
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
+	}
 
 	// ResponseRecorder
 	w.(*ResponseRecorder).operationName = "PutObject"
@@ -1040,6 +1125,10 @@ func (api ObjectAPIHandlers) AppendObjectHandler(w http.ResponseWriter, r *http.
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutObjectAction, bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// Check whether the object is exist or not
 	// Check whether the bucket is owned by the specified user
@@ -1063,7 +1152,7 @@ func (api ObjectAPIHandlers) AppendObjectHandler(w http.ResponseWriter, r *http.
 
 	if err == ErrNoSuchKey {
 		if isFirstAppend(position) {
-			acl, err = getAclFromHeader(r.Header)
+			acl, err = getAclFromHeader(r)
 			if err != nil {
 				WriteErrorResponse(w, r, err)
 				return
@@ -1083,7 +1172,7 @@ func (api ObjectAPIHandlers) AppendObjectHandler(w http.ResponseWriter, r *http.
 			WriteErrorResponse(w, r, err)
 			return
 		}
-		if sseRequest.Type == crypto.SSEC.String() {
+		if sseRequest.Type == crypto.SSEC.String() || sseRequest.Type == crypto.S3KMS.String() {
 			WriteErrorResponse(w, r, ErrNotImplemented)
 			return
 		}
@@ -1193,11 +1282,19 @@ func (api ObjectAPIHandlers) PutObjectAclHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, versionedAction(r, policy.PutObjectAclAction,
+		policy.PutObjectVersionAclAction), bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 	var acl Acl
 	var policy AccessControlPolicy
 	if _, ok := r.Header["X-Amz-Acl"]; ok {
-		acl, err = getAclFromHeader(r.Header)
-		if err != nil {
+		acl, err = getAclFromHeader(r)
+		if err == ErrPublicAclBlocked {
+			WriteErrorResponse(w, r, err)
+			return
+		} else if err != nil {
 			WriteErrorResponse(w, r, ErrInvalidAcl)
 			return
 		}
@@ -1244,7 +1341,7 @@ func (api ObjectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	if credential, err = checkRequestAuth(r, policy.GetObjectAction); err != nil {
+	if credential, err = checkRequestAuth(r, policy.RestoreObjectAction); err != nil {
 		WriteErrorResponse(w, r, err)
 		return
 	}
@@ -1277,6 +1374,12 @@ func (api ObjectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http
 	if err != nil {
 		logger.Error("Unable to get freezer info:", err)
 		WriteErrorResponse(w, r, ErrInvalidRestoreInfo)
+		return
+	}
+	tier, err := meta.MatchRestoreTier(info.GlacierJobParameters.Tier)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
 	}
 
 	freezer, err := api.ObjectAPI.GetFreezerStatus(object.BucketName, object.Name, object.VersionId)
@@ -1284,12 +1387,14 @@ func (api ObjectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http
 		logger.Error("Unable to get restore object status", object.BucketName, object.Name,
 			"error:", err)
 		WriteErrorResponse(w, r, err)
+		return
 	}
 	if err == ErrNoSuchKey || freezer.Name == "" {
 		status, err := meta.MatchStatusIndex("READY")
 		if err != nil {
 			logger.Error("Unable to get freezer status:", err)
 			WriteErrorResponse(w, r, ErrInvalidRestoreInfo)
+			return
 		}
 
 		lifeTime := info.Days
@@ -1302,24 +1407,28 @@ func (api ObjectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http
 		targetFreezer.Name = object.Name
 		targetFreezer.Status = status
 		targetFreezer.LifeTime = lifeTime
+		targetFreezer.Tier = tier
+		targetFreezer.LastModifiedTime = time.Now().UTC()
 		err = api.ObjectAPI.CreateFreezer(targetFreezer)
 		if err != nil {
 			logger.Error("Unable to create freezer:", err)
 			WriteErrorResponse(w, r, ErrCreateRestoreObject)
+			return
 		}
 		logger.Info("Submit thaw request successfully")
 
 		// ResponseRecorder
-		w.WriteHeader(http.StatusAccepted)
 		w.(*ResponseRecorder).operationName = "RestoreObject"
 
 		WriteSuccessResponseWithStatus(w, nil, http.StatusAccepted)
+		return
 	}
 	if freezer.Status == meta.ObjectHasRestored {
 		err = api.ObjectAPI.UpdateFreezerDate(freezer, info.Days, true)
 		if err != nil {
 			logger.Error("Unable to Update freezer date:", err)
 			WriteErrorResponse(w, r, ErrInvalidRestoreInfo)
+			return
 		}
 
 		// ResponseRecorder
@@ -1331,6 +1440,7 @@ func (api ObjectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http
 			if err != nil {
 				logger.Error("Unable to Update freezer date:", err)
 				WriteErrorResponse(w, r, ErrInvalidRestoreInfo)
+				return
 			}
 		}
 		// ResponseRecorder
@@ -1361,6 +1471,11 @@ func (api ObjectAPIHandlers) GetObjectAclHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, versionedAction(r, policy.GetObjectAclAction,
+		policy.GetObjectVersionAclAction), bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	version := r.URL.Query().Get("versionId")
 	acl, err := api.ObjectAPI.GetObjectAcl(bucketName, objectName, version, credential)
@@ -1420,7 +1535,7 @@ func (api ObjectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		}
 	}
 
-	acl, err := getAclFromHeader(r.Header)
+	acl, err := getAclFromHeader(r)
 	if err != nil {
 		WriteErrorResponse(w, r, err)
 		return
@@ -1437,10 +1552,7 @@ func (api ObjectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 			return
 		}
 	} else if configuration, ok := api.ObjectAPI.CheckBucketEncryption(bucketName); ok {
-		if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 {
-			sseRequest.Type = crypto.S3.String()
-		}
-		//TODO:add kms
+		sseRequest = bucketSseRequest(configuration)
 	}
 
 	storageClass, err := getStorageClassFromHeader(r)
@@ -1449,8 +1561,32 @@ func (api ObjectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	tags, err := ParseTaggingHeader(r.Header.Get("X-Amz-Tagging"))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	lock, err := ParseObjectLockHeaders(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	// Bucket policy may deny uploads by s3:RequestObjectTag
+	if err = checkUploadPolicy(r, &credential, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	checksumAlgorithm, err := ParseChecksumAlgorithmHeader(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
 	uploadID, err := api.ObjectAPI.NewMultipartUpload(credential, bucketName, objectName,
-		metadata, acl, sseRequest, storageClass)
+		metadata, acl, tags, lock, sseRequest, storageClass, checksumAlgorithm)
 	if err != nil {
 		logger.Error("Unable to initiate new multipart upload id:", err)
 		WriteErrorResponse(w, r, err)
@@ -1470,6 +1606,12 @@ func (api ObjectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 			w.Header().Set(headerName, header)
 		}
 	}
+	if sseRequest.Type == crypto.S3KMS.String() {
+		setSseKmsHeaders(w, sseRequest.SseAwsKmsKeyId)
+	}
+	if checksumAlgorithm != "" {
+		w.Header().Set("X-Amz-Checksum-Algorithm", checksumAlgorithm)
+	}
 
 	//ResponseRecorder
 	w.(*ResponseRecorder).operationName = "NewMultipartUpload"
@@ -1548,6 +1690,10 @@ func (api ObjectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutObjectAction, bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	var result PutObjectPartResult
 	// No need to verify signature, anonymous request access is already allowed.
@@ -1563,13 +1709,12 @@ func (api ObjectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 	if result.ETag != "" {
 		w.Header()["ETag"] = []string{"\"" + result.ETag + "\""}
 	}
+	setChecksumHeaders(w, result.Checksum)
 	switch result.SseType {
 	case "":
 		break
 	case crypto.S3KMS.String():
-		w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
-		w.Header().Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
-			result.SseAwsKmsKeyIdBase64)
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
 	case crypto.S3.String():
 		w.Header().Set("X-Amz-Server-Side-Encryption", "AES256")
 	case crypto.SSEC.String():
@@ -1612,6 +1757,11 @@ func (api ObjectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutObjectAction,
+		targetBucketName, targetObjectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	targetUploadId := r.URL.Query().Get("uploadId")
 	partIdString := r.URL.Query().Get("partNumber")
@@ -1671,8 +1821,14 @@ func (api ObjectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	sourceCredential, err := api.getCopySourceCredential(r, credential,
+		sourceBucketName, sourceObjectName, sourceVersion)
+	if err != nil {
+		WriteErrorResponseWithResource(w, r, err, copySource)
+		return
+	}
 	sourceObject, err := api.ObjectAPI.GetObjectInfo(sourceBucketName, sourceObjectName,
-		sourceVersion, credential)
+		sourceVersion, sourceCredential)
 	if err != nil {
 		logger.Error("Unable to fetch object info:", err)
 		WriteErrorResponseWithResource(w, r, err, copySource)
@@ -1783,6 +1939,9 @@ func (api ObjectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 			w.Header().Set(headerName, header)
 		}
 	}
+	if result.SseAwsKmsKeyId != "" {
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
+	}
 
 	//ResponseRecorder
 	w.(*ResponseRecorder).operationName = "CopyObjectPart"
@@ -1814,6 +1973,11 @@ func (api ObjectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter,
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.AbortMultipartUploadAction,
+		bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	uploadId := r.URL.Query().Get("uploadId")
 	if err := api.ObjectAPI.AbortMultipartUpload(credential, bucketName,
@@ -1853,6 +2017,11 @@ func (api ObjectAPIHandlers) ListObjectPartsHandler(w http.ResponseWriter, r *ht
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.ListMultipartUploadPartsAction,
+		bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	request, err := parseListObjectPartsQuery(r.URL.Query())
 	if err != nil {
@@ -1901,6 +2070,15 @@ func (api ObjectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutObjectAction, bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	condition, err := ParseWriteCondition(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 	completeMultipartBytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		logger.Error(
@@ -1937,7 +2115,7 @@ func (api ObjectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 
 	var result CompleteMultipartResult
 	result, err = api.ObjectAPI.CompleteMultipartUpload(credential, bucketName,
-		objectName, uploadId, completeParts)
+		objectName, uploadId, completeParts, condition)
 
 	if err != nil {
 		logger.Error("Unable to complete multipart upload:", err)
@@ -1955,7 +2133,8 @@ func (api ObjectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	// Get object location.
 	location := GetLocation(r)
 	// Generate complete multipart response.
-	response := GenerateCompleteMultpartUploadResponse(bucketName, objectName, location, result.ETag)
+	response := GenerateCompleteMultpartUploadResponse(bucketName, objectName, location, result.ETag,
+		result.Checksum)
 	encodedSuccessResponse, err := xmlFormat(response)
 	if err != nil {
 		logger.Error("Unable to parse CompleteMultipartUpload response:", err)
@@ -1970,9 +2149,7 @@ func (api ObjectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	case "":
 		break
 	case crypto.S3KMS.String():
-		w.Header().Set("X-Amz-Server-Side-Encryption", "aws:kms")
-		w.Header().Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
-			result.SseAwsKmsKeyIdBase64)
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
 	case crypto.S3.String():
 		w.Header().Set("X-Amz-Server-Side-Encryption", "AES256")
 	case crypto.SSEC.String():
@@ -2013,10 +2190,16 @@ func (api ObjectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 			return
 		}
 	}
+	if err = checkRequestPolicy(r, &credential, versionedAction(r, policy.DeleteObjectAction,
+		policy.DeleteObjectVersionAction), bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 	version := r.URL.Query().Get("versionId")
+	bypassGovernance := canBypassGovernance(r, credential.UserId, getRequestContext(r).BucketInfo, objectName)
 	// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTObjectDELETE.html
 	// Ignore delete object errors, since we are supposed to reply only 204.
-	result, err := api.ObjectAPI.DeleteObject(bucketName, objectName, version, credential)
+	result, err := api.ObjectAPI.DeleteObject(bucketName, objectName, version, bypassGovernance, credential)
 	if err != nil {
 		WriteErrorResponse(w, r, err)
 		return
@@ -2101,6 +2284,10 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, r, err)
 		return
 	}
+	if err = checkRequestPolicy(r, &credential, policy.PutObjectAction, bucketName, objectName); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
 
 	// Convert form values to header type so those values could be handled as in
 	// normal requests
@@ -2113,7 +2300,7 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 
 	var acl Acl
 	acl.CannedAcl = headerfiedFormValues.Get("acl")
-	if acl.CannedAcl == "" {
+	if acl.CannedAcl == "" || aclsDisabled(r) {
 		acl.CannedAcl = "private"
 	}
 	err = IsValidCannedAcl(acl)
@@ -2121,6 +2308,10 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, r, ErrInvalidCannedAcl)
 		return
 	}
+	if getRequestContext(r).PublicAccessBlock.BlockPublicAcls && IsPublicAcl(acl) {
+		WriteErrorResponse(w, r, ErrPublicAclBlocked)
+		return
+	}
 
 	sseRequest, err := parseSseHeader(headerfiedFormValues)
 	if err != nil {
@@ -2129,10 +2320,7 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 	}
 	if sseRequest.Type == "" {
 		if configuration, ok := api.ObjectAPI.CheckBucketEncryption(bucketName); ok {
-			if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 {
-				sseRequest.Type = crypto.S3.String()
-			}
-			//TODO:add kms
+			sseRequest = bucketSseRequest(configuration)
 		}
 	}
 
@@ -2142,8 +2330,24 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	result, err := api.ObjectAPI.PutObject(bucketName, objectName, credential, -1, fileBody,
-		metadata, acl, sseRequest, storageClass)
+	// Form field "tagging" is a Tagging XML document
+	var tags map[string]string
+	if tagging, ok := formValues["Tagging"]; ok {
+		tags, err = ParseTagging(strings.NewReader(tagging), MaxObjectTagsCount)
+		if err != nil {
+			WriteErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	lock, err := ParseObjectLockHeaders(headerfiedFormValues)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	result, err := api.ObjectAPI.PostObject(bucketName, objectName, credential, -1, fileBody,
+		metadata, acl, tags, lock, sseRequest, storageClass)
 	if err != nil {
 		logger.Error("Unable to create object", objectName, "error:", err)
 		WriteErrorResponse(w, r, err)
@@ -2152,6 +2356,9 @@ func (api ObjectAPIHandlers) PostObjectHandler(w http.ResponseWriter, r *http.Re
 	if result.Md5 != "" {
 		w.Header().Set("ETag", "\""+result.Md5+"\"")
 	}
+	if result.SseAwsKmsKeyId != "" {
+		setSseKmsHeaders(w, result.SseAwsKmsKeyId)
+	}
 
 	var redirect string
 	redirect, _ = formValues["Success_action_redirect"]
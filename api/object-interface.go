@@ -28,7 +28,7 @@ import (
 // ObjectLayer implements primitives for object API layer.
 type ObjectLayer interface {
 	// Bucket operations.
-	MakeBucket(bucket string, acl datatype.Acl, credential common.Credential) error
+	MakeBucket(bucket string, acl datatype.Acl, objectLockEnabled bool, credential common.Credential) error
 	SetBucketLogging(bucket string, config datatype.BucketLoggingStatus) error
 	GetBucketLogging(bucket string) (datatype.BucketLoggingStatus, error)
 	SetBucketLifecycle(bucket string, config datatype.Lifecycle,
@@ -69,13 +69,48 @@ type ObjectLayer interface {
 	DeleteBucketEncryption(bucket *meta.Bucket) error
 	CheckBucketEncryption(bucket string) (*datatype.ApplyServerSideEncryptionByDefault, bool)
 
+	// Block Public Access operations
+	SetBucketPublicAccessBlock(bucket *meta.Bucket, config datatype.PublicAccessBlockConfiguration) error
+	GetBucketPublicAccessBlock(bucket string) (datatype.PublicAccessBlockConfiguration, error)
+	DeleteBucketPublicAccessBlock(bucket *meta.Bucket) error
+	GetEffectivePublicAccessBlock(bucket *meta.Bucket) (datatype.PublicAccessBlockConfiguration, error)
+
+	// Object Ownership operations
+	SetBucketOwnershipControls(bucket *meta.Bucket, controls datatype.OwnershipControls) error
+	GetBucketOwnershipControls(bucket string) (datatype.OwnershipControls, error)
+	DeleteBucketOwnershipControls(bucket *meta.Bucket) error
+
+	// Bucket tagging operations
+	SetBucketTagging(bucket *meta.Bucket, tags map[string]string) error
+	GetBucketTagging(bucket string) (map[string]string, error)
+	DeleteBucketTagging(bucket *meta.Bucket) error
+
+	// Notification operations
+	SetBucketNotification(bucket *meta.Bucket, config datatype.NotificationConfiguration) error
+	GetBucketNotification(bucket string) (datatype.NotificationConfiguration, error)
+
+	// Replication operations
+	SetBucketReplication(bucket *meta.Bucket, config datatype.ReplicationConfiguration) error
+	GetBucketReplication(bucket string) (datatype.ReplicationConfiguration, error)
+	DeleteBucketReplication(bucket *meta.Bucket) error
+
+	// Inventory operations
+	SetBucketInventory(bucket *meta.Bucket, config datatype.InventoryConfiguration) error
+	GetBucketInventory(bucket, id string) (datatype.InventoryConfiguration, error)
+	ListBucketInventory(bucket, continuationToken string) (datatype.ListInventoryConfigurationsResult, error)
+	DeleteBucketInventory(bucket *meta.Bucket, id string) error
+
 	// Object operations.
 	GetObject(object *meta.Object, startOffset int64, length int64, writer io.Writer,
 		sse datatype.SseRequest) (err error)
 	GetObjectInfo(bucket, object, version string, credential common.Credential) (objInfo *meta.Object, err error)
 	GetObjectInfoByCtx(ctx RequestContext, version string, credential common.Credential) (objInfo *meta.Object, err error)
 	PutObject(bucket, object string, credential common.Credential, size int64, data io.ReadCloser,
-		metadata map[string]string, acl datatype.Acl,
+		metadata map[string]string, acl datatype.Acl, tags map[string]string, lock datatype.ObjectLock,
+		sse datatype.SseRequest, storageClass meta.StorageClass,
+		condition datatype.WriteCondition) (result datatype.PutObjectResult, err error)
+	PostObject(bucket, object string, credential common.Credential, size int64, data io.ReadCloser,
+		metadata map[string]string, acl datatype.Acl, tags map[string]string, lock datatype.ObjectLock,
 		sse datatype.SseRequest, storageClass meta.StorageClass) (result datatype.PutObjectResult, err error)
 	AppendObject(bucket, object string, credential common.Credential, offset uint64, size int64, data io.ReadCloser,
 		metadata map[string]string, acl datatype.Acl,
@@ -89,15 +124,31 @@ type ObjectLayer interface {
 		acl datatype.Acl, credential common.Credential) error
 	GetObjectAcl(bucket string, object string, version string, credential common.Credential) (
 		policy datatype.AccessControlPolicyResponse, err error)
-	DeleteObject(bucket, object, version string, credential common.Credential) (datatype.DeleteObjectResult,
-		error)
+	DeleteObject(bucket, object, version string, bypassGovernance bool,
+		credential common.Credential) (datatype.DeleteObjectResult, error)
+
+	// Object tagging operations.
+	PutObjectTagging(bucket, object, version string, tags map[string]string,
+		credential common.Credential) (objInfo *meta.Object, err error)
+	GetObjectTagging(bucket, object, version string, credential common.Credential) (objInfo *meta.Object, err error)
+	DeleteObjectTagging(bucket, object, version string, credential common.Credential) (objInfo *meta.Object, err error)
+
+	// Object Lock operations.
+	SetBucketObjectLock(bucket *meta.Bucket, config datatype.ObjectLockConfiguration) error
+	GetBucketObjectLock(bucket string) (datatype.ObjectLockConfiguration, error)
+	PutObjectRetention(bucket, object, version string, retention datatype.ObjectRetention,
+		bypassGovernance bool, credential common.Credential) (objInfo *meta.Object, err error)
+	GetObjectRetention(bucket, object, version string, credential common.Credential) (objInfo *meta.Object, err error)
+	PutObjectLegalHold(bucket, object, version string, legalHold datatype.ObjectLegalHold,
+		credential common.Credential) (objInfo *meta.Object, err error)
+	GetObjectLegalHold(bucket, object, version string, credential common.Credential) (objInfo *meta.Object, err error)
 
 	// Multipart operations.
 	ListMultipartUploads(credential common.Credential, bucket string,
 		request datatype.ListUploadsRequest) (result datatype.ListMultipartUploadsResponse, err error)
 	NewMultipartUpload(credential common.Credential, bucket, object string,
-		metadata map[string]string, acl datatype.Acl,
-		sse datatype.SseRequest, storageClass meta.StorageClass) (uploadID string, err error)
+		metadata map[string]string, acl datatype.Acl, tags map[string]string, lock datatype.ObjectLock,
+		sse datatype.SseRequest, storageClass meta.StorageClass, checksumAlgorithm string) (uploadID string, err error)
 	PutObjectPart(bucket, object string, credential common.Credential, uploadID string, partID int,
 		size int64, data io.ReadCloser, md5Hex string,
 		sse datatype.SseRequest) (result datatype.PutObjectPartResult, err error)
@@ -108,7 +159,8 @@ type ObjectLayer interface {
 		request datatype.ListPartsRequest) (result datatype.ListPartsResponse, err error)
 	AbortMultipartUpload(credential common.Credential, bucket, object, uploadID string) error
 	CompleteMultipartUpload(credential common.Credential, bucket, object, uploadID string,
-		uploadedParts []meta.CompletePart) (result datatype.CompleteMultipartResult, err error)
+		uploadedParts []meta.CompletePart,
+		condition datatype.WriteCondition) (result datatype.CompleteMultipartResult, err error)
 
 	// Freezer operations.
 	GetFreezer(bucketName string, objectName string, version string) (freezer *meta.Freezer, err error)
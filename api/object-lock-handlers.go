@@ -0,0 +1,292 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/signature"
+)
+
+// PutBucketObjectLockConfigHandler - PUT Bucket object-lock
+// ----------
+// Enables Object Lock, or replaces the default retention of a bucket.
+// Object Lock could only be enabled on versioned buckets and never disabled.
+func (api ObjectAPIHandlers) PutBucketObjectLockConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	var credential common.Credential
+	var err error
+	switch ctx.AuthType {
+	default:
+		// For all unknown auth types return error.
+		WriteErrorResponse(w, r, ErrAccessDenied)
+		return
+	case signature.AuthTypePresignedV4, signature.AuthTypeSignedV4,
+		signature.AuthTypePresignedV2, signature.AuthTypeSignedV2:
+		if credential, err = signature.IsReqAuthenticated(r); err != nil {
+			WriteErrorResponse(w, r, err)
+			return
+		}
+	}
+	if err = checkRequestPolicy(r, &credential, policy.PutBucketObjectLockConfigurationAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if ctx.BucketInfo == nil {
+		WriteErrorResponse(w, r, ErrNoSuchBucket)
+		return
+	}
+	if credential.UserId != ctx.BucketInfo.OwnerId {
+		WriteErrorResponse(w, r, ErrBucketAccessForbidden)
+		return
+	}
+	// PutBucketObjectLockConfig always needs Content-Length.
+	if r.ContentLength <= 0 {
+		WriteErrorResponse(w, r, ErrMissingContentLength)
+		return
+	}
+
+	config, err := datatype.ParseObjectLockConfig(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	err = api.ObjectAPI.SetBucketObjectLock(ctx.BucketInfo, *config)
+	if err != nil {
+		logger.Error("Unable to set object lock for bucket:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "PutBucketObjectLockConfig"
+	WriteSuccessResponse(w, nil)
+}
+
+// GetBucketObjectLockConfigHandler - GET Bucket object-lock
+func (api ObjectAPIHandlers) GetBucketObjectLockConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	var credential common.Credential
+	var err error
+	switch ctx.AuthType {
+	default:
+		// For all unknown auth types return error.
+		WriteErrorResponse(w, r, ErrAccessDenied)
+		return
+	case signature.AuthTypePresignedV4, signature.AuthTypeSignedV4,
+		signature.AuthTypePresignedV2, signature.AuthTypeSignedV2:
+		if credential, err = signature.IsReqAuthenticated(r); err != nil {
+			WriteErrorResponse(w, r, err)
+			return
+		}
+	}
+	if err = checkRequestPolicy(r, &credential, policy.GetBucketObjectLockConfigurationAction, ctx.BucketName, ""); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if ctx.BucketInfo == nil {
+		WriteErrorResponse(w, r, ErrNoSuchBucket)
+		return
+	}
+	if credential.UserId != ctx.BucketInfo.OwnerId {
+		WriteErrorResponse(w, r, ErrBucketAccessForbidden)
+		return
+	}
+
+	config, err := api.ObjectAPI.GetBucketObjectLock(ctx.BucketName)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	config.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	encodedSuccessResponse, err := xmlFormat(config)
+	if err != nil {
+		logger.Error("Failed to marshal object lock XML for bucket", ctx.BucketName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+
+	setXmlHeader(w)
+	//ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetBucketObjectLockConfig"
+	// Write to client.
+	WriteSuccessResponse(w, encodedSuccessResponse)
+}
+
+// PutObjectRetentionHandler - PUT Object retention
+// ----------
+// Sets retention of an object, or of a specific version if "versionId" is set.
+// Shortening retention of GOVERNANCE mode needs x-amz-bypass-governance-retention.
+func (api ObjectAPIHandlers) PutObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, policy.PutObjectRetentionAction)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if r.ContentLength <= 0 {
+		WriteErrorResponse(w, r, ErrMissingContentLength)
+		return
+	}
+
+	retention, err := datatype.ParseObjectRetention(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	bypassGovernance := canBypassGovernance(r, credential.UserId, ctx.BucketInfo, objectName)
+	object, err := api.ObjectAPI.PutObjectRetention(bucketName, objectName, version, *retention,
+		bypassGovernance, credential)
+	if err != nil {
+		logger.Error("Unable to put object retention:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "PutObjectRetention"
+	WriteSuccessResponse(w, nil)
+}
+
+// GetObjectRetentionHandler - GET Object retention
+func (api ObjectAPIHandlers) GetObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, policy.GetObjectRetentionAction)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.GetObjectRetention(bucketName, objectName, version, credential)
+	if err != nil {
+		logger.Error("Unable to get object retention:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if object.ObjectLock.Mode == "" {
+		WriteErrorResponse(w, r, ErrNoSuchObjectLockConfiguration)
+		return
+	}
+
+	retention := object.ObjectLock.Retention()
+	retention.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	retentionBuffer, err := xmlFormat(retention)
+	if err != nil {
+		logger.Error("Failed to marshal retention XML for object", objectName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+	setXmlHeader(w)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetObjectRetention"
+	WriteSuccessResponse(w, retentionBuffer)
+}
+
+// PutObjectLegalHoldHandler - PUT Object legal-hold
+func (api ObjectAPIHandlers) PutObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, policy.PutObjectLegalHoldAction)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if r.ContentLength <= 0 {
+		WriteErrorResponse(w, r, ErrMissingContentLength)
+		return
+	}
+
+	legalHold, err := datatype.ParseObjectLegalHold(io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.PutObjectLegalHold(bucketName, objectName, version, *legalHold, credential)
+	if err != nil {
+		logger.Error("Unable to put object legal hold:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "PutObjectLegalHold"
+	WriteSuccessResponse(w, nil)
+}
+
+// GetObjectLegalHoldHandler - GET Object legal-hold
+func (api ObjectAPIHandlers) GetObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, policy.GetObjectLegalHoldAction)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.GetObjectLegalHold(bucketName, objectName, version, credential)
+	if err != nil {
+		logger.Error("Unable to get object legal hold:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	legalHold := datatype.ObjectLegalHold{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Status: datatype.LegalHoldOff,
+	}
+	if object.ObjectLock.IsLegalHoldOn() {
+		legalHold.Status = datatype.LegalHoldOn
+	}
+	legalHoldBuffer, err := xmlFormat(legalHold)
+	if err != nil {
+		logger.Error("Failed to marshal legal hold XML for object", objectName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+	setXmlHeader(w)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetObjectLegalHold"
+	WriteSuccessResponse(w, legalHoldBuffer)
+}
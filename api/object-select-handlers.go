@@ -0,0 +1,95 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/s3select"
+)
+
+// SelectObjectContentHandler - POST Object "?select&select-type=2"
+// ----------
+// Filters content of a CSV or JSON object with a SQL expression, results are
+// streamed in event stream encoding. Objects are decrypted as GetObject does.
+func (api ObjectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+	var credential common.Credential
+	var err error
+	if credential, err = checkRequestAuth(r, versionedAction(r, policy.GetObjectAction, policy.GetObjectVersionAction)); err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	request, err := datatype.ParseSelectRequest(r.Body)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	selector, err := s3select.New(request)
+	if err != nil {
+		logger.Error("Unable to parse select expression:", request.Expression, "err:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.GetObjectInfoByCtx(ctx, version, credential)
+	if err != nil {
+		logger.Error("Unable to fetch object info:", err)
+		if err == ErrNoSuchKey {
+			api.errAllowableObjectNotFound(w, r, credential)
+			return
+		}
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if object.DeleteMarker {
+		w.Header().Set("x-amz-delete-marker", "true")
+		WriteErrorResponse(w, r, ErrNoSuchKey)
+		return
+	}
+	if object.StorageClass == meta.ObjectStorageClassGlacier {
+		WriteErrorResponse(w, r, ErrInvalidGlacierObject)
+		return
+	}
+
+	sseRequest, err := parseSseHeader(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if len(sseRequest.CopySourceSseCustomerKey) != 0 {
+		WriteErrorResponse(w, r, ErrInvalidSseHeader)
+		return
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		err := api.ObjectAPI.GetObject(object, 0, object.Size, writer, sseRequest)
+		writer.CloseWithError(err)
+	}()
+	defer reader.Close()
+
+	// errors are sent in the event stream since now
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.(*ResponseRecorder).operationName = "SelectObjectContent"
+	w.WriteHeader(http.StatusOK)
+	err = selector.Run(reader, w)
+	if err != nil {
+		logger.Error("Select object content error:", err)
+		w.(*ResponseRecorder).errorCode = selectErrorCode(err)
+	}
+}
+
+func selectErrorCode(err error) string {
+	if apiErr, ok := err.(ApiError); ok {
+		return apiErr.AwsErrorCode()
+	}
+	return "InternalError"
+}
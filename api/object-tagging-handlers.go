@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+func setSubresourceVersionHeader(w http.ResponseWriter, object *meta.Object, version string) {
+	if version != "" || !object.NullVersion {
+		w.Header().Set("x-amz-version-id", object.GetVersionId())
+	}
+}
+
+// PutObjectTaggingHandler - PUT Object tagging
+// ----------
+// Replaces the whole tag set of an object, or of a specific version
+// if "versionId" is set.
+func (api ObjectAPIHandlers) PutObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	// Tags in request body are needed for s3:RequestObjectTag in bucket policy,
+	// so read the body before authentication and put it back for signature verifying.
+	taggingBuffer, err := ioutil.ReadAll(io.LimitReader(r.Body, datatype.MaxTaggingSize+1))
+	if err != nil {
+		logger.Error("Unable to read tagging body:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(taggingBuffer))
+	tags, parseErr := datatype.ParseTagging(bytes.NewReader(taggingBuffer), datatype.MaxObjectTagsCount)
+
+	credential, err := checkRequestAuthWithConditions(r, versionedAction(r, policy.PutObjectTaggingAction,
+		policy.PutObjectVersionTaggingAction),
+		getRequestTagConditionValues(tags))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	if parseErr != nil {
+		WriteErrorResponse(w, r, parseErr)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.PutObjectTagging(bucketName, objectName, version, tags, credential)
+	if err != nil {
+		logger.Error("Unable to put object tagging:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "PutObjectTagging"
+	WriteSuccessResponse(w, nil)
+}
+
+// GetObjectTaggingHandler - GET Object tagging
+func (api ObjectAPIHandlers) GetObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, versionedAction(r, policy.GetObjectTaggingAction,
+		policy.GetObjectVersionTaggingAction))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.GetObjectTagging(bucketName, objectName, version, credential)
+	if err != nil {
+		logger.Error("Unable to get object tagging:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	taggingBuffer, err := xmlFormat(datatype.NewTagging(object.Tags))
+	if err != nil {
+		logger.Error("Failed to marshal tagging XML for object", objectName,
+			"error:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+	setXmlHeader(w)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "GetObjectTagging"
+	WriteSuccessResponse(w, taggingBuffer)
+}
+
+// DeleteObjectTaggingHandler - DELETE Object tagging
+func (api ObjectAPIHandlers) DeleteObjectTaggingHandler(w http.ResponseWriter, r *http.Request) {
+	logger := ContextLogger(r)
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectName := vars["object"]
+
+	credential, err := checkRequestAuth(r, versionedAction(r, policy.DeleteObjectTaggingAction,
+		policy.DeleteObjectVersionTaggingAction))
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	version := r.URL.Query().Get("versionId")
+	object, err := api.ObjectAPI.DeleteObjectTagging(bucketName, objectName, version, credential)
+	if err != nil {
+		logger.Error("Unable to delete object tagging:", err)
+		WriteErrorResponse(w, r, err)
+		return
+	}
+	setSubresourceVersionHeader(w, object, version)
+
+	// ResponseRecorder
+	w.(*ResponseRecorder).operationName = "DeleteObjectTagging"
+	WriteSuccessNoContent(w)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/journeymidnight/yig/api/datatype"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/log"
@@ -25,14 +26,19 @@ type ContextLoggerKeyType string
 const ContextLoggerKey ContextLoggerKeyType = "ContextLogger"
 
 type RequestContext struct {
-	RequestID      string
-	Logger         log.Logger
-	BucketName     string
-	ObjectName     string
-	BucketInfo     *types.Bucket
-	ObjectInfo     *types.Object
-	AuthType       signature.AuthType
-	IsBucketDomain bool
+	RequestID       string
+	Logger          log.Logger
+	BucketName      string
+	ObjectName      string
+	BucketInfo      *types.Bucket
+	ObjectInfo      *types.Object
+	AuthType        signature.AuthType
+	IsBucketDomain  bool
+	IsWebsiteDomain bool
+	// Block Public Access settings enforced on the bucket
+	PublicAccessBlock datatype.PublicAccessBlockConfiguration
+	// last authorization of request to explain its denial, nil unless requested
+	accessRecord *accessRecord
 }
 
 type Server struct {
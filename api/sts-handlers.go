@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/signature"
+)
+
+// StsHandler - STS API, POST / with Action in form
+// ----------
+// AssumeRole and GetSessionToken issue temporary credentials of the caller,
+// which are used with x-amz-security-token in all signature versions.
+func (api ObjectAPIHandlers) StsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := getRequestContext(r)
+	logger := ctx.Logger
+
+	if !iam.IsStsEnabled() {
+		WriteErrorResponse(w, r, ErrNotImplemented)
+		return
+	}
+	var credential common.Credential
+	var err error
+	switch ctx.AuthType {
+	default:
+		// Temporary credentials are never issued to anonymous users
+		WriteErrorResponse(w, r, ErrAccessDenied)
+		return
+	case signature.AuthTypeSignedV4, signature.AuthTypeSignedV2:
+		if credential, err = signature.IsReqAuthenticated(r); err != nil {
+			WriteErrorResponse(w, r, err)
+			return
+		}
+	}
+	if err = r.ParseForm(); err != nil {
+		logger.Error("Unable to parse STS form:", err)
+		WriteErrorResponse(w, r, ErrInvalidStsParameter)
+		return
+	}
+
+	var response interface{}
+	switch action := r.PostForm.Get("Action"); action {
+	case "AssumeRole":
+		response, err = assumeRole(r, credential)
+		w.(*ResponseRecorder).operationName = "AssumeRole"
+	case "GetSessionToken":
+		response, err = getSessionToken(r, credential)
+		w.(*ResponseRecorder).operationName = "GetSessionToken"
+	default:
+		logger.Info("Unsupported STS action:", action)
+		err = ErrNotImplemented
+	}
+	if err != nil {
+		WriteErrorResponse(w, r, err)
+		return
+	}
+
+	encodedSuccessResponse, err := xmlFormat(response)
+	if err != nil {
+		logger.Error("Failed to marshal STS XML:", err)
+		WriteErrorResponse(w, r, ErrInternalError)
+		return
+	}
+	setXmlHeader(w)
+	WriteSuccessResponse(w, encodedSuccessResponse)
+}
+
+// assumeRole issues temporary credentials scoped by session policy. There're no
+// IAM roles in YIG, the caller assumes itself and RoleArn must be in its account.
+func assumeRole(r *http.Request, credential common.Credential) (response AssumeRoleResponse, err error) {
+	account, role, err := ParseRoleArn(r.PostForm.Get("RoleArn"))
+	if err != nil {
+		return
+	}
+	if account != "" && account != credential.UserId {
+		return response, ErrAccessDenied
+	}
+	sessionName := r.PostForm.Get("RoleSessionName")
+	if !IsValidRoleSessionName(sessionName) {
+		return response, ErrInvalidStsParameter
+	}
+	duration, err := ParseStsDuration(r.PostForm.Get("DurationSeconds"),
+		AssumeRoleDefaultDuration, AssumeRoleMaxDuration)
+	if err != nil {
+		return
+	}
+	sessionPolicy := r.PostForm.Get("Policy")
+	if sessionPolicy != "" {
+		if len(sessionPolicy) > policy.MaxSessionPolicySize {
+			return response, ErrSessionPolicyTooLarge
+		}
+		if _, err = policy.ParseSessionPolicy(sessionPolicy); err != nil {
+			getRequestContext(r).Logger.Info("Invalid session policy:", err)
+			return response, ErrMalformedSessionPolicy
+		}
+	}
+
+	session, err := iam.NewSessionCredential(credential, duration, sessionPolicy)
+	if err != nil {
+		return
+	}
+	response.Xmlns = StsXmlns
+	response.Result.Credentials = stsCredentials(session)
+	response.Result.AssumedRoleUser = AssumedRoleUser{
+		Arn:           fmt.Sprintf("arn:aws:sts::%s:assumed-role/%s/%s", credential.UserId, role, sessionName),
+		AssumedRoleId: session.AccessKeyID + ":" + sessionName,
+	}
+	response.ResponseMetadata.RequestId = getRequestContext(r).RequestID
+	return response, nil
+}
+
+// getSessionToken issues temporary credentials with all rights of the caller
+func getSessionToken(r *http.Request, credential common.Credential) (response GetSessionTokenResponse, err error) {
+	duration, err := ParseStsDuration(r.PostForm.Get("DurationSeconds"),
+		SessionTokenDefaultDuration, SessionTokenMaxDuration)
+	if err != nil {
+		return
+	}
+	session, err := iam.NewSessionCredential(credential, duration, "")
+	if err != nil {
+		return
+	}
+	response.Xmlns = StsXmlns
+	response.Result.Credentials = stsCredentials(session)
+	response.ResponseMetadata.RequestId = getRequestContext(r).RequestID
+	return response, nil
+}
+
+func stsCredentials(session common.Credential) StsCredentials {
+	return StsCredentials{
+		AccessKeyId:     session.AccessKeyID,
+		SecretAccessKey: session.SecretAccessKey,
+		SessionToken:    session.SessionToken,
+		Expiration:      session.Expiration,
+	}
+}
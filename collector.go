@@ -23,6 +23,7 @@ type UsageDataWithBucket struct {
 	value        int64
 	owner        string
 	storageClass string
+	tags         []string // values of bucket tags in helper.CONFIG.UsageTagLabels
 }
 
 type UsageData struct {
@@ -34,10 +35,25 @@ func newGlobalMetric(namespace string, metricName string, docString string, labe
 	return prometheus.NewDesc(namespace+"_"+metricName, docString, labels, nil)
 }
 
+// Bucket tag key is exported as label "tag_<key>", with characters
+// not allowed in label names replaced by "_", e.g. "cost-center" as "tag_cost_center"
+func tagLabelName(key string) string {
+	return "tag_" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
 func NewMetrics(namespace string) *Metrics {
+	bucketLabels := []string{"bucket_name", "owner", "storage_class"}
+	for _, key := range helper.CONFIG.UsageTagLabels {
+		bucketLabels = append(bucketLabels, tagLabelName(key))
+	}
 	return &Metrics{
 		metrics: map[string]*prometheus.Desc{
-			"bucket_usage_byte_metric": newGlobalMetric(namespace, "bucket_usage_byte_metric", "The description of bucket_usage_byte_metric", []string{"bucket_name", "owner", "storage_class"}),
+			"bucket_usage_byte_metric": newGlobalMetric(namespace, "bucket_usage_byte_metric", "The description of bucket_usage_byte_metric", bucketLabels),
 			"user_usage_byte_metric":   newGlobalMetric(namespace, "user_usage_byte_metric", "The description of User_usage_byte_metric", []string{"owner_id", "storage_class"}),
 		},
 	}
@@ -56,7 +72,8 @@ func (c *Metrics) Collect(ch chan<- prometheus.Metric) {
 	GaugeMetricDataForBucket := c.GenerateBucketUsageData()
 	for bucket, data := range GaugeMetricDataForBucket {
 		for _, v := range data {
-			ch <- prometheus.MustNewConstMetric(c.metrics["bucket_usage_byte_metric"], prometheus.GaugeValue, float64(v.value), bucket, v.owner, v.storageClass)
+			labelValues := append([]string{bucket, v.owner, v.storageClass}, v.tags...)
+			ch <- prometheus.MustNewConstMetric(c.metrics["bucket_usage_byte_metric"], prometheus.GaugeValue, float64(v.value), labelValues...)
 		}
 	}
 
@@ -91,8 +108,12 @@ func (c *Metrics) GenerateBucketUsageData() (GaugeMetricData map[string][]UsageD
 				err.Error())
 			return
 		}
+		tags := make([]string, 0, len(helper.CONFIG.UsageTagLabels))
+		for _, key := range helper.CONFIG.UsageTagLabels {
+			tags = append(tags, bucket.Tags[key])
+		}
 		for _, data := range datas {
-			GaugeMetricData[bucket.Name] = append(GaugeMetricData[bucket.Name], UsageDataWithBucket{data.value, bucket.OwnerId, data.storageClass})
+			GaugeMetricData[bucket.Name] = append(GaugeMetricData[bucket.Name], UsageDataWithBucket{data.value, bucket.OwnerId, data.storageClass, tags})
 		}
 	}
 	return
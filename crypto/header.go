@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"strings"
 )
@@ -125,6 +126,22 @@ func (s3KMS) IsRequested(h http.Header) bool {
 	return false
 }
 
+// ParseContext decodes the SSE-KMS encryption context, which is
+// a base64-encoded UTF-8 string holding JSON with the key-value pairs.
+func (s3KMS) ParseContext(encoded string) (context Context, err error) {
+	if encoded == "" {
+		return Context{}, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidEncryptionContext
+	}
+	if err = json.Unmarshal(b, &context); err != nil {
+		return nil, ErrInvalidEncryptionContext
+	}
+	return context, nil
+}
+
 var (
 	// SSEC represents AWS SSE-C. It provides functionality to handle
 	// SSE-C requests.
@@ -54,6 +54,35 @@ func TestKMSIsRequested(t *testing.T) {
 	}
 }
 
+var kmsParseContextTests = []struct {
+	Encoded     string
+	Expected    Context
+	ExpectedErr error
+}{
+	{Encoded: "", Expected: Context{}, ExpectedErr: nil},                                        // 0
+	{Encoded: "eyJrZXkiOiJ2YWx1ZSJ9", Expected: Context{"key": "value"}, ExpectedErr: nil},      // 1
+	{Encoded: "eyJrZXkiOiJ2YWx1ZSJ9=", Expected: nil, ExpectedErr: ErrInvalidEncryptionContext}, // 2
+	{Encoded: "eyJrZXkiOjF9", Expected: nil, ExpectedErr: ErrInvalidEncryptionContext},          // 3
+	{Encoded: "W10=", Expected: nil, ExpectedErr: ErrInvalidEncryptionContext},                  // 4
+}
+
+func TestKMSParseContext(t *testing.T) {
+	for i, test := range kmsParseContextTests {
+		context, err := S3KMS.ParseContext(test.Encoded)
+		if err != test.ExpectedErr {
+			t.Errorf("Test %d: Wanted error %v but got %v", i, test.ExpectedErr, err)
+		}
+		if len(context) != len(test.Expected) {
+			t.Errorf("Test %d: Wanted %v but got %v", i, test.Expected, context)
+		}
+		for k, v := range test.Expected {
+			if context[k] != v {
+				t.Errorf("Test %d: Wanted %v but got %v", i, test.Expected, context)
+			}
+		}
+	}
+}
+
 var s3IsRequestedTests = []struct {
 	Header   http.Header
 	Expected bool
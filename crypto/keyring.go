@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/journeymidnight/yig/helper"
+)
+
+// KeyVersionUnsealed is the version of keys stored as they are, i.e. keys
+// stored before any master key is configured.
+const KeyVersionUnsealed = 0
+
+// MasterKeyProvider could be implemented by KMS plugins to provide
+// master keys of the keyring, instead of reading them from config.
+type MasterKeyProvider interface {
+	// MasterKeys returns 32 bytes master keys keyed by version,
+	// and the version used to seal new keys.
+	MasterKeys() (keys map[int][]byte, current int, err error)
+}
+
+// Keyring holds versions of master keys, which seal data keys of SSE
+// objects before they are stored in metadata. New keys are sealed with
+// the current version, and keys sealed with any version in keyring
+// could be unsealed, so the current version could be rotated without
+// touching sealed keys, which are re-wrapped later by tools/rewrap.
+type Keyring struct {
+	keys    map[int][]byte
+	current int
+}
+
+func NewKeyring(keys map[int][]byte, current int) (*Keyring, error) {
+	for version, key := range keys {
+		if version <= KeyVersionUnsealed {
+			return nil, fmt.Errorf("invalid master key version %d", version)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key of version %d is not 32 bytes", version)
+		}
+	}
+	if len(keys) == 0 {
+		current = KeyVersionUnsealed
+	} else if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current master key version %d is not found", current)
+	}
+	return &Keyring{keys: keys, current: current}, nil
+}
+
+// LoadKeyring loads master keys from kms if it's a MasterKeyProvider,
+// otherwise from "sse_master_keys" of config.
+func LoadKeyring(kms KMS) (*Keyring, error) {
+	if provider, ok := kms.(MasterKeyProvider); ok {
+		keys, current, err := provider.MasterKeys()
+		if err != nil {
+			return nil, err
+		}
+		return NewKeyring(keys, current)
+	}
+	keys := make(map[int][]byte)
+	for v, encoded := range helper.CONFIG.SseMasterKeys {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master key version %s", v)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key of version %d is not base64 encoded", version)
+		}
+		keys[version] = key
+	}
+	keyring, err := NewKeyring(keys, helper.CONFIG.SseMasterKeyVersion)
+	if err == nil && keyring.current == KeyVersionUnsealed {
+		helper.Logger.Warn("No SSE master key configured, keys are stored unsealed")
+	}
+	return keyring, err
+}
+
+func (k *Keyring) CurrentVersion() int {
+	return k.current
+}
+
+// Seal seals key with the current master key, empty keys are never sealed.
+func (k *Keyring) Seal(key []byte) (version int, sealed []byte, err error) {
+	if len(key) == 0 {
+		return KeyVersionUnsealed, key, nil
+	}
+	sealed, err = k.SealWith(k.current, key)
+	return k.current, sealed, err
+}
+
+// SealWith seals key with master key of version, the random nonce of
+// AES-256-GCM is prepended to the sealed key.
+func (k *Keyring) SealWith(version int, key []byte) ([]byte, error) {
+	if version == KeyVersionUnsealed {
+		return key, nil
+	}
+	aead, err := k.aead(version)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errOutOfEntropy
+	}
+	return aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// Unseal unseals key sealed with master key of version.
+func (k *Keyring) Unseal(version int, sealed []byte) ([]byte, error) {
+	if version == KeyVersionUnsealed || len(sealed) == 0 {
+		return sealed, nil
+	}
+	aead, err := k.aead(version)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrSecretKeyMismatch
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	key, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrSecretKeyMismatch
+	}
+	return key, nil
+}
+
+func (k *Keyring) aead(version int) (cipher.AEAD, error) {
+	masterKey, ok := k.keys[version]
+	if !ok {
+		return nil, ErrUnknownMasterKeyVersion
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
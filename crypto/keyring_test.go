@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+var newKeyringTests = []struct {
+	Keys       map[int][]byte
+	Current    int
+	ShouldFail bool
+}{
+	{Keys: nil, Current: 0, ShouldFail: false},                                                      // 0
+	{Keys: nil, Current: 1, ShouldFail: false},                                                      // 1
+	{Keys: map[int][]byte{1: make([]byte, 32)}, Current: 1, ShouldFail: false},                      // 2
+	{Keys: map[int][]byte{1: make([]byte, 32), 2: make([]byte, 32)}, Current: 2, ShouldFail: false}, // 3
+	{Keys: map[int][]byte{1: make([]byte, 32)}, Current: 2, ShouldFail: true},                       // 4
+	{Keys: map[int][]byte{0: make([]byte, 32)}, Current: 0, ShouldFail: true},                       // 5
+	{Keys: map[int][]byte{1: make([]byte, 16)}, Current: 1, ShouldFail: true},                       // 6
+}
+
+func TestNewKeyring(t *testing.T) {
+	for i, test := range newKeyringTests {
+		_, err := NewKeyring(test.Keys, test.Current)
+		if err != nil && !test.ShouldFail {
+			t.Errorf("Test %d: should pass but failed with: %v", i, err)
+		}
+		if err == nil && test.ShouldFail {
+			t.Errorf("Test %d: should fail but passed", i)
+		}
+	}
+}
+
+func TestKeyringSealUnseal(t *testing.T) {
+	keys := map[int][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+	}
+	keyring, err := NewKeyring(keys, 1)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	key := bytes.Repeat([]byte{'k'}, 32)
+
+	version, sealed, err := keyring.Seal(key)
+	if err != nil {
+		t.Fatalf("Failed to seal key: %v", err)
+	}
+	if version != 1 || bytes.Equal(sealed, key) {
+		t.Fatalf("Key is not sealed with the current version")
+	}
+	if _, err = keyring.Unseal(2, sealed); err != ErrSecretKeyMismatch {
+		t.Errorf("Unseal with another version should fail with %v, got %v", ErrSecretKeyMismatch, err)
+	}
+	if _, err = keyring.Unseal(3, sealed); err != ErrUnknownMasterKeyVersion {
+		t.Errorf("Unseal with unknown version should fail with %v, got %v", ErrUnknownMasterKeyVersion, err)
+	}
+
+	// rewrap with version 2, as keys are rotated
+	unsealed, err := keyring.Unseal(version, sealed)
+	if err != nil || !bytes.Equal(unsealed, key) {
+		t.Fatalf("Failed to unseal key: %v", err)
+	}
+	resealed, err := keyring.SealWith(2, unsealed)
+	if err != nil {
+		t.Fatalf("Failed to seal key with version 2: %v", err)
+	}
+	unsealed, err = keyring.Unseal(2, resealed)
+	if err != nil || !bytes.Equal(unsealed, key) {
+		t.Errorf("Failed to unseal key sealed with version 2: %v", err)
+	}
+}
+
+func TestKeyringUnsealed(t *testing.T) {
+	keyring, err := NewKeyring(nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	key := bytes.Repeat([]byte{'k'}, 32)
+	version, sealed, err := keyring.Seal(key)
+	if err != nil || version != KeyVersionUnsealed || !bytes.Equal(sealed, key) {
+		t.Fatalf("Key should be stored unsealed without master keys, version: %d err: %v", version, err)
+	}
+	unsealed, err := keyring.Unseal(KeyVersionUnsealed, sealed)
+	if err != nil || !bytes.Equal(unsealed, key) {
+		t.Errorf("Failed to unseal key: %v", err)
+	}
+	if _, err = keyring.Unseal(1, sealed); err != ErrUnknownMasterKeyVersion {
+		t.Errorf("Unseal with unknown version should fail with %v, got %v", ErrUnknownMasterKeyVersion, err)
+	}
+}
@@ -178,6 +178,59 @@ const (
 	ErrInvalidRestoreInfo
 	ErrCreateRestoreObject
 	ErrInvalidGlacierObject
+	ErrNoSuchWebsiteConfiguration
+	ErrInvalidTag
+	ErrTooManyTags
+	ErrNoSuchTagSet
+	ErrARNNotification
+	ErrEventNotification
+	ErrFilterNameInvalid
+	ErrFilterValueInvalid
+	ErrOverlappingConfigs
+	ErrReplicationConfigurationNotFound
+	ErrReplicationVersioningRequired
+	ErrInvalidReplicationDestination
+	ErrInvalidReplicationRule
+	ErrObjectLocked
+	ErrObjectLockConfigurationNotFound
+	ErrNoSuchObjectLockConfiguration
+	ErrObjectLockVersioningRequired
+	ErrObjectLockNotEnabled
+	ErrInvalidRetentionPeriod
+	ErrObjectLockInvalidHeaders
+	ErrPastObjectLockRetainDate
+	ErrUnknownWormModeDirective
+	ErrInvalidLegalHoldStatus
+	ErrInvalidTargetBucketForLogging
+	ErrInvalidExpressionType
+	ErrInvalidCompressionFormat
+	ErrInvalidFileHeaderInfo
+	ErrInvalidJsonType
+	ErrInvalidQuoteFields
+	ErrInvalidSelectRequestParameter
+	ErrSelectParseFailure
+	ErrUnsupportedSqlOperation
+	ErrCSVParsingError
+	ErrJSONParsingError
+	ErrSelectInvalidDataType
+	ErrNoSuchInventoryConfiguration
+	ErrInvalidInventoryConfiguration
+	ErrInvalidInventoryDestination
+	ErrTooManyInventoryConfigurations
+	ErrQuotaExceeded
+	ErrInvalidChecksum
+	ErrBadChecksum
+	ErrInvalidObjectAttributes
+	ErrInvalidToken
+	ErrExpiredToken
+	ErrInvalidStsParameter
+	ErrMalformedSessionPolicy
+	ErrSessionPolicyTooLarge
+	ErrNoSuchPublicAccessBlockConfiguration
+	ErrPublicAclBlocked
+	ErrPublicPolicyBlocked
+	ErrOwnershipControlsNotFound
+	ErrAccessControlListNotSupported
 )
 
 // error code to APIError structure, these fields carry respective
@@ -288,6 +341,271 @@ var ErrorCodeResponse = map[ApiErrorCode]ApiErrorStruct{
 		Description:    "Glacier objects need to be thawed before this operation can be performed.",
 		HttpStatusCode: http.StatusBadRequest,
 	},
+	ErrNoSuchWebsiteConfiguration: {
+		AwsErrorCode:   "NoSuchWebsiteConfiguration",
+		Description:    "The specified bucket does not have a website configuration.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidTag: {
+		AwsErrorCode:   "InvalidTag",
+		Description:    "The tag provided was not a valid tag.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrTooManyTags: {
+		AwsErrorCode:   "BadRequest",
+		Description:    "The number of tags exceeds the limit.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchTagSet: {
+		AwsErrorCode:   "NoSuchTagSet",
+		Description:    "The TagSet does not exist.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrARNNotification: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "A specified destination ARN does not exist or is not well-formed.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrEventNotification: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "A specified event is not supported for notifications.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterNameInvalid: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "filter rule name must be either prefix or suffix, and may appear only once.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrFilterValueInvalid: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Size of filter rule value cannot exceed 1024 bytes in UTF-8 representation.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrOverlappingConfigs: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Configurations overlap. Configuration Ids on the same bucket must be unique.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrReplicationConfigurationNotFound: {
+		AwsErrorCode:   "ReplicationConfigurationNotFoundError",
+		Description:    "The replication configuration was not found.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrReplicationVersioningRequired: {
+		AwsErrorCode:   "InvalidRequest",
+		Description:    "Versioning must be 'Enabled' on the bucket to apply a replication configuration.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidReplicationDestination: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "The destination bucket ARN is not well-formed or its region has no replication endpoint configured.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidReplicationRule: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Replication rule ID must be unique and no longer than 255 characters.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectLocked: {
+		AwsErrorCode:   "AccessDenied",
+		Description:    "Access Denied because object protected by object lock.",
+		HttpStatusCode: http.StatusForbidden,
+	},
+	ErrObjectLockConfigurationNotFound: {
+		AwsErrorCode:   "ObjectLockConfigurationNotFoundError",
+		Description:    "Object Lock configuration does not exist for this bucket.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrNoSuchObjectLockConfiguration: {
+		AwsErrorCode:   "NoSuchObjectLockConfiguration",
+		Description:    "The specified object does not have a ObjectLock configuration.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrObjectLockVersioningRequired: {
+		AwsErrorCode:   "InvalidBucketState",
+		Description:    "Versioning must be 'Enabled' on the bucket to apply a Object Lock configuration.",
+		HttpStatusCode: http.StatusConflict,
+	},
+	ErrObjectLockNotEnabled: {
+		AwsErrorCode:   "InvalidRequest",
+		Description:    "Bucket is missing Object Lock Configuration.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidRetentionPeriod: {
+		AwsErrorCode:   "InvalidRetentionPeriod",
+		Description:    "Default retention period must be a positive integer value for either Days or Years.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectLockInvalidHeaders: {
+		AwsErrorCode:   "InvalidRequest",
+		Description:    "x-amz-object-lock-retain-until-date and x-amz-object-lock-mode must both be supplied.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrPastObjectLockRetainDate: {
+		AwsErrorCode:   "InvalidRequest",
+		Description:    "The retain until date must be in the future.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrUnknownWormModeDirective: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Unknown wormMode directive.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidLegalHoldStatus: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Legal Hold must be either of 'ON' or 'OFF'.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidTargetBucketForLogging: {
+		AwsErrorCode:   "InvalidTargetBucketForLogging",
+		Description:    "The target bucket for logging does not exist, is not owned by you, or does not have the appropriate grants for the log-delivery group.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidExpressionType: {
+		AwsErrorCode:   "InvalidExpressionType",
+		Description:    "The ExpressionType is invalid. Only SQL expressions are supported.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidCompressionFormat: {
+		AwsErrorCode:   "InvalidCompressionFormat",
+		Description:    "The file is not in a supported compression format. Only GZIP and BZIP2 are supported.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidFileHeaderInfo: {
+		AwsErrorCode:   "InvalidFileHeaderInfo",
+		Description:    "The FileHeaderInfo is invalid. Only NONE, USE, and IGNORE are supported.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidJsonType: {
+		AwsErrorCode:   "InvalidJsonType",
+		Description:    "The JsonType is invalid. Only DOCUMENT and LINES are supported.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidQuoteFields: {
+		AwsErrorCode:   "InvalidQuoteFields",
+		Description:    "The QuoteFields is invalid. Only ALWAYS and ASNEEDED are supported.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidSelectRequestParameter: {
+		AwsErrorCode:   "InvalidRequestParameter",
+		Description:    "The value of a parameter in SelectRequest element is invalid.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrSelectParseFailure: {
+		AwsErrorCode:   "ParseSelectFailure",
+		Description:    "The SQL expression could not be parsed.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrUnsupportedSqlOperation: {
+		AwsErrorCode:   "UnsupportedSqlOperation",
+		Description:    "Encountered an unsupported SQL operation.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrCSVParsingError: {
+		AwsErrorCode:   "CSVParsingError",
+		Description:    "Encountered an error parsing the CSV file.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrJSONParsingError: {
+		AwsErrorCode:   "JSONParsingError",
+		Description:    "Encountered an error parsing the JSON file.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrSelectInvalidDataType: {
+		AwsErrorCode:   "InvalidDataType",
+		Description:    "The SQL expression contains an invalid data type.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchInventoryConfiguration: {
+		AwsErrorCode:   "NoSuchConfiguration",
+		Description:    "The specified inventory configuration does not exist.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrInvalidInventoryConfiguration: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "The inventory configuration is not valid.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidInventoryDestination: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "The destination bucket of inventory does not exist, or is not owned by you.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrTooManyInventoryConfigurations: {
+		AwsErrorCode:   "TooManyConfigurations",
+		Description:    "You are attempting to create a new configuration but have already reached the 1,000-configuration limit.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrQuotaExceeded: {
+		AwsErrorCode:   "QuotaExceeded",
+		Description:    "The bucket or user quota is exceeded.",
+		HttpStatusCode: http.StatusForbidden,
+	},
+	ErrInvalidChecksum: {
+		AwsErrorCode:   "InvalidRequest",
+		Description:    "The checksum algorithm or value you specified is invalid.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrBadChecksum: {
+		AwsErrorCode:   "BadDigest",
+		Description:    "The x-amz-checksum you specified did not match what we received.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidObjectAttributes: {
+		AwsErrorCode:   "InvalidArgument",
+		Description:    "Invalid attribute name specified in x-amz-object-attributes.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidToken: {
+		AwsErrorCode:   "InvalidToken",
+		Description:    "The provided token is malformed or otherwise invalid.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrExpiredToken: {
+		AwsErrorCode:   "ExpiredToken",
+		Description:    "The provided token has expired.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidStsParameter: {
+		AwsErrorCode:   "ValidationError",
+		Description:    "The input fails to satisfy the constraints specified by STS.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrMalformedSessionPolicy: {
+		AwsErrorCode:   "MalformedPolicyDocument",
+		Description:    "The session policy document is malformed.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrSessionPolicyTooLarge: {
+		AwsErrorCode:   "PackedPolicyTooLarge",
+		Description:    "The session policy document exceeds the allowed size.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
+	ErrNoSuchPublicAccessBlockConfiguration: {
+		AwsErrorCode:   "NoSuchPublicAccessBlockConfiguration",
+		Description:    "The public access block configuration was not found.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrPublicAclBlocked: {
+		AwsErrorCode:   "AccessDenied",
+		Description:    "Public ACLs are blocked by the BlockPublicAcls setting of public access block.",
+		HttpStatusCode: http.StatusForbidden,
+	},
+	ErrPublicPolicyBlocked: {
+		AwsErrorCode:   "AccessDenied",
+		Description:    "Public policies are blocked by the BlockPublicPolicy setting of public access block.",
+		HttpStatusCode: http.StatusForbidden,
+	},
+	ErrOwnershipControlsNotFound: {
+		AwsErrorCode:   "OwnershipControlsNotFoundError",
+		Description:    "The bucket ownership controls were not found.",
+		HttpStatusCode: http.StatusNotFound,
+	},
+	ErrAccessControlListNotSupported: {
+		AwsErrorCode:   "AccessControlListNotSupported",
+		Description:    "The bucket does not allow ACLs.",
+		HttpStatusCode: http.StatusBadRequest,
+	},
 	ErrAccessDenied: {
 		AwsErrorCode:   "AccessDenied",
 		Description:    "Access Denied.",
@@ -16,13 +16,20 @@ const (
 )
 
 type Config struct {
-	S3Domain             []string                `toml:"s3domain"` // Domain name of YIG
-	Region               string                  `toml:"region"`   // Region name this instance belongs to, e.g cn-bj-1
+	S3Domain             []string                `toml:"s3domain"`       // Domain name of YIG
+	WebsiteDomain        []string                `toml:"website_domain"` // Domain name of YIG static website endpoint
+	Region               string                  `toml:"region"`         // Region name this instance belongs to, e.g cn-bj-1
 	Plugins              map[string]PluginConfig `toml:"plugins"`
 	PiggybackUpdateUsage bool                    `toml:"piggyback_update_usage"`
+	EnableQuota          bool                    `toml:"enable_quota"`       // quotas of buckets and users are checked on writes
+	StsKey               string                  `toml:"sts_key"`            // signs session tokens of STS, STS is disabled if empty
+	DenyReasonAdmins     []string                `toml:"deny_reason_admins"` // uids who get reasons of denials in header of responses if requested
+	TrustedProxies       []string                `toml:"trusted_proxies"`    // IPs or CIDRs of proxies whose forwarded scheme is trusted
 	LogPath              string                  `toml:"log_path"`
 	AccessLogPath        string                  `toml:"access_log_path"`
 	AccessLogFormat      string                  `toml:"access_log_format"`
+	LogDeliverySpoolDir  string                  `toml:"log_delivery_spool_dir"` // access logs of buckets are spooled before delivered
+	LogDeliveryInterval  int                     `toml:"log_delivery_interval"`  // seconds between deliveries to target buckets
 	PanicLogPath         string                  `toml:"panic_log_path"`
 	PidFile              string                  `toml:"pid_file"`
 	BindApiAddress       string                  `toml:"api_listener"`
@@ -33,19 +40,28 @@ type Config struct {
 
 	InstanceId             string // if empty, generated one at server startup
 	ConcurrentRequestLimit int
-	DebugMode              bool   `toml:"debug_mode"`
-	EnablePProf            bool   `toml:"enable_pprof"`
-	BindPProfAddress       string `toml:"pprof_listener"`
-	AdminKey               string `toml:"admin_key"` //used for tools/admin to communicate with yig
-	GcThread               int    `toml:"gc_thread"`
-	LcThread               int    //used for tools/lc only, set worker numbers to do lc
-	LogLevel               string `toml:"log_level"` // "info", "warn", "error"
-	CephConfigPattern      string `toml:"ceph_config_pattern"`
-	ReservedOrigins        string `toml:"reserved_origins"` // www.ccc.com,www.bbb.com,127.0.0.1
-	MetaStore              string `toml:"meta_store"`
-	TidbInfo               string `toml:"tidb_info"`
-	KeepAlive              bool   `toml:"keepalive"`
-	EnableCompression      bool   `toml:"enable_compression"`
+	DebugMode              bool     `toml:"debug_mode"`
+	EnablePProf            bool     `toml:"enable_pprof"`
+	BindPProfAddress       string   `toml:"pprof_listener"`
+	AdminKey               string   `toml:"admin_key"`        //used for tools/admin to communicate with yig
+	UsageTagLabels         []string `toml:"usage_tag_labels"` // bucket tag keys exported as labels of usage metrics
+	GcThread               int      `toml:"gc_thread"`
+	LcThread               int      //used for tools/lc only, set worker numbers to do lc
+	LcWindowStart          string   `toml:"lc_window_start"`         // daily window of tools/lc daemon, e.g. "01:00"
+	LcWindowEnd            string   `toml:"lc_window_end"`           // e.g. "05:00", the window is whole day if not set
+	LcMetricsListener      string   `toml:"lc_metrics_listener"`     // address of tools/lc exposing prometheus metrics
+	ReplicationThread      int      `toml:"replication_thread"`      // used for tools/replicate only
+	ReplicationMaxRetries  int      `toml:"replication_max_retries"` // failed replications are retried before marked FAILED
+	RestoreThread          int      `toml:"restore_thread"`          // used for tools/restore only
+	InventoryThread        int      `toml:"inventory_thread"`        // used for tools/inventory only
+	InventorySpoolDir      string   `toml:"inventory_spool_dir"`     // report files are spooled here before uploaded
+	LogLevel               string   `toml:"log_level"`               // "info", "warn", "error"
+	CephConfigPattern      string   `toml:"ceph_config_pattern"`
+	ReservedOrigins        string   `toml:"reserved_origins"` // www.ccc.com,www.bbb.com,127.0.0.1
+	MetaStore              string   `toml:"meta_store"`
+	TidbInfo               string   `toml:"tidb_info"`
+	KeepAlive              bool     `toml:"keepalive"`
+	EnableCompression      bool     `toml:"enable_compression"`
 
 	//About cache
 	EnableUsagePush       bool   `toml:"enable_usage_push"`
@@ -81,6 +97,14 @@ type Config struct {
 	DownloadBufPoolSize int64 `toml:"download_buf_pool_size"`
 	UploadMinChunkSize  int64 `toml:"upload_min_chunk_size"`
 	UploadMaxChunkSize  int64 `toml:"upload_max_chunk_size"`
+
+	// S3 endpoints of other deployments keyed by region, used as replication destinations
+	ReplicationEndpoints map[string]ReplicationEndpoint `toml:"replication_endpoints"`
+
+	// base64 encoded 32 bytes master keys keyed by version, sealing SSE keys stored in metadata,
+	// old versions should be kept until keys sealed with them are re-wrapped by tools/rewrap
+	SseMasterKeys       map[string]string `toml:"sse_master_keys"`
+	SseMasterKeyVersion int               `toml:"sse_master_key_version"` // version to seal new keys
 }
 
 type PluginConfig struct {
@@ -89,6 +113,12 @@ type PluginConfig struct {
 	Args   map[string]interface{} `toml:"args"`
 }
 
+type ReplicationEndpoint struct {
+	Endpoint  string `toml:"endpoint"` // e.g http://s3.cn-sh-1.test.com:8080
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key"`
+}
+
 var CONFIG Config
 
 func SetupConfig() {
@@ -109,12 +139,21 @@ func MarshalTOMLConfig() error {
 	}
 	// setup CONFIG with defaults
 	CONFIG.S3Domain = c.S3Domain
+	CONFIG.WebsiteDomain = c.WebsiteDomain
 	CONFIG.Region = c.Region
 	CONFIG.Plugins = c.Plugins
 	CONFIG.PiggybackUpdateUsage = c.PiggybackUpdateUsage
+	CONFIG.EnableQuota = c.EnableQuota
+	CONFIG.StsKey = c.StsKey
+	CONFIG.DenyReasonAdmins = c.DenyReasonAdmins
+	CONFIG.TrustedProxies = c.TrustedProxies
 	CONFIG.LogPath = logFilePathWithPid(c.LogPath)
 	CONFIG.AccessLogPath = logFilePathWithPid(c.AccessLogPath)
 	CONFIG.AccessLogFormat = c.AccessLogFormat
+	CONFIG.LogDeliverySpoolDir = Ternary(c.LogDeliverySpoolDir == "",
+		"/var/spool/yig/log-delivery", c.LogDeliverySpoolDir).(string)
+	CONFIG.LogDeliveryInterval = Ternary(c.LogDeliveryInterval == 0,
+		300, c.LogDeliveryInterval).(int)
 	CONFIG.PanicLogPath = c.PanicLogPath
 	CONFIG.PidFile = c.PidFile
 	CONFIG.BindApiAddress = c.BindApiAddress
@@ -126,6 +165,7 @@ func MarshalTOMLConfig() error {
 	CONFIG.EnablePProf = c.EnablePProf
 	CONFIG.BindPProfAddress = c.BindPProfAddress
 	CONFIG.AdminKey = c.AdminKey
+	CONFIG.UsageTagLabels = c.UsageTagLabels
 	CONFIG.CephConfigPattern = c.CephConfigPattern
 	CONFIG.ReservedOrigins = c.ReservedOrigins
 	CONFIG.TidbInfo = c.TidbInfo
@@ -139,6 +179,19 @@ func MarshalTOMLConfig() error {
 		1, c.GcThread).(int)
 	CONFIG.LcThread = Ternary(c.LcThread == 0,
 		1, c.LcThread).(int)
+	CONFIG.LcWindowStart = c.LcWindowStart
+	CONFIG.LcWindowEnd = c.LcWindowEnd
+	CONFIG.LcMetricsListener = c.LcMetricsListener
+	CONFIG.ReplicationThread = Ternary(c.ReplicationThread == 0,
+		1, c.ReplicationThread).(int)
+	CONFIG.ReplicationMaxRetries = Ternary(c.ReplicationMaxRetries == 0,
+		10, c.ReplicationMaxRetries).(int)
+	CONFIG.RestoreThread = Ternary(c.RestoreThread == 0,
+		1, c.RestoreThread).(int)
+	CONFIG.InventoryThread = Ternary(c.InventoryThread == 0,
+		1, c.InventoryThread).(int)
+	CONFIG.InventorySpoolDir = Ternary(c.InventorySpoolDir == "",
+		"/var/spool/yig/inventory", c.InventorySpoolDir).(string)
 	CONFIG.LogLevel = Ternary(len(c.LogLevel) == 0, "info", c.LogLevel).(string)
 	CONFIG.MetaStore = Ternary(c.MetaStore == "", "tidb", c.MetaStore).(string)
 
@@ -169,6 +222,9 @@ func MarshalTOMLConfig() error {
 
 	CONFIG.DownloadBufPoolSize = Ternary(c.DownloadBufPoolSize < MIN_BUFFER_SIZE || c.DownloadBufPoolSize > MAX_BUFEER_SIZE, MIN_BUFFER_SIZE, c.DownloadBufPoolSize).(int64)
 	CONFIG.UploadMinChunkSize = Ternary(c.UploadMinChunkSize < MIN_BUFFER_SIZE || c.UploadMinChunkSize > MAX_BUFEER_SIZE, MIN_BUFFER_SIZE, c.UploadMinChunkSize).(int64)
+	CONFIG.ReplicationEndpoints = c.ReplicationEndpoints
+	CONFIG.SseMasterKeys = c.SseMasterKeys
+	CONFIG.SseMasterKeyVersion = c.SseMasterKeyVersion
 	CONFIG.UploadMaxChunkSize = Ternary(c.UploadMaxChunkSize < CONFIG.UploadMinChunkSize || c.UploadMaxChunkSize > MAX_BUFEER_SIZE, MAX_BUFEER_SIZE, c.UploadMaxChunkSize).(int64)
 
 	return nil
@@ -181,4 +237,4 @@ func logFilePathWithPid(rawPath string) string {
 	parts := strings.Split(rawPath, "/")
 	parts[len(parts)-1] = fmt.Sprintf("%d.%s", pid, parts[len(parts)-1])
 	return strings.Join(parts, "/")
-}
\ No newline at end of file
+}
@@ -1,6 +1,10 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
+
 // credential container for access and secret keys.
 type Credential struct {
 	UserId               string
@@ -8,6 +12,16 @@ type Credential struct {
 	AccessKeyID          string
 	SecretAccessKey      string
 	AllowOtherUserAccess bool
+	// public ACLs grant nothing, by IgnorePublicAcls of Block Public Access settings
+	IgnorePublicAcls bool
+	// temporary credentials issued by STS
+	SessionToken  string
+	SessionPolicy string // inline session policy in JSON, empty if not scoped
+	Expiration    time.Time
+}
+
+func (a Credential) IsTemporary() bool {
+	return a.SessionToken != ""
 }
 
 func (a Credential) String() string {
@@ -0,0 +1,146 @@
+package iam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+)
+
+const (
+	// access keys of temporary credentials are prefixed like AWS,
+	// so they never collide with access keys of IAM plugins
+	sessionAccessKeyPrefix = "ASIA"
+	sessionAccessKeyLength = 20
+	sessionSecretKeyLength = 40
+	accessKeyAlphabet      = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// Session tokens are stateless, they're JWTs signed with sts_key, and secret
+// key of a temporary credential is derived from its access key, so nothing
+// is stored. Temporary credentials are revoked with the access key which
+// requested them.
+type sessionClaims struct {
+	jwt.StandardClaims
+	AccessKey       string `json:"ak"`
+	ParentAccessKey string `json:"pak"`
+	Policy          string `json:"policy,omitempty"`
+}
+
+func IsStsEnabled() bool {
+	return helper.CONFIG.StsKey != ""
+}
+
+func newSessionAccessKey() (string, error) {
+	b := make([]byte, sessionAccessKeyLength-len(sessionAccessKeyPrefix))
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = accessKeyAlphabet[int(b[i])%len(accessKeyAlphabet)]
+	}
+	return sessionAccessKeyPrefix + string(b), nil
+}
+
+func sessionSecretKey(accessKey string) string {
+	mac := hmac.New(sha256.New, []byte(helper.CONFIG.StsKey))
+	mac.Write([]byte(accessKey))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))[:sessionSecretKeyLength]
+}
+
+// NewSessionCredential issues a temporary credential of parent, valid for duration,
+// and scoped by session policy if not empty
+func NewSessionCredential(parent common.Credential, duration time.Duration,
+	sessionPolicy string) (credential common.Credential, err error) {
+
+	if !IsStsEnabled() {
+		return credential, ErrNotImplemented
+	}
+	if parent.IsTemporary() {
+		// temporary credentials could not be renewed by themselves
+		return credential, ErrAccessDenied
+	}
+	accessKey, err := newSessionAccessKey()
+	if err != nil {
+		return credential, err
+	}
+	now := time.Now().UTC()
+	expiration := now.Add(duration).Truncate(time.Second)
+	claims := sessionClaims{
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiration.Unix(),
+			Subject:   parent.UserId,
+		},
+		AccessKey:       accessKey,
+		ParentAccessKey: parent.AccessKeyID,
+		Policy:          sessionPolicy,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(helper.CONFIG.StsKey))
+	if err != nil {
+		return credential, err
+	}
+	return common.Credential{
+		UserId:          parent.UserId,
+		DisplayName:     parent.DisplayName,
+		AccessKeyID:     accessKey,
+		SecretAccessKey: sessionSecretKey(accessKey),
+		SessionToken:    token,
+		SessionPolicy:   sessionPolicy,
+		Expiration:      expiration,
+	}, nil
+}
+
+// GetSessionCredential validates session token of access key,
+// returns ErrExpiredToken if the temporary credential is expired
+func GetSessionCredential(accessKey, sessionToken string) (credential common.Credential, err error) {
+	if !IsStsEnabled() {
+		return credential, ErrInvalidToken
+	}
+	claims := new(sessionClaims)
+	_, err = jwt.ParseWithClaims(sessionToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(helper.CONFIG.StsKey), nil
+	})
+	if err != nil {
+		if e, ok := err.(*jwt.ValidationError); ok && e.Errors&jwt.ValidationErrorExpired != 0 {
+			return credential, ErrExpiredToken
+		}
+		helper.Logger.Info("Invalid session token of", accessKey, "err:", err)
+		return credential, ErrInvalidToken
+	}
+	if claims.AccessKey != accessKey {
+		return credential, ErrInvalidToken
+	}
+	parent, err := GetCredential(claims.ParentAccessKey)
+	if err != nil {
+		helper.Logger.Info("Parent access key of", accessKey, "is invalid:", err)
+		return credential, ErrInvalidToken
+	}
+	return common.Credential{
+		UserId:          parent.UserId,
+		DisplayName:     parent.DisplayName,
+		AccessKeyID:     accessKey,
+		SecretAccessKey: sessionSecretKey(accessKey),
+		SessionToken:    sessionToken,
+		SessionPolicy:   claims.Policy,
+		Expiration:      time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// GetCredentialWithToken resolves access key of a request, temporary
+// credentials are validated with session token
+func GetCredentialWithToken(accessKey, sessionToken string) (credential common.Credential, err error) {
+	if sessionToken != "" {
+		return GetSessionCredential(accessKey, sessionToken)
+	}
+	return GetCredential(accessKey)
+}
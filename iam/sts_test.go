@@ -0,0 +1,145 @@
+package iam
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIamClient knows credentials of its keys, other access keys do not exist
+type fakeIamClient struct {
+	keys map[string]common.Credential
+}
+
+func (c fakeIamClient) GetKeysByUid(uid string) ([]common.Credential, error) {
+	return nil, nil
+}
+
+func (c fakeIamClient) GetCredential(accessKey string) (common.Credential, error) {
+	credential, ok := c.keys[accessKey]
+	if !ok {
+		return credential, common.ErrAccessKeyNotExist
+	}
+	return credential, nil
+}
+
+func setupSts(parents ...common.Credential) func() {
+	helper.Logger = log.NewLogger(os.Stderr, log.ParseLevel("error"))
+	stsKey := helper.CONFIG.StsKey
+	helper.CONFIG.StsKey = "sts-key-for-test"
+	keys := make(map[string]common.Credential)
+	for _, parent := range parents {
+		keys[parent.AccessKeyID] = parent
+	}
+	client := iamClient
+	iamClient = fakeIamClient{keys: keys}
+	return func() {
+		helper.CONFIG.StsKey = stsKey
+		iamClient = client
+	}
+}
+
+func TestSessionCredential(t *testing.T) {
+	parent := common.Credential{UserId: "user", DisplayName: "name",
+		AccessKeyID: "parentsessionkey", SecretAccessKey: "secret"}
+	defer setupSts(parent)()
+
+	sessionPolicy := `{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`
+	credential, err := NewSessionCredential(parent, time.Hour, sessionPolicy)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, credential.IsTemporary())
+	assert.True(t, strings.HasPrefix(credential.AccessKeyID, sessionAccessKeyPrefix))
+	assert.Len(t, credential.AccessKeyID, sessionAccessKeyLength)
+	assert.Len(t, credential.SecretAccessKey, sessionSecretKeyLength)
+	assert.Equal(t, "user", credential.UserId)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), credential.Expiration, 2*time.Second)
+
+	validated, err := GetCredentialWithToken(credential.AccessKeyID, credential.SessionToken)
+	assert.Nil(t, err)
+	assert.Equal(t, credential, validated)
+
+	// temporary credentials could not issue others
+	_, err = NewSessionCredential(credential, time.Hour, "")
+	assert.Equal(t, ErrAccessDenied, err)
+}
+
+func TestSessionTokenValidation(t *testing.T) {
+	parent := common.Credential{UserId: "user", AccessKeyID: "validationparent", SecretAccessKey: "secret"}
+	defer setupSts(parent)()
+
+	credential, err := NewSessionCredential(parent, time.Hour, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	other, err := NewSessionCredential(parent, time.Hour, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	expired, err := NewSessionCredential(parent, -time.Minute, "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		StandardClaims:  jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		AccessKey:       credential.AccessKeyID,
+		ParentAccessKey: parent.AccessKeyID,
+	}).SignedString([]byte("other-sts-key"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, sessionClaims{
+		StandardClaims:  jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		AccessKey:       credential.AccessKeyID,
+		ParentAccessKey: parent.AccessKeyID,
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if !assert.Nil(t, err) {
+		return
+	}
+	revoked, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		StandardClaims:  jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		AccessKey:       credential.AccessKeyID,
+		ParentAccessKey: "revokedparentkey",
+	}).SignedString([]byte(helper.CONFIG.StsKey))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		accessKey    string
+		sessionToken string
+		err          error
+	}{
+		{credential.AccessKeyID, credential.SessionToken, nil},
+		// token of another temporary credential
+		{credential.AccessKeyID, other.SessionToken, ErrInvalidToken},
+		{expired.AccessKeyID, expired.SessionToken, ErrExpiredToken},
+		{credential.AccessKeyID, forged, ErrInvalidToken},
+		{credential.AccessKeyID, unsigned, ErrInvalidToken},
+		{credential.AccessKeyID, revoked, ErrInvalidToken},
+		{credential.AccessKeyID, credential.SessionToken + "x", ErrInvalidToken},
+		{credential.AccessKeyID, "not a token", ErrInvalidToken},
+	}
+	for i, testCase := range testCases {
+		_, err := GetSessionCredential(testCase.accessKey, testCase.sessionToken)
+		if err != testCase.err {
+			t.Errorf("Case %d: error %v, expected %v", i, err, testCase.err)
+		}
+	}
+
+	// tokens are no longer valid when STS is disabled
+	helper.CONFIG.StsKey = ""
+	_, err = GetSessionCredential(credential.AccessKeyID, credential.SessionToken)
+	assert.Equal(t, ErrInvalidToken, err)
+	_, err = NewSessionCredential(parent, time.Hour, "")
+	assert.Equal(t, ErrNotImplemented, err)
+}
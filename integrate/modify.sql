@@ -50,4 +50,148 @@ CREATE TABLE `objects` (
    UNIQUE KEY `rowkey` (`bucketname`,`name`,`version`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 
-INSERT INTO `objects` SELECT * FROM `objects_bak`;
\ No newline at end of file
+INSERT INTO `objects` SELECT * FROM `objects_bak`;
+
+-- object tagging
+
+ALTER TABLE `objects`
+	ADD COLUMN `tags` JSON DEFAULT NULL;
+
+ALTER TABLE `multiparts`
+	ADD COLUMN `tags` JSON DEFAULT NULL;
+
+-- bucket tagging
+
+ALTER TABLE `buckets`
+	ADD COLUMN `tags` JSON DEFAULT NULL AFTER `encryption`;
+
+-- bucket notifications
+
+ALTER TABLE `buckets`
+	ADD COLUMN `notification` JSON DEFAULT NULL AFTER `tags`;
+
+-- replication, objects are queued to be replicated by replicationstatus
+
+ALTER TABLE `buckets`
+	ADD COLUMN `replication` JSON DEFAULT NULL AFTER `notification`;
+
+ALTER TABLE `objects`
+	ADD COLUMN `replicationstatus` varchar(20) DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS `replication` (
+  `bucketname` varchar(255) DEFAULT NULL,
+  `objectname` varchar(255) DEFAULT NULL,
+  `version` bigint(20) UNSIGNED DEFAULT NULL,
+  `triedtimes` int(11) DEFAULT 0,
+  `nexttime` datetime DEFAULT NULL,
+  UNIQUE KEY `rowkey` (`bucketname`,`objectname`,`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+-- object lock
+
+ALTER TABLE `buckets`
+	ADD COLUMN `objectlock` JSON DEFAULT NULL AFTER `replication`;
+
+ALTER TABLE `objects`
+	ADD COLUMN `objectlock` JSON DEFAULT NULL;
+
+ALTER TABLE `multiparts`
+	ADD COLUMN `objectlock` JSON DEFAULT NULL;
+
+-- SSE-KMS key IDs and encryption contexts
+
+ALTER TABLE `objects`
+	ADD COLUMN `ssekmskeyid` varchar(255) DEFAULT NULL;
+
+ALTER TABLE `objects`
+	ADD COLUMN `ssecontext` varchar(2048) DEFAULT NULL;
+
+-- versions of master keys sealing SSE keys, 0 for keys stored unsealed before
+
+ALTER TABLE `objects`
+	ADD COLUMN `keyversion` int(11) DEFAULT 0;
+
+ALTER TABLE `multiparts`
+	ADD COLUMN `keyversion` int(11) DEFAULT 0;
+
+-- restore tiers, 1 is Standard
+
+ALTER TABLE `restoreobjects`
+	ADD COLUMN `tier` tinyint(1) DEFAULT '1';
+
+-- lifecycle leases and progress, buckets are made unique
+
+ALTER TABLE `lifecycle`
+	RENAME TO `lifecycle_bak`;
+
+CREATE TABLE `lifecycle` (
+  `bucketname` varchar(255) DEFAULT NULL,
+  `status` varchar(255) DEFAULT NULL,
+  `owner` varchar(255) DEFAULT NULL,
+  `leaseexpire` datetime DEFAULT NULL,
+  `marker` varchar(1024) DEFAULT NULL,
+  `lastfinished` datetime DEFAULT NULL,
+  UNIQUE KEY `rowkey` (`bucketname`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+INSERT IGNORE INTO `lifecycle` (`bucketname`,`status`) SELECT `bucketname`,`status` FROM `lifecycle_bak`;
+
+-- inventory configurations with leases and last exports
+
+CREATE TABLE IF NOT EXISTS `inventory` (
+  `bucketname` varchar(255) DEFAULT NULL,
+  `id` varchar(64) DEFAULT NULL,
+  `configuration` JSON DEFAULT NULL,
+  `owner` varchar(255) DEFAULT NULL,
+  `leaseexpire` datetime DEFAULT NULL,
+  `lastexport` datetime DEFAULT NULL,
+  UNIQUE KEY `rowkey` (`bucketname`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+-- quotas, object counts of buckets are counted from existing objects
+
+CREATE TABLE IF NOT EXISTS `quotas` (
+  `kind` varchar(16) DEFAULT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  `maxbytes` bigint(20) DEFAULT -1,
+  `maxobjects` bigint(20) DEFAULT -1,
+  UNIQUE KEY `rowkey` (`kind`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+ALTER TABLE `buckets`
+	ADD COLUMN `objects` bigint(20) DEFAULT 0 AFTER `usages`;
+
+UPDATE `buckets` SET `objects` = (
+  SELECT COUNT(*) FROM `objects`
+  WHERE `objects`.`bucketname` = `buckets`.`bucketname` AND NOT COALESCE(`objects`.`deletemarker`, 0)
+);
+
+-- checksums of objects and parts
+
+ALTER TABLE `objects`
+	ADD COLUMN `checksum` JSON DEFAULT NULL;
+
+ALTER TABLE `multiparts`
+	ADD COLUMN `checksumalgorithm` varchar(20) DEFAULT NULL;
+
+ALTER TABLE `multipartpart`
+	ADD COLUMN `checksum` varchar(255) DEFAULT NULL;
+
+ALTER TABLE `objectpart`
+	ADD COLUMN `checksum` varchar(255) DEFAULT NULL;
+
+-- Block Public Access settings of buckets and accounts
+
+ALTER TABLE `buckets`
+	ADD COLUMN `publicaccessblock` JSON DEFAULT NULL AFTER `objectlock`;
+
+CREATE TABLE IF NOT EXISTS `publicaccessblocks` (
+  `uid` varchar(255) NOT NULL DEFAULT '',
+  `configuration` JSON DEFAULT NULL,
+  PRIMARY KEY (`uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+-- Object Ownership of buckets
+
+ALTER TABLE `buckets`
+	ADD COLUMN `ownershipcontrols` JSON DEFAULT NULL AFTER `publicaccessblock`;
@@ -32,8 +32,15 @@ CREATE TABLE `buckets` (
   `policy` JSON DEFAULT NULL,
   `website` JSON DEFAULT NULL,
   `encryption` JSON DEFAULT NULL,
+  `tags` JSON DEFAULT NULL,
+  `notification` JSON DEFAULT NULL,
+  `replication` JSON DEFAULT NULL,
+  `objectlock` JSON DEFAULT NULL,
+  `publicaccessblock` JSON DEFAULT NULL,
+  `ownershipcontrols` JSON DEFAULT NULL,
   `createtime` datetime DEFAULT NULL,
   `usages` bigint(20) DEFAULT NULL,
+  `objects` bigint(20) DEFAULT 0,
   `versioning` varchar(255) DEFAULT NULL,
   PRIMARY KEY (`bucketname`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
@@ -116,6 +123,7 @@ CREATE TABLE `multipartpart` (
   `bucketname` varchar(255) DEFAULT NULL,
   `objectname` varchar(255) DEFAULT NULL,
   `uploadtime` bigint(20) UNSIGNED DEFAULT NULL,
+  `checksum` varchar(255) DEFAULT NULL,
    KEY `rowkey` (`bucketname`,`objectname`,`uploadtime`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -142,6 +150,10 @@ CREATE TABLE `multiparts` (
   `cipher` blob DEFAULT NULL,
   `attrs` JSON DEFAULT NULL,
   `storageclass` tinyint(1) DEFAULT 0,
+  `tags` JSON DEFAULT NULL,
+  `objectlock` JSON DEFAULT NULL,
+  `keyversion` int(11) DEFAULT 0,
+  `checksumalgorithm` varchar(20) DEFAULT NULL,
   UNIQUE KEY `rowkey` (`bucketname`,`objectname`,`uploadtime`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -164,6 +176,7 @@ CREATE TABLE `objectpart` (
   `bucketname` varchar(255) DEFAULT NULL,
   `objectname` varchar(255) DEFAULT NULL,
   `version` varchar(255) DEFAULT NULL,
+  `checksum` varchar(255) DEFAULT NULL,
    KEY `rowkey` (`bucketname`,`objectname`,`version`)
 
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
@@ -197,6 +210,13 @@ CREATE TABLE `objects` (
   `initializationvector` blob DEFAULT NULL,
   `type` tinyint(1) DEFAULT 0,
   `storageclass` tinyint(1) DEFAULT 0,
+  `tags` JSON DEFAULT NULL,
+  `replicationstatus` varchar(20) DEFAULT NULL,
+  `objectlock` JSON DEFAULT NULL,
+  `ssekmskeyid` varchar(255) DEFAULT NULL,
+  `ssecontext` varchar(2048) DEFAULT NULL,
+  `keyversion` int(11) DEFAULT 0,
+  `checksum` JSON DEFAULT NULL,
    UNIQUE KEY `rowkey` (`bucketname`,`name`,`version`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -243,6 +263,7 @@ CREATE TABLE `restoreobjects` (
   `size` bigint(20) DEFAULT NULL,
   `objectid` varchar(255) DEFAULT NULL,
   `etag` varchar(255) DEFAULT NULL,
+  `tier` tinyint(1) DEFAULT '1',
   UNIQUE KEY `rowkey` (`bucketname`,`objectname`,`version`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 /*!40101 SET character_set_client = @saved_cs_client */;
@@ -290,5 +311,47 @@ CREATE TABLE `users` (
 DROP TABLE IF EXISTS `lifecycle`;
 CREATE TABLE `lifecycle` (
                        `bucketname` varchar(255) DEFAULT NULL,
-                       `status` varchar(255) DEFAULT NULL
-) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
\ No newline at end of file
+                       `status` varchar(255) DEFAULT NULL,
+                       `owner` varchar(255) DEFAULT NULL,
+                       `leaseexpire` datetime DEFAULT NULL,
+                       `marker` varchar(1024) DEFAULT NULL,
+                       `lastfinished` datetime DEFAULT NULL,
+                       UNIQUE KEY `rowkey` (`bucketname`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+DROP TABLE IF EXISTS `replication`;
+CREATE TABLE `replication` (
+                       `bucketname` varchar(255) DEFAULT NULL,
+                       `objectname` varchar(255) DEFAULT NULL,
+                       `version` bigint(20) UNSIGNED DEFAULT NULL,
+                       `triedtimes` int(11) DEFAULT 0,
+                       `nexttime` datetime DEFAULT NULL,
+                       UNIQUE KEY `rowkey` (`bucketname`,`objectname`,`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+DROP TABLE IF EXISTS `inventory`;
+CREATE TABLE `inventory` (
+                       `bucketname` varchar(255) DEFAULT NULL,
+                       `id` varchar(64) DEFAULT NULL,
+                       `configuration` JSON DEFAULT NULL,
+                       `owner` varchar(255) DEFAULT NULL,
+                       `leaseexpire` datetime DEFAULT NULL,
+                       `lastexport` datetime DEFAULT NULL,
+                       UNIQUE KEY `rowkey` (`bucketname`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+DROP TABLE IF EXISTS `quotas`;
+CREATE TABLE `quotas` (
+                       `kind` varchar(16) DEFAULT NULL,
+                       `name` varchar(255) DEFAULT NULL,
+                       `maxbytes` bigint(20) DEFAULT -1,
+                       `maxobjects` bigint(20) DEFAULT -1,
+                       UNIQUE KEY `rowkey` (`kind`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+DROP TABLE IF EXISTS `publicaccessblocks`;
+CREATE TABLE `publicaccessblocks` (
+                       `uid` varchar(255) NOT NULL DEFAULT '',
+                       `configuration` JSON DEFAULT NULL,
+                       PRIMARY KEY (`uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
@@ -38,8 +38,8 @@ func (m *Meta) GetBuckets() (buckets []Bucket, err error) {
 	return
 }
 
-func (m *Meta) UpdateUsage(bucketName string, size int64) {
-	m.Client.UpdateUsage(bucketName, size, nil)
+func (m *Meta) UpdateUsage(bucketName string, size int64, objects int64) {
+	m.Client.UpdateUsage(bucketName, size, objects, nil)
 }
 
 func (m *Meta) GetUsage(bucketName string) (int64, error) {
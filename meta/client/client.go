@@ -2,6 +2,8 @@ package client
 
 import (
 	"database/sql"
+	"time"
+
 	"github.com/journeymidnight/yig/api/datatype"
 	. "github.com/journeymidnight/yig/meta/types"
 )
@@ -15,6 +17,8 @@ type Client interface {
 	//object
 	GetObject(bucketName, objectName, version string) (object *Object, err error)
 	GetAllObject(bucketName, objectName, version string) (object []*Object, err error)
+	GetLatestObjectForUpdate(bucketName, objectName string, tx DB) (object *Object, err error)
+	GetReplacedObjectsForUpdate(object *Object, tx DB) (objects []*Object, err error)
 	PutObject(object *Object, tx DB) error
 	UpdateAppendObject(object *Object, tx DB) error
 	RenameObjectPart(object *Object, sourceObject string, tx DB) (err error)
@@ -22,7 +26,11 @@ type Client interface {
 	ReplaceObjectMetas(object *Object, tx DB) (err error)
 	DeleteObject(object *Object, tx DB) error
 	UpdateObject(object *Object, tx DB) (err error)
+	TransitObject(object, oldObject *Object, tx DB) (transited bool, err error)
 	UpdateObjectAcl(object *Object) error
+	UpdateObjectTags(object *Object) error
+	UpdateObjectReplicationStatus(object *Object) error
+	UpdateObjectLock(object *Object) error
 	UpdateObjectAttrs(object *Object) error
 	//bucket
 	GetBucket(bucketName string) (bucket *Bucket, err error)
@@ -31,7 +39,7 @@ type Client interface {
 	CheckAndPutBucket(bucket Bucket) (bool, error)
 	DeleteBucket(bucket Bucket) error
 	ListObjects(bucketName, marker, verIdMarker, prefix, delimiter string, versioned bool, maxKeys int) (retObjects []*Object, prefixes []string, truncated bool, nextMarker, nextVerIdMarker string, err error)
-	UpdateUsage(bucketName string, size int64, tx DB) error
+	UpdateUsage(bucketName string, size int64, objects int64, tx DB) error
 
 	//multipart
 	GetMultipart(bucketName, objectName, uploadId string) (multipart Multipart, err error)
@@ -39,6 +47,7 @@ type Client interface {
 	PutObjectPart(multipart *Multipart, part *Part, tx DB) (err error)
 	DeleteMultipart(multipart *Multipart, tx DB) (err error)
 	ListMultipartUploads(bucketName, keyMarker, uploadIdMarker, prefix, delimiter, encodingType string, maxUploads int) (uploads []datatype.Upload, prefixs []string, isTruncated bool, nextKeyMarker, nextUploadIdMarker string, err error)
+	ScanStaleMultiparts(bucketName, prefix string, initiatedBefore time.Time, marker Multipart, limit int) (multiparts []Multipart, err error)
 	//objmap
 	GetObjectMap(bucketName, objectName string) (objMap *ObjMap, err error)
 	PutObjectMap(objMap *ObjMap, tx DB) error
@@ -49,6 +58,31 @@ type Client interface {
 	PutBucketToLifeCycle(lifeCycle LifeCycle) error
 	RemoveBucketFromLifeCycle(bucket Bucket) error
 	ScanLifeCycle(limit int, marker string) (result ScanLifeCycleResult, err error)
+	GetLifeCycle(bucketName string) (lc LifeCycle, err error)
+	AcquireLifeCycle(bucketName, owner string, leaseExpire, finishedBefore time.Time) (bool, error)
+	RenewLifeCycle(bucketName, owner string, leaseExpire time.Time) (bool, error)
+	UpdateLifeCycleMarker(bucketName, owner, marker string) error
+	ReleaseLifeCycle(bucketName, owner string, finished bool) error
+	//inventory
+	PutInventory(inventory Inventory) error
+	GetInventory(bucketName, id string) (inventory Inventory, err error)
+	ListInventories(bucketName, marker string, limit int) (inventories []Inventory, err error)
+	ScanInventories(marker Inventory, limit int) (inventories []Inventory, err error)
+	DeleteInventory(bucketName, id string) error
+	DeleteBucketInventories(bucketName string) error
+	AcquireInventory(bucketName, id, owner string, leaseExpire, exportedBefore time.Time) (bool, error)
+	RenewInventory(bucketName, id, owner string, leaseExpire time.Time) (bool, error)
+	ReleaseInventory(bucketName, id, owner string, exported bool) error
+	//quota
+	PutQuota(quota Quota) error
+	GetQuota(kind, name string) (quota Quota, err error)
+	DeleteQuota(kind, name string) error
+	GetBucketQuotaUsage(bucketName string) (usage QuotaUsage, err error)
+	GetUserQuotaUsage(userId string) (usage QuotaUsage, err error)
+	//account public access block
+	PutPublicAccessBlock(uid string, config datatype.PublicAccessBlockConfiguration) error
+	GetPublicAccessBlock(uid string) (config datatype.PublicAccessBlockConfiguration, err error)
+	DeletePublicAccessBlock(uid string) error
 	//user
 	GetUserBuckets(userId string) (buckets []string, err error)
 	AddBucketForUser(bucketName, userId string) (err error)
@@ -58,10 +92,24 @@ type Client interface {
 	PutFreezerToGarbageCollection(object *Freezer, tx DB) (err error)
 	ScanGarbageCollection(limit int, startRowKey string) ([]GarbageCollection, error)
 	RemoveGarbageCollection(garbage GarbageCollection) error
+	//replication
+	PutObjectToReplication(object *Object, tx DB) error
+	ScanReplication(limit int) ([]Replication, error)
+	UpdateReplication(replication Replication) error
+	RemoveReplication(replication Replication) error
+	//sse master key rotation
+	ScanObjectSealedKeys(keyVersion int, marker SealedKey, limit int) ([]SealedKey, error)
+	UpdateObjectSealedKey(old, key SealedKey) (bool, error)
+	ScanMultipartSealedKeys(keyVersion int, marker SealedKey, limit int) ([]SealedKey, error)
+	UpdateMultipartSealedKey(old, key SealedKey) (bool, error)
 	//freezer
 	CreateFreezer(freezer *Freezer) (err error)
 	GetFreezer(bucketName, objectName, version string) (freezer *Freezer, err error)
 	GetFreezerStatus(bucketName, objectName, version string) (freezer *Freezer, err error)
 	UploadFreezerDate(bucketName, objectName string, lifetime int) (err error)
 	DeleteFreezer(bucketName, objectName string, tx DB) (err error)
+	ScanFreezers(limit int, restoringBefore time.Time) (freezers []Freezer, err error)
+	ScanExpiredFreezers(limit int) (freezers []Freezer, err error)
+	UpdateFreezer(freezer *Freezer, status Status, modified time.Time, tx DB) (updated bool, err error)
+	PutFreezerParts(freezer *Freezer, tx DB) (err error)
 }
@@ -3,6 +3,7 @@ package tidbclient
 import (
 	"database/sql"
 	"encoding/json"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -14,8 +15,8 @@ import (
 )
 
 func (t *TidbClient) GetBucket(bucketName string) (bucket *Bucket, err error) {
-	var acl, cors, logging, lc, policy, website, encryption, createTime string
-	sqltext := "select bucketname,acl,cors,COALESCE(logging,\"\"),lc,uid,policy,website,COALESCE(encryption,\"\"),createtime,usages,versioning from buckets where bucketname=?;"
+	var acl, cors, logging, lc, policy, website, encryption, tags, notification, replication, objectLock, publicAccessBlock, ownershipControls, createTime string
+	sqltext := "select bucketname,acl,cors,COALESCE(logging,\"\"),lc,uid,policy,website,COALESCE(encryption,\"\"),COALESCE(tags,\"{}\"),COALESCE(notification,\"{}\"),COALESCE(replication,\"{}\"),COALESCE(objectlock,\"{}\"),COALESCE(publicaccessblock,\"{}\"),COALESCE(ownershipcontrols,\"{}\"),createtime,usages,versioning from buckets where bucketname=?;"
 	bucket = new(Bucket)
 	err = t.Client.QueryRow(sqltext, bucketName).Scan(
 		&bucket.Name,
@@ -27,6 +28,12 @@ func (t *TidbClient) GetBucket(bucketName string) (bucket *Bucket, err error) {
 		&policy,
 		&website,
 		&encryption,
+		&tags,
+		&notification,
+		&replication,
+		&objectLock,
+		&publicAccessBlock,
+		&ownershipControls,
 		&createTime,
 		&bucket.Usage,
 		&bucket.Versioning,
@@ -69,11 +76,35 @@ func (t *TidbClient) GetBucket(bucketName string) (bucket *Bucket, err error) {
 	if err != nil {
 		return
 	}
+	err = json.Unmarshal([]byte(tags), &bucket.Tags)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(notification), &bucket.Notification)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(replication), &bucket.Replication)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(objectLock), &bucket.ObjectLock)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(publicAccessBlock), &bucket.PublicAccessBlock)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(ownershipControls), &bucket.OwnershipControls)
+	if err != nil {
+		return
+	}
 	return
 }
 
 func (t *TidbClient) GetBuckets() (buckets []Bucket, err error) {
-	sqltext := "select bucketname,acl,cors,COALESCE(logging,\"\"),lc,uid,policy,website,COALESCE(encryption,\"\"),createtime,usages,versioning from buckets;"
+	sqltext := "select bucketname,acl,cors,COALESCE(logging,\"\"),lc,uid,policy,website,COALESCE(encryption,\"\"),COALESCE(tags,\"{}\"),COALESCE(notification,\"{}\"),COALESCE(replication,\"{}\"),COALESCE(objectlock,\"{}\"),COALESCE(publicaccessblock,\"{}\"),COALESCE(ownershipcontrols,\"{}\"),createtime,usages,versioning from buckets;"
 	rows, err := t.Client.Query(sqltext)
 	if err == sql.ErrNoRows {
 		err = nil
@@ -85,7 +116,7 @@ func (t *TidbClient) GetBuckets() (buckets []Bucket, err error) {
 
 	for rows.Next() {
 		var tmp Bucket
-		var acl, cors, logging, lc, policy, website,encryption, createTime string
+		var acl, cors, logging, lc, policy, website,encryption, tags, notification, replication, objectLock, publicAccessBlock, ownershipControls, createTime string
 		err = rows.Scan(
 			&tmp.Name,
 			&acl,
@@ -96,6 +127,12 @@ func (t *TidbClient) GetBuckets() (buckets []Bucket, err error) {
 			&policy,
 			&website,
 			&encryption,
+			&tags,
+			&notification,
+			&replication,
+			&objectLock,
+			&publicAccessBlock,
+			&ownershipControls,
 			&createTime,
 			&tmp.Usage,
 			&tmp.Versioning)
@@ -134,6 +171,30 @@ func (t *TidbClient) GetBuckets() (buckets []Bucket, err error) {
 		if err != nil {
 			return
 		}
+		err = json.Unmarshal([]byte(tags), &tmp.Tags)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal([]byte(notification), &tmp.Notification)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal([]byte(replication), &tmp.Replication)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal([]byte(objectLock), &tmp.ObjectLock)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal([]byte(publicAccessBlock), &tmp.PublicAccessBlock)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal([]byte(ownershipControls), &tmp.OwnershipControls)
+		if err != nil {
+			return
+		}
 		buckets = append(buckets, tmp)
 	}
 	return
@@ -168,7 +229,7 @@ func (t *TidbClient) CheckAndPutBucket(bucket Bucket) (bool, error) {
 
 func (t *TidbClient) ListObjects(bucketName, marker, verIdMarker, prefix, delimiter string, versioned bool, maxKeys int) (retObjects []*Object, prefixes []string, truncated bool, nextMarker, nextVerIdMarker string, err error) {
 	if versioned {
-		return
+		return t.listVersionedObjects(bucketName, marker, verIdMarker, prefix, delimiter, maxKeys)
 	}
 	var count int
 	var exit bool
@@ -321,6 +382,104 @@ func (t *TidbClient) ListObjects(bucketName, marker, verIdMarker, prefix, delimi
 	return
 }
 
+// listVersionedObjects lists all versions including delete markers, versions of the same
+// object are listed from the newest. verIdMarker is version id of the last version listed.
+func (t *TidbClient) listVersionedObjects(bucketName, marker, verIdMarker, prefix, delimiter string,
+	maxKeys int) (retObjects []*Object, prefixes []string, truncated bool, nextMarker, nextVerIdMarker string, err error) {
+
+	var version uint64
+	if verIdMarker != "" {
+		marked := &Object{VersionId: verIdMarker}
+		var timestamp uint64
+		timestamp, err = marked.GetVersionNumber()
+		if err != nil {
+			return
+		}
+		version = math.MaxUint64 - timestamp
+	}
+	omarker := marker
+	prefixPattern := prefix + "%"
+	commonPrefixes := make(map[string]struct{})
+	var count int
+	for {
+		var rows *sql.Rows
+		if version == 0 {
+			// starts from objects after marker
+			sqltext := `select bucketname,name,version from objects
+				where bucketname=? and name>? and name like ?
+				order by bucketname,name,version
+				limit ?`
+			rows, err = t.Client.Query(sqltext, bucketName, marker, prefixPattern, maxKeys)
+		} else {
+			sqltext := `select bucketname,name,version from objects
+				where bucketname=? and ((name=? and version>?) or name>?) and name like ?
+				order by bucketname,name,version
+				limit ?`
+			rows, err = t.Client.Query(sqltext, bucketName, marker, version, marker, prefixPattern, maxKeys)
+		}
+		if err != nil {
+			return
+		}
+		var loopcount int
+		var exit bool
+		for rows.Next() {
+			loopcount += 1
+			var bucketname, name string
+			err = rows.Scan(&bucketname, &name, &version)
+			if err != nil {
+				_ = rows.Close()
+				return
+			}
+			marker = name
+			if len(delimiter) != 0 {
+				subStr := strings.TrimPrefix(name, prefix)
+				n := strings.Index(subStr, delimiter)
+				if n != -1 {
+					prefixKey := prefix + subStr[0:(n+1)]
+					if prefixKey == omarker {
+						continue
+					}
+					if _, ok := commonPrefixes[prefixKey]; !ok {
+						if count == maxKeys {
+							truncated = true
+							exit = true
+							break
+						}
+						commonPrefixes[prefixKey] = struct{}{}
+						nextMarker, nextVerIdMarker = prefixKey, ""
+						count += 1
+					}
+					continue
+				}
+			}
+			if count == maxKeys {
+				truncated = true
+				exit = true
+				break
+			}
+			var o *Object
+			o, err = t.GetObject(bucketname, name, strconv.FormatUint(version, 10))
+			if err == ErrNoSuchKey {
+				// it's possible the version is already deleted
+				continue
+			}
+			if err != nil {
+				_ = rows.Close()
+				return
+			}
+			count += 1
+			retObjects = append(retObjects, o)
+			nextMarker, nextVerIdMarker = name, o.VersionId
+		}
+		_ = rows.Close()
+		if loopcount < maxKeys || exit {
+			break
+		}
+	}
+	prefixes = helper.Keys(commonPrefixes)
+	return
+}
+
 func (t *TidbClient) DeleteBucket(bucket Bucket) error {
 	sqltext := "delete from buckets where bucketname=?;"
 	_, err := t.Client.Exec(sqltext, bucket.Name)
@@ -330,15 +489,17 @@ func (t *TidbClient) DeleteBucket(bucket Bucket) error {
 	return nil
 }
 
-func (t *TidbClient) UpdateUsage(bucketName string, size int64, tx DB) (err error) {
-	if !helper.CONFIG.PiggybackUpdateUsage {
+// UpdateUsage adds size to bytes used and objects to object count of bucket,
+// usage is always tracked if quota is enabled
+func (t *TidbClient) UpdateUsage(bucketName string, size int64, objects int64, tx DB) (err error) {
+	if !helper.CONFIG.PiggybackUpdateUsage && !helper.CONFIG.EnableQuota {
 		return nil
 	}
 
 	if tx == nil {
 		tx = t.Client
 	}
-	sql := "update buckets set usages= usages + ? where bucketname=?;"
-	_, err = tx.Exec(sql, size, bucketName)
+	sql := "update buckets set usages= usages + ?, objects= COALESCE(objects,0) + ? where bucketname=?;"
+	_, err = tx.Exec(sql, size, objects, bucketName)
 	return
 }
@@ -7,6 +7,10 @@ import (
 	"time"
 )
 
+const freezerColumns = "bucketname,objectname,IFNULL(version,''),status,lifetime,lastmodifiedtime," +
+	"IFNULL(location,''),IFNULL(pool,''),IFNULL(ownerid,''),IFNULL(size,'0'),IFNULL(objectid,''),IFNULL(etag,'')," +
+	"IFNULL(tier,1)"
+
 func (t *TidbClient) CreateFreezer(freezer *Freezer) (err error) {
 	sql, args := freezer.GetCreateSql()
 	_, err = t.Client.Exec(sql, args...)
@@ -15,7 +19,7 @@ func (t *TidbClient) CreateFreezer(freezer *Freezer) (err error) {
 
 func (t *TidbClient) GetFreezer(bucketName, objectName, version string) (freezer *Freezer, err error) {
 	var lastmodifiedtime string
-	sqltext := "select bucketname,objectname,IFNULL(version,''),status,lifetime,lastmodifiedtime,IFNULL(location,''),IFNULL(pool,''),IFNULL(ownerid,''),IFNULL(size,'0'),IFNULL(objectid,''),IFNULL(etag,'') from restoreobjects where bucketname=? and objectname=?;"
+	sqltext := "select " + freezerColumns + " from restoreobjects where bucketname=? and objectname=?;"
 	row := t.Client.QueryRow(sqltext, bucketName, objectName)
 	freezer = &Freezer{}
 	err = row.Scan(
@@ -31,6 +35,7 @@ func (t *TidbClient) GetFreezer(bucketName, objectName, version string) (freezer
 		&freezer.Size,
 		&freezer.ObjectId,
 		&freezer.Etag,
+		&freezer.Tier,
 	)
 	if err == sql.ErrNoRows {
 		err = ErrNoSuchKey
@@ -98,7 +103,7 @@ func (t *TidbClient) DeleteFreezer(bucketName, objectName string, tx DB) (err er
 	if err != nil {
 		return err
 	}
-	sqltext = "delete from restoreobjectpart where objectname=? and bucketname=?;"
+	sqltext = "delete from restoreobjectpart where bucketname=? and objectname=?;"
 	_, err = tx.Exec(sqltext, bucketName, objectName)
 	if err != nil {
 		return err
@@ -106,7 +111,102 @@ func (t *TidbClient) DeleteFreezer(bucketName, objectName string, tx DB) (err er
 	return nil
 }
 
+// ScanFreezers returns restore requests to be processed, which are READY, or RESTORING
+// but started before restoringBefore, in order of tier and request time
+func (t *TidbClient) ScanFreezers(limit int, restoringBefore time.Time) (freezers []Freezer, err error) {
+	sqltext := "select " + freezerColumns + " from restoreobjects where status=? or (status=? and lastmodifiedtime<?) " +
+		"order by tier,lastmodifiedtime limit ?;"
+	return t.scanFreezers(sqltext, ObjectNeedRestore, ObjectRestoring,
+		restoringBefore.UTC().Format(TIME_LAYOUT_TIDB), limit)
+}
+
+// ScanExpiredFreezers returns restored objects whose life time is over
+func (t *TidbClient) ScanExpiredFreezers(limit int) (freezers []Freezer, err error) {
+	now := time.Now().UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "select " + freezerColumns + " from restoreobjects " +
+		"where status=? and date_add(lastmodifiedtime, interval lifetime day)<=? limit ?;"
+	return t.scanFreezers(sqltext, ObjectHasRestored, now, limit)
+}
+
+// UpdateFreezer changes status and modified time of freezer, along with location of the
+// restored copy, only if status and modified time of freezer are not changed by others,
+// so a timed-out RESTORING freezer is claimed by only one worker
+func (t *TidbClient) UpdateFreezer(freezer *Freezer, status Status, modified time.Time, tx DB) (updated bool, err error) {
+	if tx == nil {
+		tx = t.Client
+	}
+	sqltext, args := freezer.GetUpdateSql(status, modified)
+	result, err := tx.Exec(sqltext, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// PutFreezerParts replaces parts of the restored copy
+func (t *TidbClient) PutFreezerParts(freezer *Freezer, tx DB) (err error) {
+	if tx == nil {
+		tx = t.Client
+	}
+	sqltext := "delete from restoreobjectpart where bucketname=? and objectname=?;"
+	_, err = tx.Exec(sqltext, freezer.BucketName, freezer.Name)
+	if err != nil {
+		return err
+	}
+	for _, p := range freezer.Parts {
+		psql, args := p.GetCreateFreezerSql(freezer.BucketName, freezer.Name)
+		_, err = tx.Exec(psql, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //util function
+func (t *TidbClient) scanFreezers(sqltext string, args ...interface{}) (freezers []Freezer, err error) {
+	rows, err := t.Client.Query(sqltext, args...)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f Freezer
+		var lastmodifiedtime string
+		err = rows.Scan(
+			&f.BucketName,
+			&f.Name,
+			&f.VersionId,
+			&f.Status,
+			&f.LifeTime,
+			&lastmodifiedtime,
+			&f.Location,
+			&f.Pool,
+			&f.OwnerId,
+			&f.Size,
+			&f.ObjectId,
+			&f.Etag,
+			&f.Tier,
+		)
+		if err != nil {
+			return
+		}
+		f.LastModifiedTime, err = time.Parse(TIME_LAYOUT_TIDB, lastmodifiedtime)
+		if err != nil {
+			return
+		}
+		freezers = append(freezers, f)
+	}
+	return freezers, rows.Err()
+}
+
 func getFreezerParts(bucketName, objectName string, cli *sql.DB) (parts map[int]*Part, err error) {
 	parts = make(map[int]*Part)
 	sqltext := "select partnumber,size,objectid,offset,etag,lastmodified,initializationvector from restoreobjectpart where bucketname=? and objectname=?;"
@@ -0,0 +1,39 @@
+package tidbclient_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/journeymidnight/yig/meta/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTidbClient_UpdateFreezer(t *testing.T) {
+	client, mock, err := newClient()
+	if err != nil {
+		t.Fatal("Error creating mock client:", err)
+	}
+	defer client.Client.Close()
+
+	scanned := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	started := scanned.Add(25 * time.Hour)
+	freezer := &Freezer{BucketName: "b", Name: "o", Status: ObjectRestoring, LastModifiedTime: scanned}
+
+	mock.ExpectExec("update restoreobjects set .* where bucketname=\\? and objectname=\\? "+
+		"and status=\\? and lastmodifiedtime=\\?").
+		WithArgs(ObjectRestoring, "2020-01-02 01:00:00", "", "", "", int64(0), "", "",
+			"b", "o", ObjectRestoring, "2020-01-01 00:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	claimed, err := client.UpdateFreezer(freezer, ObjectRestoring, started, nil)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+
+	// restarted by another worker since scanned
+	mock.ExpectExec("update restoreobjects set").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	claimed, err = client.UpdateFreezer(freezer, ObjectRestoring, started, nil)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,155 @@
+package tidbclient
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	. "github.com/journeymidnight/yig/error"
+	. "github.com/journeymidnight/yig/meta/types"
+)
+
+const inventoryColumns = "bucketname,id,configuration,COALESCE(owner,''),COALESCE(leaseexpire,'1970-01-01 00:00:00')," +
+	"COALESCE(lastexport,'1970-01-01 00:00:00')"
+
+// PutInventory creates the configuration, or replaces it if the id exists,
+// export progress of the configuration is kept
+func (t *TidbClient) PutInventory(inventory Inventory) error {
+	config, _ := json.Marshal(inventory.Config)
+	sqltext := "insert into inventory(bucketname,id,configuration) values(?,?,?) " +
+		"on duplicate key update configuration=?;"
+	_, err := t.Client.Exec(sqltext, inventory.BucketName, inventory.Id, config, config)
+	return err
+}
+
+func (t *TidbClient) GetInventory(bucketName, id string) (inventory Inventory, err error) {
+	sqltext := "select " + inventoryColumns + " from inventory where bucketname=? and id=?;"
+	inventory, err = scanInventory(t.Client.QueryRow(sqltext, bucketName, id))
+	if err == sql.ErrNoRows {
+		err = ErrNoSuchInventoryConfiguration
+	}
+	return
+}
+
+// ListInventories returns configurations of bucket with id after marker, ordered by id
+func (t *TidbClient) ListInventories(bucketName, marker string, limit int) (inventories []Inventory, err error) {
+	sqltext := "select " + inventoryColumns + " from inventory where bucketname=? and id>? order by id limit ?;"
+	rows, err := t.Client.Query(sqltext, bucketName, marker, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		inventory, err := scanInventory(rows)
+		if err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, inventory)
+	}
+	return inventories, rows.Err()
+}
+
+// ScanInventories returns configurations of all buckets after marker, ordered by bucket and id
+func (t *TidbClient) ScanInventories(marker Inventory, limit int) (inventories []Inventory, err error) {
+	sqltext := "select " + inventoryColumns + " from inventory " +
+		"where bucketname>? or (bucketname=? and id>?) order by bucketname,id limit ?;"
+	rows, err := t.Client.Query(sqltext, marker.BucketName, marker.BucketName, marker.Id, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		inventory, err := scanInventory(rows)
+		if err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, inventory)
+	}
+	return inventories, rows.Err()
+}
+
+func (t *TidbClient) DeleteInventory(bucketName, id string) error {
+	sqltext := "delete from inventory where bucketname=? and id=?;"
+	_, err := t.Client.Exec(sqltext, bucketName, id)
+	return err
+}
+
+func (t *TidbClient) DeleteBucketInventories(bucketName string) error {
+	sqltext := "delete from inventory where bucketname=?;"
+	_, err := t.Client.Exec(sqltext, bucketName)
+	return err
+}
+
+func scanInventory(row interface {
+	Scan(dest ...interface{}) error
+}) (inventory Inventory, err error) {
+	var config []byte
+	var leaseExpire, lastExport string
+	err = row.Scan(
+		&inventory.BucketName,
+		&inventory.Id,
+		&config,
+		&inventory.Owner,
+		&leaseExpire,
+		&lastExport)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(config, &inventory.Config)
+	if err != nil {
+		return
+	}
+	inventory.LeaseExpire, _ = time.Parse(TIME_LAYOUT_TIDB, leaseExpire)
+	inventory.LastExport, _ = time.Parse(TIME_LAYOUT_TIDB, lastExport)
+	return inventory, nil
+}
+
+// AcquireInventory takes the lease of configuration if it's not exported by other
+// instances and not exported after exportedBefore
+func (t *TidbClient) AcquireInventory(bucketName, id, owner string, leaseExpire, exportedBefore time.Time) (bool, error) {
+	now := time.Now().UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "update inventory set owner=?,leaseexpire=? where bucketname=? and id=? " +
+		"and (owner is null or owner='' or owner=? or leaseexpire<?) " +
+		"and (lastexport is null or lastexport<?);"
+	result, err := t.Client.Exec(sqltext, owner, leaseExpire.UTC().Format(TIME_LAYOUT_TIDB), bucketName, id,
+		owner, now, exportedBefore.UTC().Format(TIME_LAYOUT_TIDB))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// RenewInventory extends the lease of configuration, false if the lease is taken by others
+func (t *TidbClient) RenewInventory(bucketName, id, owner string, leaseExpire time.Time) (bool, error) {
+	sqltext := "update inventory set leaseexpire=? where bucketname=? and id=? and owner=?;"
+	result, err := t.Client.Exec(sqltext, leaseExpire.UTC().Format(TIME_LAYOUT_TIDB), bucketName, id, owner)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+	// leaseexpire is not changed if renewed within a second
+	inventory, err := t.GetInventory(bucketName, id)
+	if err != nil {
+		return false, err
+	}
+	return inventory.Owner == owner, nil
+}
+
+// ReleaseInventory gives up the lease of configuration, and records the export time if exported
+func (t *TidbClient) ReleaseInventory(bucketName, id, owner string, exported bool) error {
+	if !exported {
+		sqltext := "update inventory set owner='' where bucketname=? and id=? and owner=?;"
+		_, err := t.Client.Exec(sqltext, bucketName, id, owner)
+		return err
+	}
+	sqltext := "update inventory set owner='',lastexport=? where bucketname=? and id=? and owner=?;"
+	_, err := t.Client.Exec(sqltext, time.Now().UTC().Format(TIME_LAYOUT_TIDB), bucketName, id, owner)
+	return err
+}
@@ -2,13 +2,18 @@ package tidbclient
 
 import (
 	"database/sql"
+	"time"
 
+	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	. "github.com/journeymidnight/yig/meta/types"
 )
 
+const lifeCycleColumns = "bucketname,status,COALESCE(owner,''),COALESCE(leaseexpire,'1970-01-01 00:00:00')," +
+	"COALESCE(marker,''),COALESCE(lastfinished,'1970-01-01 00:00:00')"
+
 func (t *TidbClient) PutBucketToLifeCycle(lifeCycle LifeCycle) error {
-	sqltext := "insert into lifecycle(bucketname,status) values (?,?);"
+	sqltext := "insert ignore into lifecycle(bucketname,status) values (?,?);"
 	_, err := t.Client.Exec(sqltext, lifeCycle.BucketName, lifeCycle.Status)
 	if err != nil {
 		helper.Logger.Error("Failed to execute:", sqltext, "err:", err)
@@ -29,7 +34,7 @@ func (t *TidbClient) RemoveBucketFromLifeCycle(bucket Bucket) error {
 
 func (t *TidbClient) ScanLifeCycle(limit int, marker string) (result ScanLifeCycleResult, err error) {
 	result.Truncated = false
-	sqltext := "select bucketname,status from lifecycle where bucketname > ? limit ?;"
+	sqltext := "select " + lifeCycleColumns + " from lifecycle where bucketname > ? order by bucketname limit ?;"
 	rows, err := t.Client.Query(sqltext, marker, limit)
 	if err == sql.ErrNoRows {
 		helper.Logger.Error("Failed in sql.ErrNoRows:", sqltext, "err:", err)
@@ -42,9 +47,7 @@ func (t *TidbClient) ScanLifeCycle(limit int, marker string) (result ScanLifeCyc
 	result.Lcs = make([]LifeCycle, 0, limit)
 	var lc LifeCycle
 	for rows.Next() {
-		err = rows.Scan(
-			&lc.BucketName,
-			&lc.Status)
+		lc, err = scanLifeCycle(rows)
 		if err != nil {
 			helper.Logger.Error("Failed in scan LifeCycle:", err)
 			return
@@ -57,3 +60,87 @@ func (t *TidbClient) ScanLifeCycle(limit int, marker string) (result ScanLifeCyc
 	}
 	return result, nil
 }
+
+func (t *TidbClient) GetLifeCycle(bucketName string) (lc LifeCycle, err error) {
+	sqltext := "select " + lifeCycleColumns + " from lifecycle where bucketname=?;"
+	lc, err = scanLifeCycle(t.Client.QueryRow(sqltext, bucketName))
+	if err == sql.ErrNoRows {
+		err = ErrNoSuchBucketLc
+	}
+	return
+}
+
+func scanLifeCycle(row interface {
+	Scan(dest ...interface{}) error
+}) (lc LifeCycle, err error) {
+	var leaseExpire, lastFinished string
+	err = row.Scan(
+		&lc.BucketName,
+		&lc.Status,
+		&lc.Owner,
+		&leaseExpire,
+		&lc.Marker,
+		&lastFinished)
+	if err != nil {
+		return
+	}
+	lc.LeaseExpire, _ = time.Parse(TIME_LAYOUT_TIDB, leaseExpire)
+	lc.LastFinished, _ = time.Parse(TIME_LAYOUT_TIDB, lastFinished)
+	return lc, nil
+}
+
+// AcquireLifeCycle takes the lease of bucket if it's not processed by other instances
+// and not finished after finishedBefore
+func (t *TidbClient) AcquireLifeCycle(bucketName, owner string, leaseExpire, finishedBefore time.Time) (bool, error) {
+	now := time.Now().UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "update lifecycle set owner=?,leaseexpire=? where bucketname=? " +
+		"and (owner is null or owner='' or owner=? or leaseexpire<?) " +
+		"and (lastfinished is null or lastfinished<?);"
+	result, err := t.Client.Exec(sqltext, owner, leaseExpire.UTC().Format(TIME_LAYOUT_TIDB), bucketName,
+		owner, now, finishedBefore.UTC().Format(TIME_LAYOUT_TIDB))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// RenewLifeCycle extends the lease of bucket, false if the lease is taken by others
+func (t *TidbClient) RenewLifeCycle(bucketName, owner string, leaseExpire time.Time) (bool, error) {
+	sqltext := "update lifecycle set leaseexpire=? where bucketname=? and owner=?;"
+	result, err := t.Client.Exec(sqltext, leaseExpire.UTC().Format(TIME_LAYOUT_TIDB), bucketName, owner)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+	// leaseexpire is not changed if renewed within a second
+	lc, err := t.GetLifeCycle(bucketName)
+	if err != nil {
+		return false, err
+	}
+	return lc.Owner == owner, nil
+}
+
+func (t *TidbClient) UpdateLifeCycleMarker(bucketName, owner, marker string) error {
+	sqltext := "update lifecycle set marker=? where bucketname=? and owner=?;"
+	_, err := t.Client.Exec(sqltext, marker, bucketName, owner)
+	return err
+}
+
+// ReleaseLifeCycle gives up the lease of bucket, progress is reset if finished
+func (t *TidbClient) ReleaseLifeCycle(bucketName, owner string, finished bool) error {
+	if !finished {
+		sqltext := "update lifecycle set owner='' where bucketname=? and owner=?;"
+		_, err := t.Client.Exec(sqltext, bucketName, owner)
+		return err
+	}
+	sqltext := "update lifecycle set owner='',marker='',lastfinished=? where bucketname=? and owner=?;"
+	_, err := t.Client.Exec(sqltext, time.Now().UTC().Format(TIME_LAYOUT_TIDB), bucketName, owner)
+	return err
+}
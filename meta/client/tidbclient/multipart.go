@@ -30,9 +30,11 @@ func (t *TidbClient) GetMultipart(bucketName, objectName, uploadId string) (mult
 	}
 	uploadTime = math.MaxUint64 - uploadTime
 	sqltext := "select bucketname,objectname,uploadtime,initiatorid,ownerid,contenttype,location,pool,acl,sserequest," +
-		"encryption,COALESCE(cipher,\"\"),attrs,storageclass from multiparts where bucketname=? and objectname=? and uploadtime=?;"
+		"encryption,COALESCE(cipher,\"\"),attrs,storageclass,COALESCE(tags,\"{}\"),COALESCE(objectlock,\"{}\"),COALESCE(keyversion,0)," +
+		"COALESCE(checksumalgorithm,\"\") from multiparts " +
+		"where bucketname=? and objectname=? and uploadtime=?;"
 	var initialTime uint64
-	var acl, sseRequest, attrs string
+	var acl, sseRequest, attrs, tags, objectLock string
 	err = t.Client.QueryRow(sqltext, bucketName, objectName, uploadTime).Scan(
 		&multipart.BucketName,
 		&multipart.ObjectName,
@@ -48,6 +50,10 @@ func (t *TidbClient) GetMultipart(bucketName, objectName, uploadId string) (mult
 		&multipart.Metadata.CipherKey,
 		&attrs,
 		&multipart.Metadata.StorageClass,
+		&tags,
+		&objectLock,
+		&multipart.Metadata.KeyVersion,
+		&multipart.Metadata.ChecksumAlgorithm,
 	)
 	if err != nil && err == sql.ErrNoRows {
 		err = ErrNoSuchUpload
@@ -71,8 +77,16 @@ func (t *TidbClient) GetMultipart(bucketName, objectName, uploadId string) (mult
 	if err != nil {
 		return
 	}
+	err = json.Unmarshal([]byte(tags), &multipart.Metadata.Tags)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(objectLock), &multipart.Metadata.ObjectLock)
+	if err != nil {
+		return
+	}
 
-	sqltext = "select partnumber,size,objectid,offset,etag,lastmodified,initializationvector from multipartpart where bucketname=? and objectname=? and uploadtime=?;"
+	sqltext = "select partnumber,size,objectid,offset,etag,lastmodified,initializationvector,COALESCE(checksum,\"\") from multipartpart where bucketname=? and objectname=? and uploadtime=?;"
 	rows, err := t.Client.Query(sqltext, bucketName, objectName, uploadTime)
 	if err != nil {
 		return
@@ -88,6 +102,7 @@ func (t *TidbClient) GetMultipart(bucketName, objectName, uploadId string) (mult
 			&p.Etag,
 			&p.LastModified,
 			&p.InitializationVector,
+			&p.Checksum,
 		)
 		ts, e := time.Parse(TIME_LAYOUT_TIDB, p.LastModified)
 		if e != nil {
@@ -108,9 +123,11 @@ func (t *TidbClient) CreateMultipart(multipart Multipart) (err error) {
 	acl, _ := json.Marshal(m.Acl)
 	sseRequest, _ := json.Marshal(m.SseRequest)
 	attrs, _ := json.Marshal(m.Attrs)
-	sqltext := "insert into multiparts(bucketname,objectname,uploadtime,initiatorid,ownerid,contenttype,location,pool,acl,sserequest,encryption,cipher,attrs,storageclass) " +
-		"values(?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
-	_, err = t.Client.Exec(sqltext, multipart.BucketName, multipart.ObjectName, uploadtime, m.InitiatorId, m.OwnerId, m.ContentType, m.Location, m.Pool, acl, sseRequest, m.EncryptionKey,m.CipherKey, attrs, m.StorageClass)
+	tags, _ := json.Marshal(m.Tags)
+	objectLock, _ := json.Marshal(m.ObjectLock)
+	sqltext := "insert into multiparts(bucketname,objectname,uploadtime,initiatorid,ownerid,contenttype,location,pool,acl,sserequest,encryption,cipher,attrs,storageclass,tags,objectlock,keyversion,checksumalgorithm) " +
+		"values(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	_, err = t.Client.Exec(sqltext, multipart.BucketName, multipart.ObjectName, uploadtime, m.InitiatorId, m.OwnerId, m.ContentType, m.Location, m.Pool, acl, sseRequest, m.EncryptionKey,m.CipherKey, attrs, m.StorageClass, tags, objectLock, m.KeyVersion, m.ChecksumAlgorithm)
 	return
 }
 
@@ -125,9 +142,9 @@ func (t *TidbClient) PutObjectPart(multipart *Multipart, part *Part, tx DB) (err
 		return
 	}
 	lastModified := lastt.Format(TIME_LAYOUT_TIDB)
-	sqltext := "insert into multipartpart(partnumber,size,objectid,offset,etag,lastmodified,initializationvector,bucketname,objectname,uploadtime) " +
-		"values(?,?,?,?,?,?,?,?,?,?)"
-	_, err = tx.Exec(sqltext, part.PartNumber, part.Size, part.ObjectId, part.Offset, part.Etag, lastModified, part.InitializationVector, multipart.BucketName, multipart.ObjectName, uploadtime)
+	sqltext := "insert into multipartpart(partnumber,size,objectid,offset,etag,lastmodified,initializationvector,bucketname,objectname,uploadtime,checksum) " +
+		"values(?,?,?,?,?,?,?,?,?,?,?)"
+	_, err = tx.Exec(sqltext, part.PartNumber, part.Size, part.ObjectId, part.Offset, part.Etag, lastModified, part.InitializationVector, multipart.BucketName, multipart.ObjectName, uploadtime, part.Checksum)
 	return
 }
 
@@ -289,3 +306,39 @@ func (t *TidbClient) RenameObjectPart(object *Object, sourceObject string, tx DB
 	_, err = tx.Exec(sql, args...)
 	return err
 }
+
+// ScanStaleMultiparts lists multipart uploads of bucket with prefix initiated before
+// initiatedBefore, after marker of the same order
+func (t *TidbClient) ScanStaleMultiparts(bucketName, prefix string, initiatedBefore time.Time,
+	marker Multipart, limit int) (multiparts []Multipart, err error) {
+
+	// uploadtime is reversed, uploads initiated earlier have greater uploadtime
+	before := math.MaxUint64 - uint64(initiatedBefore.UnixNano())
+	var markerTime uint64
+	if !marker.InitialTime.IsZero() {
+		markerTime = math.MaxUint64 - uint64(marker.InitialTime.UnixNano())
+	}
+	sqltext := "select objectname,uploadtime from multiparts where bucketname=? and objectname like ? and uploadtime>=? " +
+		"and (objectname>? or (objectname=? and uploadtime>?)) order by bucketname,objectname,uploadtime limit ?;"
+	rows, err := t.Client.Query(sqltext, bucketName, prefix+"%", before,
+		marker.ObjectName, marker.ObjectName, markerTime, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var uploadtime uint64
+		err = rows.Scan(&name, &uploadtime)
+		if err != nil {
+			return
+		}
+		multiparts = append(multiparts, Multipart{
+			BucketName:  bucketName,
+			ObjectName:  name,
+			InitialTime: time.Unix(0, int64(math.MaxUint64-uploadtime)),
+			UploadId:    GetMultipartUploadIdForTidb(uploadtime),
+		})
+	}
+	return multiparts, rows.Err()
+}
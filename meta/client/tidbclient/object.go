@@ -14,12 +14,14 @@ import (
 )
 
 func (t *TidbClient) GetObject(bucketName, objectName, version string) (object *Object, err error) {
-	var ibucketname, iname, customattributes, acl, lastModifiedTime string
+	var ibucketname, iname, customattributes, acl, tags, objectLock, checksum, lastModifiedTime string
 	var iversion uint64
 
 	var row *sql.Row
 	sqltext := "select bucketname,name,version,location,pool,ownerid,size,objectid,lastmodifiedtime,etag,contenttype," +
-		"customattributes,acl,nullversion,deletemarker,ssetype,encryptionkey,initializationvector,type,storageclass from objects where bucketname=? and name=? "
+		"customattributes,acl,nullversion,deletemarker,ssetype,encryptionkey,initializationvector,type,storageclass," +
+		"COALESCE(tags,\"{}\"),COALESCE(replicationstatus,\"\"),COALESCE(objectlock,\"{}\"),COALESCE(ssekmskeyid,\"\"),COALESCE(ssecontext,\"\"),COALESCE(keyversion,0)," +
+		"COALESCE(checksum,\"{}\") from objects where bucketname=? and name=? "
 	if version == "" {
 		sqltext += "order by bucketname,name,version limit 1;"
 		row = t.Client.QueryRow(sqltext, bucketName, objectName)
@@ -49,6 +51,13 @@ func (t *TidbClient) GetObject(bucketName, objectName, version string) (object *
 		&object.InitializationVector,
 		&object.Type,
 		&object.StorageClass,
+		&tags,
+		&object.ReplicationStatus,
+		&objectLock,
+		&object.SseKmsKeyId,
+		&object.SseContext,
+		&object.KeyVersion,
+		&checksum,
 	)
 	if err == sql.ErrNoRows {
 		err = ErrNoSuchKey
@@ -70,6 +79,18 @@ func (t *TidbClient) GetObject(bucketName, objectName, version string) (object *
 	if err != nil {
 		return
 	}
+	err = json.Unmarshal([]byte(tags), &object.Tags)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(objectLock), &object.ObjectLock)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(checksum), &object.Checksum)
+	if err != nil {
+		return
+	}
 	object.Parts, err = getParts(object.BucketName, object.Name, iversion, t.Client)
 	//build simple index for multipart
 	if len(object.Parts) != 0 {
@@ -79,9 +100,7 @@ func (t *TidbClient) GetObject(bucketName, objectName, version string) (object *
 		}
 		object.PartsIndex = &SimpleIndex{Index: sortedPartNum}
 	}
-	var reversedTime uint64
-	timestamp := math.MaxUint64 - reversedTime
-	timeData := []byte(strconv.FormatUint(timestamp, 10))
+	timeData := []byte(strconv.FormatUint(rversion, 10))
 	object.VersionId = hex.EncodeToString(xxtea.Encrypt(timeData, XXTEA_KEY))
 	return
 }
@@ -113,6 +132,94 @@ func (t *TidbClient) GetAllObject(bucketName, objectName, version string) (objec
 	return
 }
 
+// GetLatestObjectForUpdate locks the bucket row in tx, so conditional writes to
+// the bucket are serialized, then returns etag of the latest version of object
+func (t *TidbClient) GetLatestObjectForUpdate(bucketName, objectName string, tx DB) (object *Object, err error) {
+	var ibucketname string
+	err = tx.QueryRow("select bucketname from buckets where bucketname=? for update;",
+		bucketName).Scan(&ibucketname)
+	if err == sql.ErrNoRows {
+		err = ErrNoSuchBucket
+		return
+	} else if err != nil {
+		return
+	}
+
+	var iversion uint64
+	object = &Object{
+		BucketName: bucketName,
+		Name:       objectName,
+	}
+	sqltext := "select version,etag,nullversion,deletemarker from objects where bucketname=? and name=? " +
+		"order by bucketname,name,version limit 1;"
+	err = tx.QueryRow(sqltext, bucketName, objectName).Scan(
+		&iversion,
+		&object.Etag,
+		&object.NullVersion,
+		&object.DeleteMarker,
+	)
+	if err == sql.ErrNoRows {
+		err = ErrNoSuchKey
+		return
+	} else if err != nil {
+		return
+	}
+	rversion := math.MaxUint64 - iversion
+	object.LastModifiedTime = time.Unix(0, int64(rversion))
+	return
+}
+
+// GetReplacedObjectsForUpdate locks null versions of object older than object in tx,
+// which are replaced by object, and returns them
+func (t *TidbClient) GetReplacedObjectsForUpdate(object *Object, tx DB) (objects []*Object, err error) {
+	version := math.MaxUint64 - uint64(object.LastModifiedTime.UnixNano())
+	sqltext := "select version from objects where bucketname=? and name=? and nullversion=true " +
+		"and version>? for update;"
+	rows, err := tx.Query(sqltext, object.BucketName, object.Name, version)
+	if err != nil {
+		return
+	}
+	var versions []string
+	for rows.Next() {
+		var sversion string
+		err = rows.Scan(&sversion)
+		if err != nil {
+			rows.Close()
+			return
+		}
+		versions = append(versions, sversion)
+	}
+	rows.Close()
+	// rows are locked, so they are read outside tx
+	for _, v := range versions {
+		var obj *Object
+		obj, err = t.GetObject(object.BucketName, object.Name, v)
+		if err != nil {
+			return
+		}
+		objects = append(objects, obj)
+	}
+	return
+}
+
+func (t *TidbClient) UpdateObjectTags(object *Object) error {
+	sql, args := object.GetUpdateTagsSql()
+	_, err := t.Client.Exec(sql, args...)
+	return err
+}
+
+func (t *TidbClient) UpdateObjectReplicationStatus(object *Object) error {
+	sql, args := object.GetUpdateReplicationStatusSql()
+	_, err := t.Client.Exec(sql, args...)
+	return err
+}
+
+func (t *TidbClient) UpdateObjectLock(object *Object) error {
+	sql, args := object.GetUpdateObjectLockSql()
+	_, err := t.Client.Exec(sql, args...)
+	return err
+}
+
 func (t *TidbClient) UpdateObjectAttrs(object *Object) error {
 	sql, args := object.GetUpdateAttrsSql()
 	_, err := t.Client.Exec(sql, args...)
@@ -221,6 +328,34 @@ func (t *TidbClient) UpdateObject(object *Object, tx DB) (err error) {
 	return nil
 }
 
+// TransitObject updates object with data transited from oldObject, returns false
+// if data of the object has been changed by others since oldObject is read
+func (t *TidbClient) TransitObject(object, oldObject *Object, tx DB) (transited bool, err error) {
+	if tx == nil {
+		tx = t.Client
+	}
+	sql, args := object.GetTransitSql(oldObject.ObjectId, oldObject.StorageClass)
+	result, err := tx.Exec(sql, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	v := math.MaxUint64 - uint64(object.LastModifiedTime.UnixNano())
+	version := strconv.FormatUint(v, 10)
+	for _, p := range object.Parts {
+		sqltext := "update objectpart set objectid=? where objectname=? and bucketname=? and version=? and partnumber=?;"
+		_, err = tx.Exec(sqltext, p.ObjectId, object.Name, object.BucketName, version, p.PartNumber)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func (t *TidbClient) DeleteObject(object *Object, tx DB) (err error) {
 	if tx == nil {
 		tx, err = t.Client.Begin()
@@ -255,7 +390,7 @@ func (t *TidbClient) DeleteObject(object *Object, tx DB) (err error) {
 //util function
 func getParts(bucketName, objectName string, version uint64, cli *sql.DB) (parts map[int]*Part, err error) {
 	parts = make(map[int]*Part)
-	sqltext := "select partnumber,size,objectid,offset,etag,lastmodified,initializationvector from objectpart where bucketname=? and objectname=? and version=?;"
+	sqltext := "select partnumber,size,objectid,offset,etag,lastmodified,initializationvector,COALESCE(checksum,\"\") from objectpart where bucketname=? and objectname=? and version=?;"
 	rows, err := cli.Query(sqltext, bucketName, objectName, version)
 	if err != nil {
 		return
@@ -271,6 +406,7 @@ func getParts(bucketName, objectName string, version uint64, cli *sql.DB) (parts
 			&p.Etag,
 			&p.LastModified,
 			&p.InitializationVector,
+			&p.Checksum,
 		)
 		parts[p.PartNumber] = p
 	}
@@ -0,0 +1,91 @@
+package tidbclient_test
+
+import (
+	"database/sql/driver"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journeymidnight/yig/meta/client/tidbclient"
+	. "github.com/journeymidnight/yig/meta/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// versionConverter accepts object versions, which are uint64 with high bit set
+// and sent in decimal by the mysql driver
+type versionConverter struct{}
+
+func (versionConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if version, ok := v.(uint64); ok {
+		return strconv.FormatUint(version, 10), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+func newVersionedClient() (*tidbclient.TidbClient, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(versionConverter{}))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tidbclient.TidbClient{Client: db}, mock, nil
+}
+
+func TestTidbClient_TransitObject(t *testing.T) {
+	client, mock, err := newVersionedClient()
+	if err != nil {
+		t.Fatal("Error creating mock client:", err)
+	}
+	defer client.Client.Close()
+
+	modified := time.Unix(100, 0)
+	version := math.MaxUint64 - uint64(modified.UnixNano())
+	old := &Object{BucketName: "b", Name: "o", LastModifiedTime: modified, Location: "ceph",
+		Pool: "rabbit", ObjectId: "old", StorageClass: ObjectStorageClassStandard}
+	transited := *old
+	transited.Pool, transited.ObjectId, transited.StorageClass = "tiger", "new", ObjectStorageClassGlacier
+
+	mock.ExpectExec("update objects set location=\\?,pool=\\?,objectid=\\?,storageclass=\\? "+
+		"where bucketname=\\? and name=\\? and version=\\? and objectid=\\? and storageclass=\\?").
+		WithArgs("ceph", "tiger", "new", ObjectStorageClassGlacier, "b", "o", version,
+			"old", ObjectStorageClassStandard).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ok, err := client.TransitObject(&transited, old, nil)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// overwritten during copying, parts are not touched
+	mock.ExpectExec("update objects set").WillReturnResult(sqlmock.NewResult(0, 0))
+	transited.Parts = map[int]*Part{1: {PartNumber: 1, ObjectId: "part"}}
+	ok, err = client.TransitObject(&transited, old, nil)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTidbClient_GetReplacedObjectsForUpdate(t *testing.T) {
+	client, mock, err := newVersionedClient()
+	if err != nil {
+		t.Fatal("Error creating mock client:", err)
+	}
+	defer client.Client.Close()
+
+	modified := time.Unix(100, 0)
+	object := &Object{BucketName: "b", Name: "o", LastModifiedTime: modified, NullVersion: true}
+
+	// older versions have larger version numbers
+	mock.ExpectBegin()
+	mock.ExpectQuery("select version from objects where bucketname=\\? and name=\\? and nullversion=true "+
+		"and version>\\? for update").
+		WithArgs("b", "o", math.MaxUint64-uint64(modified.UnixNano())).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	tx, err := client.Client.Begin()
+	if err != nil {
+		t.Fatal("Error beginning transaction:", err)
+	}
+	replaced, err := client.GetReplacedObjectsForUpdate(object, tx)
+	assert.Nil(t, err)
+	assert.Empty(t, replaced)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,39 @@
+package tidbclient
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/journeymidnight/yig/api/datatype"
+)
+
+func (t *TidbClient) PutPublicAccessBlock(uid string, config datatype.PublicAccessBlockConfiguration) error {
+	configuration, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	sqltext := "insert into publicaccessblocks(uid,configuration) values(?,?) " +
+		"on duplicate key update configuration=?;"
+	_, err = t.Client.Exec(sqltext, uid, configuration, configuration)
+	return err
+}
+
+// GetPublicAccessBlock returns empty configuration if not set
+func (t *TidbClient) GetPublicAccessBlock(uid string) (config datatype.PublicAccessBlockConfiguration, err error) {
+	var configuration string
+	sqltext := "select configuration from publicaccessblocks where uid=?;"
+	err = t.Client.QueryRow(sqltext, uid).Scan(&configuration)
+	if err == sql.ErrNoRows {
+		return config, nil
+	} else if err != nil {
+		return
+	}
+	err = json.Unmarshal([]byte(configuration), &config)
+	return
+}
+
+func (t *TidbClient) DeletePublicAccessBlock(uid string) error {
+	sqltext := "delete from publicaccessblocks where uid=?;"
+	_, err := t.Client.Exec(sqltext, uid)
+	return err
+}
@@ -0,0 +1,52 @@
+package tidbclient
+
+import (
+	"database/sql"
+
+	. "github.com/journeymidnight/yig/meta/types"
+)
+
+func (t *TidbClient) PutQuota(quota Quota) error {
+	sqltext := "insert into quotas(kind,name,maxbytes,maxobjects) values(?,?,?,?) " +
+		"on duplicate key update maxbytes=?,maxobjects=?;"
+	_, err := t.Client.Exec(sqltext, quota.Kind, quota.Name, quota.MaxBytes, quota.MaxObjects,
+		quota.MaxBytes, quota.MaxObjects)
+	return err
+}
+
+// GetQuota returns unlimited quota if not set
+func (t *TidbClient) GetQuota(kind, name string) (quota Quota, err error) {
+	sqltext := "select kind,name,maxbytes,maxobjects from quotas where kind=? and name=?;"
+	err = t.Client.QueryRow(sqltext, kind, name).Scan(
+		&quota.Kind,
+		&quota.Name,
+		&quota.MaxBytes,
+		&quota.MaxObjects,
+	)
+	if err == sql.ErrNoRows {
+		return UnlimitedQuota(kind, name), nil
+	}
+	return
+}
+
+func (t *TidbClient) DeleteQuota(kind, name string) error {
+	sqltext := "delete from quotas where kind=? and name=?;"
+	_, err := t.Client.Exec(sqltext, kind, name)
+	return err
+}
+
+func (t *TidbClient) GetBucketQuotaUsage(bucketName string) (usage QuotaUsage, err error) {
+	sqltext := "select COALESCE(usages,0),COALESCE(objects,0) from buckets where bucketname=?;"
+	err = t.Client.QueryRow(sqltext, bucketName).Scan(&usage.Bytes, &usage.Objects)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return
+}
+
+// GetUserQuotaUsage sums usage of all buckets owned by user
+func (t *TidbClient) GetUserQuotaUsage(userId string) (usage QuotaUsage, err error) {
+	sqltext := "select COALESCE(sum(usages),0),COALESCE(sum(objects),0) from buckets where uid=?;"
+	err = t.Client.QueryRow(sqltext, userId).Scan(&usage.Bytes, &usage.Objects)
+	return
+}
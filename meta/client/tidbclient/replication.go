@@ -0,0 +1,70 @@
+package tidbclient
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	. "github.com/journeymidnight/yig/meta/types"
+)
+
+//replication
+func (t *TidbClient) PutObjectToReplication(object *Object, tx DB) (err error) {
+	if tx == nil {
+		tx = t.Client
+	}
+	version := math.MaxUint64 - uint64(object.LastModifiedTime.UnixNano())
+	nextTime := time.Now().UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "insert ignore into replication(bucketname,objectname,version,triedtimes,nexttime) values(?,?,?,?,?);"
+	_, err = tx.Exec(sqltext, object.BucketName, object.Name, version, 0, nextTime)
+	return err
+}
+
+// ScanReplication returns entries due to be replicated, earliest first
+func (t *TidbClient) ScanReplication(limit int) (replications []Replication, err error) {
+	now := time.Now().UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "select bucketname,objectname,version,triedtimes,nexttime from replication " +
+		"where nexttime<=? order by nexttime limit ?;"
+	rows, err := t.Client.Query(sqltext, now, limit)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r Replication
+		var nextTime string
+		err = rows.Scan(
+			&r.BucketName,
+			&r.ObjectName,
+			&r.Version,
+			&r.TriedTimes,
+			&nextTime,
+		)
+		if err != nil {
+			return
+		}
+		r.NextTime, err = time.Parse(TIME_LAYOUT_TIDB, nextTime)
+		if err != nil {
+			return
+		}
+		replications = append(replications, r)
+	}
+	return replications, rows.Err()
+}
+
+func (t *TidbClient) UpdateReplication(replication Replication) error {
+	nextTime := replication.NextTime.UTC().Format(TIME_LAYOUT_TIDB)
+	sqltext := "update replication set triedtimes=?,nexttime=? where bucketname=? and objectname=? and version=?;"
+	_, err := t.Client.Exec(sqltext, replication.TriedTimes, nextTime,
+		replication.BucketName, replication.ObjectName, replication.Version)
+	return err
+}
+
+func (t *TidbClient) RemoveReplication(replication Replication) error {
+	sqltext := "delete from replication where bucketname=? and objectname=? and version=?;"
+	_, err := t.Client.Exec(sqltext, replication.BucketName, replication.ObjectName, replication.Version)
+	return err
+}
@@ -0,0 +1,96 @@
+package tidbclient
+
+import (
+	"database/sql"
+
+	. "github.com/journeymidnight/yig/meta/types"
+)
+
+// ScanObjectSealedKeys returns keys of object versions not sealed with keyVersion, after marker
+func (t *TidbClient) ScanObjectSealedKeys(keyVersion int, marker SealedKey, limit int) (keys []SealedKey, err error) {
+	sqltext := "select bucketname,name,version,COALESCE(keyversion,0),encryptionkey from objects " +
+		"where (bucketname,name,version)>(?,?,?) and COALESCE(keyversion,0)<>? and length(encryptionkey)>0 " +
+		"order by bucketname,name,version limit ?;"
+	rows, err := t.Client.Query(sqltext, marker.BucketName, marker.ObjectName, marker.Version, keyVersion, limit)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k SealedKey
+		err = rows.Scan(
+			&k.BucketName,
+			&k.ObjectName,
+			&k.Version,
+			&k.KeyVersion,
+			&k.EncryptionKey,
+		)
+		if err != nil {
+			return
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// UpdateObjectSealedKey replaces sealed key old of object version with key, only if
+// it's not changed since scanned, updated is false otherwise
+func (t *TidbClient) UpdateObjectSealedKey(old, key SealedKey) (updated bool, err error) {
+	sqltext := "update objects set encryptionkey=?,keyversion=? where bucketname=? and name=? and version=? " +
+		"and encryptionkey=? and COALESCE(keyversion,0)=?;"
+	result, err := t.Client.Exec(sqltext, key.EncryptionKey, key.KeyVersion, old.BucketName, old.ObjectName,
+		old.Version, old.EncryptionKey, old.KeyVersion)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// ScanMultipartSealedKeys returns keys of multipart uploads not sealed with keyVersion, after marker
+func (t *TidbClient) ScanMultipartSealedKeys(keyVersion int, marker SealedKey, limit int) (keys []SealedKey, err error) {
+	sqltext := "select bucketname,objectname,uploadtime,COALESCE(keyversion,0),encryption,COALESCE(cipher,\"\") " +
+		"from multiparts where (bucketname,objectname,uploadtime)>(?,?,?) and COALESCE(keyversion,0)<>? " +
+		"and length(encryption)>0 order by bucketname,objectname,uploadtime limit ?;"
+	rows, err := t.Client.Query(sqltext, marker.BucketName, marker.ObjectName, marker.Version, keyVersion, limit)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k SealedKey
+		err = rows.Scan(
+			&k.BucketName,
+			&k.ObjectName,
+			&k.Version,
+			&k.KeyVersion,
+			&k.EncryptionKey,
+			&k.CipherKey,
+		)
+		if err != nil {
+			return
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// UpdateMultipartSealedKey replaces sealed keys old of multipart upload with key, only if
+// they're not changed since scanned, updated is false otherwise
+func (t *TidbClient) UpdateMultipartSealedKey(old, key SealedKey) (updated bool, err error) {
+	sqltext := "update multiparts set encryption=?,cipher=?,keyversion=? " +
+		"where bucketname=? and objectname=? and uploadtime=? and encryption=? and COALESCE(keyversion,0)=?;"
+	result, err := t.Client.Exec(sqltext, key.EncryptionKey, key.CipherKey, key.KeyVersion,
+		old.BucketName, old.ObjectName, old.Version, old.EncryptionKey, old.KeyVersion)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
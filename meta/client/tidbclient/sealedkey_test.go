@@ -0,0 +1,59 @@
+package tidbclient_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/journeymidnight/yig/meta/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTidbClient_UpdateObjectSealedKey(t *testing.T) {
+	client, mock, err := newClient()
+	if err != nil {
+		t.Fatal("Error creating mock client:", err)
+	}
+	defer client.Client.Close()
+
+	old := SealedKey{BucketName: "b", ObjectName: "o", Version: 1, KeyVersion: 1, EncryptionKey: []byte("old")}
+	key := old
+	key.KeyVersion, key.EncryptionKey = 2, []byte("new")
+
+	mock.ExpectExec("update objects set encryptionkey=\\?,keyversion=\\? where .* "+
+		"and encryptionkey=\\? and COALESCE\\(keyversion,0\\)=\\?").
+		WithArgs([]byte("new"), 2, "b", "o", uint64(1), []byte("old"), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	updated, err := client.UpdateObjectSealedKey(old, key)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	// the key is rewritten since scanned
+	mock.ExpectExec("update objects set encryptionkey=\\?,keyversion=\\? where").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	updated, err = client.UpdateObjectSealedKey(old, key)
+	assert.Nil(t, err)
+	assert.False(t, updated)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTidbClient_UpdateMultipartSealedKey(t *testing.T) {
+	client, mock, err := newClient()
+	if err != nil {
+		t.Fatal("Error creating mock client:", err)
+	}
+	defer client.Client.Close()
+
+	old := SealedKey{BucketName: "b", ObjectName: "o", Version: 1, KeyVersion: 0,
+		EncryptionKey: []byte("old"), CipherKey: []byte("oldcipher")}
+	key := old
+	key.KeyVersion, key.EncryptionKey, key.CipherKey = 2, []byte("new"), []byte("newcipher")
+
+	mock.ExpectExec("update multiparts set encryption=\\?,cipher=\\?,keyversion=\\? where .* "+
+		"and encryption=\\? and COALESCE\\(keyversion,0\\)=\\?").
+		WithArgs([]byte("new"), []byte("newcipher"), 2, "b", "o", uint64(1), []byte("old"), 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	updated, err := client.UpdateMultipartSealedKey(old, key)
+	assert.Nil(t, err)
+	assert.False(t, updated)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
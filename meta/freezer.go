@@ -2,6 +2,8 @@ package meta
 
 import (
 	"database/sql"
+	"time"
+
 	"github.com/journeymidnight/yig/meta/types"
 )
 
@@ -48,3 +50,40 @@ func (m *Meta) DeleteFreezer(freezer *types.Freezer) (err error) {
 
 	return err
 }
+
+func (m *Meta) ScanFreezers(limit int, restoringBefore time.Time) ([]types.Freezer, error) {
+	return m.Client.ScanFreezers(limit, restoringBefore)
+}
+
+func (m *Meta) ScanExpiredFreezers(limit int) ([]types.Freezer, error) {
+	return m.Client.ScanExpiredFreezers(limit)
+}
+
+// UpdateFreezerStatus returns false if status of freezer has been changed by others
+func (m *Meta) UpdateFreezerStatus(freezer *types.Freezer, status types.Status, modified time.Time) (bool, error) {
+	return m.Client.UpdateFreezer(freezer, status, modified, nil)
+}
+
+// FinishFreezer saves the restored copy and marks freezer FINISH, returns false
+// if status of freezer has been changed by others, e.g. the freezer is deleted
+func (m *Meta) FinishFreezer(freezer *types.Freezer, modified time.Time) (updated bool, err error) {
+	var tx *sql.Tx
+	tx, err = m.Client.NewTrans()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err == nil && updated {
+			err = m.Client.CommitTrans(tx)
+		}
+		if err != nil || !updated {
+			m.Client.AbortTrans(tx)
+		}
+	}()
+
+	err = m.Client.PutFreezerParts(freezer, tx)
+	if err != nil {
+		return false, err
+	}
+	return m.Client.UpdateFreezer(freezer, types.ObjectHasRestored, modified, tx)
+}
@@ -0,0 +1,23 @@
+package meta
+
+import (
+	"time"
+
+	. "github.com/journeymidnight/yig/meta/types"
+)
+
+func (m *Meta) ScanInventories(marker Inventory, limit int) ([]Inventory, error) {
+	return m.Client.ScanInventories(marker, limit)
+}
+
+func (m *Meta) AcquireInventory(bucketName, id, owner string, leaseExpire, exportedBefore time.Time) (bool, error) {
+	return m.Client.AcquireInventory(bucketName, id, owner, leaseExpire, exportedBefore)
+}
+
+func (m *Meta) RenewInventory(bucketName, id, owner string, leaseExpire time.Time) (bool, error) {
+	return m.Client.RenewInventory(bucketName, id, owner, leaseExpire)
+}
+
+func (m *Meta) ReleaseInventory(bucketName, id, owner string, exported bool) error {
+	return m.Client.ReleaseInventory(bucketName, id, owner, exported)
+}
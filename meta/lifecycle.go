@@ -1,6 +1,10 @@
 package meta
 
-import . "github.com/journeymidnight/yig/meta/types"
+import (
+	"time"
+
+	. "github.com/journeymidnight/yig/meta/types"
+)
 
 func LifeCycleFromBucket(b Bucket) (lc LifeCycle) {
 	lc.BucketName = b.Name
@@ -20,3 +24,23 @@ func (m *Meta) RemoveBucketFromLifeCycle(bucket Bucket) error {
 func (m *Meta) ScanLifeCycle(limit int, marker string) (result ScanLifeCycleResult, err error) {
 	return m.Client.ScanLifeCycle(limit, marker)
 }
+
+func (m *Meta) GetLifeCycle(bucketName string) (LifeCycle, error) {
+	return m.Client.GetLifeCycle(bucketName)
+}
+
+func (m *Meta) AcquireLifeCycle(bucketName, owner string, leaseExpire, finishedBefore time.Time) (bool, error) {
+	return m.Client.AcquireLifeCycle(bucketName, owner, leaseExpire, finishedBefore)
+}
+
+func (m *Meta) RenewLifeCycle(bucketName, owner string, leaseExpire time.Time) (bool, error) {
+	return m.Client.RenewLifeCycle(bucketName, owner, leaseExpire)
+}
+
+func (m *Meta) UpdateLifeCycleMarker(bucketName, owner, marker string) error {
+	return m.Client.UpdateLifeCycleMarker(bucketName, owner, marker)
+}
+
+func (m *Meta) ReleaseLifeCycle(bucketName, owner string, finished bool) error {
+	return m.Client.ReleaseLifeCycle(bucketName, owner, finished)
+}
@@ -2,6 +2,8 @@ package meta
 
 import (
 	"database/sql"
+	"time"
+
 	. "github.com/journeymidnight/yig/meta/types"
 )
 
@@ -27,7 +29,7 @@ func (m *Meta) DeleteMultipart(multipart Multipart) (err error) {
 	for _, p := range multipart.Parts {
 		removedSize += p.Size
 	}
-	err = m.Client.UpdateUsage(multipart.BucketName, -removedSize, tx)
+	err = m.Client.UpdateUsage(multipart.BucketName, -removedSize, 0, tx)
 	if err != nil {
 		return
 	}
@@ -53,7 +55,7 @@ func (m *Meta) PutObjectPart(multipart Multipart, part Part) (err error) {
 	if part, ok := multipart.Parts[part.PartNumber]; ok {
 		removedSize += part.Size
 	}
-	err = m.Client.UpdateUsage(multipart.BucketName, part.Size-removedSize, tx)
+	err = m.Client.UpdateUsage(multipart.BucketName, part.Size-removedSize, 0, tx)
 	if err != nil {
 		return
 	}
@@ -83,3 +85,8 @@ func (m *Meta) RenameObjectPart(object *Object, sourceObject string) (err error)
 	err = m.Client.CommitTrans(tx)
 	return err
 }
+
+func (m *Meta) ScanStaleMultiparts(bucketName, prefix string, initiatedBefore time.Time,
+	marker Multipart, limit int) (multiparts []Multipart, err error) {
+	return m.Client.ScanStaleMultiparts(bucketName, prefix, initiatedBefore, marker, limit)
+}
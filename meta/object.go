@@ -2,6 +2,9 @@ package meta
 
 import (
 	"database/sql"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	. "github.com/journeymidnight/yig/meta/types"
@@ -82,9 +85,20 @@ func (m *Meta) GetObjectVersion(bucketName, objectName, version string, willNeed
 }
 
 func (m *Meta) PutObject(object *Object, multipart *Multipart, objMap *ObjMap, updateUsage bool) error {
+	_, err := m.PutObjectIf(object, multipart, objMap, updateUsage, datatype.WriteCondition{})
+	return err
+}
+
+// PutObjectIf puts object only if condition holds for the latest version of object,
+// which is checked in the same transaction, returns ErrPreconditionFailed otherwise.
+// Null versions replaced by a conditional write of null version are removed in the
+// transaction as well, so they are kept if the condition fails, and returned.
+func (m *Meta) PutObjectIf(object *Object, multipart *Multipart, objMap *ObjMap, updateUsage bool,
+	condition datatype.WriteCondition) (replaced []*Object, err error) {
+
 	tx, err := m.Client.NewTrans()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -92,32 +106,72 @@ func (m *Meta) PutObject(object *Object, multipart *Multipart, objMap *ObjMap, u
 		}
 	}()
 
+	if condition.IsSet() {
+		var current *Object
+		current, err = m.Client.GetLatestObjectForUpdate(object.BucketName, object.Name, tx)
+		if err != nil && err != ErrNoSuchKey {
+			return nil, err
+		}
+		exists, etag := false, ""
+		if err == nil && !current.DeleteMarker {
+			exists, etag = true, current.Etag
+		}
+		err = nil
+		if !condition.Satisfied(exists, etag) {
+			err = ErrPreconditionFailed
+			return nil, err
+		}
+		if object.NullVersion {
+			replaced, err = m.removeReplacedObjects(object, tx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	err = m.Client.PutObject(object, tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if objMap != nil {
 		err = m.Client.PutObjectMap(objMap, tx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if multipart != nil {
 		err = m.Client.DeleteMultipart(multipart, tx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if updateUsage {
-		err = m.Client.UpdateUsage(object.BucketName, object.Size, tx)
+		err = m.Client.UpdateUsage(object.BucketName, object.Size, 1, tx)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	} else if multipart != nil {
+		// size of parts is counted when uploaded
+		err = m.Client.UpdateUsage(object.BucketName, 0, 1, tx)
+		if err != nil {
+			return nil, err
 		}
 	}
-	return m.Client.CommitTrans(tx)
+
+	if object.ReplicationStatus == ReplicationStatusPending {
+		err = m.Client.PutObjectToReplication(object, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = m.Client.CommitTrans(tx)
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
 }
 
 func (m *Meta) PutObjectEntry(object *Object) error {
@@ -130,6 +184,21 @@ func (m *Meta) UpdateObjectAcl(object *Object) error {
 	return err
 }
 
+func (m *Meta) UpdateObjectTags(object *Object) error {
+	err := m.Client.UpdateObjectTags(object)
+	return err
+}
+
+func (m *Meta) UpdateObjectReplicationStatus(object *Object) error {
+	err := m.Client.UpdateObjectReplicationStatus(object)
+	return err
+}
+
+func (m *Meta) UpdateObjectLock(object *Object) error {
+	err := m.Client.UpdateObjectLock(object)
+	return err
+}
+
 func (m *Meta) UpdateObjectAttrs(object *Object) error {
 	err := m.Client.UpdateObjectAttrs(object)
 	return err
@@ -150,6 +219,36 @@ func (m *Meta) PutObjMapEntry(objMap *ObjMap) error {
 	return err
 }
 
+// removeReplacedObjects removes null versions replaced by object in tx, the same as DeleteObject
+func (m *Meta) removeReplacedObjects(object *Object, tx DB) (replaced []*Object, err error) {
+	replaced, err = m.Client.GetReplacedObjectsForUpdate(object, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range replaced {
+		lock := obj.ObjectLock
+		if lock.IsLegalHoldOn() || lock.IsRetained(time.Now()) {
+			return nil, ErrObjectLocked
+		}
+		err = m.Client.DeleteObject(obj, tx)
+		if err != nil {
+			return nil, err
+		}
+		if obj.DeleteMarker {
+			continue
+		}
+		err = m.Client.PutObjectToGarbageCollection(obj, tx)
+		if err != nil {
+			return nil, err
+		}
+		err = m.Client.UpdateUsage(obj.BucketName, -obj.Size, -1, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return replaced, nil
+}
+
 func (m *Meta) DeleteObject(object *Object, DeleteMarker bool, objMap *ObjMap) (err error) {
 	var tx *sql.Tx
 	tx, err = m.Client.NewTrans()
@@ -186,7 +285,7 @@ func (m *Meta) DeleteObject(object *Object, DeleteMarker bool, objMap *ObjMap) (
 		return err
 	}
 
-	return m.Client.UpdateUsage(object.BucketName, -object.Size, tx)
+	return m.Client.UpdateUsage(object.BucketName, -object.Size, -1, tx)
 }
 
 func (m *Meta) UpdateGlacierObject(targetObject, sourceObject *Object, isFreezer bool) (err error) {
@@ -229,7 +328,36 @@ func (m *Meta) UpdateGlacierObject(targetObject, sourceObject *Object, isFreezer
 	return err
 }
 
-func (m *Meta) AppendObject(object *Object, isExist bool) error {
+// TransitObject updates object with data moved to another pool, and sends old data to gc.
+// It returns false if the object is overwritten or transited by others, nothing is changed then.
+func (m *Meta) TransitObject(object, oldObject *Object) (transited bool, err error) {
+	var tx *sql.Tx
+	tx, err = m.Client.NewTrans()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err == nil && transited {
+			err = m.Client.CommitTrans(tx)
+		}
+		if err != nil || !transited {
+			m.Client.AbortTrans(tx)
+		}
+	}()
+
+	transited, err = m.Client.TransitObject(object, oldObject, tx)
+	if err != nil || !transited {
+		return false, err
+	}
+	err = m.Client.PutObjectToGarbageCollection(oldObject, tx)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AppendObject creates or updates the appendable object, appendedSize is added to usage
+func (m *Meta) AppendObject(object *Object, isExist bool, appendedSize int64) error {
 	tx, err := m.Client.NewTrans()
 	if err != nil {
 		return err
@@ -247,7 +375,11 @@ func (m *Meta) AppendObject(object *Object, isExist bool) error {
 	if err != nil {
 		return err
 	}
-	err = m.Client.UpdateUsage(object.BucketName, object.Size, tx)
+	var objects int64
+	if !isExist {
+		objects = 1
+	}
+	err = m.Client.UpdateUsage(object.BucketName, appendedSize, objects, tx)
 	if err != nil {
 		return err
 	}
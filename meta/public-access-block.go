@@ -0,0 +1,63 @@
+package meta
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	. "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+// GetPublicAccessBlock returns account level Block Public Access settings of user,
+// which are the default of all buckets owned by the user
+func (m *Meta) GetPublicAccessBlock(uid string) (config datatype.PublicAccessBlockConfiguration, err error) {
+	getConfig := func() (c interface{}, err error) {
+		c, err = m.Client.GetPublicAccessBlock(uid)
+		helper.Logger.Info("GetPublicAccessBlock CacheMiss. uid:", uid)
+		return c, err
+	}
+	unmarshaller := func(in []byte) (interface{}, error) {
+		var config datatype.PublicAccessBlockConfiguration
+		err := helper.MsgPackUnMarshal(in, &config)
+		return config, err
+	}
+	c, err := m.Cache.Get(redis.PublicAccessBlockTable, uid, getConfig, unmarshaller, true)
+	if err != nil {
+		return
+	}
+	config, ok := c.(datatype.PublicAccessBlockConfiguration)
+	if !ok {
+		helper.Logger.Info("Cast c failed:", c)
+		err = ErrInternalError
+		return
+	}
+	return config, nil
+}
+
+func (m *Meta) PutPublicAccessBlock(uid string, config datatype.PublicAccessBlockConfiguration) error {
+	err := m.Client.PutPublicAccessBlock(uid, config)
+	if err != nil {
+		return err
+	}
+	m.Cache.Remove(redis.PublicAccessBlockTable, uid)
+	return nil
+}
+
+func (m *Meta) DeletePublicAccessBlock(uid string) error {
+	err := m.Client.DeletePublicAccessBlock(uid)
+	if err != nil {
+		return err
+	}
+	m.Cache.Remove(redis.PublicAccessBlockTable, uid)
+	return nil
+}
+
+// GetEffectivePublicAccessBlock returns Block Public Access settings enforced on bucket,
+// combined from settings of the bucket and its owner
+func (m *Meta) GetEffectivePublicAccessBlock(bucket *Bucket) (config datatype.PublicAccessBlockConfiguration, err error) {
+	accountConfig, err := m.GetPublicAccessBlock(bucket.OwnerId)
+	if err != nil {
+		return
+	}
+	return bucket.PublicAccessBlock.Merge(accountConfig), nil
+}
@@ -0,0 +1,123 @@
+package meta
+
+import (
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	. "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+// usages are cached shortly, so deletions and changes not charged
+// by ChargeQuota are reconciled with database soon
+const quotaUsageExpire = 60 // seconds
+
+func quotaUsageKey(kind, name string) string {
+	return "quota_usage_" + kind + "_" + name
+}
+
+func (m *Meta) GetQuota(kind, name string) (quota Quota, err error) {
+	getQuota := func() (q interface{}, err error) {
+		q, err = m.Client.GetQuota(kind, name)
+		helper.Logger.Info("GetQuota CacheMiss. quota:", kind, name)
+		return q, err
+	}
+	unmarshaller := func(in []byte) (interface{}, error) {
+		var quota Quota
+		err := helper.MsgPackUnMarshal(in, &quota)
+		return quota, err
+	}
+	q, err := m.Cache.Get(redis.QuotaTable, kind+"_"+name, getQuota, unmarshaller, true)
+	if err != nil {
+		return
+	}
+	quota, ok := q.(Quota)
+	if !ok {
+		helper.Logger.Info("Cast q failed:", q)
+		err = ErrInternalError
+		return
+	}
+	return quota, nil
+}
+
+func (m *Meta) PutQuota(quota Quota) error {
+	err := m.Client.PutQuota(quota)
+	if err != nil {
+		return err
+	}
+	m.Cache.Remove(redis.QuotaTable, quota.Kind+"_"+quota.Name)
+	return nil
+}
+
+func (m *Meta) DeleteQuota(kind, name string) error {
+	err := m.Client.DeleteQuota(kind, name)
+	if err != nil {
+		return err
+	}
+	m.Cache.Remove(redis.QuotaTable, kind+"_"+name)
+	return nil
+}
+
+// GetQuotaUsage returns usage of bucket or user, from redis if cached
+func (m *Meta) GetQuotaUsage(kind, name string) (usage QuotaUsage, err error) {
+	key := quotaUsageKey(kind, name)
+	if redis.Pool() != nil {
+		counters, err := redis.GetCounters(key)
+		if err == nil && counters != nil {
+			return QuotaUsage{Bytes: counters["bytes"], Objects: counters["objects"]}, nil
+		}
+	}
+	if kind == QuotaKindBucket {
+		usage, err = m.Client.GetBucketQuotaUsage(name)
+	} else {
+		usage, err = m.Client.GetUserQuotaUsage(name)
+	}
+	if err != nil {
+		return
+	}
+	if redis.Pool() != nil {
+		redis.SetCounters(key, map[string]int64{
+			"bytes":   usage.Bytes,
+			"objects": usage.Objects,
+		}, quotaUsageExpire)
+	}
+	return usage, nil
+}
+
+// CheckQuota returns ErrQuotaExceeded if adding bytes and objects to bucket
+// exceeds quota of the bucket or its owner
+func (m *Meta) CheckQuota(bucket *Bucket, bytes, objects int64) error {
+	for _, q := range []struct{ kind, name string }{
+		{QuotaKindBucket, bucket.Name},
+		{QuotaKindUser, bucket.OwnerId},
+	} {
+		quota, err := m.GetQuota(q.kind, q.name)
+		if err != nil {
+			return err
+		}
+		if quota.Unlimited() {
+			continue
+		}
+		usage, err := m.GetQuotaUsage(q.kind, q.name)
+		if err != nil {
+			return err
+		}
+		if quota.Exceeded(usage, bytes, objects) {
+			return ErrQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// ChargeQuota adds bytes and objects to cached usages of bucket and its owner,
+// usages in database are updated with objects
+func (m *Meta) ChargeQuota(bucket *Bucket, bytes, objects int64) {
+	if redis.Pool() == nil {
+		return
+	}
+	deltas := map[string]int64{
+		"bytes":   bytes,
+		"objects": objects,
+	}
+	redis.IncrCounters(quotaUsageKey(QuotaKindBucket, bucket.Name), deltas)
+	redis.IncrCounters(quotaUsageKey(QuotaKindUser, bucket.OwnerId), deltas)
+}
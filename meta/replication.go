@@ -0,0 +1,15 @@
+package meta
+
+import . "github.com/journeymidnight/yig/meta/types"
+
+func (m *Meta) ScanReplication(limit int) ([]Replication, error) {
+	return m.Client.ScanReplication(limit)
+}
+
+func (m *Meta) UpdateReplication(replication Replication) error {
+	return m.Client.UpdateReplication(replication)
+}
+
+func (m *Meta) RemoveReplication(replication Replication) error {
+	return m.Client.RemoveReplication(replication)
+}
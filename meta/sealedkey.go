@@ -0,0 +1,19 @@
+package meta
+
+import . "github.com/journeymidnight/yig/meta/types"
+
+func (m *Meta) ScanObjectSealedKeys(keyVersion int, marker SealedKey, limit int) ([]SealedKey, error) {
+	return m.Client.ScanObjectSealedKeys(keyVersion, marker, limit)
+}
+
+func (m *Meta) UpdateObjectSealedKey(old, key SealedKey) (bool, error) {
+	return m.Client.UpdateObjectSealedKey(old, key)
+}
+
+func (m *Meta) ScanMultipartSealedKeys(keyVersion int, marker SealedKey, limit int) ([]SealedKey, error) {
+	return m.Client.ScanMultipartSealedKeys(keyVersion, marker, limit)
+}
+
+func (m *Meta) UpdateMultipartSealedKey(old, key SealedKey) (bool, error) {
+	return m.Client.UpdateMultipartSealedKey(old, key)
+}
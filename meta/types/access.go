@@ -0,0 +1,118 @@
+package types
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/iam/common"
+)
+
+// Access is the decision of ownership and ACL checks and its reason,
+// the checks are shared by storage and the access simulator
+type Access struct {
+	Allowed bool
+	Reason  string
+}
+
+// publicAclAccess returns the access granted by public ACL acl of a bucket or an object,
+// which is nothing if public ACLs are ignored by Block Public Access settings
+func publicAclAccess(credential common.Credential, kind, acl string) Access {
+	if credential.IgnorePublicAcls {
+		return Access{Reason: "public " + kind + " ACL " + acl + " is ignored by IgnorePublicAcls"}
+	}
+	return Access{Allowed: true, Reason: "allowed by " + kind + " ACL " + acl}
+}
+
+// ObjectOwner returns the owner of object, which is the bucket owner
+// if ACLs are disabled, whoever uploaded it
+func (b *Bucket) ObjectOwner(object *Object) string {
+	if b.OwnershipControls.AclsDisabled() {
+		return b.OwnerId
+	}
+	return object.OwnerId
+}
+
+// ObjectReadAccess evaluates if object ownership and ACL grant reading object to
+// credential, which is checked unless bucket policy permits other users.
+// Object ACLs are ignored if ACLs are disabled.
+func (b *Bucket) ObjectReadAccess(credential common.Credential, object *Object) Access {
+	if b.OwnershipControls.AclsDisabled() {
+		if b.OwnerId == credential.UserId {
+			return Access{Allowed: true, Reason: "requester owns the bucket and its objects, " +
+				"as ACLs are disabled by " + datatype.ObjectOwnershipBucketOwnerEnforced + " Object Ownership"}
+		}
+		return Access{Reason: "ACLs are disabled by " +
+			datatype.ObjectOwnershipBucketOwnerEnforced + " Object Ownership"}
+	}
+	acl := object.ACL.CannedAcl
+	switch acl {
+	case "public-read", "public-read-write":
+		return publicAclAccess(credential, "object", acl)
+	case "authenticated-read":
+		if credential.UserId != "" {
+			return publicAclAccess(credential, "object", acl)
+		}
+	case "bucket-owner-read", "bucket-owner-full-control":
+		if b.OwnerId == credential.UserId {
+			return Access{Allowed: true, Reason: "allowed by object ACL " + acl}
+		}
+	default:
+		if object.OwnerId == credential.UserId {
+			return Access{Allowed: true, Reason: "requester owns the object"}
+		}
+	}
+	return Access{Reason: "not allowed by object ACL " + acl}
+}
+
+// ObjectSubresourceAccess evaluates if credential could access tags, retention
+// or legal hold of object as the bucket owner or the object owner, which is
+// checked unless bucket policy permits other users
+func (b *Bucket) ObjectSubresourceAccess(credential common.Credential, object *Object) Access {
+	if b.OwnerId == credential.UserId {
+		return Access{Allowed: true, Reason: "requester owns the bucket"}
+	}
+	if b.ObjectOwner(object) == credential.UserId {
+		return Access{Allowed: true, Reason: "requester owns the object"}
+	}
+	return Access{Reason: "requester owns neither the bucket nor the object"}
+}
+
+// ReadAccess evaluates if bucket ownership and ACL grant listing the bucket to
+// credential, which is checked unless bucket policy permits other users
+func (b *Bucket) ReadAccess(credential common.Credential) Access {
+	if b.OwnerId == credential.UserId {
+		return Access{Allowed: true, Reason: "requester owns the bucket"}
+	}
+	acl := b.ACL.CannedAcl
+	switch acl {
+	case "public-read", "public-read-write":
+		return publicAclAccess(credential, "bucket", acl)
+	case "authenticated-read":
+		if credential.UserId != "" {
+			return publicAclAccess(credential, "bucket", acl)
+		}
+	}
+	return Access{Reason: "not allowed by bucket ACL " + acl}
+}
+
+// WriteAccess evaluates if bucket ownership and ACL grant writing objects
+// of the bucket to credential, bucket policy doesn't grant writes yet
+func (b *Bucket) WriteAccess(credential common.Credential) Access {
+	if b.OwnerId == credential.UserId {
+		return Access{Allowed: true, Reason: "requester owns the bucket"}
+	}
+	acl := b.ACL.CannedAcl
+	if acl == "public-read-write" {
+		return publicAclAccess(credential, "bucket", acl)
+	}
+	return Access{Reason: "not allowed by bucket ACL " + acl}
+}
+
+// DeleteAccess evaluates if bucket ownership and ACL grant deleting objects of
+// the bucket to credential, which is checked unless bucket policy permits other
+// users. Anonymous deletes are not checked by ACL, as they always were, unless
+// public ACLs are ignored.
+func (b *Bucket) DeleteAccess(credential common.Credential) Access {
+	if credential.UserId == "" && !credential.IgnorePublicAcls {
+		return Access{Allowed: true, Reason: "anonymous deletes are not checked by bucket ACL"}
+	}
+	return b.WriteAccess(credential)
+}
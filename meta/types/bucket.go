@@ -28,6 +28,12 @@ type Bucket struct {
 	Policy     policy.Policy
 	Website    datatype.WebsiteConfiguration
 	Encryption datatype.EncryptionConfiguration
+	Tags       map[string]string
+	Notification datatype.NotificationConfiguration
+	Replication  datatype.ReplicationConfiguration
+	ObjectLock   datatype.ObjectLockConfiguration
+	PublicAccessBlock datatype.PublicAccessBlockConfiguration
+	OwnershipControls datatype.OwnershipControls
 	Versioning string // actually enum: Disabled/Enabled/Suspended
 	Usage      int64
 }
@@ -43,11 +49,52 @@ func (b *Bucket) String() (s string) {
 	s += "Policy: " + fmt.Sprintf("%+v", b.Policy) + "\t"
 	s += "Website: " + fmt.Sprintf("%+v", b.Website) + "\t"
 	s += "Encryption" + fmt.Sprintf("%+v", b.Encryption) + "\t"
+	s += "Tags: " + fmt.Sprintf("%+v", b.Tags) + "\t"
+	s += "Notification: " + fmt.Sprintf("%+v", b.Notification) + "\t"
+	s += "Replication: " + fmt.Sprintf("%+v", b.Replication) + "\t"
+	s += "ObjectLock: " + fmt.Sprintf("%+v", b.ObjectLock) + "\t"
+	s += "PublicAccessBlock: " + fmt.Sprintf("%+v", b.PublicAccessBlock) + "\t"
+	s += "OwnershipControls: " + fmt.Sprintf("%+v", b.OwnershipControls) + "\t"
 	s += "Version: " + b.Versioning + "\t"
 	s += "Usage: " + humanize.Bytes(uint64(b.Usage)) + "\t"
 	return
 }
 
+// AllowsLogDelivery returns if access logs of buckets owned by sourceOwner could be
+// delivered as objectName of the bucket, i.e. the bucket is owned by sourceOwner too,
+// or the log delivery service is granted s3:PutObject by bucket policy or by ACL
+// log-delivery-write or public-read-write, with Block Public Access settings config.
+func (b *Bucket) AllowsLogDelivery(sourceOwner, objectName string,
+	config datatype.PublicAccessBlockConfiguration) bool {
+
+	if b.OwnerId == sourceOwner {
+		return true
+	}
+	policyResult := b.Policy.IsAllowed(policy.Args{
+		AccountName:     policy.LogDeliveryService,
+		Action:          policy.PutObjectAction,
+		BucketName:      b.Name,
+		ConditionValues: map[string][]string{},
+		ObjectName:      objectName,
+	})
+	if policyResult == policy.PolicyDeny {
+		return false
+	}
+	if policyResult == policy.PolicyAllow && !(config.RestrictPublicBuckets && b.Policy.IsPublic()) {
+		return true
+	}
+	if b.OwnershipControls.AclsDisabled() {
+		return false
+	}
+	switch b.ACL.CannedAcl {
+	case "log-delivery-write":
+		return true
+	case "public-read-write":
+		return !config.IgnorePublicAcls
+	}
+	return false
+}
+
 //Tidb related function
 func (b Bucket) GetUpdateSql() (string, []interface{}) {
 	acl, _ := json.Marshal(b.ACL)
@@ -57,8 +104,14 @@ func (b Bucket) GetUpdateSql() (string, []interface{}) {
 	bucket_policy, _ := json.Marshal(b.Policy)
 	website, _ := json.Marshal(b.Website)
 	encryption,_ := json.Marshal(b.Encryption)
-	sql := "update buckets set bucketname=?,acl=?,policy=?,cors=?,logging=?,lc=?,website=?,encryption=?,uid=?,versioning=? where bucketname=?"
-	args := []interface{}{b.Name, acl, bucket_policy, cors, logging, lc, website, encryption, b.OwnerId, b.Versioning, b.Name}
+	tags, _ := json.Marshal(b.Tags)
+	notification, _ := json.Marshal(b.Notification)
+	replication, _ := json.Marshal(b.Replication)
+	objectLock, _ := json.Marshal(b.ObjectLock)
+	publicAccessBlock, _ := json.Marshal(b.PublicAccessBlock)
+	ownershipControls, _ := json.Marshal(b.OwnershipControls)
+	sql := "update buckets set bucketname=?,acl=?,policy=?,cors=?,logging=?,lc=?,website=?,encryption=?,tags=?,notification=?,replication=?,objectlock=?,publicaccessblock=?,ownershipcontrols=?,uid=?,versioning=? where bucketname=?"
+	args := []interface{}{b.Name, acl, bucket_policy, cors, logging, lc, website, encryption, tags, notification, replication, objectLock, publicAccessBlock, ownershipControls, b.OwnerId, b.Versioning, b.Name}
 	return sql, args
 }
 
@@ -70,9 +123,15 @@ func (b Bucket) GetCreateSql() (string, []interface{}) {
 	bucket_policy, _ := json.Marshal(b.Policy)
 	website, _ := json.Marshal(b.Website)
 	encryption,_ := json.Marshal(b.Encryption)
+	tags, _ := json.Marshal(b.Tags)
+	notification, _ := json.Marshal(b.Notification)
+	replication, _ := json.Marshal(b.Replication)
+	objectLock, _ := json.Marshal(b.ObjectLock)
+	publicAccessBlock, _ := json.Marshal(b.PublicAccessBlock)
+	ownershipControls, _ := json.Marshal(b.OwnershipControls)
 	createTime := b.CreateTime.Format(TIME_LAYOUT_TIDB)
-	sql := "insert into buckets(bucketname,acl,cors,logging,lc,uid,policy,website,encryption,createtime,usages,versioning) " +
-		"values(?,?,?,?,?,?,?,?,?,?,?,?);"
-	args := []interface{}{b.Name, acl, cors, logging, lc, b.OwnerId, bucket_policy, website, encryption, createTime, b.Usage, b.Versioning}
+	sql := "insert into buckets(bucketname,acl,cors,logging,lc,uid,policy,website,encryption,tags,notification,replication,objectlock,publicaccessblock,ownershipcontrols,createtime,usages,versioning) " +
+		"values(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);"
+	args := []interface{}{b.Name, acl, cors, logging, lc, b.OwnerId, bucket_policy, website, encryption, tags, notification, replication, objectLock, publicAccessBlock, ownershipControls, createTime, b.Usage, b.Versioning}
 	return sql, args
 }
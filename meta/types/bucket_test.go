@@ -0,0 +1,69 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+)
+
+func TestBucketAllowsLogDelivery(t *testing.T) {
+	parsePolicy := func(effect, principal string) policy.Policy {
+		p, err := policy.ParseConfig(strings.NewReader(`{"Version":"2012-10-17","Statement":[{`+
+			`"Effect":"`+effect+`","Principal":`+principal+`,"Action":["s3:PutObject"],`+
+			`"Resource":["arn:aws:s3:::logs/access/*"]}]}`), "logs")
+		if err != nil {
+			t.Fatal("ParseConfig failed:", err)
+		}
+		return *p
+	}
+	service := `{"Service":"` + policy.LogDeliveryService + `"}`
+	enforced := datatype.OwnershipControls{Rules: []datatype.OwnershipControlsRule{
+		{ObjectOwnership: datatype.ObjectOwnershipBucketOwnerEnforced}}}
+	ignorePublic := datatype.PublicAccessBlockConfiguration{IgnorePublicAcls: true, RestrictPublicBuckets: true}
+
+	var testcase = [...]struct {
+		description string
+		bucket      Bucket
+		objectName  string
+		config      datatype.PublicAccessBlockConfiguration
+		allowed     bool
+	}{
+		{"same owner", Bucket{OwnerId: "source"}, "access/1", datatype.PublicAccessBlockConfiguration{}, true},
+		{"private", Bucket{OwnerId: "target", ACL: datatype.Acl{CannedAcl: "private"}},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, false},
+		{"log-delivery-write", Bucket{OwnerId: "target", ACL: datatype.Acl{CannedAcl: "log-delivery-write"}},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, true},
+		{"log-delivery-write with ACLs disabled", Bucket{OwnerId: "target", OwnershipControls: enforced,
+			ACL: datatype.Acl{CannedAcl: "log-delivery-write"}},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, false},
+		{"public-read-write", Bucket{OwnerId: "target", ACL: datatype.Acl{CannedAcl: "public-read-write"}},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, true},
+		{"public-read-write ignored", Bucket{OwnerId: "target", ACL: datatype.Acl{CannedAcl: "public-read-write"}},
+			"access/1", ignorePublic, false},
+		{"policy", Bucket{Name: "logs", OwnerId: "target", Policy: parsePolicy("Allow", service)},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, true},
+		{"policy of other prefix", Bucket{Name: "logs", OwnerId: "target", Policy: parsePolicy("Allow", service)},
+			"other/1", datatype.PublicAccessBlockConfiguration{}, false},
+		{"policy with ACLs disabled", Bucket{Name: "logs", OwnerId: "target", OwnershipControls: enforced,
+			Policy: parsePolicy("Allow", service)}, "access/1", ignorePublic, true},
+		{"policy of other users", Bucket{Name: "logs", OwnerId: "target",
+			Policy: parsePolicy("Allow", `{"AWS":["someone"]}`)},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, false},
+		{"public policy", Bucket{Name: "logs", OwnerId: "target", Policy: parsePolicy("Allow", `"*"`)},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, true},
+		{"public policy restricted", Bucket{Name: "logs", OwnerId: "target", Policy: parsePolicy("Allow", `"*"`)},
+			"access/1", ignorePublic, false},
+		{"denied by policy", Bucket{Name: "logs", OwnerId: "target", Policy: parsePolicy("Deny", service),
+			ACL: datatype.Acl{CannedAcl: "log-delivery-write"}},
+			"access/1", datatype.PublicAccessBlockConfiguration{}, false},
+	}
+
+	for _, v := range testcase {
+		ret := v.bucket.AllowsLogDelivery("source", v.objectName, v.config)
+		if ret != v.allowed {
+			t.Errorf("AllowsLogDelivery of %s failed, expected %v, got %v\n", v.description, v.allowed, ret)
+		}
+	}
+}
@@ -11,6 +11,5 @@ const (
 )
 
 var (
-	XXTEA_KEY         = []byte("hehehehe")
-	SSE_S3_MASTER_KEY = []byte("hehehehehehehehehehehehehehehehe") // 32 bytes to select AES-256
+	XXTEA_KEY = []byte("hehehehe")
 )
@@ -1,6 +1,39 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+// RestoreTier is the priority of restore requests, restore requests
+// of lower value are processed earlier
+type RestoreTier uint8
+
+const (
+	RestoreTierExpedited RestoreTier = iota
+	RestoreTierStandard
+	RestoreTierBulk
+)
+
+var (
+	RestoreTierStringMap = map[string]RestoreTier{
+		"Expedited": RestoreTierExpedited,
+		"Standard":  RestoreTierStandard,
+		"Bulk":      RestoreTierBulk,
+	}
+)
+
+// MatchRestoreTier returns tier of GlacierJobParameters, "Standard" if not specified
+func MatchRestoreTier(tier string) (RestoreTier, error) {
+	if tier == "" {
+		return RestoreTierStandard, nil
+	}
+	if index, ok := RestoreTierStringMap[tier]; ok {
+		return index, nil
+	}
+	return 0, ErrInvalidRestoreInfo
+}
 
 type Freezer struct {
 	Rowkey           []byte // Rowkey cache
@@ -11,30 +44,45 @@ type Freezer struct {
 	OwnerId          string
 	Size             int64     // file size
 	ObjectId         string    // object name in Ceph
-	LastModifiedTime time.Time // in format "2006-01-02T15:04:05.000Z"
+	LastModifiedTime time.Time // when restore is requested, started or finished, according to Status
 	Etag             string
 	Parts            map[int]*Part
 	PartsIndex       *SimpleIndex
 	VersionId        string // version cache
 	Status           Status
-	LifeTime         int
+	LifeTime         int // days to keep the restored copy after restore finished
+	Tier             RestoreTier
+}
+
+// ExpireTime returns when the restored copy should be removed
+func (o *Freezer) ExpireTime() time.Time {
+	return o.LastModifiedTime.AddDate(0, 0, o.LifeTime)
 }
 
 func (o *Freezer) GetCreateSql() (string, []interface{}) {
 	// TODO Multi-version control
 	lastModifiedTime := o.LastModifiedTime.Format(TIME_LAYOUT_TIDB)
-	sql := "insert into restoreobjects(bucketname,objectname,status,lifetime,lastmodifiedtime) values(?,?,?,?,?)"
-	args := []interface{}{o.BucketName, o.Name, o.Status, o.LifeTime, lastModifiedTime}
+	sql := "insert into restoreobjects(bucketname,objectname,status,lifetime,lastmodifiedtime,tier) values(?,?,?,?,?,?)"
+	args := []interface{}{o.BucketName, o.Name, o.Status, o.LifeTime, lastModifiedTime, o.Tier}
 	return sql, args
 }
 
-func (o *Freezer) GetUpdateSql(status Status) (string, []interface{}) {
+// GetUpdateSql updates freezer only if its status and lastmodifiedtime are not changed by others
+func (o *Freezer) GetUpdateSql(status Status, modified time.Time) (string, []interface{}) {
 	// TODO Multi-version control
 	// version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
-	lastModifiedTime := o.LastModifiedTime.Format(TIME_LAYOUT_TIDB)
+	lastModifiedTime := modified.Format(TIME_LAYOUT_TIDB)
 	sql := "update restoreobjects set status=?,lastmodifiedtime=?,location=?,pool=?," +
-		"ownerid=?,size=?,etag=? where bucketname=? and objectname=? and status=?"
-	args := []interface{}{status, lastModifiedTime, o.Location, o.Pool, o.OwnerId, o.Size, o.Etag, o.BucketName, o.Name, o.Status}
+		"ownerid=?,size=?,objectid=?,etag=? where bucketname=? and objectname=? and status=? and lastmodifiedtime=?"
+	args := []interface{}{status, lastModifiedTime, o.Location, o.Pool, o.OwnerId, o.Size, o.ObjectId, o.Etag,
+		o.BucketName, o.Name, o.Status, o.LastModifiedTime.Format(TIME_LAYOUT_TIDB)}
+
+	return sql, args
+}
 
+func (p *Part) GetCreateFreezerSql(bucketname, objectname string) (string, []interface{}) {
+	sql := "insert into restoreobjectpart(partnumber,size,objectid,offset,etag,lastmodified,initializationvector,bucketname,objectname) " +
+		"values(?,?,?,?,?,?,?,?,?)"
+	args := []interface{}{p.PartNumber, p.Size, p.ObjectId, p.Offset, p.Etag, p.LastModified, p.InitializationVector, bucketname, objectname}
 	return sql, args
 }
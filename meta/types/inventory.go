@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+)
+
+// Inventory is an entry of the `inventory` table, a configuration of
+// inventory reports exported by tools/inventory
+type Inventory struct {
+	BucketName string
+	Id         string
+	Config     datatype.InventoryConfiguration
+	// lease of tools/inventory instance exporting the report
+	Owner       string
+	LeaseExpire time.Time
+	LastExport  time.Time // when the report is exported last time
+}
@@ -1,8 +1,16 @@
 package types
 
+import "time"
+
 type LifeCycle struct {
 	BucketName string
 	Status     string // status of this entry, in Pending/Deleting
+	// lease of tools/lc instance processing the bucket, marker is the
+	// last object processed to resume from
+	Owner        string
+	LeaseExpire  time.Time
+	Marker       string
+	LastFinished time.Time // when lifecycle rules are applied to all objects last time
 }
 
 type ScanLifeCycleResult struct {
@@ -12,3 +20,33 @@ type ScanLifeCycleResult struct {
 	Lcs []LifeCycle
 }
 
+func parseClock(now time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return t, err
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+// LifeCycleWindow returns the daily window from startClock to endClock, in "15:04" format,
+// which now is in, or the next window. The window is the whole day if clocks are invalid.
+func LifeCycleWindow(now time.Time, startClock, endClock string) (start, end time.Time) {
+	start, startErr := parseClock(now, startClock)
+	end, endErr := parseClock(now, endClock)
+	if startErr != nil || endErr != nil {
+		// whole day
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		// e.g. 22:00 to 04:00
+		end = end.AddDate(0, 0, 1)
+	}
+	if now.Before(start) && now.Before(end.AddDate(0, 0, -1)) {
+		start, end = start.AddDate(0, 0, -1), end.AddDate(0, 0, -1)
+	}
+	if !now.Before(end) {
+		start, end = start.AddDate(0, 0, 1), end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
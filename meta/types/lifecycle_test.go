@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifeCycleWindow(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2020, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	var testcase = [...]struct {
+		now        time.Time
+		startClock string
+		endClock   string
+		start      time.Time
+		end        time.Time
+	}{
+		// in the window
+		{at(10, 3, 0), "02:00", "06:00", at(10, 2, 0), at(10, 6, 0)},
+		{at(10, 2, 0), "02:00", "06:00", at(10, 2, 0), at(10, 6, 0)},
+		// before and after the window, the next window is returned
+		{at(10, 1, 0), "02:00", "06:00", at(10, 2, 0), at(10, 6, 0)},
+		{at(10, 6, 0), "02:00", "06:00", at(11, 2, 0), at(11, 6, 0)},
+		// window across midnight
+		{at(10, 23, 0), "22:00", "04:00", at(10, 22, 0), at(11, 4, 0)},
+		{at(10, 1, 30), "22:00", "04:00", at(9, 22, 0), at(10, 4, 0)},
+		{at(10, 12, 0), "22:00", "04:00", at(10, 22, 0), at(11, 4, 0)},
+		{at(10, 4, 0), "22:00", "04:00", at(10, 22, 0), at(11, 4, 0)},
+		// whole day if not configured
+		{at(10, 12, 0), "", "", at(10, 0, 0), at(11, 0, 0)},
+		{at(10, 12, 0), "2:00am", "06:00", at(10, 0, 0), at(11, 0, 0)},
+	}
+
+	for _, v := range testcase {
+		start, end := LifeCycleWindow(v.now, v.startClock, v.endClock)
+		if !start.Equal(v.start) || !end.Equal(v.end) {
+			t.Errorf("LifeCycleWindow at %v from %q to %q failed, expected %v to %v, got %v to %v\n",
+				v.now, v.startClock, v.endClock, v.start, v.end, start, end)
+		}
+	}
+}
@@ -23,6 +23,7 @@ type Part struct {
 	Etag                 string
 	LastModified         string // time string of format "2006-01-02T15:04:05.000Z"
 	InitializationVector []byte
+	Checksum             string // base64 encoded checksum of ChecksumAlgorithm of the upload
 }
 
 type MultipartMetadata struct {
@@ -35,8 +36,14 @@ type MultipartMetadata struct {
 	SseRequest    datatype.SseRequest
 	EncryptionKey []byte
 	CipherKey     []byte
+	KeyVersion    int // master key version sealing EncryptionKey and CipherKey
 	Attrs         map[string]string
+	Tags          map[string]string
+	ObjectLock    datatype.ObjectLock // requested retention and legal hold
 	StorageClass  StorageClass
+	// algorithm of x-amz-checksum-algorithm, parts are checksummed and the
+	// object gets a composite checksum if set
+	ChecksumAlgorithm string
 }
 
 type Multipart struct {
@@ -86,9 +93,9 @@ func valuesForParts(parts map[int]*Part) (values map[string][]byte, err error) {
 }
 
 func (p *Part) GetCreateSql(bucketname, objectname, version string) (string, []interface{}) {
-	sql := "insert into objectpart(partnumber,size,objectid,offset,etag,lastmodified,initializationvector,bucketname,objectname,version) " +
-		"values(?,?,?,?,?,?,?,?,?,?)"
-	args := []interface{}{p.PartNumber, p.Size, p.ObjectId, p.Offset, p.Etag, p.LastModified, p.InitializationVector, bucketname, objectname, version}
+	sql := "insert into objectpart(partnumber,size,objectid,offset,etag,lastmodified,initializationvector,bucketname,objectname,version,checksum) " +
+		"values(?,?,?,?,?,?,?,?,?,?,?)"
+	args := []interface{}{p.PartNumber, p.Size, p.ObjectId, p.Offset, p.Etag, p.LastModified, p.InitializationVector, bucketname, objectname, version, p.Checksum}
 	return sql, args
 }
 
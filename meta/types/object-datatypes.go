@@ -59,6 +59,9 @@ type CompletePart struct {
 
 	// Entity tag returned when the part was uploaded.
 	ETag string
+
+	// Checksum returned when the part was uploaded, optional
+	datatype.ObjectChecksum
 }
 
 // completedParts - is a collection satisfying sort.Interface.
@@ -1,13 +1,9 @@
 package types
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"strconv"
 	"time"
@@ -30,22 +26,32 @@ type Object struct {
 	Etag             string
 	ContentType      string
 	CustomAttributes map[string]string
+	Tags             map[string]string // object tagging, stored per version
 	Parts            map[int]*Part
 	PartsIndex       *SimpleIndex
 	ACL              datatype.Acl
 	NullVersion      bool   // if this entry has `null` version
 	DeleteMarker     bool   // if this entry is a delete marker
 	VersionId        string // version cache
-	// type of Server Side Encryption, could be "SSE-KMS", "SSE-S3", "SSE-C"(custom), or ""(none),
-	// KMS is not implemented yet
+	// type of Server Side Encryption, could be "SSE-KMS", "SSE-S3", "SSE-C"(custom), or ""(none)
 	SseType string
-	// encryption key for SSE-S3, the key itself is encrypted with SSE_S3_MASTER_KEY,
-	// in AES256-GCM
+	// encryption key for SSE-S3 and SSE-KMS, the key itself is sealed by KMS, and then
+	// by master key of KeyVersion in keyring
 	EncryptionKey        []byte
+	KeyVersion           int
 	InitializationVector []byte
+	// KMS key ID and encryption context the SSE-KMS data key is generated with
+	SseKmsKeyId string
+	SseContext  string
 	// ObjectType include `Normal`, `Appendable`, 'Multipart'
 	Type         ObjectType
 	StorageClass StorageClass
+	// replication status, PENDING/COMPLETED/FAILED, or "" if not replicated
+	ReplicationStatus string
+	// retention and legal hold of this version, only for buckets with object lock enabled
+	ObjectLock datatype.ObjectLock
+	// additional checksum of x-amz-checksum-* headers, or composite checksum of parts
+	Checksum datatype.Checksum
 }
 
 type ObjectType int
@@ -96,36 +102,6 @@ func (o *Object) GetVersionNumber() (uint64, error) {
 	return version, nil
 }
 
-func (o *Object) encryptSseKey() (err error) {
-	// Don't encrypt if `EncryptionKey` is not set
-	if len(o.EncryptionKey) == 0 {
-		return
-	}
-
-	if len(o.InitializationVector) == 0 {
-		o.InitializationVector = make([]byte, INITIALIZATION_VECTOR_LENGTH)
-		_, err = io.ReadFull(rand.Reader, o.InitializationVector)
-		if err != nil {
-			return
-		}
-	}
-
-	block, err := aes.NewCipher(SSE_S3_MASTER_KEY)
-	if err != nil {
-		return err
-	}
-
-	aesGcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return err
-	}
-
-	// InitializationVector is 16 bytes(because of CTR), but use only first 12 bytes in GCM
-	// for performance
-	o.EncryptionKey = aesGcm.Seal(nil, o.InitializationVector[:12], o.EncryptionKey, nil)
-	return nil
-}
-
 func (o *Object) GetVersionId() string {
 	if o.NullVersion {
 		return "null"
@@ -144,13 +120,17 @@ func (o *Object) GetCreateSql() (string, []interface{}) {
 	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
 	customAttributes, _ := json.Marshal(o.CustomAttributes)
 	acl, _ := json.Marshal(o.ACL)
+	tags, _ := json.Marshal(o.Tags)
+	objectLock, _ := json.Marshal(o.ObjectLock)
+	checksum, _ := json.Marshal(o.Checksum)
 	lastModifiedTime := o.LastModifiedTime.Format(TIME_LAYOUT_TIDB)
 	sql := "insert into objects(bucketname,name,version,location,pool,ownerid,size,objectid,lastmodifiedtime,etag," +
-		"contenttype,customattributes,acl,nullversion,deletemarker,ssetype,encryptionkey,initializationvector,type,storageclass) " +
-		"values(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+		"contenttype,customattributes,acl,nullversion,deletemarker,ssetype,encryptionkey,initializationvector,type,storageclass,tags,replicationstatus,objectlock,ssekmskeyid,ssecontext,keyversion,checksum) " +
+		"values(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
 	args := []interface{}{o.BucketName, o.Name, version, o.Location, o.Pool, o.OwnerId, o.Size, o.ObjectId,
 		lastModifiedTime, o.Etag, o.ContentType, customAttributes, acl, o.NullVersion, o.DeleteMarker,
-		o.SseType, o.EncryptionKey, o.InitializationVector, o.Type, o.StorageClass}
+		o.SseType, o.EncryptionKey, o.InitializationVector, o.Type, o.StorageClass, tags, o.ReplicationStatus, objectLock,
+		o.SseKmsKeyId, o.SseContext, o.KeyVersion, checksum}
 	return sql, args
 }
 
@@ -170,6 +150,17 @@ func (o *Object) GetUpdateSql() (string, []interface{}) {
 	return sql, args
 }
 
+// GetTransitSql updates data location and storage class of object, only if its data
+// is still oldObjectId and oldStorageClass, i.e. not overwritten or transited by others
+func (o *Object) GetTransitSql(oldObjectId string, oldStorageClass StorageClass) (string, []interface{}) {
+	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
+	sql := "update objects set location=?,pool=?,objectid=?,storageclass=? " +
+		"where bucketname=? and name=? and version=? and objectid=? and storageclass=?"
+	args := []interface{}{o.Location, o.Pool, o.ObjectId, o.StorageClass, o.BucketName, o.Name, version,
+		oldObjectId, oldStorageClass}
+	return sql, args
+}
+
 func (o *Object) GetUpdateAclSql() (string, []interface{}) {
 	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
 	acl, _ := json.Marshal(o.ACL)
@@ -178,6 +169,29 @@ func (o *Object) GetUpdateAclSql() (string, []interface{}) {
 	return sql, args
 }
 
+func (o *Object) GetUpdateTagsSql() (string, []interface{}) {
+	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
+	tags, _ := json.Marshal(o.Tags)
+	sql := "update objects set tags=? where bucketname=? and name=? and version=?"
+	args := []interface{}{tags, o.BucketName, o.Name, version}
+	return sql, args
+}
+
+func (o *Object) GetUpdateReplicationStatusSql() (string, []interface{}) {
+	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
+	sql := "update objects set replicationstatus=? where bucketname=? and name=? and version=?"
+	args := []interface{}{o.ReplicationStatus, o.BucketName, o.Name, version}
+	return sql, args
+}
+
+func (o *Object) GetUpdateObjectLockSql() (string, []interface{}) {
+	version := math.MaxUint64 - uint64(o.LastModifiedTime.UnixNano())
+	objectLock, _ := json.Marshal(o.ObjectLock)
+	sql := "update objects set objectlock=? where bucketname=? and name=? and version=?"
+	args := []interface{}{objectLock, o.BucketName, o.Name, version}
+	return sql, args
+}
+
 func (o *Object) GetUpdateAttrsSql() (string, []interface{}) {
 	customAttributes, _ := json.Marshal(o.CustomAttributes)
 	sql := "update objects set customattributes=? where bucketname=? and name=?"
@@ -207,7 +221,8 @@ func (o *Object) GetSubUsageSql() (string, []interface{}) {
 // TODO : with Version
 func (o *Object) GetReplaceObjectMetasSql() (string, []interface{}) {
 	customAttributes, _ := json.Marshal(o.CustomAttributes)
-	sql := "update objects set contenttype=?,customattributes=?,storageclass=? where bucketname=? and name=?"
-	args := []interface{}{o.ContentType, customAttributes, o.StorageClass, o.BucketName, o.Name}
+	tags, _ := json.Marshal(o.Tags)
+	sql := "update objects set contenttype=?,customattributes=?,storageclass=?,tags=? where bucketname=? and name=?"
+	args := []interface{}{o.ContentType, customAttributes, o.StorageClass, tags, o.BucketName, o.Name}
 	return sql, args
 }
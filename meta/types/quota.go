@@ -0,0 +1,45 @@
+package types
+
+const (
+	QuotaKindBucket = "bucket"
+	QuotaKindUser   = "user"
+)
+
+// Quota limits bytes and object count of a bucket, or all buckets of a user,
+// negative values are unlimited
+type Quota struct {
+	Kind       string // QuotaKindBucket or QuotaKindUser
+	Name       string // bucket name, or user id
+	MaxBytes   int64
+	MaxObjects int64
+}
+
+// QuotaUsage is bytes and object count used by a bucket or a user
+type QuotaUsage struct {
+	Bytes   int64
+	Objects int64
+}
+
+func UnlimitedQuota(kind, name string) Quota {
+	return Quota{
+		Kind:       kind,
+		Name:       name,
+		MaxBytes:   -1,
+		MaxObjects: -1,
+	}
+}
+
+func (q Quota) Unlimited() bool {
+	return q.MaxBytes < 0 && q.MaxObjects < 0
+}
+
+// Exceeded returns true if adding bytes and objects to usage exceeds the quota
+func (q Quota) Exceeded(usage QuotaUsage, bytes, objects int64) bool {
+	if q.MaxBytes >= 0 && bytes > 0 && usage.Bytes+bytes > q.MaxBytes {
+		return true
+	}
+	if q.MaxObjects >= 0 && objects > 0 && usage.Objects+objects > q.MaxObjects {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package types
+
+import "testing"
+
+func TestQuotaExceeded(t *testing.T) {
+	quota := Quota{Kind: QuotaKindBucket, Name: "b", MaxBytes: 100, MaxObjects: -1}
+	usage := QuotaUsage{Bytes: 90, Objects: 1000}
+
+	var testcase = [...]struct {
+		bytes    int64
+		objects  int64
+		exceeded bool
+	}{
+		{10, 1, false},
+		{11, 1, true},
+		// deletions and updates of metadata are never limited
+		{0, 0, false},
+		{-50, -1, false},
+	}
+
+	for _, v := range testcase {
+		ret := quota.Exceeded(usage, v.bytes, v.objects)
+		if ret != v.exceeded {
+			t.Errorf("Exceeded for %d bytes %d objects failed, expected %v, got %v\n",
+				v.bytes, v.objects, v.exceeded, ret)
+		}
+	}
+	if !UnlimitedQuota(QuotaKindUser, "u").Unlimited() {
+		t.Errorf("UnlimitedQuota is not unlimited\n")
+	}
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+)
+
+// Replication status of objects, returned as "X-Amz-Replication-Status"
+const (
+	ReplicationStatusPending   = "PENDING"
+	ReplicationStatusCompleted = "COMPLETED"
+	ReplicationStatusFailed    = "FAILED"
+)
+
+// Replication is an entry of the `replication` table, the queue of
+// object versions waiting to be copied to destination buckets
+type Replication struct {
+	BucketName string
+	ObjectName string
+	Version    uint64 // same as `version` of objects table
+	TriedTimes int
+	NextTime   time.Time // when to try again after failure
+}
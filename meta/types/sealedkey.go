@@ -0,0 +1,13 @@
+package types
+
+// SealedKey is the sealed SSE key of an object version or a multipart upload,
+// scanned and updated when keys are re-wrapped with another master key version
+type SealedKey struct {
+	BucketName string
+	ObjectName string
+	Version    uint64 // `version` of objects table, or `uploadtime` of multiparts table
+	KeyVersion int    // master key version sealing EncryptionKey and CipherKey
+	// EncryptionKey of objects, or of multipart uploads along with CipherKey
+	EncryptionKey []byte
+	CipherKey     []byte
+}
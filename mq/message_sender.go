@@ -16,19 +16,42 @@ type MessageSender interface {
 
 var MsgSender MessageSender
 
-// create the singleton MessageSender
+// all the MessageSenders keyed by plugin name, used as targets of bucket notifications
+var Senders = make(map[string]MessageSender)
+
+// create all the MessageSenders, the first one is used as the singleton MsgSender
 func InitMessageSender(plugins map[string]*mods.YigPlugin) (MessageSender, error) {
+	if err := InitNotificationSenders(plugins); err != nil {
+		return nil, err
+	}
+	if MsgSender == nil {
+		panic("Failed to initialize any MessageQueue plugin, quiting...\n")
+	}
+	return MsgSender, nil
+}
+
+// create all the MessageSenders for bucket notifications, which are optional,
+// notifications to plugins not configured are dropped
+func InitNotificationSenders(plugins map[string]*mods.YigPlugin) error {
 	for name, p := range plugins {
 		if p.PluginType == mods.MQ_PLUGIN {
 			c, err := p.Create(helper.CONFIG.Plugins[name].Args)
 			if err != nil {
 				helper.Logger.Error("failed to initial message Queue plugin:", name, "\nerr:", err)
-				return nil, err
+				return err
 			}
 			helper.Logger.Println("Message Queue plugin is", name)
-			MsgSender = c.(MessageSender)
-			return MsgSender, nil
+			Senders[name] = c.(MessageSender)
+			if MsgSender == nil {
+				MsgSender = Senders[name]
+			}
 		}
 	}
-	panic("Failed to initialize any MessageQueue plugin, quiting...\n")
+	return nil
+}
+
+// get the MessageSender created by plugin name
+func GetSender(name string) (MessageSender, bool) {
+	sender, ok := Senders[name]
+	return sender, ok
 }
@@ -41,9 +41,11 @@ const (
 	ObjectTable
 	FileTable
 	ClusterTable
+	QuotaTable
+	PublicAccessBlockTable
 )
 
-var MetadataTables = []RedisDatabase{UserTable, BucketTable, ObjectTable, ClusterTable}
+var MetadataTables = []RedisDatabase{UserTable, BucketTable, ObjectTable, ClusterTable, QuotaTable, PublicAccessBlockTable}
 var DataTables = []RedisDatabase{FileTable}
 
 func Initialize() {
@@ -218,6 +220,90 @@ func GetUsage(key string) (value string, err error) {
 	return value, nil
 }
 
+// incrCountersScript increases fields of the hash only if it's cached,
+// so counters are always loaded from database first
+var incrCountersScript = redigo.NewScript(1, `
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	for i = 1, #ARGV, 2 do
+		redis.call("HINCRBY", KEYS[1], ARGV[i], ARGV[i+1])
+	end
+	return 1
+end
+return 0
+`)
+
+// GetCounters returns nil if counters of key are not cached
+func GetCounters(key string) (counters map[string]int64, err error) {
+	err = CacheCircuit.Execute(
+		context.Background(),
+		func(ctx context.Context) (err error) {
+			c, err := GetClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			counters, err = redigo.Int64Map(c.Do("HGETALL", key))
+			if err == redigo.ErrNil {
+				return nil
+			}
+			return err
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(counters) == 0 {
+		return nil, nil
+	}
+	return counters, nil
+}
+
+// SetCounters caches counters of key for expire seconds
+func SetCounters(key string, counters map[string]int64, expire int) (err error) {
+	return CacheCircuit.Execute(
+		context.Background(),
+		func(ctx context.Context) (err error) {
+			c, err := GetClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			args := redigo.Args{}.Add(key).AddFlat(counters)
+			c.Send("MULTI")
+			c.Send("HMSET", args...)
+			c.Send("EXPIRE", key, expire)
+			_, err = c.Do("EXEC")
+			if err != nil {
+				helper.Logger.Error("Redis HMSET", key, "error:", err)
+			}
+			return err
+		},
+		nil,
+	)
+}
+
+// IncrCounters increases counters of key if they are cached
+func IncrCounters(key string, deltas map[string]int64) (err error) {
+	return CacheCircuit.Execute(
+		context.Background(),
+		func(ctx context.Context) (err error) {
+			c, err := GetClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			args := redigo.Args{}.Add(key).AddFlat(deltas)
+			_, err = incrCountersScript.Do(c, args...)
+			if err != nil {
+				helper.Logger.Error("Redis HINCRBY", key, "error:", err)
+			}
+			return err
+		},
+		nil,
+	)
+}
+
 // Get file bytes
 // `start` and `end` are inclusive
 // FIXME: this API causes an extra memory copy, need to patch radix to fix it
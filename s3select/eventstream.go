@@ -0,0 +1,98 @@
+package s3select
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+
+	"github.com/journeymidnight/yig/api/datatype"
+)
+
+// Messages of the response are framed in AWS event stream encoding:
+//
+//	total length (4) | headers length (4) | prelude CRC (4) | headers | payload | message CRC (4)
+//
+// and each header is name length (1) | name | value type (1) | value length (2) | value,
+// only string values (type 7) are used.
+
+const headerValueTypeString = 7
+
+type header struct {
+	name, value string
+}
+
+func encodeMessage(headers []header, payload []byte) []byte {
+	var headerBuffer bytes.Buffer
+	for _, h := range headers {
+		headerBuffer.WriteByte(byte(len(h.name)))
+		headerBuffer.WriteString(h.name)
+		headerBuffer.WriteByte(headerValueTypeString)
+		binary.Write(&headerBuffer, binary.BigEndian, uint16(len(h.value)))
+		headerBuffer.WriteString(h.value)
+	}
+
+	totalLength := 4 + 4 + 4 + headerBuffer.Len() + len(payload) + 4
+	message := make([]byte, 0, totalLength)
+	buffer := bytes.NewBuffer(message)
+	binary.Write(buffer, binary.BigEndian, uint32(totalLength))
+	binary.Write(buffer, binary.BigEndian, uint32(headerBuffer.Len()))
+	binary.Write(buffer, binary.BigEndian, crc32.ChecksumIEEE(buffer.Bytes()[:8]))
+	buffer.Write(headerBuffer.Bytes())
+	buffer.Write(payload)
+	binary.Write(buffer, binary.BigEndian, crc32.ChecksumIEEE(buffer.Bytes()))
+	return buffer.Bytes()
+}
+
+func eventHeaders(eventType, contentType string) []header {
+	headers := []header{
+		{":event-type", eventType},
+	}
+	if contentType != "" {
+		headers = append(headers, header{":content-type", contentType})
+	}
+	return append(headers, header{":message-type", "event"})
+}
+
+func recordsMessage(payload []byte) []byte {
+	return encodeMessage(eventHeaders("Records", "application/octet-stream"), payload)
+}
+
+func continuationMessage() []byte {
+	return encodeMessage(eventHeaders("Cont", ""), nil)
+}
+
+func statsMessage(eventType string, stats datatype.SelectStats) []byte {
+	var payload []byte
+	switch eventType {
+	case "Stats":
+		payload, _ = xml.Marshal(struct {
+			XMLName xml.Name `xml:"Stats"`
+			datatype.SelectStats
+		}{SelectStats: stats})
+	default:
+		payload, _ = xml.Marshal(struct {
+			XMLName xml.Name `xml:"Progress"`
+			datatype.SelectStats
+		}{SelectStats: stats})
+	}
+	return encodeMessage(eventHeaders(eventType, "text/xml"), payload)
+}
+
+func endMessage() []byte {
+	return encodeMessage(eventHeaders("End", ""), nil)
+}
+
+func errorMessage(code, message string) []byte {
+	return encodeMessage([]header{
+		{":error-code", code},
+		{":error-message", message},
+		{":message-type", "error"},
+	}, nil)
+}
+
+func writeMessage(w io.Writer, message []byte) error {
+	_, err := w.Write(message)
+	return err
+}
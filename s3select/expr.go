@@ -0,0 +1,506 @@
+package s3select
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+// record is a row of input, fields are looked up by paths
+type record interface {
+	// get returns NULL if the path is missing
+	get(path []pathElem) Value
+	// columns returns names and values of all fields, for SELECT *
+	columns() ([]string, []Value)
+}
+
+type expr interface {
+	eval(r record) (Value, error)
+	children() []expr
+}
+
+func walkExpr(e expr, f func(expr)) {
+	if e == nil {
+		return
+	}
+	f(e)
+	for _, child := range e.children() {
+		walkExpr(child, f)
+	}
+}
+
+func containsAggregation(e expr) bool {
+	found := false
+	walkExpr(e, func(e expr) {
+		if _, ok := e.(*aggregateExpr); ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// isAggregation returns if e is computed from aggregations only, e.g. SUM(a) / COUNT(*)
+func isAggregation(e expr) bool {
+	if _, ok := e.(*aggregateExpr); ok {
+		return true
+	}
+	if _, ok := e.(*pathExpr); ok {
+		return false
+	}
+	for _, child := range e.children() {
+		if !isAggregation(child) {
+			return false
+		}
+	}
+	return true
+}
+
+type literalExpr struct {
+	v Value
+}
+
+func (e *literalExpr) eval(r record) (Value, error) { return e.v, nil }
+func (e *literalExpr) children() []expr             { return nil }
+
+type pathElem struct {
+	name   string
+	quoted bool // quoted names are case sensitive
+	index  int  // index of arrays, -1 if it is a name
+}
+
+type pathExpr struct {
+	elems []pathElem
+}
+
+func (e *pathExpr) eval(r record) (Value, error) { return r.get(e.elems), nil }
+func (e *pathExpr) children() []expr             { return nil }
+
+// name is used as column name of projections, i.e. the last name in path
+func (e *pathExpr) name() string {
+	for i := len(e.elems) - 1; i >= 0; i-- {
+		if e.elems[i].index < 0 {
+			return e.elems[i].name
+		}
+	}
+	return ""
+}
+
+type logicalExpr struct {
+	op          string
+	left, right expr
+}
+
+// eval follows three-valued logic, NULL is returned if the result is unknown
+func (e *logicalExpr) eval(r record) (Value, error) {
+	left, err := e.left.eval(r)
+	if err != nil {
+		return null, err
+	}
+	if e.op == "AND" && !left.IsNull() && !left.truth() {
+		return boolValue(false), nil
+	}
+	if e.op == "OR" && !left.IsNull() && left.truth() {
+		return boolValue(true), nil
+	}
+	right, err := e.right.eval(r)
+	if err != nil {
+		return null, err
+	}
+	if !right.IsNull() {
+		if e.op == "AND" && !right.truth() {
+			return boolValue(false), nil
+		}
+		if e.op == "OR" && right.truth() {
+			return boolValue(true), nil
+		}
+	}
+	if left.IsNull() || right.IsNull() {
+		return null, nil
+	}
+	return boolValue(e.op == "AND"), nil
+}
+
+func (e *logicalExpr) children() []expr { return []expr{e.left, e.right} }
+
+type notExpr struct {
+	e expr
+}
+
+func (e *notExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil || v.IsNull() {
+		return null, err
+	}
+	return boolValue(!v.truth()), nil
+}
+
+func (e *notExpr) children() []expr { return []expr{e.e} }
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *compareExpr) eval(r record) (Value, error) {
+	left, err := e.left.eval(r)
+	if err != nil {
+		return null, err
+	}
+	right, err := e.right.eval(r)
+	if err != nil {
+		return null, err
+	}
+	if left.IsNull() || right.IsNull() {
+		return null, nil
+	}
+	result, ok := compare(left, right)
+	if !ok {
+		// values of different types are never equal
+		return boolValue(e.op == "!=" || e.op == "<>"), nil
+	}
+	switch e.op {
+	case "=":
+		return boolValue(result == 0), nil
+	case "!=", "<>":
+		return boolValue(result != 0), nil
+	case "<":
+		return boolValue(result < 0), nil
+	case "<=":
+		return boolValue(result <= 0), nil
+	case ">":
+		return boolValue(result > 0), nil
+	case ">=":
+		return boolValue(result >= 0), nil
+	}
+	return null, ErrUnsupportedSqlOperation
+}
+
+func (e *compareExpr) children() []expr { return []expr{e.left, e.right} }
+
+type isNullExpr struct {
+	e   expr
+	not bool
+}
+
+func (e *isNullExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil {
+		return null, err
+	}
+	return boolValue(v.IsNull() != e.not), nil
+}
+
+func (e *isNullExpr) children() []expr { return []expr{e.e} }
+
+type likeExpr struct {
+	e, pattern, escape expr
+	not                bool
+	// patterns of literals are compiled once
+	compiled *regexp.Regexp
+}
+
+func (e *likeExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil {
+		return null, err
+	}
+	re := e.compiled
+	if re == nil {
+		pattern, err := e.pattern.eval(r)
+		if err != nil {
+			return null, err
+		}
+		var escape Value
+		if e.escape != nil {
+			escape, err = e.escape.eval(r)
+			if err != nil {
+				return null, err
+			}
+		}
+		if pattern.IsNull() {
+			return null, nil
+		}
+		re, err = likePattern(pattern.String(), escape.String())
+		if err != nil {
+			return null, err
+		}
+		_, patternIsLiteral := e.pattern.(*literalExpr)
+		_, escapeIsLiteral := e.escape.(*literalExpr)
+		if patternIsLiteral && (e.escape == nil || escapeIsLiteral) {
+			e.compiled = re
+		}
+	}
+	if v.IsNull() {
+		return null, nil
+	}
+	return boolValue(re.MatchString(v.String()) != e.not), nil
+}
+
+func (e *likeExpr) children() []expr {
+	if e.escape != nil {
+		return []expr{e.e, e.pattern, e.escape}
+	}
+	return []expr{e.e, e.pattern}
+}
+
+// likePattern converts LIKE pattern to regexp, % matches any characters and _ matches one
+func likePattern(pattern, escape string) (*regexp.Regexp, error) {
+	if utf8.RuneCountInString(escape) > 1 {
+		return nil, ErrSelectInvalidDataType
+	}
+	escapeRune, _ := utf8.DecodeRuneInString(escape)
+	var re strings.Builder
+	re.WriteString("(?s)^")
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case escape != "" && c == escapeRune:
+			escaped = true
+		case c == '%':
+			re.WriteString(".*")
+		case c == '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
+
+type betweenExpr struct {
+	e, low, high expr
+	not          bool
+}
+
+func (e *betweenExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil {
+		return null, err
+	}
+	low, err := e.low.eval(r)
+	if err != nil {
+		return null, err
+	}
+	high, err := e.high.eval(r)
+	if err != nil {
+		return null, err
+	}
+	lowResult, ok1 := compare(v, low)
+	highResult, ok2 := compare(v, high)
+	if !ok1 || !ok2 {
+		return null, nil
+	}
+	return boolValue((lowResult >= 0 && highResult <= 0) != e.not), nil
+}
+
+func (e *betweenExpr) children() []expr { return []expr{e.e, e.low, e.high} }
+
+type inExpr struct {
+	e    expr
+	list []expr
+	not  bool
+}
+
+func (e *inExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil {
+		return null, err
+	}
+	if v.IsNull() {
+		return null, nil
+	}
+	for _, item := range e.list {
+		candidate, err := item.eval(r)
+		if err != nil {
+			return null, err
+		}
+		if result, ok := compare(v, candidate); ok && result == 0 {
+			return boolValue(!e.not), nil
+		}
+	}
+	return boolValue(e.not), nil
+}
+
+func (e *inExpr) children() []expr { return append([]expr{e.e}, e.list...) }
+
+type arithmeticExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *arithmeticExpr) eval(r record) (Value, error) {
+	left, err := e.left.eval(r)
+	if err != nil {
+		return null, err
+	}
+	right, err := e.right.eval(r)
+	if err != nil {
+		return null, err
+	}
+	if e.op == "||" {
+		if left.IsNull() || right.IsNull() {
+			return null, nil
+		}
+		return stringValue(left.String() + right.String()), nil
+	}
+	return arithmetic(e.op, left, right)
+}
+
+func (e *arithmeticExpr) children() []expr { return []expr{e.left, e.right} }
+
+type castExpr struct {
+	e   expr
+	typ string
+}
+
+func (e *castExpr) eval(r record) (Value, error) {
+	v, err := e.e.eval(r)
+	if err != nil {
+		return null, err
+	}
+	return cast(v, e.typ)
+}
+
+func (e *castExpr) children() []expr { return []expr{e.e} }
+
+type functionExpr struct {
+	name string
+	f    func(args []Value) (Value, error)
+	args []expr
+}
+
+func (e *functionExpr) eval(r record) (Value, error) {
+	args := make([]Value, 0, len(e.args))
+	for _, arg := range e.args {
+		v, err := arg.eval(r)
+		if err != nil {
+			return null, err
+		}
+		args = append(args, v)
+	}
+	return e.f(args)
+}
+
+func (e *functionExpr) children() []expr { return e.args }
+
+var functions = map[string]func(args []Value) (Value, error){
+	"LOWER": func(args []Value) (Value, error) {
+		if args[0].IsNull() {
+			return null, nil
+		}
+		return stringValue(strings.ToLower(args[0].String())), nil
+	},
+	"UPPER": func(args []Value) (Value, error) {
+		if args[0].IsNull() {
+			return null, nil
+		}
+		return stringValue(strings.ToUpper(args[0].String())), nil
+	},
+	"CHAR_LENGTH":      charLength,
+	"CHARACTER_LENGTH": charLength,
+	"COALESCE": func(args []Value) (Value, error) {
+		for _, arg := range args {
+			if !arg.IsNull() {
+				return arg, nil
+			}
+		}
+		return null, nil
+	},
+}
+
+func charLength(args []Value) (Value, error) {
+	if args[0].IsNull() {
+		return null, nil
+	}
+	return intValue(int64(utf8.RuneCountInString(args[0].String()))), nil
+}
+
+var aggregations = map[string]struct{}{
+	"COUNT": {},
+	"SUM":   {},
+	"AVG":   {},
+	"MIN":   {},
+	"MAX":   {},
+}
+
+// aggregateExpr accumulates values of matched records, and evaluates to the result
+type aggregateExpr struct {
+	name string
+	e    expr
+	star bool // COUNT(*)
+
+	count int64
+	sum   Value
+	min   Value
+	max   Value
+}
+
+func (e *aggregateExpr) accumulate(r record) error {
+	if e.star {
+		e.count++
+		return nil
+	}
+	v, err := e.e.eval(r)
+	if err != nil {
+		return err
+	}
+	if v.IsNull() {
+		return nil
+	}
+	e.count++
+	switch e.name {
+	case "SUM", "AVG":
+		if e.sum.IsNull() {
+			e.sum = intValue(0)
+		}
+		e.sum, err = arithmetic("+", e.sum, v)
+		return err
+	case "MIN":
+		if n, ok := v.number(); ok {
+			v = n
+		}
+		if result, ok := compare(v, e.min); e.min.IsNull() || (ok && result < 0) {
+			e.min = v
+		}
+	case "MAX":
+		if n, ok := v.number(); ok {
+			v = n
+		}
+		if result, ok := compare(v, e.max); e.max.IsNull() || (ok && result > 0) {
+			e.max = v
+		}
+	}
+	return nil
+}
+
+func (e *aggregateExpr) eval(r record) (Value, error) {
+	switch e.name {
+	case "COUNT":
+		return intValue(e.count), nil
+	case "SUM":
+		return e.sum, nil
+	case "AVG":
+		if e.count == 0 {
+			return null, nil
+		}
+		return floatValue(e.sum.float() / float64(e.count)), nil
+	case "MIN":
+		return e.min, nil
+	case "MAX":
+		return e.max, nil
+	}
+	return null, ErrUnsupportedSqlOperation
+}
+
+func (e *aggregateExpr) children() []expr {
+	if e.e == nil {
+		return nil
+	}
+	return []expr{e.e}
+}
@@ -0,0 +1,350 @@
+package s3select
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+)
+
+// recordReader reads records of input one by one, io.EOF is returned at the end
+type recordReader interface {
+	read() (record, error)
+}
+
+// countingReader counts bytes read, for BytesScanned and BytesProcessed of stats
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+func decompress(reader io.Reader, compressionType string) (io.Reader, error) {
+	switch compressionType {
+	case datatype.SelectCompressionGzip:
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, ErrInvalidCompressionFormat
+		}
+		return gzipReader, nil
+	case datatype.SelectCompressionBzip2:
+		return bzip2.NewReader(reader), nil
+	}
+	return reader, nil
+}
+
+func newRecordReader(reader io.Reader, input datatype.InputSerialization) (recordReader, error) {
+	if input.CSV != nil {
+		return newCSVReader(reader, input.CSV)
+	}
+	return &jsonReader{decoder: newJSONDecoder(reader)}, nil
+}
+
+type csvRecord struct {
+	fields []string
+	header []string // nil if header is not used
+}
+
+func (r *csvRecord) get(path []pathElem) Value {
+	if len(path) != 1 || path[0].index >= 0 {
+		return null
+	}
+	name := path[0].name
+	if !path[0].quoted && strings.HasPrefix(name, "_") {
+		// _1 is the first column
+		if i, err := strconv.Atoi(name[1:]); err == nil {
+			if i < 1 || i > len(r.fields) {
+				return null
+			}
+			return stringValue(r.fields[i-1])
+		}
+	}
+	for i, column := range r.header {
+		if column == name && i < len(r.fields) {
+			return stringValue(r.fields[i])
+		}
+	}
+	if !path[0].quoted {
+		for i, column := range r.header {
+			if strings.EqualFold(column, name) && i < len(r.fields) {
+				return stringValue(r.fields[i])
+			}
+		}
+	}
+	return null
+}
+
+func (r *csvRecord) columns() ([]string, []Value) {
+	names := make([]string, len(r.fields))
+	values := make([]Value, len(r.fields))
+	for i, field := range r.fields {
+		if i < len(r.header) {
+			names[i] = r.header[i]
+		} else {
+			names[i] = "_" + strconv.Itoa(i+1)
+		}
+		values[i] = stringValue(field)
+	}
+	return names, values
+}
+
+// csvReader parses CSV with configurable delimiters and quote characters,
+// which are not supported by encoding/csv
+type csvReader struct {
+	reader          *bufio.Reader
+	fieldDelimiter  byte
+	recordDelimiter []byte
+	quote           byte
+	escape          byte
+	comment         byte // 0 if not set
+	header          []string
+}
+
+func newCSVReader(reader io.Reader, input *datatype.CSVInput) (*csvReader, error) {
+	r := &csvReader{
+		reader:          bufio.NewReaderSize(reader, 64<<10),
+		fieldDelimiter:  input.FieldDelimiter[0],
+		recordDelimiter: []byte(input.RecordDelimiter),
+		quote:           input.QuoteCharacter[0],
+		escape:          input.QuoteEscapeCharacter[0],
+	}
+	if input.Comments != "" {
+		r.comment = input.Comments[0]
+	}
+	if input.FileHeaderInfo == datatype.CsvFileHeaderNone {
+		return r, nil
+	}
+	header, err := r.readFields()
+	if err == io.EOF {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if input.FileHeaderInfo == datatype.CsvFileHeaderUse {
+		r.header = header
+	}
+	return r, nil
+}
+
+func (r *csvReader) read() (record, error) {
+	fields, err := r.readFields()
+	if err != nil {
+		return nil, err
+	}
+	return &csvRecord{fields: fields, header: r.header}, nil
+}
+
+// readFields returns fields of the next record, empty lines and comments are skipped
+func (r *csvReader) readFields() ([]string, error) {
+	for {
+		fields, empty, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if !empty {
+			return fields, nil
+		}
+	}
+}
+
+func (r *csvReader) readLine() (fields []string, empty bool, err error) {
+	var field bytes.Buffer
+	quoted := false    // in quotes
+	started := false   // any character of the record is read
+	wasQuoted := false // the current field is quoted
+	endField := func() {
+		s := field.String()
+		if !wasQuoted && r.recordDelimiter[0] == '\n' {
+			// CRLF line endings
+			s = strings.TrimSuffix(s, "\r")
+		}
+		fields = append(fields, s)
+		field.Reset()
+		wasQuoted = false
+	}
+	for {
+		c, err := r.reader.ReadByte()
+		if err == io.EOF {
+			if quoted {
+				return nil, false, ErrCSVParsingError
+			}
+			if !started {
+				return nil, false, io.EOF
+			}
+			endField()
+			return fields, len(fields) == 1 && fields[0] == "", nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if quoted {
+			switch {
+			case c == r.escape && r.escape != r.quote:
+				next, err := r.reader.ReadByte()
+				if err != nil {
+					return nil, false, ErrCSVParsingError
+				}
+				field.WriteByte(next)
+			case c == r.quote:
+				next, err := r.reader.ReadByte()
+				if err == nil && next == r.quote && r.escape == r.quote {
+					field.WriteByte(r.quote)
+					continue
+				}
+				if err == nil {
+					r.reader.UnreadByte()
+				}
+				quoted = false
+			default:
+				field.WriteByte(c)
+			}
+			continue
+		}
+		if !started && r.comment != 0 && c == r.comment {
+			if err = r.skipLine(); err != nil && err != io.EOF {
+				return nil, false, err
+			}
+			return nil, true, nil
+		}
+		started = true
+		switch {
+		case c == r.quote && field.Len() == 0 && !wasQuoted:
+			quoted, wasQuoted = true, true
+		case c == r.fieldDelimiter:
+			endField()
+		case c == r.recordDelimiter[0]:
+			if len(r.recordDelimiter) == 2 {
+				next, err := r.reader.ReadByte()
+				if err != nil || next != r.recordDelimiter[1] {
+					if err == nil {
+						r.reader.UnreadByte()
+					}
+					field.WriteByte(c)
+					continue
+				}
+			}
+			endField()
+			return fields, len(fields) == 1 && fields[0] == "", nil
+		default:
+			field.WriteByte(c)
+		}
+	}
+}
+
+func (r *csvReader) skipLine() error {
+	for {
+		c, err := r.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if c == r.recordDelimiter[len(r.recordDelimiter)-1] {
+			return nil
+		}
+	}
+}
+
+type jsonRecord struct {
+	raw    json.RawMessage
+	values map[string]interface{}
+}
+
+func (r *jsonRecord) get(path []pathElem) Value {
+	var current interface{} = r.values
+	for _, elem := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if elem.index >= 0 {
+				return null
+			}
+			v, ok := node[elem.name]
+			if !ok && !elem.quoted {
+				for key, value := range node {
+					if strings.EqualFold(key, elem.name) {
+						v, ok = value, true
+						break
+					}
+				}
+			}
+			if !ok {
+				return null
+			}
+			current = v
+		case []interface{}:
+			if elem.index < 0 || elem.index >= len(node) {
+				return null
+			}
+			current = node[elem.index]
+		default:
+			return null
+		}
+	}
+	return jsonValue(current)
+}
+
+// columns keeps order of keys in the document
+func (r *jsonRecord) columns() ([]string, []Value) {
+	decoder := json.NewDecoder(bytes.NewReader(r.raw))
+	decoder.UseNumber()
+	var names []string
+	var values []Value
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil
+	}
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return names, values
+		}
+		key, _ := t.(string)
+		var v interface{}
+		if err = decoder.Decode(&v); err != nil {
+			return names, values
+		}
+		names = append(names, key)
+		values = append(values, jsonValue(v))
+	}
+	return names, values
+}
+
+type jsonReader struct {
+	decoder *json.Decoder
+}
+
+func newJSONDecoder(reader io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	return decoder
+}
+
+// read returns top level values one by one, for both LINES and DOCUMENT input
+func (r *jsonReader) read() (record, error) {
+	var raw json.RawMessage
+	err := r.decoder.Decode(&raw)
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ErrJSONParsingError
+	}
+	rec := &jsonRecord{raw: raw}
+	decoder := newJSONDecoder(bytes.NewReader(raw))
+	var v interface{}
+	if err = decoder.Decode(&v); err != nil {
+		return nil, ErrJSONParsingError
+	}
+	rec.values, _ = v.(map[string]interface{})
+	return rec, nil
+}
@@ -0,0 +1,95 @@
+package s3select
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/journeymidnight/yig/api/datatype"
+)
+
+// recordWriter serializes projected records into buffer
+type recordWriter interface {
+	write(buffer *bytes.Buffer, names []string, values []Value) error
+}
+
+func newRecordWriter(output datatype.OutputSerialization) recordWriter {
+	if output.CSV != nil {
+		return &csvWriter{
+			fieldDelimiter:  output.CSV.FieldDelimiter,
+			recordDelimiter: output.CSV.RecordDelimiter,
+			quote:           output.CSV.QuoteCharacter,
+			escape:          output.CSV.QuoteEscapeCharacter,
+			always:          output.CSV.QuoteFields == datatype.CsvQuoteFieldsAlways,
+		}
+	}
+	return &jsonWriter{recordDelimiter: output.JSON.RecordDelimiter}
+}
+
+type csvWriter struct {
+	fieldDelimiter  string
+	recordDelimiter string
+	quote           string
+	escape          string
+	always          bool // quote all fields, otherwise fields are quoted as needed
+}
+
+func (w *csvWriter) write(buffer *bytes.Buffer, names []string, values []Value) error {
+	for i, v := range values {
+		if i > 0 {
+			buffer.WriteString(w.fieldDelimiter)
+		}
+		field := v.String()
+		if w.always || strings.Contains(field, w.fieldDelimiter) || strings.Contains(field, w.quote) ||
+			strings.ContainsAny(field, "\r\n") || strings.Contains(field, w.recordDelimiter) {
+			buffer.WriteString(w.quote)
+			buffer.WriteString(strings.Replace(field, w.quote, w.escape+w.quote, -1))
+			buffer.WriteString(w.quote)
+		} else {
+			buffer.WriteString(field)
+		}
+	}
+	buffer.WriteString(w.recordDelimiter)
+	return nil
+}
+
+type jsonWriter struct {
+	recordDelimiter string
+}
+
+// write keeps order of names, which is lost by marshaling maps
+func (w *jsonWriter) write(buffer *bytes.Buffer, names []string, values []Value) error {
+	buffer.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, err := json.Marshal(names[i])
+		if err != nil {
+			return err
+		}
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		value, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	buffer.WriteString(w.recordDelimiter)
+	return nil
+}
+
+// projectionNames returns column names of projections, _N for the N-th projection without a name
+func projectionNames(q *Query) []string {
+	names := make([]string, len(q.projections))
+	for i, proj := range q.projections {
+		names[i] = proj.name
+		if names[i] == "" {
+			names[i] = "_" + strconv.Itoa(i+1)
+		}
+	}
+	return names
+}
@@ -0,0 +1,194 @@
+// Package s3select implements SelectObjectContent, filtering CSV and JSON objects
+// with a SQL subset, and streaming results in AWS event stream encoding.
+package s3select
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+)
+
+const (
+	// records are sent in messages of about this size
+	maxRecordsPayload = 128 << 10
+	// Cont messages keep the connection alive if no records are matched for long
+	keepAliveInterval = 5 * time.Second
+)
+
+type Select struct {
+	request      *datatype.SelectObjectContentRequest
+	query        *Query
+	aggregations []*aggregateExpr
+}
+
+// New validates the SQL expression of request, errors should be returned as
+// usual error responses since the event stream is not started yet
+func New(request *datatype.SelectObjectContentRequest) (*Select, error) {
+	query, err := ParseQuery(request.Expression)
+	if err != nil {
+		return nil, err
+	}
+	s := &Select{
+		request: request,
+		query:   query,
+	}
+	for _, proj := range query.projections {
+		walkExpr(proj.expr, func(e expr) {
+			if agg, ok := e.(*aggregateExpr); ok {
+				s.aggregations = append(s.aggregations, agg)
+			}
+		})
+	}
+	return s, nil
+}
+
+// Run reads records from input, and writes messages of matched records to w.
+// Errors while processing are sent as error messages, and returned for logging.
+func (s *Select) Run(input io.Reader, w io.Writer) error {
+	scanned := &countingReader{reader: input}
+	decompressed, err := decompress(scanned, s.request.InputSerialization.CompressionType)
+	if err != nil {
+		return s.fail(w, err)
+	}
+	processed := &countingReader{reader: decompressed}
+	reader, err := newRecordReader(processed, s.request.InputSerialization)
+	if err != nil {
+		return s.fail(w, err)
+	}
+	writer := newRecordWriter(s.request.OutputSerialization)
+
+	var buffer bytes.Buffer
+	var returned int64
+	lastMessage := time.Now()
+	stats := func() datatype.SelectStats {
+		return datatype.SelectStats{
+			BytesScanned:   scanned.count,
+			BytesProcessed: processed.count,
+			BytesReturned:  returned,
+		}
+	}
+	flush := func() error {
+		if buffer.Len() == 0 {
+			return nil
+		}
+		returned += int64(buffer.Len())
+		err := s.write(w, recordsMessage(buffer.Bytes()))
+		buffer.Reset()
+		if err != nil {
+			return err
+		}
+		if s.request.RequestProgress.Enabled {
+			err = s.write(w, statsMessage("Progress", stats()))
+		}
+		lastMessage = time.Now()
+		return err
+	}
+
+	q := s.query
+	var names []string
+	if !q.star {
+		names = projectionNames(q)
+	}
+	var matched int64
+	for q.limit < 0 || matched < q.limit {
+		if time.Since(lastMessage) > keepAliveInterval {
+			if err = s.write(w, continuationMessage()); err != nil {
+				return err
+			}
+			lastMessage = time.Now()
+		}
+		r, err := reader.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return s.fail(w, err)
+		}
+		if q.where != nil {
+			v, err := q.where.eval(r)
+			if err != nil {
+				return s.fail(w, err)
+			}
+			if v.IsNull() || !v.truth() {
+				continue
+			}
+		}
+		matched++
+
+		if q.aggregate {
+			for _, agg := range s.aggregations {
+				if err = agg.accumulate(r); err != nil {
+					return s.fail(w, err)
+				}
+			}
+			continue
+		}
+		columns := names
+		var values []Value
+		if q.star {
+			columns, values = r.columns()
+		} else if values, err = project(q, r); err != nil {
+			return s.fail(w, err)
+		}
+		if err = writer.write(&buffer, columns, values); err != nil {
+			return s.fail(w, err)
+		}
+		if buffer.Len() >= maxRecordsPayload {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if q.aggregate {
+		// aggregations evaluate to accumulated results without records
+		values, err := project(q, nil)
+		if err != nil {
+			return s.fail(w, err)
+		}
+		if err = writer.write(&buffer, names, values); err != nil {
+			return s.fail(w, err)
+		}
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+	if err = s.write(w, statsMessage("Stats", stats())); err != nil {
+		return err
+	}
+	return s.write(w, endMessage())
+}
+
+func project(q *Query, r record) ([]Value, error) {
+	values := make([]Value, len(q.projections))
+	for i, proj := range q.projections {
+		v, err := proj.expr.eval(r)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (s *Select) write(w io.Writer, message []byte) error {
+	err := writeMessage(w, message)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return err
+}
+
+// fail sends err as an error message, which ends the event stream
+func (s *Select) fail(w io.Writer, err error) error {
+	code, message := "InternalError", err.Error()
+	if apiErr, ok := err.(ApiError); ok {
+		code, message = apiErr.AwsErrorCode(), apiErr.Description()
+	}
+	s.write(w, errorMessage(code, message))
+	return err
+}
@@ -0,0 +1,176 @@
+package s3select
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype"
+)
+
+const csvInput = `name,age,city
+alice,30,"Beijing, China"
+bob,25,Shanghai
+carol,41,Shenzhen
+dave,,Beijing
+`
+
+const jsonInput = `{"name": "alice", "age": 30, "address": {"city": "Beijing"}, "tags": ["a", "b"]}
+{"name": "bob", "age": 25.5, "address": {"city": "Shanghai"}, "tags": []}
+{"name": "carol", "age": null, "address": {"city": "Beijing"}}
+`
+
+// decodeMessages returns event types and payload of Records messages, and checks CRC of messages
+func decodeMessages(t *testing.T, stream []byte) (events []string, records string) {
+	for len(stream) > 0 {
+		totalLength := binary.BigEndian.Uint32(stream[0:4])
+		headersLength := binary.BigEndian.Uint32(stream[4:8])
+		if crc32.ChecksumIEEE(stream[0:8]) != binary.BigEndian.Uint32(stream[8:12]) {
+			t.Fatalf("Prelude CRC mismatch")
+		}
+		message := stream[:totalLength]
+		if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+			t.Fatalf("Message CRC mismatch")
+		}
+		headers := message[12 : 12+headersLength]
+		payload := message[12+headersLength : totalLength-4]
+		values := make(map[string]string)
+		for len(headers) > 0 {
+			nameLength := int(headers[0])
+			name := string(headers[1 : 1+nameLength])
+			valueLength := int(binary.BigEndian.Uint16(headers[2+nameLength : 4+nameLength]))
+			values[name] = string(headers[4+nameLength : 4+nameLength+valueLength])
+			headers = headers[4+nameLength+valueLength:]
+		}
+		if values[":message-type"] == "error" {
+			events = append(events, "Error:"+values[":error-code"])
+		} else {
+			events = append(events, values[":event-type"])
+		}
+		if values[":event-type"] == "Records" {
+			records += string(payload)
+		}
+		stream = stream[totalLength:]
+	}
+	return
+}
+
+func runSelect(t *testing.T, request *datatype.SelectObjectContentRequest, input []byte) ([]string, string) {
+	if err := request.Validate(); err != nil {
+		t.Fatalf("Invalid request: %v", err)
+	}
+	s, err := New(request)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", request.Expression, err)
+	}
+	var output bytes.Buffer
+	s.Run(bytes.NewReader(input), &output)
+	return decodeMessages(t, output.Bytes())
+}
+
+func csvRequest(expression string) *datatype.SelectObjectContentRequest {
+	return &datatype.SelectObjectContentRequest{
+		Expression:          expression,
+		ExpressionType:      "SQL",
+		InputSerialization:  datatype.InputSerialization{CSV: &datatype.CSVInput{FileHeaderInfo: "USE"}},
+		OutputSerialization: datatype.OutputSerialization{CSV: &datatype.CSVOutput{}},
+	}
+}
+
+func jsonRequest(expression string) *datatype.SelectObjectContentRequest {
+	return &datatype.SelectObjectContentRequest{
+		Expression:          expression,
+		ExpressionType:      "SQL",
+		InputSerialization:  datatype.InputSerialization{JSON: &datatype.JSONInput{Type: "LINES"}},
+		OutputSerialization: datatype.OutputSerialization{JSON: &datatype.JSONOutput{}},
+	}
+}
+
+var csvSelectTests = []struct {
+	Expression string
+	Expected   string
+}{
+	{"SELECT * FROM S3Object", "alice,30,\"Beijing, China\"\nbob,25,Shanghai\ncarol,41,Shenzhen\ndave,,Beijing\n"},   // 0
+	{"SELECT s.name FROM S3Object s WHERE s.age > 28", "alice\ncarol\n"},                                             // 1
+	{"SELECT name, age FROM S3Object WHERE city LIKE 'Beijing%' AND age <> ''", "alice,30\n"},                        // 2
+	{"SELECT _1 FROM S3Object WHERE age BETWEEN 25 AND 30 OR name IN ('dave')", "alice\nbob\ndave\n"},                // 3
+	{"SELECT name FROM S3Object LIMIT 2", "alice\nbob\n"},                                                            // 4
+	{"SELECT COUNT(*), SUM(CAST(age AS INT)), MAX(age), MIN(name) FROM S3Object WHERE age != ''", "3,96,41,alice\n"}, // 5
+	{"SELECT AVG(age) FROM S3Object WHERE NOT name = 'carol' AND age IS NOT NULL AND age <> ''", "27.5\n"},           // 6
+	{"SELECT UPPER(name) || '-' || city FROM S3Object s WHERE s.\"name\" = 'bob'", "BOB-Shanghai\n"},                 // 7
+	{"SELECT name FROM S3Object WHERE CHAR_LENGTH(city) > 8", "alice\n"},                                             // 8
+}
+
+func TestSelectCSV(t *testing.T) {
+	for i, test := range csvSelectTests {
+		events, records := runSelect(t, csvRequest(test.Expression), []byte(csvInput))
+		if records != test.Expected {
+			t.Errorf("Test %d: expected %q, got %q", i, test.Expected, records)
+		}
+		if len(events) < 2 || events[len(events)-2] != "Stats" || events[len(events)-1] != "End" {
+			t.Errorf("Test %d: unexpected events %v", i, events)
+		}
+	}
+}
+
+var jsonSelectTests = []struct {
+	Expression string
+	Expected   string
+}{
+	{"SELECT s.name, s.address.city FROM S3Object[*] s WHERE s.age >= 25.5", `{"name":"alice","city":"Beijing"}` + "\n" +
+		`{"name":"bob","city":"Shanghai"}` + "\n"}, // 0
+	{"SELECT s.tags[1] AS tag FROM S3Object s WHERE s.tags[1] IS NOT NULL", `{"tag":"b"}` + "\n"},                       // 1
+	{"SELECT COUNT(*) AS n, AVG(s.age) FROM S3Object s WHERE s.address.city = 'Beijing'", `{"n":2,"_2":30}` + "\n"},     // 2
+	{"SELECT * FROM S3Object s WHERE s.age IS NULL", `{"name":"carol","age":null,"address":{"city":"Beijing"}}` + "\n"}, // 3
+}
+
+func TestSelectJSON(t *testing.T) {
+	for i, test := range jsonSelectTests {
+		_, records := runSelect(t, jsonRequest(test.Expression), []byte(jsonInput))
+		if records != test.Expected {
+			t.Errorf("Test %d: expected %q, got %q", i, test.Expected, records)
+		}
+	}
+}
+
+func TestSelectGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(csvInput))
+	writer.Close()
+
+	request := csvRequest("SELECT COUNT(*) FROM S3Object")
+	request.InputSerialization.CompressionType = "GZIP"
+	_, records := runSelect(t, request, compressed.Bytes())
+	if records != "4\n" {
+		t.Errorf("Expected 4 records, got %q", records)
+	}
+
+	request = csvRequest("SELECT COUNT(*) FROM S3Object")
+	request.InputSerialization.CompressionType = "GZIP"
+	events, _ := runSelect(t, request, []byte(csvInput))
+	if len(events) != 1 || events[0] != "Error:InvalidCompressionFormat" {
+		t.Errorf("Uncompressed input should fail, got %v", events)
+	}
+}
+
+var invalidQueries = []string{
+	"",                                    // 0
+	"SELECT FROM S3Object",                // 1
+	"SELECT * FROM table",                 // 2
+	"SELECT name, COUNT(*) FROM S3Object", // 3
+	"SELECT * FROM S3Object WHERE COUNT(*) > 1", // 4
+	"SELECT * FROM S3Object LIMIT x",            // 5
+	"SELECT 'abc FROM S3Object",                 // 6
+	"SELECT * FROM S3Object WHERE a NOT 1",      // 7
+}
+
+func TestParseQueryFailure(t *testing.T) {
+	for i, sql := range invalidQueries {
+		if _, err := ParseQuery(sql); err == nil {
+			t.Errorf("Test %d: %q should fail", i, sql)
+		}
+	}
+}
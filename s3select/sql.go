@@ -0,0 +1,616 @@
+package s3select
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+// The SQL subset of S3 Select:
+//
+//	SELECT * | expr [[AS] alias], ... FROM S3Object[[*]] [[AS] alias]
+//	[WHERE condition] [LIMIT number]
+//
+// expressions support comparisons, [NOT] LIKE, [NOT] BETWEEN, [NOT] IN, IS [NOT] NULL,
+// AND, OR, NOT, arithmetic, CAST, LOWER, UPPER, CHAR_LENGTH, COALESCE and
+// aggregations COUNT, SUM, AVG, MIN and MAX.
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent // "column name"
+	tokenString      // 'literal'
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (t token) isOperator(op string) bool {
+	return t.kind == tokenOperator && t.value == op
+}
+
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == c {
+					// quotes are escaped by doubling
+					if j+1 < len(runes) && runes[j+1] == c {
+						value.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				value.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, ErrSelectParseFailure
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, value: value.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' ||
+				runes[j] == 'e' || runes[j] == 'E' ||
+				((runes[j] == '+' || runes[j] == '-') && (runes[j-1] == 'e' || runes[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[i:j])})
+			i = j
+		default:
+			op := string(c)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "<=", ">=", "<>", "!=", "||":
+					op = two
+				}
+			}
+			if !strings.Contains("=<>!|+-*/%(),.[]", op[:1]) || op == "!" || op == "|" {
+				return nil, ErrSelectParseFailure
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// Query is a parsed SELECT statement
+type Query struct {
+	star        bool
+	projections []projection
+	alias       string // alias of S3Object
+	where       expr
+	limit       int64 // -1 if not limited
+	aggregate   bool
+}
+
+type projection struct {
+	expr expr
+	name string // alias or name derived from expression, empty if not known
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	// aggregations are not allowed in WHERE
+	inWhere      bool
+	aggregations int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.peek().is(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptOperator(op string) bool {
+	if p.peek().isOperator(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if !p.acceptOperator(op) {
+		return ErrSelectParseFailure
+	}
+	return nil
+}
+
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "LIMIT": true, "AS": true,
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "ESCAPE": true, "IS": true,
+	"NULL": true, "TRUE": true, "FALSE": true, "BETWEEN": true, "IN": true,
+}
+
+// ParseQuery parses a SELECT statement of S3 Select
+func ParseQuery(sql string) (*Query, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &Query{limit: -1}
+	if !p.acceptKeyword("SELECT") {
+		return nil, ErrSelectParseFailure
+	}
+	if p.acceptOperator("*") {
+		q.star = true
+	} else {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			proj := projection{expr: e}
+			if p.acceptKeyword("AS") {
+				t := p.next()
+				if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+					return nil, ErrSelectParseFailure
+				}
+				proj.name = t.value
+			} else if t := p.peek(); (t.kind == tokenIdent && !reservedWords[strings.ToUpper(t.value)]) ||
+				t.kind == tokenQuotedIdent {
+				p.next()
+				proj.name = t.value
+			} else if path, ok := e.(*pathExpr); ok {
+				proj.name = path.name()
+			}
+			q.projections = append(q.projections, proj)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+	q.aggregate = p.aggregations > 0
+
+	if !p.acceptKeyword("FROM") || !p.acceptKeyword("S3Object") {
+		return nil, ErrSelectParseFailure
+	}
+	if p.acceptOperator("[") {
+		if !p.acceptOperator("*") {
+			return nil, ErrSelectParseFailure
+		}
+		if err = p.expectOperator("]"); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().isOperator(".") {
+		// paths in S3Object are not supported
+		return nil, ErrUnsupportedSqlOperation
+	}
+	if p.acceptKeyword("AS") {
+		t := p.next()
+		if t.kind != tokenIdent {
+			return nil, ErrSelectParseFailure
+		}
+		q.alias = t.value
+	} else if t := p.peek(); t.kind == tokenIdent && !reservedWords[strings.ToUpper(t.value)] {
+		p.next()
+		q.alias = t.value
+	}
+
+	if p.acceptKeyword("WHERE") {
+		p.inWhere = true
+		q.where, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.inWhere = false
+	}
+	if p.acceptKeyword("LIMIT") {
+		t := p.next()
+		if t.kind != tokenNumber {
+			return nil, ErrSelectParseFailure
+		}
+		q.limit, err = strconv.ParseInt(t.value, 10, 64)
+		if err != nil || q.limit < 0 {
+			return nil, ErrSelectParseFailure
+		}
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, ErrSelectParseFailure
+	}
+
+	if q.aggregate {
+		// all projections should be aggregations, since GROUP BY is not supported
+		for _, proj := range q.projections {
+			if !isAggregation(proj.expr) {
+				return nil, ErrUnsupportedSqlOperation
+			}
+		}
+	}
+	q.resolveAlias()
+	return q, nil
+}
+
+// resolveAlias strips alias of S3Object from paths, e.g. s.name to name
+func (q *Query) resolveAlias() {
+	resolve := func(e expr) {
+		walkExpr(e, func(e expr) {
+			path, ok := e.(*pathExpr)
+			if !ok || len(path.elems) < 2 || path.elems[0].quoted || path.elems[0].index >= 0 {
+				return
+			}
+			if (q.alias != "" && strings.EqualFold(path.elems[0].name, q.alias)) ||
+				strings.EqualFold(path.elems[0].name, "S3Object") {
+				path.elems = path.elems[1:]
+			}
+		})
+	}
+	for _, proj := range q.projections {
+		resolve(proj.expr)
+	}
+	if q.where != nil {
+		resolve(q.where)
+	}
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{e}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind == tokenOperator {
+		switch t.value {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: t.value, left: left, right: right}, nil
+		}
+		return left, nil
+	}
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if !p.acceptKeyword("NULL") {
+			return nil, ErrSelectParseFailure
+		}
+		return &isNullExpr{e: left, not: not}, nil
+	}
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("LIKE"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		like := &likeExpr{e: left, pattern: pattern, not: not}
+		if p.acceptKeyword("ESCAPE") {
+			like.escape, err = p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return like, nil
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("AND") {
+			return nil, ErrSelectParseFailure
+		}
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{e: left, low: low, high: high, not: not}, nil
+	case p.acceptKeyword("IN"):
+		if err = p.expectOperator("("); err != nil {
+			return nil, err
+		}
+		in := &inExpr{e: left, not: not}
+		for {
+			e, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, e)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+	}
+	if not {
+		return nil, ErrSelectParseFailure
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.isOperator("+") && !t.isOperator("-") && !t.isOperator("||") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticExpr{op: t.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.isOperator("*") && !t.isOperator("/") && !t.isOperator("%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticExpr{op: t.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.acceptOperator("-") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &arithmeticExpr{op: "-", left: &literalExpr{intValue(0)}, right: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return &literalExpr{intValue(i)}, nil
+		}
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, ErrSelectParseFailure
+		}
+		return &literalExpr{floatValue(f)}, nil
+	case tokenString:
+		return &literalExpr{stringValue(t.value)}, nil
+	case tokenOperator:
+		if t.value != "(" {
+			return nil, ErrSelectParseFailure
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tokenQuotedIdent:
+		return p.parsePath(pathElem{name: t.value, quoted: true, index: -1})
+	case tokenIdent:
+		switch strings.ToUpper(t.value) {
+		case "NULL":
+			return &literalExpr{null}, nil
+		case "TRUE":
+			return &literalExpr{boolValue(true)}, nil
+		case "FALSE":
+			return &literalExpr{boolValue(false)}, nil
+		}
+		if reservedWords[strings.ToUpper(t.value)] {
+			return nil, ErrSelectParseFailure
+		}
+		if p.peek().isOperator("(") {
+			return p.parseFunction(strings.ToUpper(t.value))
+		}
+		return p.parsePath(pathElem{name: t.value, index: -1})
+	}
+	return nil, ErrSelectParseFailure
+}
+
+func (p *parser) parsePath(first pathElem) (expr, error) {
+	path := &pathExpr{elems: []pathElem{first}}
+	for {
+		switch {
+		case p.acceptOperator("."):
+			t := p.next()
+			switch t.kind {
+			case tokenIdent:
+				path.elems = append(path.elems, pathElem{name: t.value, index: -1})
+			case tokenQuotedIdent:
+				path.elems = append(path.elems, pathElem{name: t.value, quoted: true, index: -1})
+			default:
+				return nil, ErrSelectParseFailure
+			}
+		case p.acceptOperator("["):
+			t := p.next()
+			index, err := strconv.Atoi(t.value)
+			if t.kind != tokenNumber || err != nil || index < 0 {
+				return nil, ErrSelectParseFailure
+			}
+			if err = p.expectOperator("]"); err != nil {
+				return nil, err
+			}
+			path.elems = append(path.elems, pathElem{index: index})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) parseFunction(name string) (expr, error) {
+	p.next() // (
+	if name == "CAST" {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("AS") {
+			return nil, ErrSelectParseFailure
+		}
+		t := p.next()
+		if t.kind != tokenIdent {
+			return nil, ErrSelectParseFailure
+		}
+		if err = p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return &castExpr{e: e, typ: strings.ToUpper(t.value)}, nil
+	}
+
+	if _, ok := aggregations[name]; ok {
+		if p.inWhere {
+			return nil, ErrUnsupportedSqlOperation
+		}
+		p.aggregations++
+		agg := &aggregateExpr{name: name}
+		if name == "COUNT" && p.acceptOperator("*") {
+			agg.star = true
+		} else {
+			var err error
+			agg.e, err = p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if containsAggregation(agg.e) {
+				return nil, ErrUnsupportedSqlOperation
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return agg, nil
+	}
+
+	f, ok := functions[name]
+	if !ok {
+		return nil, ErrUnsupportedSqlOperation
+	}
+	call := &functionExpr{name: name, f: f}
+	if !p.acceptOperator(")") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, e)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+	}
+	if (name != "COALESCE" && len(call.args) != 1) || len(call.args) == 0 {
+		return nil, ErrSelectParseFailure
+	}
+	return call, nil
+}
@@ -0,0 +1,281 @@
+package s3select
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	. "github.com/journeymidnight/yig/error"
+)
+
+type valueKind uint8
+
+const (
+	kindNull valueKind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindRaw // JSON objects and arrays
+)
+
+// Value is a SQL value of fields, literals and results of expressions
+type Value struct {
+	kind valueKind
+	b    bool
+	i    int64
+	f    float64
+	s    string
+	raw  interface{}
+}
+
+var null = Value{}
+
+func boolValue(b bool) Value       { return Value{kind: kindBool, b: b} }
+func intValue(i int64) Value       { return Value{kind: kindInt, i: i} }
+func floatValue(f float64) Value   { return Value{kind: kindFloat, f: f} }
+func stringValue(s string) Value   { return Value{kind: kindString, s: s} }
+func rawValue(v interface{}) Value { return Value{kind: kindRaw, raw: v} }
+
+// jsonValue converts values decoded by json.Decoder with UseNumber
+func jsonValue(v interface{}) Value {
+	switch v := v.(type) {
+	case nil:
+		return null
+	case bool:
+		return boolValue(v)
+	case string:
+		return stringValue(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return intValue(i)
+		}
+		f, _ := v.Float64()
+		return floatValue(f)
+	default:
+		return rawValue(v)
+	}
+}
+
+func (v Value) IsNull() bool {
+	return v.kind == kindNull
+}
+
+func (v Value) isNumber() bool {
+	return v.kind == kindInt || v.kind == kindFloat
+}
+
+// number returns numeric value of v, strings of numbers are converted
+// since all fields of CSV are strings
+func (v Value) number() (Value, bool) {
+	switch v.kind {
+	case kindInt, kindFloat:
+		return v, true
+	case kindString:
+		s := strings.TrimSpace(v.s)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return intValue(i), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return floatValue(f), true
+		}
+	}
+	return null, false
+}
+
+func (v Value) float() float64 {
+	if v.kind == kindInt {
+		return float64(v.i)
+	}
+	return v.f
+}
+
+// String returns v as a CSV field
+func (v Value) String() string {
+	switch v.kind {
+	case kindBool:
+		return strconv.FormatBool(v.b)
+	case kindInt:
+		return strconv.FormatInt(v.i, 10)
+	case kindFloat:
+		return strconv.FormatFloat(v.f, 'f', -1, 64)
+	case kindString:
+		return v.s
+	case kindRaw:
+		b, _ := json.Marshal(v.raw)
+		return string(b)
+	}
+	return ""
+}
+
+// MarshalJSON returns v as a JSON value
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.kind {
+	case kindNull:
+		return []byte("null"), nil
+	case kindBool:
+		return json.Marshal(v.b)
+	case kindInt:
+		return json.Marshal(v.i)
+	case kindFloat:
+		if math.IsNaN(v.f) || math.IsInf(v.f, 0) {
+			return []byte("null"), nil
+		}
+		return json.Marshal(v.f)
+	case kindString:
+		return json.Marshal(v.s)
+	}
+	return json.Marshal(v.raw)
+}
+
+func (v Value) truth() bool {
+	switch v.kind {
+	case kindBool:
+		return v.b
+	case kindString:
+		b, err := strconv.ParseBool(v.s)
+		return err == nil && b
+	}
+	return false
+}
+
+// compare returns -1, 0 or 1, ok is false if values are not comparable,
+// e.g. either is NULL
+func compare(a, b Value) (result int, ok bool) {
+	if a.IsNull() || b.IsNull() {
+		return 0, false
+	}
+	if a.isNumber() || b.isNumber() {
+		x, okx := a.number()
+		y, oky := b.number()
+		if !okx || !oky {
+			return 0, false
+		}
+		if x.kind == kindInt && y.kind == kindInt {
+			return compareInt(x.i, y.i), true
+		}
+		return compareFloat(x.float(), y.float()), true
+	}
+	if a.kind == kindBool || b.kind == kindBool {
+		if a.kind != b.kind {
+			x, y := a.truth(), b.truth()
+			a, b = boolValue(x), boolValue(y)
+		}
+		if a.b == b.b {
+			return 0, true
+		}
+		if !a.b {
+			return -1, true
+		}
+		return 1, true
+	}
+	return strings.Compare(a.String(), b.String()), true
+}
+
+func compareInt(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// arithmetic applies +, -, *, / or % to numbers, NULL if either is not a number
+func arithmetic(op string, a, b Value) (Value, error) {
+	if a.IsNull() || b.IsNull() {
+		return null, nil
+	}
+	x, okx := a.number()
+	y, oky := b.number()
+	if !okx || !oky {
+		return null, ErrSelectInvalidDataType
+	}
+	if x.kind == kindInt && y.kind == kindInt {
+		switch op {
+		case "+":
+			return intValue(x.i + y.i), nil
+		case "-":
+			return intValue(x.i - y.i), nil
+		case "*":
+			return intValue(x.i * y.i), nil
+		case "/":
+			if y.i == 0 {
+				return null, nil // NULL as MySQL does
+			}
+			return intValue(x.i / y.i), nil
+		case "%":
+			if y.i == 0 {
+				return null, nil // NULL as MySQL does
+			}
+			return intValue(x.i % y.i), nil
+		}
+	}
+	switch op {
+	case "+":
+		return floatValue(x.float() + y.float()), nil
+	case "-":
+		return floatValue(x.float() - y.float()), nil
+	case "*":
+		return floatValue(x.float() * y.float()), nil
+	case "/":
+		if y.float() == 0 {
+			return null, nil // NULL as MySQL does
+		}
+		return floatValue(x.float() / y.float()), nil
+	case "%":
+		if y.float() == 0 {
+			return null, nil // NULL as MySQL does
+		}
+		return floatValue(math.Mod(x.float(), y.float())), nil
+	}
+	return null, ErrUnsupportedSqlOperation
+}
+
+// cast converts v to type of CAST(v AS type)
+func cast(v Value, typ string) (Value, error) {
+	if v.IsNull() {
+		return null, nil
+	}
+	switch typ {
+	case "INT", "INTEGER":
+		n, ok := v.number()
+		if !ok {
+			return null, ErrSelectInvalidDataType
+		}
+		if n.kind == kindFloat {
+			return intValue(int64(n.f)), nil
+		}
+		return n, nil
+	case "FLOAT", "DECIMAL", "NUMERIC":
+		n, ok := v.number()
+		if !ok {
+			return null, ErrSelectInvalidDataType
+		}
+		return floatValue(n.float()), nil
+	case "STRING", "VARCHAR", "CHAR":
+		return stringValue(v.String()), nil
+	case "BOOL", "BOOLEAN":
+		if v.kind == kindBool {
+			return v, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(v.String()))
+		if err != nil {
+			return null, ErrSelectInvalidDataType
+		}
+		return boolValue(b), nil
+	}
+	return null, ErrUnsupportedSqlOperation
+}
@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam"
 	"github.com/journeymidnight/yig/iam/common"
 )
 
@@ -152,3 +153,29 @@ func IsReqAuthenticated(r *http.Request) (c common.Credential, e error) {
 	}
 	return c, ErrAccessDenied
 }
+
+// getSessionToken returns x-amz-security-token of request, from header
+// or query string of presigned requests
+func getSessionToken(r *http.Request) string {
+	if token := r.Header.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	query := r.URL.Query()
+	if token := query.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	return query.Get("x-amz-security-token")
+}
+
+// getCredential returns credential of access key, temporary credentials
+// are validated with their session tokens
+func getCredential(accessKey, sessionToken string) (credential common.Credential, err error) {
+	credential, err = iam.GetCredentialWithToken(accessKey, sessionToken)
+	if err == ErrInvalidToken || err == ErrExpiredToken {
+		return credential, err
+	}
+	if err != nil {
+		return credential, ErrInvalidAccessKeyID
+	}
+	return credential, nil
+}
@@ -0,0 +1,48 @@
+package signature
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+)
+
+func TestChecksumVerifyReadCloser(t *testing.T) {
+	testCases := []struct {
+		checksum datatype.Checksum
+		value    string
+		err      error
+	}{
+		{datatype.Checksum{Algorithm: datatype.ChecksumCRC32, Value: "DUoRhQ=="}, "DUoRhQ==", nil},
+		{datatype.Checksum{Algorithm: datatype.ChecksumCRC32C, Value: "yZRlqg=="}, "yZRlqg==", nil},
+		{datatype.Checksum{Algorithm: datatype.ChecksumSHA1, Value: "Kq5sNclPz7QV2+lfQIuc6R7oRu0="},
+			"Kq5sNclPz7QV2+lfQIuc6R7oRu0=", nil},
+		{datatype.Checksum{Algorithm: datatype.ChecksumSHA256,
+			Value: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="},
+			"uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", nil},
+		// only algorithm is specified, checksum is calculated
+		{datatype.Checksum{Algorithm: datatype.ChecksumCRC32}, "DUoRhQ==", nil},
+		{datatype.Checksum{Algorithm: datatype.ChecksumCRC32, Value: "yZRlqg=="}, "DUoRhQ==", ErrBadChecksum},
+	}
+	for i, testCase := range testCases {
+		body := ioutil.NopCloser(strings.NewReader("hello world"))
+		reader := NewChecksumVerify(body, testCase.checksum)
+		data, err := ioutil.ReadAll(reader)
+		if err != nil || string(data) != "hello world" {
+			t.Fatalf("Test %d: read %q, err: %v", i+1, data, err)
+		}
+		checksum, err := reader.Verify()
+		if err != testCase.err {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if checksum.Value != testCase.value || checksum.Algorithm != testCase.checksum.Algorithm ||
+			checksum.Type != datatype.ChecksumTypeFullObject {
+			t.Errorf("Test %d: expected checksum %s, got %+v", i+1, testCase.value, checksum)
+		}
+		if reader.Unwrap() != body {
+			t.Errorf("Test %d: Unwrap does not return the underlying reader", i+1)
+		}
+	}
+}
@@ -18,11 +18,13 @@ package signature
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"hash"
 	"io"
 	"net/http"
 
+	"github.com/journeymidnight/yig/api/datatype"
 	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/iam/common"
 )
@@ -75,9 +77,74 @@ func (v *SignVerifyReadCloser) Close() error {
 	return v.Request.Body.Close()
 }
 
+// ChecksumVerifyReadCloser calculates additional checksum of x-amz-checksum-*
+// headers while data is read, it wraps reader returned by VerifyUpload.
+// Caller should call `ChecksumVerifyReadCloser.Verify()` to validate the checksum
+// and get the underlying reader with `Unwrap()` for signature verification.
+type ChecksumVerifyReadCloser struct {
+	ReadCloser io.ReadCloser
+	Reader     io.Reader
+	Checksum   datatype.Checksum // expected value is empty if only algorithm is specified
+	Hash       hash.Hash
+}
+
+func NewChecksumVerify(dataReader io.ReadCloser, checksum datatype.Checksum) *ChecksumVerifyReadCloser {
+	h := datatype.NewChecksumHash(checksum.Algorithm)
+	return &ChecksumVerifyReadCloser{
+		ReadCloser: dataReader,
+		Reader:     io.TeeReader(dataReader, h),
+		Checksum:   checksum,
+		Hash:       h,
+	}
+}
+
+// Verify - verifies checksum and returns calculated checksum, or error upon checksum mismatch.
+func (v *ChecksumVerifyReadCloser) Verify() (datatype.Checksum, error) {
+	checksum := datatype.Checksum{
+		Algorithm: v.Checksum.Algorithm,
+		Value:     base64.StdEncoding.EncodeToString(v.Hash.Sum(nil)),
+		Type:      datatype.ChecksumTypeFullObject,
+	}
+	if v.Checksum.Value != "" && v.Checksum.Value != checksum.Value {
+		return checksum, ErrBadChecksum
+	}
+	return checksum, nil
+}
+
+func (v *ChecksumVerifyReadCloser) Unwrap() io.ReadCloser {
+	return v.ReadCloser
+}
+
+func (v *ChecksumVerifyReadCloser) Read(b []byte) (int, error) {
+	return v.Reader.Read(b)
+}
+
+func (v *ChecksumVerifyReadCloser) Close() error {
+	return v.ReadCloser.Close()
+}
+
+// VerifyUpload returns reader of request body, which verifies signature and
+// checksum of x-amz-checksum-* headers if any
 func VerifyUpload(r *http.Request) (credential common.Credential,
 	dataReader io.ReadCloser, err error) {
 
+	checksum, err := datatype.ParseChecksumHeaders(r.Header)
+	if err != nil {
+		return
+	}
+	credential, dataReader, err = verifyUpload(r)
+	if err != nil {
+		return
+	}
+	if checksum.Algorithm != "" {
+		dataReader = NewChecksumVerify(dataReader, checksum)
+	}
+	return
+}
+
+func verifyUpload(r *http.Request) (credential common.Credential,
+	dataReader io.ReadCloser, err error) {
+
 	dataReader = r.Body
 	switch GetRequestAuthType(r) {
 	default:
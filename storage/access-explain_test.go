@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/journeymidnight/yig/api"
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/meta/types"
+)
+
+// fakeListClient serves an empty listing of the bucket, and no other versions of the object
+type fakeListClient struct {
+	fakeObjectClient
+}
+
+func (c *fakeListClient) ListObjects(bucketName, marker, verIdMarker, prefix, delimiter string, versioned bool,
+	maxKeys int) (retObjects []*types.Object, prefixes []string, truncated bool, nextMarker,
+	nextVerIdMarker string, err error) {
+	return
+}
+
+func (c *fakeListClient) GetAllObject(bucketName, objectName, version string) ([]*types.Object, error) {
+	return nil, ErrNoSuchKey
+}
+
+// accessOf authorizes action of credential the way handlers do, then performs it in storage
+func accessOf(yig *YigStorage, credential common.Credential, bucket *types.Bucket,
+	config datatype.PublicAccessBlockConfiguration, action policy.Action) (allowed bool) {
+
+	r := httptest.NewRequest("GET", "/b/o", nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.RequestContextKey, api.RequestContext{}))
+	objectName := "o"
+	if action == policy.ListBucketAction {
+		objectName = ""
+	}
+	allow, err := api.IsBucketPolicyAllowed(credential, bucket, r, action, objectName)
+	if err != nil {
+		return false
+	}
+	credential.AllowOtherUserAccess = allow
+	if err = api.RestrictOtherUserAccess(&credential, bucket, config); err != nil {
+		return false
+	}
+	switch action {
+	case policy.GetObjectAction:
+		_, err = yig.GetObjectInfo("b", "o", "", credential)
+	case policy.ListBucketAction:
+		_, err = yig.ListObjects(credential, "b", datatype.ListObjectsRequest{MaxKeys: 1000})
+	case policy.DeleteObjectAction:
+		_, err = yig.DeleteObject("b", "o", "", false, credential)
+	case policy.GetObjectTaggingAction:
+		_, err = yig.GetObjectTagging("b", "o", "", credential)
+	}
+	return err == nil
+}
+
+func TestExplainAccessMatchesStorage(t *testing.T) {
+	otherPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"other"},` +
+		`"Action":["s3:GetObject","s3:ListBucket","s3:DeleteObject","s3:GetObjectTagging"],` +
+		`"Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]}]}`
+	publicPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*",` +
+		`"Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]}]}`
+	denyPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":"*",` +
+		`"Action":"s3:*","Resource":["arn:aws:s3:::b","arn:aws:s3:::b/*"]}]}`
+
+	bucketPolicies := []string{"", otherPolicy, publicPolicy, denyPolicy}
+	bucketAcls := []string{"private", "public-read", "public-read-write", "authenticated-read"}
+	objectAcls := []string{"private", "public-read", "authenticated-read", "bucket-owner-read",
+		"bucket-owner-full-control"}
+	objectOwnerships := []string{"", datatype.ObjectOwnershipBucketOwnerEnforced}
+	configs := []datatype.PublicAccessBlockConfiguration{
+		{},
+		{IgnorePublicAcls: true},
+		{RestrictPublicBuckets: true},
+	}
+	credentials := []common.Credential{
+		{UserId: "owner"},
+		{UserId: "writer"},
+		{UserId: "other"},
+		{},
+	}
+	actions := []policy.Action{policy.GetObjectAction, policy.ListBucketAction,
+		policy.DeleteObjectAction, policy.GetObjectTaggingAction}
+
+	for _, bucketPolicy := range bucketPolicies {
+		for _, bucketAcl := range bucketAcls {
+			for _, objectAcl := range objectAcls {
+				for _, objectOwnership := range objectOwnerships {
+					for _, config := range configs {
+						bucket := &types.Bucket{Name: "b", OwnerId: "owner", Versioning: types.VersionDisabled,
+							ACL:               datatype.Acl{CannedAcl: bucketAcl},
+							OwnershipControls: ownershipControlsOf(objectOwnership)}
+						if bucketPolicy != "" {
+							p, err := policy.ParseConfig(strings.NewReader(bucketPolicy), "b")
+							if err != nil {
+								t.Fatal("Invalid bucket policy:", err)
+							}
+							bucket.Policy = *p
+						}
+						// uploaded by writer, so the bucket owner doesn't own it unless ACLs are disabled
+						object := &types.Object{BucketName: "b", Name: "o", OwnerId: "writer",
+							ACL: datatype.Acl{CannedAcl: objectAcl}}
+						yig := newTestStorage(&fakeListClient{fakeObjectClient{bucket: bucket, object: object}})
+
+						for _, credential := range credentials {
+							for _, action := range actions {
+								objectName := "o"
+								if action == policy.ListBucketAction {
+									objectName = ""
+								}
+								explanation := api.ExplainAccess(credential, bucket, object, config,
+									action, objectName, nil)
+								allowed := accessOf(yig, credential, bucket, config, action)
+								if explanation.Allowed != allowed {
+									t.Errorf("%s by %q with bucket ACL %s, object ACL %s, Object Ownership %q, "+
+										"%+v and bucket policy %s is explained as allowed %v (%s), but allowed %v",
+										action, credential.UserId, bucketAcl, objectAcl, objectOwnership,
+										config, bucketPolicy, explanation.Allowed, explanation.Reason, allowed)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExplainPrivateObjectOfWriter(t *testing.T) {
+	bucket := &types.Bucket{Name: "b", OwnerId: "owner", ACL: datatype.Acl{CannedAcl: "private"}}
+	object := &types.Object{BucketName: "b", Name: "o", OwnerId: "writer",
+		ACL: datatype.Acl{CannedAcl: "private"}}
+	yig := newTestStorage(&fakeObjectClient{bucket: bucket, object: object})
+
+	// the bucket owner can't read private objects uploaded by others
+	_, err := yig.GetObjectInfo("b", "o", "", common.Credential{UserId: "owner"})
+	if err != ErrAccessDenied {
+		t.Fatal("GetObjectInfo by the bucket owner:", err)
+	}
+	explanation := api.ExplainAccess(common.Credential{UserId: "owner"}, bucket, object,
+		datatype.PublicAccessBlockConfiguration{}, policy.GetObjectAction, "o", nil)
+	if explanation.Allowed || explanation.Reason != "not allowed by object ACL private" {
+		t.Fatalf("GetObject by the bucket owner is explained as %+v", explanation)
+	}
+}
+
+func TestDeleteObjectAccess(t *testing.T) {
+	bucket := &types.Bucket{Name: "b", OwnerId: "owner", Versioning: types.VersionDisabled,
+		ACL: datatype.Acl{CannedAcl: "private"}}
+	yig := newTestStorage(&fakeListClient{fakeObjectClient{bucket: bucket}})
+
+	testCases := []struct {
+		credential common.Credential
+		err        error
+	}{
+		{common.Credential{UserId: "owner"}, nil},
+		{common.Credential{UserId: "other"}, ErrBucketAccessForbidden},
+		// granted s3:DeleteObject by bucket policy
+		{common.Credential{UserId: "other", AllowOtherUserAccess: true}, nil},
+		// anonymous deletes are not checked by ACL
+		{common.Credential{}, nil},
+	}
+	for _, testCase := range testCases {
+		_, err := yig.DeleteObject("b", "o", "", false, testCase.credential)
+		if err != testCase.err {
+			t.Errorf("DeleteObject by %q permitted by bucket policy %v: %v, expected %v",
+				testCase.credential.UserId, testCase.credential.AllowOtherUserAccess, err, testCase.err)
+		}
+	}
+}
+
+func TestIgnorePublicAcls(t *testing.T) {
+	config := datatype.PublicAccessBlockConfiguration{IgnorePublicAcls: true}
+	testCases := []struct {
+		bucketAcl  string
+		objectAcl  string
+		credential common.Credential
+		action     policy.Action
+		allowed    bool
+	}{
+		// non-public ACLs still grant access
+		{"private", "private", common.Credential{UserId: "writer"}, policy.GetObjectAction, true},
+		{"private", "bucket-owner-read", common.Credential{UserId: "owner"}, policy.GetObjectAction, true},
+		{"private", "private", common.Credential{UserId: "writer"}, policy.GetObjectTaggingAction, true},
+		{"private", "private", common.Credential{UserId: "owner"}, policy.ListBucketAction, true},
+		// public ACLs grant nothing
+		{"private", "public-read", common.Credential{UserId: "other"}, policy.GetObjectAction, false},
+		{"private", "authenticated-read", common.Credential{UserId: "other"}, policy.GetObjectAction, false},
+		{"public-read", "private", common.Credential{UserId: "other"}, policy.ListBucketAction, false},
+		{"public-read-write", "private", common.Credential{UserId: "other"}, policy.DeleteObjectAction, false},
+		{"private", "private", common.Credential{}, policy.DeleteObjectAction, false},
+	}
+	for _, testCase := range testCases {
+		bucket := &types.Bucket{Name: "b", OwnerId: "owner", Versioning: types.VersionDisabled,
+			ACL: datatype.Acl{CannedAcl: testCase.bucketAcl}}
+		object := &types.Object{BucketName: "b", Name: "o", OwnerId: "writer",
+			ACL: datatype.Acl{CannedAcl: testCase.objectAcl}}
+		yig := newTestStorage(&fakeListClient{fakeObjectClient{bucket: bucket, object: object}})
+		if allowed := accessOf(yig, testCase.credential, bucket, config, testCase.action); allowed != testCase.allowed {
+			t.Errorf("%s by %q with bucket ACL %s and object ACL %s ignoring public ACLs is allowed %v",
+				testCase.action, testCase.credential.UserId, testCase.bucketAcl, testCase.objectAcl, allowed)
+		}
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/journeymidnight/yig/meta"
 	"github.com/journeymidnight/yig/meta/types"
 	"github.com/journeymidnight/yig/redis"
-	"github.com/journeymidnight/yig/signature"
 	"io"
 	"sync"
 	"time"
@@ -33,6 +32,11 @@ func New(metaCacheType int, enableDataCache bool, kms crypto.KMS) *YigStorage {
 	if len(yig.DataStorage) == 0 {
 		panic("No data storage can be used!")
 	}
+	keyring, err := crypto.LoadKeyring(kms)
+	if err != nil {
+		panic("Failed to load SSE master keys: " + err.Error())
+	}
+	yig.Keyring = keyring
 
 	initializeRecycler(&yig)
 	return &yig
@@ -44,7 +48,7 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 	sseRequest datatype.SseRequest, storageClass types.StorageClass, objInfo *types.Object) (result datatype.AppendObjectResult, err error) {
 
 	defer data.Close()
-	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(sseRequest, bucketName, objectName)
+	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(&sseRequest, bucketName, objectName)
 	helper.Logger.Println(10, "get encryptionKey:", encryptionKey, "cipherKey:", cipherKey, "err:", err)
 	if err != nil {
 		return
@@ -53,6 +57,18 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 	//TODO: Append Support Encryption
 	encryptionKey = nil
 
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	var newObjects int64
+	if objInfo == nil {
+		newObjects = 1
+	}
+	if err = yig.checkQuota(bucket, size, newObjects); err != nil {
+		return
+	}
+
 	md5Writer := md5.New()
 
 	// Limit the reader to its provided size if specified.
@@ -107,6 +123,10 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 	if int64(bytesWritten) < size {
 		return result, ErrIncompleteBody
 	}
+	// appended data is left unreferenced by metadata, as other failures do
+	if err = yig.checkWrittenQuota(bucket, size, int64(bytesWritten), newObjects); err != nil {
+		return
+	}
 
 	calculatedMd5 := hex.EncodeToString(md5Writer.Sum(nil))
 	if userMd5, ok := metadata["md5Sum"]; ok {
@@ -117,11 +137,10 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 
 	result.Md5 = calculatedMd5
 
-	if signVerifyReader, ok := data.(*signature.SignVerifyReadCloser); ok {
-		credential, err = signVerifyReader.Verify()
-		if err != nil {
-			return
-		}
+	// checksum of appended data is verified, but not kept for the whole object
+	credential, _, err = verifyUpload(data, credential)
+	if err != nil {
+		return
 	}
 
 	// TODO validate bucket policy and fancy ACL
@@ -130,7 +149,7 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 		BucketName:           bucketName,
 		Location:             cephCluster.ID(),
 		Pool:                 poolName,
-		OwnerId:              credential.UserId,
+		OwnerId:              objectOwnerOf(bucket, acl, credential.UserId),
 		Size:                 objSize + int64(bytesWritten),
 		ObjectId:             oid,
 		LastModifiedTime:     time.Now().UTC(),
@@ -151,7 +170,7 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 	result.NextPosition = object.Size
 	helper.Logger.Println(20, "Append info.", "bucket:", bucketName, "objName:", objectName, "oid:", oid,
 		"objSize:", object.Size, "bytesWritten:", bytesWritten, "storageClass:", storageClass)
-	err = yig.MetaStorage.AppendObject(object, objInfo != nil)
+	err = yig.MetaStorage.AppendObject(object, objInfo != nil, int64(bytesWritten))
 	if err != nil {
 		return
 	}
@@ -160,5 +179,6 @@ func (yig *YigStorage) AppendObject(bucketName string, objectName string, creden
 		yig.MetaStorage.Cache.Remove(redis.ObjectTable, bucketName+":"+objectName+":")
 		yig.DataCache.Remove(bucketName + ":" + objectName + ":" + object.GetVersionId())
 	}
+	yig.chargeQuota(bucket, int64(bytesWritten), newObjects)
 	return result, nil
 }
@@ -18,7 +18,7 @@ import (
 	"github.com/journeymidnight/yig/redis"
 )
 
-func (yig *YigStorage) MakeBucket(bucketName string, acl datatype.Acl,
+func (yig *YigStorage) MakeBucket(bucketName string, acl datatype.Acl, objectLockEnabled bool,
 	credential common.Credential) error {
 	// Input validation.
 	if err := api.CheckValidBucketName(bucketName); err != nil {
@@ -33,6 +33,15 @@ func (yig *YigStorage) MakeBucket(bucketName string, acl datatype.Acl,
 		ACL:        acl,
 		Versioning: meta.VersionDisabled, // it's the default
 	}
+	// Object Lock could only be enabled on versioned buckets
+	if objectLockEnabled {
+		bucket.Versioning = meta.VersionEnabled
+		bucket.ObjectLock.ObjectLockEnabled = datatype.ObjectLockEnabled
+	}
+	// new buckets are subject to account settings of the owner
+	if err := yig.checkPublicAcl(&bucket, acl); err != nil {
+		return err
+	}
 	processed, err := yig.MetaStorage.Client.CheckAndPutBucket(bucket)
 	if err != nil {
 		helper.Logger.Error("Error making CheckAndPut:", err)
@@ -82,6 +91,12 @@ func (yig *YigStorage) SetBucketAcl(bucketName string, policy datatype.AccessCon
 	if bucket.OwnerId != credential.UserId {
 		return ErrBucketAccessForbidden
 	}
+	if bucket.OwnershipControls.AclsDisabled() {
+		return ErrAccessControlListNotSupported
+	}
+	if err = yig.checkPublicAcl(bucket, acl); err != nil {
+		return err
+	}
 	bucket.ACL = acl
 	err = yig.MetaStorage.Client.PutBucket(*bucket)
 	if err != nil {
@@ -99,6 +114,23 @@ func (yig *YigStorage) SetBucketLogging(bucketName string, bl datatype.BucketLog
 	if err != nil {
 		return err
 	}
+	if bl.Enabled() {
+		// logs are delivered to buckets of the same owner, or granting the log delivery service
+		target, err := yig.MetaStorage.GetBucket(bl.LoggingEnabled.TargetBucket, true)
+		if err == ErrNoSuchBucket {
+			return ErrInvalidTargetBucketForLogging
+		}
+		if err != nil {
+			return err
+		}
+		config, err := yig.GetEffectivePublicAccessBlock(target)
+		if err != nil {
+			return err
+		}
+		if !target.AllowsLogDelivery(bucket.OwnerId, bl.LoggingEnabled.TargetPrefix, config) {
+			return ErrInvalidTargetBucketForLogging
+		}
+	}
 	bucket.BucketLogging = bl
 	err = yig.MetaStorage.Client.PutBucket(*bucket)
 	if err != nil {
@@ -130,6 +162,10 @@ func (yig *YigStorage) SetBucketLifecycle(bucketName string, lc datatype.Lifecyc
 	if bucket.OwnerId != credential.UserId {
 		return ErrBucketAccessForbidden
 	}
+	err = lc.Validate()
+	if err != nil {
+		return err
+	}
 	bucket.Lifecycle = lc
 	err = yig.MetaStorage.Client.PutBucket(*bucket)
 	if err != nil {
@@ -256,6 +292,14 @@ func (yig *YigStorage) SetBucketVersioning(bucketName string, versioning datatyp
 	if bucket.OwnerId != credential.UserId {
 		return ErrBucketAccessForbidden
 	}
+	// Versioning could not be suspended once replication is configured
+	if len(bucket.Replication.Rules) != 0 && versioning.Status != meta.VersionEnabled {
+		return ErrReplicationVersioningRequired
+	}
+	// Versioning could not be suspended once Object Lock is enabled
+	if bucket.ObjectLock.Enabled() && versioning.Status != meta.VersionEnabled {
+		return ErrObjectLockVersioningRequired
+	}
 	bucket.Versioning = versioning.Status
 	err = yig.MetaStorage.Client.PutBucket(*bucket)
 	if err != nil {
@@ -317,7 +361,10 @@ func (yig *YigStorage) GetBucketInfo(bucketName string,
 		if bucket.OwnerId != credential.UserId {
 			switch bucket.ACL.CannedAcl {
 			case "public-read", "public-read-write", "authenticated-read":
-				break
+				if credential.IgnorePublicAcls {
+					err = ErrBucketAccessForbidden
+					return
+				}
 			default:
 				err = ErrBucketAccessForbidden
 				return
@@ -339,7 +386,10 @@ func (yig *YigStorage) GetBucketInfoByCtx(ctx api.RequestContext,
 		if bucket.OwnerId != credential.UserId {
 			switch bucket.ACL.CannedAcl {
 			case "public-read", "public-read-write", "authenticated-read":
-				break
+				if credential.IgnorePublicAcls {
+					err = ErrBucketAccessForbidden
+					return
+				}
 			default:
 				err = ErrBucketAccessForbidden
 				return
@@ -358,6 +408,9 @@ func (yig *YigStorage) SetBucketPolicy(credential common.Credential, bucketName
 	if bucket.OwnerId != credential.UserId {
 		return ErrBucketAccessForbidden
 	}
+	if err = yig.checkPublicPolicy(bucket, bucketPolicy); err != nil {
+		return err
+	}
 	data, err := bucketPolicy.MarshalJSON()
 	if err != nil {
 		return
@@ -491,15 +544,94 @@ func (yig *YigStorage) CheckBucketEncryption(bucketName string) (*datatype.Apply
 		return nil, false
 	}
 	configuration := bucketEncryption.Rules[0].ApplyServerSideEncryptionByDefault
-	if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 {
+	if configuration.SSEAlgorithm == crypto.SSEAlgorithmAES256 ||
+		configuration.SSEAlgorithm == crypto.SSEAlgorithmKMS {
 		return configuration, true
 	}
-	//if bucketEncryption.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm == crypto.SSEAlgorithmKMS {
-	//	return configuration, nil
-	//}
 	return nil, false
 }
 
+func (yig *YigStorage) SetBucketTagging(bucket *meta.Bucket, tags map[string]string) (err error) {
+	bucket.Tags = tags
+	err = yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketTagging(bucketName string) (tags map[string]string, err error) {
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if len(bucket.Tags) == 0 {
+		return nil, ErrNoSuchTagSet
+	}
+	return bucket.Tags, nil
+}
+
+func (yig *YigStorage) DeleteBucketTagging(bucket *meta.Bucket) error {
+	return yig.SetBucketTagging(bucket, nil)
+}
+
+func (yig *YigStorage) SetBucketNotification(bucket *meta.Bucket,
+	config datatype.NotificationConfiguration) (err error) {
+
+	bucket.Notification = config
+	err = yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketNotification(bucketName string) (config datatype.NotificationConfiguration, err error) {
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	return bucket.Notification, nil
+}
+
+func (yig *YigStorage) SetBucketReplication(bucket *meta.Bucket,
+	config datatype.ReplicationConfiguration) (err error) {
+
+	if bucket.Versioning != meta.VersionEnabled {
+		return ErrReplicationVersioningRequired
+	}
+	bucket.Replication = config
+	err = yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketReplication(bucketName string) (config datatype.ReplicationConfiguration, err error) {
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if len(bucket.Replication.Rules) == 0 {
+		return config, ErrReplicationConfigurationNotFound
+	}
+	return bucket.Replication, nil
+}
+
+func (yig *YigStorage) DeleteBucketReplication(bucket *meta.Bucket) error {
+	bucket.Replication = datatype.ReplicationConfiguration{}
+	err := yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
 func (yig *YigStorage) ListBuckets(credential common.Credential) (buckets []meta.Bucket, err error) {
 	bucketNames, err := yig.MetaStorage.GetUserBuckets(credential.UserId, true)
 	if err != nil {
@@ -565,6 +697,10 @@ func (yig *YigStorage) DeleteBucket(bucketName string, credential common.Credent
 			helper.Logger.Warn("Remove bucket from lifeCycle error:", err)
 		}
 	}
+	err = yig.MetaStorage.Client.DeleteBucketInventories(bucketName)
+	if err != nil {
+		helper.Logger.Warn("Remove inventory configurations of bucket error:", err)
+	}
 
 	return nil
 }
@@ -606,19 +742,9 @@ func (yig *YigStorage) ListObjects(credential common.Credential, bucketName stri
 		return
 	}
 
-	switch bucket.ACL.CannedAcl {
-	case "public-read", "public-read-write":
-		break
-	case "authenticated-read":
-		if credential.UserId == "" {
-			err = ErrBucketAccessForbidden
-			return
-		}
-	default:
-		if bucket.OwnerId != credential.UserId {
-			err = ErrBucketAccessForbidden
-			return
-		}
+	if !credential.AllowOtherUserAccess && !bucket.ReadAccess(credential).Allowed {
+		err = ErrBucketAccessForbidden
+		return
 	}
 	// TODO validate user policy and ACL
 
@@ -680,19 +806,9 @@ func (yig *YigStorage) ListVersionedObjects(credential common.Credential, bucket
 		return
 	}
 
-	switch bucket.ACL.CannedAcl {
-	case "public-read", "public-read-write":
-		break
-	case "authenticated-read":
-		if credential.UserId == "" {
-			err = ErrBucketAccessForbidden
-			return
-		}
-	default:
-		if bucket.OwnerId != credential.UserId {
-			err = ErrBucketAccessForbidden
-			return
-		}
+	if !credential.AllowOtherUserAccess && !bucket.ReadAccess(credential).Allowed {
+		err = ErrBucketAccessForbidden
+		return
 	}
 
 	retObjects, prefixes, truncated, nextMarker, nextVerIdMarker, err := yig.ListObjectsInternal(bucketName, request)
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/signature"
+)
+
+// withChecksum makes sure parts of multipart uploads with checksum algorithm
+// are checksummed, even if x-amz-checksum-* headers are not sent
+func withChecksum(data io.ReadCloser, algorithm string) (io.ReadCloser, error) {
+	if checksumReader, ok := data.(*signature.ChecksumVerifyReadCloser); ok {
+		if algorithm != "" && checksumReader.Checksum.Algorithm != algorithm {
+			return nil, ErrInvalidChecksum
+		}
+		return data, nil
+	}
+	if algorithm == "" {
+		return data, nil
+	}
+	return signature.NewChecksumVerify(data, datatype.Checksum{Algorithm: algorithm}), nil
+}
+
+// verifyUpload verifies checksum and signature of data after it's read,
+// returns the verified credential and calculated checksum if any
+func verifyUpload(data io.ReadCloser, credential common.Credential) (common.Credential,
+	datatype.Checksum, error) {
+
+	var checksum datatype.Checksum
+	var err error
+	if checksumReader, ok := data.(*signature.ChecksumVerifyReadCloser); ok {
+		checksum, err = checksumReader.Verify()
+		if err != nil {
+			return credential, checksum, err
+		}
+		data = checksumReader.Unwrap()
+	}
+	if signVerifyReader, ok := data.(*signature.SignVerifyReadCloser); ok {
+		credential, err = signVerifyReader.Verify()
+		if err != nil {
+			return credential, checksum, err
+		}
+	}
+	return credential, checksum, nil
+}
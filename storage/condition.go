@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+// checkWriteCondition fails conditional writes early, before data is uploaded.
+// The condition is checked again when object is put, see Meta.PutObjectIf
+func (yig *YigStorage) checkWriteCondition(bucket *meta.Bucket, objectName string,
+	condition datatype.WriteCondition) error {
+
+	if !condition.IsSet() {
+		return nil
+	}
+	// bypass cache to get the latest version
+	current, err := yig.MetaStorage.Client.GetObject(bucket.Name, objectName, "")
+	if err != nil && err != ErrNoSuchKey {
+		return err
+	}
+	exists, etag := false, ""
+	if err == nil && !current.DeleteMarker {
+		exists, etag = true, current.Etag
+	}
+	if !condition.Satisfied(exists, etag) {
+		return ErrPreconditionFailed
+	}
+	// replaced object is removed when put, so fail early if it's locked
+	if err == nil && current.NullVersion && bucket.Versioning != meta.VersionEnabled {
+		return checkObjectLock(current, false)
+	}
+	return nil
+}
+
+// cleanReplacedObjects cleans up after objects replaced by a conditional write,
+// which are removed in the same transaction as the put, see Meta.PutObjectIf
+func (yig *YigStorage) cleanReplacedObjects(bucket *meta.Bucket, replaced []*meta.Object) {
+	for _, obj := range replaced {
+		yig.dischargeQuota(obj)
+		yig.DataCache.Remove(obj.BucketName + ":" + obj.Name + ":" + obj.GetVersionId())
+		// restored copy is kept with other versions of suspended buckets
+		if bucket.Versioning == meta.VersionSuspended || obj.StorageClass != meta.ObjectStorageClassGlacier {
+			continue
+		}
+		freezer, err := yig.GetFreezer(obj.BucketName, obj.Name, "")
+		if err == nil && freezer.Name == obj.Name {
+			err = yig.MetaStorage.DeleteFreezer(freezer)
+		}
+		if err != nil && err != ErrNoSuchKey {
+			helper.Logger.Error("Delete freezer of replaced object", obj.BucketName, obj.Name,
+				"err:", err)
+		}
+	}
+}
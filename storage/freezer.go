@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"time"
+
+	. "github.com/journeymidnight/yig/error"
 	meta "github.com/journeymidnight/yig/meta/types"
 )
 
@@ -36,3 +39,102 @@ func (yig *YigStorage) UpdateFreezerDate(freezer *meta.Freezer, date int, isIncr
 	freezer.LifeTime = lifeTime
 	return yig.MetaStorage.UpdateFreezerDate(freezer)
 }
+
+// copyFromGlacier copies data of a GLACIER object in Ceph, including parts of multipart
+// objects, into a readable pool. Data is copied as it is, so SSE keys of the object
+// still work with the restored copy.
+func (yig *YigStorage) copyFromGlacier(object *meta.Object, freezer *meta.Freezer) (err error) {
+	source, err := yig.GetClusterByFsName(object.Location)
+	if err != nil {
+		return
+	}
+	cluster, poolName := yig.pickClusterAndPool(object.BucketName, object.Name,
+		meta.ObjectStorageClassStandard, object.Size, false)
+	if cluster == nil {
+		return ErrInternalError
+	}
+	freezer.Location = cluster.ID()
+	freezer.Pool = poolName
+	freezer.OwnerId = object.OwnerId
+	freezer.Size = object.Size
+	freezer.Etag = object.Etag
+	freezer.ObjectId = ""
+	freezer.Parts = make(map[int]*meta.Part)
+	if len(object.Parts) == 0 {
+		freezer.ObjectId, err = copyObjectData(source, object.Pool, object.ObjectId, object.Size, cluster, poolName)
+		return
+	}
+	for number, part := range object.Parts {
+		restored := *part
+		restored.ObjectId, err = copyObjectData(source, object.Pool, part.ObjectId, part.Size, cluster, poolName)
+		if err != nil {
+			yig.removeRestoredCopy(freezer)
+			return
+		}
+		freezer.Parts[number] = &restored
+	}
+	return nil
+}
+
+func (yig *YigStorage) removeRestoredCopy(freezer *meta.Freezer) {
+	cluster, ok := yig.DataStorage[freezer.Location]
+	if !ok {
+		return
+	}
+	if freezer.ObjectId != "" {
+		cluster.Remove(freezer.Pool, freezer.ObjectId)
+	}
+	for _, part := range freezer.Parts {
+		cluster.Remove(freezer.Pool, part.ObjectId)
+	}
+}
+
+// RestoreFreezer processes a restore request: it's marked RESTORING, data of the
+// object is copied out of GLACIER pool, and it's marked FINISH with the restored copy.
+// Requests of objects no longer in GLACIER are removed.
+func (yig *YigStorage) RestoreFreezer(freezer *meta.Freezer) (err error) {
+	// a timed-out RESTORING freezer may be scanned by several workers,
+	// only the one changing its modified time restores it
+	started := time.Now().UTC()
+	claimed, err := yig.MetaStorage.UpdateFreezerStatus(freezer, meta.ObjectRestoring, started)
+	if err != nil || !claimed {
+		// processed by others
+		return
+	}
+	freezer.Status = meta.ObjectRestoring
+	freezer.LastModifiedTime = started
+
+	object, err := yig.MetaStorage.GetObject(freezer.BucketName, freezer.Name, false)
+	if err == ErrNoSuchKey || (err == nil && (object.DeleteMarker ||
+		object.StorageClass != meta.ObjectStorageClassGlacier)) {
+		return yig.MetaStorage.Client.DeleteFreezer(freezer.BucketName, freezer.Name, nil)
+	} else if err != nil {
+		return
+	}
+
+	err = yig.copyFromGlacier(object, freezer)
+	if err != nil {
+		return
+	}
+	finished, err := yig.MetaStorage.FinishFreezer(freezer, time.Now().UTC())
+	if err != nil || !finished {
+		yig.removeRestoredCopy(freezer)
+		return
+	}
+	yig.NotifyObjectRestoreCompleted(object)
+	return nil
+}
+
+// ExpireFreezer removes the restored copy after its life time, data is removed by gc
+func (yig *YigStorage) ExpireFreezer(freezer *meta.Freezer) error {
+	// parts of the restored copy are needed by gc
+	restored, err := yig.MetaStorage.GetFreezer(freezer.BucketName, freezer.Name, freezer.VersionId)
+	if err != nil {
+		return err
+	}
+	if restored.Status != meta.ObjectHasRestored || restored.ExpireTime().After(time.Now()) {
+		// restored again after scanned
+		return nil
+	}
+	return yig.MetaStorage.DeleteFreezer(restored)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+// SetBucketInventory creates or replaces an inventory configuration of bucket,
+// reports are written with permission of bucket owner, so the destination
+// bucket must be owned by the same user
+func (yig *YigStorage) SetBucketInventory(bucket *meta.Bucket, config datatype.InventoryConfiguration) error {
+	destination, err := yig.MetaStorage.GetBucket(config.Destination.S3BucketDestination.DestinationBucket(), true)
+	if err == ErrNoSuchBucket {
+		return ErrInvalidInventoryDestination
+	}
+	if err != nil {
+		return err
+	}
+	if destination.OwnerId != bucket.OwnerId {
+		return ErrInvalidInventoryDestination
+	}
+
+	_, err = yig.MetaStorage.Client.GetInventory(bucket.Name, config.Id)
+	if err == ErrNoSuchInventoryConfiguration {
+		inventories, err := yig.MetaStorage.Client.ListInventories(bucket.Name, "",
+			datatype.MaxInventoryConfigurations)
+		if err != nil {
+			return err
+		}
+		if len(inventories) >= datatype.MaxInventoryConfigurations {
+			return ErrTooManyInventoryConfigurations
+		}
+	} else if err != nil {
+		return err
+	}
+	return yig.MetaStorage.Client.PutInventory(meta.Inventory{
+		BucketName: bucket.Name,
+		Id:         config.Id,
+		Config:     config,
+	})
+}
+
+func (yig *YigStorage) GetBucketInventory(bucketName, id string) (config datatype.InventoryConfiguration, err error) {
+	inventory, err := yig.MetaStorage.Client.GetInventory(bucketName, id)
+	if err != nil {
+		return
+	}
+	return inventory.Config, nil
+}
+
+// ListBucketInventory returns configurations page by page, the continuation
+// token is the last id of previous page
+func (yig *YigStorage) ListBucketInventory(bucketName,
+	continuationToken string) (result datatype.ListInventoryConfigurationsResult, err error) {
+
+	inventories, err := yig.MetaStorage.Client.ListInventories(bucketName, continuationToken,
+		datatype.MaxInventoryListSize+1)
+	if err != nil {
+		return
+	}
+	result.ContinuationToken = continuationToken
+	if len(inventories) > datatype.MaxInventoryListSize {
+		inventories = inventories[:datatype.MaxInventoryListSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = inventories[len(inventories)-1].Id
+	}
+	for _, inventory := range inventories {
+		result.InventoryConfiguration = append(result.InventoryConfiguration, inventory.Config)
+	}
+	return result, nil
+}
+
+func (yig *YigStorage) DeleteBucketInventory(bucket *meta.Bucket, id string) error {
+	_, err := yig.MetaStorage.Client.GetInventory(bucket.Name, id)
+	if err != nil {
+		return err
+	}
+	return yig.MetaStorage.Client.DeleteInventory(bucket.Name, id)
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+// TransitObject changes storage class of object by lifecycle transitions. Data of the
+// object is copied as it is into the pool matches storageClass, and old data is sent
+// to gc. GLACIER objects are only readable by restoring, so they are never transited.
+func (yig *YigStorage) TransitObject(object *meta.Object, storageClass meta.StorageClass) (err error) {
+	if object.DeleteMarker || object.Type == meta.ObjectTypeAppendable ||
+		object.StorageClass == meta.ObjectStorageClassGlacier || object.StorageClass == storageClass {
+		return nil
+	}
+	source, err := yig.GetClusterByFsName(object.Location)
+	if err != nil {
+		return
+	}
+	cluster, poolName := yig.pickClusterAndPool(object.BucketName, object.Name,
+		storageClass, object.Size, false)
+	if cluster == nil {
+		return ErrInternalError
+	}
+
+	transited := *object
+	transited.StorageClass = storageClass
+	if object.Pool == poolName {
+		// data stays where it is
+		err = yig.MetaStorage.Client.UpdateObject(&transited, nil)
+		if err != nil {
+			return
+		}
+		yig.removeObjectCache(object)
+		return nil
+	}
+
+	transited.Location = cluster.ID()
+	transited.Pool = poolName
+	transited.ObjectId = ""
+	if len(object.Parts) == 0 {
+		transited.ObjectId, err = copyObjectData(source, object.Pool, object.ObjectId, object.Size, cluster, poolName)
+		if err != nil {
+			return
+		}
+	} else {
+		transited.Parts = make(map[int]*meta.Part, len(object.Parts))
+		for number, part := range object.Parts {
+			p := *part
+			p.ObjectId, err = copyObjectData(source, object.Pool, part.ObjectId, part.Size, cluster, poolName)
+			if err != nil {
+				yig.removeTransitedData(&transited)
+				return
+			}
+			transited.Parts[number] = &p
+		}
+	}
+
+	// gc entries are unique by version, old data must not take
+	// the version of object, which is needed when object is deleted
+	oldObject := *object
+	oldObject.LastModifiedTime = time.Now().UTC()
+	ok, err := yig.MetaStorage.TransitObject(&transited, &oldObject)
+	if err != nil {
+		helper.Logger.Error("Transit object", object.BucketName, object.Name, "sql fails:", err)
+		yig.removeTransitedData(&transited)
+		return
+	}
+	if !ok {
+		// overwritten or transited by others during copying, old data is theirs
+		helper.Logger.Info("Transit object", object.BucketName, object.Name, "is changed by others")
+		yig.removeTransitedData(&transited)
+		return nil
+	}
+	yig.removeObjectCache(object)
+	return nil
+}
+
+// ExpireObjectVersion removes a version or delete marker listed from versioned bucket
+// by lifecycle rules, the same as removeObjectVersion. Locked versions are never removed.
+func (yig *YigStorage) ExpireObjectVersion(bucket *meta.Bucket, object *meta.Object) (err error) {
+	err = checkObjectLock(object, false)
+	if err != nil {
+		return
+	}
+	var objMap *meta.ObjMap
+	if object.NullVersion {
+		objMap = &meta.ObjMap{
+			Name:       object.Name,
+			BucketName: object.BucketName,
+		}
+	}
+	err = yig.removeByObject(object, objMap)
+	if err != nil {
+		return
+	}
+	yig.removeObjectCache(object)
+	yig.DataCache.Remove(object.BucketName + ":" + object.Name + ":")
+	yig.DataCache.Remove(object.BucketName + ":" + object.Name + ":" + object.GetVersionId())
+	yig.sendEventObjectNotification(bucket, datatype.ObjectRemovedDelete, datatype.EventObject{
+		Key:       url.QueryEscape(object.Name),
+		VersionId: object.GetVersionId(),
+	}, common.Credential{})
+	return nil
+}
+
+func (yig *YigStorage) removeTransitedData(object *meta.Object) {
+	cluster, ok := yig.DataStorage[object.Location]
+	if !ok {
+		return
+	}
+	if object.ObjectId != "" {
+		cluster.Remove(object.Pool, object.ObjectId)
+	}
+	for _, part := range object.Parts {
+		cluster.Remove(object.Pool, part.ObjectId)
+	}
+}
+
+func (yig *YigStorage) removeObjectCache(object *meta.Object) {
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":")
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":"+object.GetVersionId())
+}
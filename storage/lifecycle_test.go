@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/log"
+	"github.com/journeymidnight/yig/meta"
+	"github.com/journeymidnight/yig/meta/client"
+	"github.com/journeymidnight/yig/meta/types"
+	bus "github.com/journeymidnight/yig/mq"
+	"github.com/journeymidnight/yig/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMetaClient records deletions, methods not overridden are not expected to be called
+type fakeMetaClient struct {
+	client.Client
+	deleted []*types.Object
+	gc      []*types.Object
+}
+
+func (c *fakeMetaClient) NewTrans() (*sql.Tx, error)                    { return nil, nil }
+func (c *fakeMetaClient) AbortTrans(tx *sql.Tx) error                   { return nil }
+func (c *fakeMetaClient) CommitTrans(tx *sql.Tx) error                  { return nil }
+func (c *fakeMetaClient) DeleteObjectMap(*types.ObjMap, types.DB) error { return nil }
+func (c *fakeMetaClient) UpdateUsage(string, int64, int64, types.DB) error {
+	return nil
+}
+
+func (c *fakeMetaClient) DeleteObject(object *types.Object, tx types.DB) error {
+	c.deleted = append(c.deleted, object)
+	return nil
+}
+
+func (c *fakeMetaClient) PutObjectToGarbageCollection(object *types.Object, tx types.DB) error {
+	c.gc = append(c.gc, object)
+	return nil
+}
+
+type fakeMetaCache struct{}
+
+func (fakeMetaCache) Get(table redis.RedisDatabase, key string,
+	onCacheMiss func() (interface{}, error),
+	unmarshaller func([]byte) (interface{}, error), willNeed bool) (interface{}, error) {
+	return onCacheMiss()
+}
+
+func (fakeMetaCache) Remove(table redis.RedisDatabase, key string) {}
+
+func (fakeMetaCache) GetCacheHitRatio() float64 { return 0 }
+
+// fakeSender records messages sent to MQ plugin
+type fakeSender struct {
+	messages [][]byte
+}
+
+func (s *fakeSender) AsyncSend(value []byte) error {
+	s.messages = append(s.messages, value)
+	return nil
+}
+
+func (s *fakeSender) Flush(timeout int) error { return nil }
+
+func (s *fakeSender) Close() {}
+
+func newTestStorage(metaClient client.Client) *YigStorage {
+	helper.Logger = log.NewLogger(os.Stderr, log.ParseLevel("error"))
+	return &YigStorage{
+		DataCache:   newDataCache(false),
+		MetaStorage: &meta.Meta{Client: metaClient, Cache: fakeMetaCache{}},
+	}
+}
+
+func TestExpireObjectVersionNotifies(t *testing.T) {
+	metaClient := new(fakeMetaClient)
+	yig := newTestStorage(metaClient)
+	sender := new(fakeSender)
+	bus.Senders["fake"] = sender
+	defer delete(bus.Senders, "fake")
+
+	bucket := &types.Bucket{Name: "bucket", OwnerId: "owner"}
+	bucket.Notification.QueueConfigurations = []datatype.QueueConfiguration{{
+		Id:     "expired",
+		Queue:  "arn:aws:sqs:region:owner:fake",
+		Events: []string{"s3:ObjectRemoved:*"},
+	}}
+	object := &types.Object{
+		BucketName: "bucket",
+		Name:       "expired/object",
+		VersionId:  "version",
+	}
+
+	err := yig.ExpireObjectVersion(bucket, object)
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.Object{object}, metaClient.deleted)
+	assert.Equal(t, []*types.Object{object}, metaClient.gc)
+	if assert.Len(t, sender.messages, 1) {
+		var records datatype.EventRecords
+		assert.Nil(t, json.Unmarshal(sender.messages[0], &records))
+		if assert.Len(t, records.Records, 1) {
+			record := records.Records[0]
+			assert.Equal(t, datatype.ObjectRemovedDelete, record.EventName)
+			assert.Equal(t, "expired", record.S3.ConfigurationId)
+			assert.Equal(t, "bucket", record.S3.Bucket.Name)
+			assert.Equal(t, "expired%2Fobject", record.S3.Object.Key)
+			assert.Equal(t, object.GetVersionId(), record.S3.Object.VersionId)
+		}
+	}
+}
+
+func TestExpireLockedObjectVersionNotNotified(t *testing.T) {
+	metaClient := new(fakeMetaClient)
+	yig := newTestStorage(metaClient)
+	sender := new(fakeSender)
+	bus.Senders["fake"] = sender
+	defer delete(bus.Senders, "fake")
+
+	bucket := &types.Bucket{Name: "bucket", OwnerId: "owner"}
+	bucket.Notification.QueueConfigurations = []datatype.QueueConfiguration{{
+		Queue:  "arn:aws:sqs:region:owner:fake",
+		Events: []string{"s3:ObjectRemoved:*"},
+	}}
+	object := &types.Object{BucketName: "bucket", Name: "locked", VersionId: "version"}
+	object.ObjectLock.LegalHold = datatype.LegalHoldOn
+
+	err := yig.ExpireObjectVersion(bucket, object)
+	assert.NotNil(t, err)
+	assert.Empty(t, metaClient.deleted)
+	assert.Empty(t, sender.messages)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
+	"hash"
 	"io"
 	"net/url"
 	"sort"
@@ -19,7 +20,6 @@ import (
 	"github.com/journeymidnight/yig/iam/common"
 	meta "github.com/journeymidnight/yig/meta/types"
 	"github.com/journeymidnight/yig/redis"
-	"github.com/journeymidnight/yig/signature"
 )
 
 const (
@@ -34,19 +34,9 @@ func (yig *YigStorage) ListMultipartUploads(credential common.Credential, bucket
 	if err != nil {
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read", "public-read-write":
-		break
-	case "authenticated-read":
-		if credential.UserId == "" {
-			err = ErrBucketAccessForbidden
-			return
-		}
-	default:
-		if bucket.OwnerId != credential.UserId {
-			err = ErrBucketAccessForbidden
-			return
-		}
+	if !credential.AllowOtherUserAccess && !bucket.ReadAccess(credential).Allowed {
+		err = ErrBucketAccessForbidden
+		return
 	}
 	// TODO policy and fancy ACL
 
@@ -83,22 +73,21 @@ func (yig *YigStorage) ListMultipartUploads(credential common.Credential, bucket
 }
 
 func (yig *YigStorage) NewMultipartUpload(credential common.Credential, bucketName, objectName string,
-	metadata map[string]string, acl datatype.Acl,
-	sseRequest datatype.SseRequest, storageClass meta.StorageClass) (uploadId string, err error) {
+	metadata map[string]string, acl datatype.Acl, tags map[string]string, lock datatype.ObjectLock,
+	sseRequest datatype.SseRequest, storageClass meta.StorageClass,
+	checksumAlgorithm string) (uploadId string, err error) {
 
 	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
 	if err != nil {
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return "", ErrBucketAccessForbidden
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		return "", ErrBucketAccessForbidden
 	}
 	// TODO policy and fancy ACL
+	if lock.IsSet() && !bucket.ObjectLock.Enabled() {
+		return "", ErrObjectLockNotEnabled
+	}
 
 	contentType, ok := metadata["Content-Type"]
 	if !ok {
@@ -108,17 +97,35 @@ func (yig *YigStorage) NewMultipartUpload(credential common.Credential, bucketNa
 	cephCluster, pool := yig.pickClusterAndPool(bucketName, objectName, storageClass, -1, false)
 	multipartMetadata := meta.MultipartMetadata{
 		InitiatorId:  credential.UserId,
-		OwnerId:      bucket.OwnerId,
+		OwnerId:      multipartOwnerOf(bucket, acl, credential.UserId),
 		ContentType:  contentType,
 		Location:     cephCluster.ID(),
 		Pool:         pool,
 		Acl:          acl,
 		SseRequest:   sseRequest,
 		Attrs:        metadata,
+		Tags:         tags,
+		ObjectLock:   lock,
 		StorageClass: storageClass,
+
+		ChecksumAlgorithm: checksumAlgorithm,
 	}
-	if sseRequest.Type == crypto.S3.String() {
-		multipartMetadata.EncryptionKey, multipartMetadata.CipherKey, err = yig.encryptionKeyFromSseRequest(sseRequest, bucketName, objectName)
+	if sseRequest.Type == crypto.S3.String() || sseRequest.Type == crypto.S3KMS.String() {
+		// key ID of SSE-KMS is resolved into metadata, so parts and the object share it
+		multipartMetadata.EncryptionKey, multipartMetadata.CipherKey, err =
+			yig.encryptionKeyFromSseRequest(&multipartMetadata.SseRequest, bucketName, objectName)
+		if err != nil {
+			return
+		}
+		// the data key is kept for parts, so both keys are sealed by keyring
+		multipartMetadata.KeyVersion = yig.Keyring.CurrentVersion()
+		multipartMetadata.EncryptionKey, err =
+			yig.Keyring.SealWith(multipartMetadata.KeyVersion, multipartMetadata.EncryptionKey)
+		if err != nil {
+			return
+		}
+		multipartMetadata.CipherKey, err =
+			yig.Keyring.SealWith(multipartMetadata.KeyVersion, multipartMetadata.CipherKey)
 		if err != nil {
 			return
 		}
@@ -151,10 +158,22 @@ func (yig *YigStorage) PutObjectPart(bucketName, objectName string, credential c
 		return
 	}
 
+	// parts are read and recorded by their sizes, so the sizes must be known
+	if size < 0 {
+		err = ErrMissingContentLength
+		return
+	}
 	if size > MAX_PART_SIZE {
 		err = ErrEntityTooLarge
 		return
 	}
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if err = yig.checkQuota(bucket, size, 0); err != nil {
+		return
+	}
 
 	var encryptionKey []byte
 	switch multipart.Metadata.SseRequest.Type {
@@ -166,10 +185,15 @@ func (yig *YigStorage) PutObjectPart(bucketName, objectName string, credential c
 			return
 		}
 		encryptionKey = sseRequest.SseCustomerKey
-	case crypto.S3.String():
-		encryptionKey = multipart.Metadata.EncryptionKey
-	case crypto.S3KMS.String():
-		err = ErrNotImplemented
+	case crypto.S3.String(), crypto.S3KMS.String():
+		encryptionKey, err = yig.Keyring.Unseal(multipart.Metadata.KeyVersion, multipart.Metadata.EncryptionKey)
+		if err != nil {
+			return
+		}
+	}
+
+	data, err = withChecksum(data, multipart.Metadata.ChecksumAlgorithm)
+	if err != nil {
 		return
 	}
 
@@ -218,27 +242,16 @@ func (yig *YigStorage) PutObjectPart(bucketName, objectName string, credential c
 		return
 	}
 
-	if signVerifyReader, ok := data.(*signature.SignVerifyReadCloser); ok {
-		credential, err = signVerifyReader.Verify()
-		if err != nil {
-			RecycleQueue <- maybeObjectToRecycle
-			return
-		}
-	}
-
-	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	var checksum datatype.Checksum
+	credential, checksum, err = verifyUpload(data, credential)
 	if err != nil {
 		RecycleQueue <- maybeObjectToRecycle
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			RecycleQueue <- maybeObjectToRecycle
-			return result, ErrBucketAccessForbidden
-		}
+
+	if !bucket.WriteAccess(credential).Allowed {
+		RecycleQueue <- maybeObjectToRecycle
+		return result, ErrBucketAccessForbidden
 	} // TODO policy and fancy ACL
 
 	part := meta.Part{
@@ -248,6 +261,7 @@ func (yig *YigStorage) PutObjectPart(bucketName, objectName string, credential c
 		Etag:                 calculatedMd5,
 		LastModified:         time.Now().UTC().Format(meta.CREATE_TIME_LAYOUT),
 		InitializationVector: initializationVector,
+		Checksum:             checksum.Value,
 	}
 	err = yig.MetaStorage.PutObjectPart(multipart, part)
 	if err != nil {
@@ -255,17 +269,24 @@ func (yig *YigStorage) PutObjectPart(bucketName, objectName string, credential c
 		return
 	}
 	// remove possible old object in Ceph
+	removedSize := int64(0)
 	if part, ok := multipart.Parts[partId]; ok {
+		removedSize = part.Size
 		RecycleQueue <- objectToRecycle{
 			location: multipart.Metadata.Location,
 			pool:     multipart.Metadata.Pool,
 			objectId: part.ObjectId,
 		}
 	}
+	yig.chargeQuota(bucket, size-removedSize, 0)
 
 	result.ETag = calculatedMd5
+	result.Checksum = checksum
 	result.SseType = sseRequest.Type
-	result.SseAwsKmsKeyIdBase64 = base64.StdEncoding.EncodeToString([]byte(sseRequest.SseAwsKmsKeyId))
+	if multipart.Metadata.SseRequest.Type == crypto.S3KMS.String() {
+		result.SseType = crypto.S3KMS.String()
+		result.SseAwsKmsKeyId = multipart.Metadata.SseRequest.SseAwsKmsKeyId
+	}
 	result.SseCustomerAlgorithm = sseRequest.SseCustomerAlgorithm
 	result.SseCustomerKeyMd5Base64 = base64.StdEncoding.EncodeToString(sseRequest.SseCustomerKey)
 	return result, nil
@@ -284,6 +305,13 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 		err = ErrEntityTooLarge
 		return
 	}
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if err = yig.checkQuota(bucket, size, 0); err != nil {
+		return
+	}
 
 	var encryptionKey []byte
 	switch multipart.Metadata.SseRequest.Type {
@@ -295,11 +323,11 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 			return
 		}
 		encryptionKey = sseRequest.SseCustomerKey
-	case crypto.S3.String():
-		encryptionKey = multipart.Metadata.EncryptionKey
-	case crypto.S3KMS.String():
-		err = ErrNotImplemented
-		return
+	case crypto.S3.String(), crypto.S3KMS.String():
+		encryptionKey, err = yig.Keyring.Unseal(multipart.Metadata.KeyVersion, multipart.Metadata.EncryptionKey)
+		if err != nil {
+			return
+		}
 	}
 
 	md5Writer := md5.New()
@@ -310,6 +338,12 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 		return
 	}
 	dataReader := io.TeeReader(limitedDataReader, md5Writer)
+	// copied parts are checksummed for composite checksum of the upload
+	var checksumWriter hash.Hash
+	if multipart.Metadata.ChecksumAlgorithm != "" {
+		checksumWriter = datatype.NewChecksumHash(multipart.Metadata.ChecksumAlgorithm)
+		dataReader = io.TeeReader(dataReader, checksumWriter)
+	}
 
 	var initializationVector []byte
 	if len(encryptionKey) != 0 {
@@ -343,21 +377,11 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 
 	result.Md5 = hex.EncodeToString(md5Writer.Sum(nil))
 
-	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
-	if err != nil {
+	if !bucket.WriteAccess(credential).Allowed {
 		RecycleQueue <- maybeObjectToRecycle
+		err = ErrBucketAccessForbidden
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			RecycleQueue <- maybeObjectToRecycle
-			err = ErrBucketAccessForbidden
-			return
-		}
-	} // TODO policy and fancy ACL
 
 	if initializationVector == nil {
 		initializationVector = []byte{}
@@ -371,7 +395,13 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 		LastModified:         now.Format(meta.CREATE_TIME_LAYOUT),
 		InitializationVector: initializationVector,
 	}
+	if checksumWriter != nil {
+		part.Checksum = base64.StdEncoding.EncodeToString(checksumWriter.Sum(nil))
+	}
 	result.LastModified = now
+	if multipart.Metadata.SseRequest.Type == crypto.S3KMS.String() {
+		result.SseAwsKmsKeyId = multipart.Metadata.SseRequest.SseAwsKmsKeyId
+	}
 
 	err = yig.MetaStorage.PutObjectPart(multipart, part)
 	if err != nil {
@@ -380,13 +410,16 @@ func (yig *YigStorage) CopyObjectPart(bucketName, objectName, uploadId string, p
 	}
 
 	// remove possible old object in Ceph
+	removedSize := int64(0)
 	if part, ok := multipart.Parts[partId]; ok {
+		removedSize = part.Size
 		RecycleQueue <- objectToRecycle{
 			location: multipart.Metadata.Location,
 			pool:     multipart.Metadata.Pool,
 			objectId: part.ObjectId,
 		}
 	}
+	yig.chargeQuota(bucket, size-removedSize, 0)
 
 	return result, nil
 }
@@ -402,15 +435,19 @@ func (yig *YigStorage) ListObjectParts(credential common.Credential, bucketName,
 	initiatorId := multipart.Metadata.InitiatorId
 	ownerId := multipart.Metadata.OwnerId
 
+	// public ACLs grant nothing but to the owner if ignored
 	switch multipart.Metadata.Acl.CannedAcl {
 	case "public-read", "public-read-write":
-		break
+		if credential.IgnorePublicAcls && ownerId != credential.UserId {
+			err = ErrAccessDenied
+			return
+		}
 	case "authenticated-read":
-		if credential.UserId == "" {
+		if credential.UserId == "" || (credential.IgnorePublicAcls && ownerId != credential.UserId) {
 			err = ErrAccessDenied
 			return
 		}
-	case "bucket-owner-read", "bucket-owner-full-controll":
+	case "bucket-owner-read", "bucket-owner-full-control":
 		var bucket *meta.Bucket
 		bucket, err = yig.MetaStorage.GetBucket(bucketName, true)
 		if err != nil {
@@ -433,6 +470,10 @@ func (yig *YigStorage) ListObjectParts(credential common.Credential, bucketName,
 				ETag:         "\"" + p.Etag + "\"",
 				LastModified: p.LastModified,
 				Size:         p.Size,
+				ObjectChecksum: datatype.NewObjectChecksum(datatype.Checksum{
+					Algorithm: multipart.Metadata.ChecksumAlgorithm,
+					Value:     p.Checksum,
+				}),
 			}
 			result.Parts = append(result.Parts, part)
 
@@ -465,6 +506,7 @@ func (yig *YigStorage) ListObjectParts(credential common.Credential, bucketName,
 	result.Key = objectName
 	result.UploadId = request.UploadId
 	result.StorageClass = multipart.Metadata.StorageClass.ToString()
+	result.ChecksumAlgorithm = multipart.Metadata.ChecksumAlgorithm
 	result.PartNumberMarker = request.PartNumberMarker
 	result.MaxParts = request.MaxParts
 	result.EncodingType = request.EncodingType
@@ -482,14 +524,9 @@ func (yig *YigStorage) AbortMultipartUpload(credential common.Credential,
 	if err != nil {
 		return err
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return ErrBucketAccessForbidden
-		}
-	} // TODO policy and fancy ACL
+	if !bucket.WriteAccess(credential).Allowed {
+		return ErrBucketAccessForbidden
+	}
 
 	multipart, err := yig.MetaStorage.GetMultipart(bucketName, objectName, uploadId)
 	if err != nil {
@@ -515,23 +552,25 @@ func (yig *YigStorage) AbortMultipartUpload(credential common.Credential,
 }
 
 func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, bucketName,
-	objectName, uploadId string, uploadedParts []meta.CompletePart) (result datatype.CompleteMultipartResult,
-	err error) {
+	objectName, uploadId string, uploadedParts []meta.CompletePart,
+	condition datatype.WriteCondition) (result datatype.CompleteMultipartResult, err error) {
 
 	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
 	if err != nil {
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			err = ErrBucketAccessForbidden
-			return
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		err = ErrBucketAccessForbidden
+		return
 	}
 	// TODO policy and fancy ACL
+	// bytes of parts are checked when uploaded
+	if err = yig.checkQuota(bucket, 0, 1); err != nil {
+		return
+	}
+	if err = yig.checkWriteCondition(bucket, objectName, condition); err != nil {
+		return
+	}
 
 	multipart, err := yig.MetaStorage.GetMultipart(bucketName, objectName, uploadId)
 	if err != nil {
@@ -540,6 +579,7 @@ func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, buc
 
 	md5Writer := md5.New()
 	var totalSize int64 = 0
+	var partChecksums []string
 	helper.Logger.Info("Upload parts:", uploadedParts, "uploadId:", uploadId)
 	for i := 0; i < len(uploadedParts); i++ {
 		if uploadedParts[i].PartNumber != i+1 {
@@ -578,6 +618,16 @@ func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, buc
 			err = ErrInvalidPart
 			return
 		}
+		if algorithm := multipart.Metadata.ChecksumAlgorithm; algorithm != "" {
+			requestChecksum := uploadedParts[i].Value(algorithm)
+			if part.Checksum == "" || (requestChecksum != "" && requestChecksum != part.Checksum) {
+				helper.Logger.Error("part checksum mismatch;", "i:", i, "checksum:", part.Checksum,
+					"reqChecksum:", requestChecksum, "uploadId:", uploadId)
+				err = ErrInvalidPart
+				return
+			}
+			partChecksums = append(partChecksums, part.Checksum)
+		}
 		part.Offset = totalSize
 		totalSize += part.Size
 		md5Writer.Write(etagBytes)
@@ -586,13 +636,19 @@ func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, buc
 	result.ETag += "-" + strconv.Itoa(len(uploadedParts))
 	// See http://stackoverflow.com/questions/12186993
 	// for how to calculate multipart Etag
+	if multipart.Metadata.ChecksumAlgorithm != "" {
+		result.Checksum, err = datatype.CompositeChecksum(multipart.Metadata.ChecksumAlgorithm, partChecksums)
+		if err != nil {
+			return
+		}
+	}
 
 	// Add to objects table
 	contentType := multipart.Metadata.ContentType
 	object := &meta.Object{
 		Name:             objectName,
 		BucketName:       bucketName,
-		OwnerId:          multipart.Metadata.OwnerId,
+		OwnerId:          completedOwnerOf(bucket, multipart),
 		Pool:             multipart.Metadata.Pool,
 		Location:         multipart.Metadata.Location,
 		Size:             totalSize,
@@ -605,16 +661,30 @@ func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, buc
 		DeleteMarker:     false,
 		SseType:          multipart.Metadata.SseRequest.Type,
 		EncryptionKey:    multipart.Metadata.CipherKey,
+		KeyVersion:       multipart.Metadata.KeyVersion,
+		SseKmsKeyId:      multipart.Metadata.SseRequest.SseAwsKmsKeyId,
+		SseContext:       multipart.Metadata.SseRequest.SseContext,
 		CustomAttributes: multipart.Metadata.Attrs,
+		Tags:             multipart.Metadata.Tags,
 		Type:             meta.ObjectTypeMultipart,
 		StorageClass:     multipart.Metadata.StorageClass,
+		Checksum:         result.Checksum,
 	}
-
-	var nullVerNum uint64
-	nullVerNum, err = yig.checkOldObject(bucketName, objectName, bucket.Versioning)
+	// default retention is counted from the time object is created
+	object.ObjectLock, err = objectLockOf(bucket, multipart.Metadata.ObjectLock, object.LastModifiedTime)
 	if err != nil {
 		return
 	}
+	object.ReplicationStatus = replicationStatusOf(bucket, object)
+
+	var nullVerNum uint64
+	// objects replaced by conditional writes are removed after put succeeds
+	if !condition.IsSet() || bucket.Versioning == meta.VersionEnabled {
+		nullVerNum, err = yig.checkOldObject(bucketName, objectName, bucket.Versioning)
+		if err != nil {
+			return
+		}
+	}
 	if bucket.Versioning == "Enabled" {
 		result.VersionId = object.GetVersionId()
 	}
@@ -640,21 +710,25 @@ func (yig *YigStorage) CompleteMultipartUpload(credential common.Credential, buc
 		}
 	}
 
+	var replaced []*meta.Object
 	if nullVerNum != 0 {
-		err = yig.MetaStorage.PutObject(object, &multipart, objMap, false)
+		replaced, err = yig.MetaStorage.PutObjectIf(object, &multipart, objMap, false, condition)
 	} else {
-		err = yig.MetaStorage.PutObject(object, &multipart, nil, false)
+		replaced, err = yig.MetaStorage.PutObjectIf(object, &multipart, nil, false, condition)
 	}
 
 	sseRequest := multipart.Metadata.SseRequest
 	result.SseType = sseRequest.Type
-	result.SseAwsKmsKeyIdBase64 = base64.StdEncoding.EncodeToString([]byte(sseRequest.SseAwsKmsKeyId))
+	result.SseAwsKmsKeyId = sseRequest.SseAwsKmsKeyId
 	result.SseCustomerAlgorithm = sseRequest.SseCustomerAlgorithm
 	result.SseCustomerKeyMd5Base64 = base64.StdEncoding.EncodeToString(sseRequest.SseCustomerKey)
 
 	if err == nil {
 		yig.MetaStorage.Cache.Remove(redis.ObjectTable, bucketName+":"+objectName+":")
 		yig.DataCache.Remove(bucketName + ":" + objectName + ":" + object.GetVersionId())
+		yig.cleanReplacedObjects(bucket, replaced)
+		yig.chargeQuota(bucket, 0, 1)
+		yig.sendNotification(bucket, datatype.ObjectCreatedCompleteMultipartUpload, object, credential)
 	}
 
 	return
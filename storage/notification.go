@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+	bus "github.com/journeymidnight/yig/mq"
+)
+
+const (
+	eventVersion       = "2.1"
+	eventSource        = "aws:s3"
+	eventSchemaVersion = "1.0"
+)
+
+func newEventObject(object *meta.Object) datatype.EventObject {
+	eventObject := datatype.EventObject{
+		Key:       url.QueryEscape(object.Name),
+		Size:      object.Size,
+		ETag:      object.Etag,
+		Sequencer: fmt.Sprintf("%016X", object.LastModifiedTime.UnixNano()),
+	}
+	if !object.NullVersion {
+		eventObject.VersionId = object.GetVersionId()
+	}
+	return eventObject
+}
+
+// NotifyObjectRestoreCompleted is called when a GLACIER object is restored and readable
+func (yig *YigStorage) NotifyObjectRestoreCompleted(object *meta.Object) {
+	bucket, err := yig.MetaStorage.GetBucket(object.BucketName, true)
+	if err != nil {
+		helper.Logger.Error("Failed to get bucket", object.BucketName, "for notification, err:", err)
+		return
+	}
+	yig.sendNotification(bucket, datatype.ObjectRestoreCompleted, object, common.Credential{})
+}
+
+func (yig *YigStorage) sendNotification(bucket *meta.Bucket, eventName string, object *meta.Object,
+	credential common.Credential) {
+
+	yig.sendEventObjectNotification(bucket, eventName, newEventObject(object), credential)
+}
+
+// sendEventObjectNotification sends an event record to all the MQ plugins
+// subscribing the event in bucket notification configuration.
+// Failures are only logged since the object operation has been committed.
+func (yig *YigStorage) sendEventObjectNotification(bucket *meta.Bucket, eventName string,
+	eventObject datatype.EventObject, credential common.Credential) {
+
+	objectName, _ := url.QueryUnescape(eventObject.Key)
+	targets := bucket.Notification.MatchedTargets(eventName, objectName)
+	if len(targets) == 0 {
+		return
+	}
+	if eventObject.Sequencer == "" {
+		eventObject.Sequencer = fmt.Sprintf("%016X", time.Now().UnixNano())
+	}
+	for _, target := range targets {
+		sender, ok := bus.GetSender(target.PluginName())
+		if !ok {
+			helper.Logger.Error("No message queue plugin", target.PluginName(),
+				"for notification of bucket", bucket.Name, "event", eventName, "is dropped")
+			continue
+		}
+		record := datatype.EventRecord{
+			EventVersion: eventVersion,
+			EventSource:  eventSource,
+			AwsRegion:    helper.CONFIG.Region,
+			EventTime:    time.Now().UTC().Format(meta.CREATE_TIME_LAYOUT),
+			EventName:    eventName,
+			UserIdentity: datatype.EventIdentity{PrincipalId: credential.UserId},
+			S3: datatype.EventS3{
+				SchemaVersion:   eventSchemaVersion,
+				ConfigurationId: target.Id,
+				Bucket: datatype.EventBucket{
+					Name:          bucket.Name,
+					OwnerIdentity: datatype.EventIdentity{PrincipalId: bucket.OwnerId},
+					Arn:           "arn:aws:s3:::" + bucket.Name,
+				},
+				Object: eventObject,
+			},
+		}
+		value, err := json.Marshal(datatype.EventRecords{Records: []datatype.EventRecord{record}})
+		if err != nil {
+			helper.Logger.Error("Failed to marshal event record of bucket", bucket.Name, "err:", err)
+			continue
+		}
+		err = sender.AsyncSend(value)
+		if err != nil {
+			helper.Logger.Error("Failed to send event record of bucket", bucket.Name,
+				"to", target.PluginName(), "err:", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	meta "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+// checkObjectLock returns ErrObjectLocked if the version could not be deleted
+// or overwritten, retention of GOVERNANCE mode could be bypassed
+func checkObjectLock(object *meta.Object, bypassGovernance bool) error {
+	lock := object.ObjectLock
+	if lock.IsLegalHoldOn() {
+		return ErrObjectLocked
+	}
+	if !lock.IsRetained(time.Now()) {
+		return nil
+	}
+	if lock.Mode == datatype.RetentionModeGovernance && bypassGovernance {
+		return nil
+	}
+	return ErrObjectLocked
+}
+
+// objectLockOf returns lock of new versions, default retention of bucket
+// is applied if no retention is specified in request
+func objectLockOf(bucket *meta.Bucket, lock datatype.ObjectLock, now time.Time) (datatype.ObjectLock, error) {
+	if !bucket.ObjectLock.Enabled() {
+		if lock.IsSet() {
+			return lock, ErrObjectLockNotEnabled
+		}
+		return lock, nil
+	}
+	if lock.Mode == "" {
+		defaultLock := bucket.ObjectLock.DefaultObjectLock(now)
+		lock.Mode = defaultLock.Mode
+		lock.RetainUntilDate = defaultLock.RetainUntilDate
+	}
+	return lock, nil
+}
+
+func (yig *YigStorage) SetBucketObjectLock(bucket *meta.Bucket, config datatype.ObjectLockConfiguration) error {
+	if bucket.Versioning != meta.VersionEnabled {
+		return ErrObjectLockVersioningRequired
+	}
+	bucket.ObjectLock = config
+	err := yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketObjectLock(bucketName string) (config datatype.ObjectLockConfiguration, err error) {
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if !bucket.ObjectLock.Enabled() {
+		return config, ErrObjectLockConfigurationNotFound
+	}
+	return bucket.ObjectLock, nil
+}
+
+// getLockedObject works like getSubresourceObject, but the bucket must be Object Lock enabled
+func (yig *YigStorage) getLockedObject(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if !bucket.ObjectLock.Enabled() {
+		return nil, ErrObjectLockNotEnabled
+	}
+	return yig.getSubresourceObject(bucketName, objectName, version, credential)
+}
+
+func (yig *YigStorage) updateObjectLock(object *meta.Object, version string) error {
+	err := yig.MetaStorage.UpdateObjectLock(object)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":")
+	if version != "" {
+		yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":"+version)
+	}
+	return nil
+}
+
+func (yig *YigStorage) GetObjectRetention(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	return yig.getLockedObject(bucketName, objectName, version, credential)
+}
+
+// PutObjectRetention changes retention of a version. Retention could always be
+// extended, but shortening or removing it needs bypassGovernance for GOVERNANCE
+// mode, and is never allowed for COMPLIANCE mode.
+func (yig *YigStorage) PutObjectRetention(bucketName, objectName, version string,
+	retention datatype.ObjectRetention, bypassGovernance bool,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	object, err = yig.getLockedObject(bucketName, objectName, version, credential)
+	if err != nil {
+		return
+	}
+	old := object.ObjectLock
+	if old.IsRetained(time.Now()) {
+		weakened := retention.Mode == "" || retention.RetainUntilDate.Before(old.RetainUntilDate) ||
+			(old.Mode == datatype.RetentionModeCompliance && retention.Mode != datatype.RetentionModeCompliance)
+		if weakened {
+			if old.Mode == datatype.RetentionModeCompliance || !bypassGovernance {
+				return nil, ErrObjectLocked
+			}
+		}
+	}
+	object.ObjectLock.Mode = retention.Mode
+	object.ObjectLock.RetainUntilDate = time.Time{}
+	if retention.RetainUntilDate != nil {
+		object.ObjectLock.RetainUntilDate = retention.RetainUntilDate.UTC()
+	}
+	err = yig.updateObjectLock(object, version)
+	return
+}
+
+func (yig *YigStorage) GetObjectLegalHold(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	return yig.getLockedObject(bucketName, objectName, version, credential)
+}
+
+func (yig *YigStorage) PutObjectLegalHold(bucketName, objectName, version string,
+	legalHold datatype.ObjectLegalHold, credential common.Credential) (object *meta.Object, err error) {
+
+	object, err = yig.getLockedObject(bucketName, objectName, version, credential)
+	if err != nil {
+		return
+	}
+	object.ObjectLock.LegalHold = ""
+	if legalHold.Status == datatype.LegalHoldOn {
+		object.ObjectLock.LegalHold = datatype.LegalHoldOn
+	}
+	err = yig.updateObjectLock(object, version)
+	return
+}
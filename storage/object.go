@@ -6,7 +6,7 @@ import (
 	"errors"
 	"io"
 	"math/rand"
-	"path"
+	"net/url"
 	"sync"
 	"time"
 
@@ -20,7 +20,6 @@ import (
 	"github.com/journeymidnight/yig/iam/common"
 	meta "github.com/journeymidnight/yig/meta/types"
 	"github.com/journeymidnight/yig/redis"
-	"github.com/journeymidnight/yig/signature"
 )
 
 var latestQueryTime [3]time.Time // 0 is for SMALL_FILE_POOLNAME, 1 is for BIG_FILE_POOLNAME, 2 is for GLACIER_FILE_POOLNAME
@@ -122,6 +121,26 @@ func (yig *YigStorage) GetClusterByFsName(fsName string) (cluster backend.Cluste
 	return
 }
 
+// copyObjectData copies data of objectId in Ceph as it is, e.g. from one pool to another
+func copyObjectData(source backend.Cluster, sourcePool, objectId string, size int64,
+	target backend.Cluster, targetPool string) (oid string, err error) {
+
+	reader, err := source.GetReader(sourcePool, objectId, 0, uint64(size))
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+	oid, written, err := target.Put(targetPool, reader)
+	if err != nil {
+		return
+	}
+	if int64(written) != size {
+		target.Remove(targetPool, oid)
+		return "", ErrIncompleteBody
+	}
+	return oid, nil
+}
+
 /*this pool is for download only */
 var (
 	downloadBufPool sync.Pool
@@ -183,19 +202,38 @@ func getAlignedReader(cluster backend.Cluster, poolName, objectName string,
 	return cluster.GetReader(poolName, objectName, alignedOffset, length)
 }
 
+// encryptionKeyOfObject unseals the data key of SSE-S3 and SSE-KMS objects
+func (yig *YigStorage) encryptionKeyOfObject(object *meta.Object) ([]byte, error) {
+	if yig.KMS == nil {
+		return nil, ErrKMSNotConfigured
+	}
+	keyID := yig.KMS.GetKeyID()
+	if object.SseType == crypto.S3KMS.String() {
+		keyID = object.SseKmsKeyId
+	}
+	kmsContext, err := kmsContextOf(object.BucketName, object.Name, object.SseContext)
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := yig.Keyring.Unseal(object.KeyVersion, object.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := yig.KMS.UnsealKey(keyID, sealedKey, kmsContext)
+	if err != nil {
+		return nil, err
+	}
+	return key[:], nil
+}
+
 func (yig *YigStorage) GetObject(object *meta.Object, startOffset int64,
 	length int64, writer io.Writer, sseRequest datatype.SseRequest) (err error) {
 	var encryptionKey []byte
-	if object.SseType == crypto.S3.String() {
-		if yig.KMS == nil {
-			return ErrKMSNotConfigured
-		}
-		key, err := yig.KMS.UnsealKey(yig.KMS.GetKeyID(), object.EncryptionKey,
-			crypto.Context{object.BucketName: path.Join(object.BucketName, object.Name)})
+	if object.SseType == crypto.S3.String() || object.SseType == crypto.S3KMS.String() {
+		encryptionKey, err = yig.encryptionKeyOfObject(object)
 		if err != nil {
 			return err
 		}
-		encryptionKey = key[:]
 	} else { // SSE-C
 		if len(sseRequest.CopySourceSseCustomerKey) != 0 {
 			encryptionKey = sseRequest.CopySourceSseCustomerKey
@@ -335,26 +373,9 @@ func (yig *YigStorage) GetObjectInfo(bucketName string, objectName string,
 		return
 	}
 
-	if !credential.AllowOtherUserAccess {
-		switch object.ACL.CannedAcl {
-		case "public-read", "public-read-write":
-			break
-		case "authenticated-read":
-			if credential.UserId == "" {
-				err = ErrAccessDenied
-				return
-			}
-		case "bucket-owner-read", "bucket-owner-full-control":
-			if bucket.OwnerId != credential.UserId {
-				err = ErrAccessDenied
-				return
-			}
-		default:
-			if object.OwnerId != credential.UserId {
-				err = ErrAccessDenied
-				return
-			}
-		}
+	if !credential.AllowOtherUserAccess && !bucket.ObjectReadAccess(credential, object).Allowed {
+		err = ErrAccessDenied
+		return
 	}
 
 	return
@@ -377,26 +398,9 @@ func (yig *YigStorage) GetObjectInfoByCtx(ctx api.RequestContext,
 		}
 	}
 
-	if !credential.AllowOtherUserAccess {
-		switch object.ACL.CannedAcl {
-		case "public-read", "public-read-write":
-			break
-		case "authenticated-read":
-			if credential.UserId == "" {
-				err = ErrAccessDenied
-				return
-			}
-		case "bucket-owner-read", "bucket-owner-full-control":
-			if bucket.OwnerId != credential.UserId {
-				err = ErrAccessDenied
-				return
-			}
-		default:
-			if object.OwnerId != credential.UserId {
-				err = ErrAccessDenied
-				return
-			}
-		}
+	if !credential.AllowOtherUserAccess && !bucket.ObjectReadAccess(credential, object).Allowed {
+		err = ErrAccessDenied
+		return
 	}
 
 	return
@@ -427,7 +431,7 @@ func (yig *YigStorage) GetObjectAcl(bucketName string, objectName string,
 			return
 		}
 	default:
-		if object.OwnerId != credential.UserId {
+		if bucket.ObjectOwner(object) != credential.UserId {
 			err = ErrAccessDenied
 			return
 		}
@@ -472,6 +476,12 @@ func (yig *YigStorage) SetObjectAcl(bucketName string, objectName string, versio
 			return ErrAccessDenied
 		}
 	} // TODO policy and fancy ACL
+	if bucket.OwnershipControls.AclsDisabled() {
+		return ErrAccessControlListNotSupported
+	}
+	if err = yig.checkPublicAcl(bucket, acl); err != nil {
+		return err
+	}
 	var object *meta.Object
 	if version == "" {
 		object, err = yig.MetaStorage.GetObject(bucketName, objectName, false)
@@ -493,6 +503,63 @@ func (yig *YigStorage) SetObjectAcl(bucketName string, objectName string, versio
 	return nil
 }
 
+// getSubresourceObject returns the object (or the specified version) whose tags,
+// retention or legal hold could be accessed by credential
+func (yig *YigStorage) getSubresourceObject(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if version == "" {
+		object, err = yig.MetaStorage.GetObject(bucketName, objectName, false)
+	} else {
+		object, err = yig.getObjWithVersion(bucketName, objectName, version)
+	}
+	if err != nil {
+		return
+	}
+	if object.DeleteMarker {
+		return nil, ErrNoSuchKey
+	}
+	if !credential.AllowOtherUserAccess && !bucket.ObjectSubresourceAccess(credential, object).Allowed {
+		return nil, ErrAccessDenied
+	}
+	return
+}
+
+func (yig *YigStorage) GetObjectTagging(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	return yig.getSubresourceObject(bucketName, objectName, version, credential)
+}
+
+func (yig *YigStorage) PutObjectTagging(bucketName, objectName, version string, tags map[string]string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	object, err = yig.getSubresourceObject(bucketName, objectName, version, credential)
+	if err != nil {
+		return
+	}
+	object.Tags = tags
+	err = yig.MetaStorage.UpdateObjectTags(object)
+	if err != nil {
+		return
+	}
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, bucketName+":"+objectName+":")
+	if version != "" {
+		yig.MetaStorage.Cache.Remove(redis.ObjectTable, bucketName+":"+objectName+":"+version)
+	}
+	return
+}
+
+func (yig *YigStorage) DeleteObjectTagging(bucketName, objectName, version string,
+	credential common.Credential) (object *meta.Object, err error) {
+
+	return yig.PutObjectTagging(bucketName, objectName, version, nil, credential)
+}
+
 // Write path:
 //                                           +-----------+
 // PUT object/part                           |           |   Ceph
@@ -505,15 +572,39 @@ func (yig *YigStorage) SetObjectAcl(bucketName string, objectName string, versio
 // SHA256 is calculated only for v4 signed authentication
 // Encryptor is enabled when user set SSE headers
 func (yig *YigStorage) PutObject(bucketName string, objectName string, credential common.Credential,
-	size int64, data io.ReadCloser, metadata map[string]string, acl datatype.Acl,
-	sseRequest datatype.SseRequest, storageClass meta.StorageClass) (result datatype.PutObjectResult, err error) {
+	size int64, data io.ReadCloser, metadata map[string]string, acl datatype.Acl, tags map[string]string,
+	lock datatype.ObjectLock, sseRequest datatype.SseRequest, storageClass meta.StorageClass,
+	condition datatype.WriteCondition) (result datatype.PutObjectResult, err error) {
+
+	return yig.putObject(bucketName, objectName, credential, size, data, metadata, acl, tags,
+		lock, sseRequest, storageClass, condition, datatype.ObjectCreatedPut)
+}
+
+// PostObject is the same as PutObject except the event name in bucket notifications
+func (yig *YigStorage) PostObject(bucketName string, objectName string, credential common.Credential,
+	size int64, data io.ReadCloser, metadata map[string]string, acl datatype.Acl, tags map[string]string,
+	lock datatype.ObjectLock, sseRequest datatype.SseRequest,
+	storageClass meta.StorageClass) (result datatype.PutObjectResult, err error) {
+
+	return yig.putObject(bucketName, objectName, credential, size, data, metadata, acl, tags,
+		lock, sseRequest, storageClass, datatype.WriteCondition{}, datatype.ObjectCreatedPost)
+}
+
+func (yig *YigStorage) putObject(bucketName string, objectName string, credential common.Credential,
+	size int64, data io.ReadCloser, metadata map[string]string, acl datatype.Acl, tags map[string]string,
+	lock datatype.ObjectLock, sseRequest datatype.SseRequest, storageClass meta.StorageClass,
+	condition datatype.WriteCondition, eventName string) (result datatype.PutObjectResult, err error) {
 
 	defer data.Close()
-	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(sseRequest, bucketName, objectName)
+	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(&sseRequest, bucketName, objectName)
 	helper.Logger.Info("get encryptionKey:", encryptionKey, "cipherKey:", cipherKey, "err:", err)
 	if err != nil {
 		return
 	}
+	keyVersion, cipherKey, err := yig.Keyring.Seal(cipherKey)
+	if err != nil {
+		return
+	}
 
 	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
 	if err != nil {
@@ -521,13 +612,18 @@ func (yig *YigStorage) PutObject(bucketName string, objectName string, credentia
 		return
 	}
 
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return result, ErrBucketAccessForbidden
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		return result, ErrBucketAccessForbidden
+	}
+	lock, err = objectLockOf(bucket, lock, time.Now().UTC())
+	if err != nil {
+		return
+	}
+	if err = yig.checkQuota(bucket, size, 1); err != nil {
+		return
+	}
+	if err = yig.checkWriteCondition(bucket, objectName, condition); err != nil {
+		return
 	}
 
 	md5Writer := md5.New()
@@ -576,6 +672,10 @@ func (yig *YigStorage) PutObject(bucketName string, objectName string, credentia
 			bytesWritten, "total size", size)
 		return result, ErrIncompleteBody
 	}
+	if err = yig.checkWrittenQuota(bucket, size, int64(bytesWritten), 1); err != nil {
+		RecycleQueue <- maybeObjectToRecycle
+		return
+	}
 
 	calculatedMd5 := hex.EncodeToString(md5Writer.Sum(nil))
 	helper.Logger.Info("CalculatedMd5:", calculatedMd5, "userMd5:", metadata["md5Sum"])
@@ -588,20 +688,20 @@ func (yig *YigStorage) PutObject(bucketName string, objectName string, credentia
 
 	result.Md5 = calculatedMd5
 
-	if signVerifyReader, ok := data.(*signature.SignVerifyReadCloser); ok {
-		credential, err = signVerifyReader.Verify()
-		if err != nil {
-			RecycleQueue <- maybeObjectToRecycle
-			return
-		}
+	var checksum datatype.Checksum
+	credential, checksum, err = verifyUpload(data, credential)
+	if err != nil {
+		RecycleQueue <- maybeObjectToRecycle
+		return
 	}
+	result.Checksum = checksum
 	// TODO validate bucket policy and fancy ACL
 	object := &meta.Object{
 		Name:             objectName,
 		BucketName:       bucketName,
 		Location:         cluster.ID(),
 		Pool:             poolName,
-		OwnerId:          credential.UserId,
+		OwnerId:          objectOwnerOf(bucket, acl, credential.UserId),
 		Size:             int64(bytesWritten),
 		ObjectId:         objectId,
 		LastModifiedTime: time.Now().UTC(),
@@ -611,20 +711,33 @@ func (yig *YigStorage) PutObject(bucketName string, objectName string, credentia
 		NullVersion:      helper.Ternary(bucket.Versioning == "Enabled", false, true).(bool),
 		DeleteMarker:     false,
 		SseType:          sseRequest.Type,
-		EncryptionKey: helper.Ternary(sseRequest.Type == crypto.S3.String(),
+		EncryptionKey: helper.Ternary(sseRequest.Type == crypto.S3.String() || sseRequest.Type == crypto.S3KMS.String(),
 			cipherKey, []byte("")).([]byte),
+		KeyVersion:           keyVersion,
 		InitializationVector: initializationVector,
 		CustomAttributes:     metadata,
+		Tags:                 tags,
 		Type:                 meta.ObjectTypeNormal,
 		StorageClass:         storageClass,
+		ObjectLock:           lock,
+		Checksum:             checksum,
+	}
+	if sseRequest.Type == crypto.S3KMS.String() {
+		object.SseKmsKeyId = sseRequest.SseAwsKmsKeyId
+		object.SseContext = sseRequest.SseContext
+		result.SseAwsKmsKeyId = sseRequest.SseAwsKmsKeyId
 	}
+	object.ReplicationStatus = replicationStatusOf(bucket, object)
 
 	result.LastModified = object.LastModifiedTime
 	var nullVerNum uint64
-	nullVerNum, err = yig.checkOldObject(bucketName, objectName, bucket.Versioning)
-	if err != nil {
-		RecycleQueue <- maybeObjectToRecycle
-		return
+	// objects replaced by conditional writes are removed after put succeeds
+	if !condition.IsSet() || bucket.Versioning == meta.VersionEnabled {
+		nullVerNum, err = yig.checkOldObject(bucketName, objectName, bucket.Versioning)
+		if err != nil {
+			RecycleQueue <- maybeObjectToRecycle
+			return
+		}
 	}
 	if bucket.Versioning == meta.VersionEnabled {
 		result.VersionId = object.GetVersionId()
@@ -646,36 +759,39 @@ func (yig *YigStorage) PutObject(bucketName string, objectName string, credentia
 		}
 	}
 
+	var replaced []*meta.Object
 	if nullVerNum != 0 {
 		objMap := &meta.ObjMap{
 			Name:       objectName,
 			BucketName: bucketName,
 		}
-		err = yig.MetaStorage.PutObject(object, nil, objMap, true)
+		replaced, err = yig.MetaStorage.PutObjectIf(object, nil, objMap, true, condition)
 	} else {
-		err = yig.MetaStorage.PutObject(object, nil, nil, true)
+		replaced, err = yig.MetaStorage.PutObjectIf(object, nil, nil, true, condition)
 	}
 
 	if err != nil {
 		RecycleQueue <- maybeObjectToRecycle
 		return
 	}
+	yig.cleanReplacedObjects(bucket, replaced)
 
 	if err == nil {
 		yig.MetaStorage.Cache.Remove(redis.ObjectTable, bucketName+":"+objectName+":")
 		yig.DataCache.Remove(bucketName + ":" + objectName + ":" + object.GetVersionId())
 	}
+	yig.chargeQuota(bucket, object.Size, 1)
+	yig.sendNotification(bucket, eventName, object, credential)
 	return result, nil
 }
 
 func (yig *YigStorage) PutObjectMeta(bucket *meta.Bucket, targetObject *meta.Object, credential common.Credential) (err error) {
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return ErrBucketAccessForbidden
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		return ErrBucketAccessForbidden
+	}
+	err = checkObjectLock(targetObject, false)
+	if err != nil {
+		return
 	}
 
 	err = yig.MetaStorage.UpdateObjectAttrs(targetObject)
@@ -696,13 +812,12 @@ func (yig *YigStorage) RenameObject(targetObject *meta.Object, sourceObject stri
 	if err != nil {
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return result, ErrBucketAccessForbidden
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		return result, ErrBucketAccessForbidden
+	}
+	err = checkObjectLock(targetObject, false)
+	if err != nil {
+		return
 	}
 
 	if len(targetObject.Parts) != 0 {
@@ -732,7 +847,11 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 	var oid string
 	var maybeObjectToRecycle objectToRecycle
 	var encryptionKey []byte
-	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(sseRequest, targetObject.BucketName, targetObject.Name)
+	encryptionKey, cipherKey, err := yig.encryptionKeyFromSseRequest(&sseRequest, targetObject.BucketName, targetObject.Name)
+	if err != nil {
+		return
+	}
+	keyVersion, cipherKey, err := yig.Keyring.Seal(cipherKey)
 	if err != nil {
 		return
 	}
@@ -742,16 +861,16 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 		return
 	}
 
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId {
-			return result, ErrBucketAccessForbidden
-		}
+	if !bucket.WriteAccess(credential).Allowed {
+		return result, ErrBucketAccessForbidden
 	}
 
 	if isMetadataOnly {
+		// metadata is replaced in place, which is not allowed for locked versions
+		err = checkObjectLock(sourceObject, false)
+		if err != nil {
+			return
+		}
 		if sourceObject.StorageClass == meta.ObjectStorageClassGlacier {
 			targetObject.LastModifiedTime = sourceObject.LastModifiedTime
 			err = yig.MetaStorage.UpdateGlacierObject(targetObject, sourceObject, true)
@@ -765,6 +884,7 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 			}
 			yig.MetaStorage.Cache.Remove(redis.ObjectTable, targetObject.BucketName+":"+targetObject.Name+":")
 			yig.DataCache.Remove(targetObject.BucketName + ":" + targetObject.Name + ":" + targetObject.GetVersionId())
+			yig.sendNotification(bucket, datatype.ObjectCreatedCopy, targetObject, credential)
 			return result, nil
 		}
 		err = yig.MetaStorage.ReplaceObjectMetas(targetObject)
@@ -779,9 +899,18 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 		}
 		yig.MetaStorage.Cache.Remove(redis.ObjectTable, targetObject.BucketName+":"+targetObject.Name+":")
 		yig.DataCache.Remove(targetObject.BucketName + ":" + targetObject.Name + ":" + targetObject.GetVersionId())
+		yig.sendNotification(bucket, datatype.ObjectCreatedCopy, targetObject, credential)
 		return result, nil
 	}
 
+	targetObject.ObjectLock, err = objectLockOf(bucket, targetObject.ObjectLock, time.Now().UTC())
+	if err != nil {
+		return
+	}
+	if err = yig.checkQuota(bucket, targetObject.Size, 1); err != nil {
+		return
+	}
+
 	// Limit the reader to its provided size if specified.
 	var limitedDataReader io.Reader
 	limitedDataReader = io.LimitReader(source, targetObject.Size)
@@ -900,13 +1029,22 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 	targetObject.VersionId = "" // clear the versionId cache
 	targetObject.Location = cephCluster.ID()
 	targetObject.Pool = poolName
-	targetObject.OwnerId = credential.UserId
+	targetObject.OwnerId = objectOwnerOf(bucket, targetObject.ACL, credential.UserId)
 	targetObject.LastModifiedTime = time.Now().UTC()
 	targetObject.NullVersion = helper.Ternary(bucket.Versioning == "Enabled", false, true).(bool)
 	targetObject.DeleteMarker = false
 	targetObject.SseType = sseRequest.Type
-	targetObject.EncryptionKey = helper.Ternary(sseRequest.Type == crypto.S3.String(),
+	targetObject.EncryptionKey = helper.Ternary(sseRequest.Type == crypto.S3.String() || sseRequest.Type == crypto.S3KMS.String(),
 		cipherKey, []byte("")).([]byte)
+	targetObject.KeyVersion = keyVersion
+	targetObject.SseKmsKeyId = ""
+	targetObject.SseContext = ""
+	if sseRequest.Type == crypto.S3KMS.String() {
+		targetObject.SseKmsKeyId = sseRequest.SseAwsKmsKeyId
+		targetObject.SseContext = sseRequest.SseContext
+		result.SseAwsKmsKeyId = sseRequest.SseAwsKmsKeyId
+	}
+	targetObject.ReplicationStatus = replicationStatusOf(bucket, targetObject)
 
 	result.LastModified = targetObject.LastModifiedTime
 
@@ -949,6 +1087,8 @@ func (yig *YigStorage) CopyObject(targetObject *meta.Object, sourceObject *meta.
 
 	yig.MetaStorage.Cache.Remove(redis.ObjectTable, targetObject.BucketName+":"+targetObject.Name+":")
 	yig.DataCache.Remove(targetObject.BucketName + ":" + targetObject.Name + ":" + targetObject.GetVersionId())
+	yig.chargeQuota(bucket, targetObject.Size, 1)
+	yig.sendNotification(bucket, datatype.ObjectCreatedCopy, targetObject, credential)
 
 	return result, nil
 }
@@ -959,6 +1099,7 @@ func (yig *YigStorage) removeByObject(object *meta.Object, objMap *meta.ObjMap)
 	if err != nil {
 		return
 	}
+	yig.dischargeQuota(object)
 	return nil
 }
 
@@ -983,6 +1124,12 @@ func (yig *YigStorage) removeAllObjectsEntryByName(bucketName, objectName string
 	if err != nil {
 		return err
 	}
+	for _, obj := range objs {
+		err = checkObjectLock(obj, false)
+		if err != nil {
+			return err
+		}
+	}
 	for _, obj := range objs {
 		if obj.StorageClass == meta.ObjectStorageClassGlacier {
 			freezer, err := yig.GetFreezer(bucketName, objectName, "")
@@ -1056,10 +1203,15 @@ func (yig *YigStorage) checkOldObject(bucketName, objectName, versioning string)
 		} else {
 			helper.Logger.Info("object.NullVersion:", object.NullVersion)
 			if objectExist && object.NullVersion {
+				err = checkObjectLock(object, false)
+				if err != nil {
+					return
+				}
 				err = yig.MetaStorage.DeleteObject(object, object.DeleteMarker, nil)
 				if err != nil {
 					return
 				}
+				yig.dischargeQuota(object)
 			}
 		}
 		return
@@ -1068,7 +1220,9 @@ func (yig *YigStorage) checkOldObject(bucketName, objectName, versioning string)
 	return 0, errors.New("No Such versioning status!")
 }
 
-func (yig *YigStorage) removeObjectVersion(bucketName, objectName, version string) error {
+func (yig *YigStorage) removeObjectVersion(bucketName, objectName, version string,
+	bypassGovernance bool) error {
+
 	object, err := yig.getObjWithVersion(bucketName, objectName, version)
 	if err == ErrNoSuchKey {
 		return nil
@@ -1076,6 +1230,10 @@ func (yig *YigStorage) removeObjectVersion(bucketName, objectName, version strin
 	if err != nil {
 		return err
 	}
+	err = checkObjectLock(object, bypassGovernance)
+	if err != nil {
+		return err
+	}
 
 	if version == "null" {
 		objMap := &meta.ObjMap{
@@ -1101,6 +1259,7 @@ func (yig *YigStorage) addDeleteMarker(bucket meta.Bucket, objectName string,
 		NullVersion:      nullVersion,
 		DeleteMarker:     true,
 	}
+	deleteMarker.ReplicationStatus = replicationStatusOf(&bucket, deleteMarker)
 
 	versionId = deleteMarker.GetVersionId()
 	objMap := &meta.ObjMap{
@@ -1127,21 +1286,19 @@ func (yig *YigStorage) addDeleteMarker(bucket meta.Bucket, objectName string,
 // |           |                              | null version delete marker                             |
 //
 // See http://docs.aws.amazon.com/AmazonS3/latest/dev/Versioning.html
+//
+// Versions protected by Object Lock could not be removed, unless bypassGovernance
+// is set for versions in GOVERNANCE mode.
 func (yig *YigStorage) DeleteObject(bucketName string, objectName string, version string,
-	credential common.Credential) (result datatype.DeleteObjectResult, err error) {
+	bypassGovernance bool, credential common.Credential) (result datatype.DeleteObjectResult, err error) {
 
 	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
 	if err != nil {
 		return
 	}
-	switch bucket.ACL.CannedAcl {
-	case "public-read-write":
-		break
-	default:
-		if bucket.OwnerId != credential.UserId && credential.UserId != "" {
-			return result, ErrBucketAccessForbidden
-		}
-	} // TODO policy and fancy ACL
+	if !credential.AllowOtherUserAccess && !bucket.DeleteAccess(credential).Allowed {
+		return result, ErrBucketAccessForbidden
+	}
 
 	switch bucket.Versioning {
 	case meta.VersionDisabled:
@@ -1160,7 +1317,7 @@ func (yig *YigStorage) DeleteObject(bucketName string, objectName string, versio
 			}
 			result.DeleteMarker = true
 		} else {
-			err = yig.removeObjectVersion(bucketName, objectName, version)
+			err = yig.removeObjectVersion(bucketName, objectName, version, bypassGovernance)
 			if err != nil {
 				return
 			}
@@ -1168,7 +1325,7 @@ func (yig *YigStorage) DeleteObject(bucketName string, objectName string, versio
 		}
 	case meta.VersionSuspended:
 		if version == "" {
-			err = yig.removeObjectVersion(bucketName, objectName, "null")
+			err = yig.removeObjectVersion(bucketName, objectName, "null", bypassGovernance)
 			if err != nil {
 				return
 			}
@@ -1178,7 +1335,7 @@ func (yig *YigStorage) DeleteObject(bucketName string, objectName string, versio
 			}
 			result.DeleteMarker = true
 		} else {
-			err = yig.removeObjectVersion(bucketName, objectName, version)
+			err = yig.removeObjectVersion(bucketName, objectName, version, bypassGovernance)
 			if err != nil {
 				return
 			}
@@ -1199,5 +1356,13 @@ func (yig *YigStorage) DeleteObject(bucketName string, objectName string, versio
 			yig.DataCache.Remove(bucketName + ":" + objectName + ":" + version)
 		}
 	}
+	eventName := datatype.ObjectRemovedDelete
+	if result.DeleteMarker {
+		eventName = datatype.ObjectRemovedDeleteMarkerCreated
+	}
+	yig.sendEventObjectNotification(bucket, eventName, datatype.EventObject{
+		Key:       url.QueryEscape(objectName),
+		VersionId: result.VersionId,
+	}, credential)
 	return result, nil
 }
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	meta "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+func (yig *YigStorage) SetBucketOwnershipControls(bucket *meta.Bucket, controls datatype.OwnershipControls) error {
+	bucket.OwnershipControls = controls
+	err := yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketOwnershipControls(bucketName string) (controls datatype.OwnershipControls, err error) {
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if !bucket.OwnershipControls.IsSet() {
+		return controls, ErrOwnershipControlsNotFound
+	}
+	return bucket.OwnershipControls, nil
+}
+
+func (yig *YigStorage) DeleteBucketOwnershipControls(bucket *meta.Bucket) error {
+	return yig.SetBucketOwnershipControls(bucket, datatype.OwnershipControls{})
+}
+
+// objectOwnerOf returns owner of new objects uploaded by uploader with acl,
+// according to Object Ownership of bucket
+func objectOwnerOf(bucket *meta.Bucket, acl datatype.Acl, uploader string) string {
+	switch bucket.OwnershipControls.ObjectOwnership() {
+	case datatype.ObjectOwnershipBucketOwnerEnforced:
+		return bucket.OwnerId
+	case datatype.ObjectOwnershipBucketOwnerPreferred:
+		if acl.CannedAcl == "bucket-owner-full-control" {
+			return bucket.OwnerId
+		}
+	}
+	return uploader
+}
+
+// multipartOwnerOf returns owner of new multipart uploads, which is the bucket owner
+// unless Object Ownership of bucket is specified
+func multipartOwnerOf(bucket *meta.Bucket, acl datatype.Acl, initiator string) string {
+	if !bucket.OwnershipControls.IsSet() {
+		return bucket.OwnerId
+	}
+	return objectOwnerOf(bucket, acl, initiator)
+}
+
+// completedOwnerOf returns owner of object completed from multipart upload,
+// Object Ownership of bucket may be changed since the upload is initiated
+func completedOwnerOf(bucket *meta.Bucket, multipart meta.Multipart) string {
+	if !bucket.OwnershipControls.IsSet() {
+		return multipart.Metadata.OwnerId
+	}
+	return objectOwnerOf(bucket, multipart.Metadata.Acl, multipart.Metadata.InitiatorId)
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/journeymidnight/yig/api"
+	"github.com/journeymidnight/yig/api/datatype"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/meta/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeObjectClient serves a bucket and its latest object
+type fakeObjectClient struct {
+	fakeMetaClient
+	bucket *types.Bucket
+	object *types.Object
+}
+
+func (c *fakeObjectClient) GetBucket(bucketName string) (*types.Bucket, error) {
+	if c.bucket == nil || c.bucket.Name != bucketName {
+		return nil, ErrNoSuchBucket
+	}
+	return c.bucket, nil
+}
+
+func (c *fakeObjectClient) GetObject(bucketName, objectName, version string) (*types.Object, error) {
+	if c.object == nil || c.object.BucketName != bucketName || c.object.Name != objectName {
+		return nil, ErrNoSuchKey
+	}
+	return c.object, nil
+}
+
+func ownershipControlsOf(objectOwnership string) datatype.OwnershipControls {
+	if objectOwnership == "" {
+		return datatype.OwnershipControls{}
+	}
+	return datatype.OwnershipControls{Rules: []datatype.OwnershipControlsRule{{ObjectOwnership: objectOwnership}}}
+}
+
+func TestObjectOwnerOf(t *testing.T) {
+	testCases := []struct {
+		objectOwnership string
+		acl             string
+		owner           string
+	}{
+		{"", "private", "uploader"},
+		{"", "bucket-owner-full-control", "uploader"},
+		{datatype.ObjectOwnershipObjectWriter, "private", "uploader"},
+		{datatype.ObjectOwnershipObjectWriter, "bucket-owner-full-control", "uploader"},
+		{datatype.ObjectOwnershipBucketOwnerPreferred, "private", "uploader"},
+		{datatype.ObjectOwnershipBucketOwnerPreferred, "bucket-owner-read", "uploader"},
+		{datatype.ObjectOwnershipBucketOwnerPreferred, "bucket-owner-full-control", "owner"},
+		{datatype.ObjectOwnershipBucketOwnerEnforced, "private", "owner"},
+		{datatype.ObjectOwnershipBucketOwnerEnforced, "bucket-owner-full-control", "owner"},
+	}
+	for _, testCase := range testCases {
+		bucket := &types.Bucket{Name: "b", OwnerId: "owner",
+			OwnershipControls: ownershipControlsOf(testCase.objectOwnership)}
+		owner := objectOwnerOf(bucket, datatype.Acl{CannedAcl: testCase.acl}, "uploader")
+		if owner != testCase.owner {
+			t.Errorf("Owner of object with %s by %q is %s, expected %s", testCase.acl,
+				testCase.objectOwnership, owner, testCase.owner)
+		}
+	}
+}
+
+func TestMultipartOwnerOf(t *testing.T) {
+	testCases := []struct {
+		objectOwnership string
+		acl             string
+		owner           string
+	}{
+		// multipart uploads are owned by the bucket owner unless Object Ownership is specified
+		{"", "private", "owner"},
+		{datatype.ObjectOwnershipObjectWriter, "private", "initiator"},
+		{datatype.ObjectOwnershipBucketOwnerPreferred, "private", "initiator"},
+		{datatype.ObjectOwnershipBucketOwnerPreferred, "bucket-owner-full-control", "owner"},
+		{datatype.ObjectOwnershipBucketOwnerEnforced, "private", "owner"},
+	}
+	for _, testCase := range testCases {
+		bucket := &types.Bucket{Name: "b", OwnerId: "owner",
+			OwnershipControls: ownershipControlsOf(testCase.objectOwnership)}
+		acl := datatype.Acl{CannedAcl: testCase.acl}
+		owner := multipartOwnerOf(bucket, acl, "initiator")
+		if owner != testCase.owner {
+			t.Errorf("Owner of upload with %s by %q is %s, expected %s", testCase.acl,
+				testCase.objectOwnership, owner, testCase.owner)
+		}
+		multipart := types.Multipart{Metadata: types.MultipartMetadata{InitiatorId: "initiator",
+			OwnerId: owner, Acl: acl}}
+		if completed := completedOwnerOf(bucket, multipart); completed != testCase.owner {
+			t.Errorf("Owner of completed upload with %s by %q is %s, expected %s", testCase.acl,
+				testCase.objectOwnership, completed, testCase.owner)
+		}
+	}
+
+	// Object Ownership is changed since the upload is initiated
+	multipart := types.Multipart{Metadata: types.MultipartMetadata{InitiatorId: "initiator",
+		OwnerId: "owner", Acl: datatype.Acl{CannedAcl: "private"}}}
+	bucket := &types.Bucket{Name: "b", OwnerId: "owner",
+		OwnershipControls: ownershipControlsOf(datatype.ObjectOwnershipObjectWriter)}
+	assert.Equal(t, "initiator", completedOwnerOf(bucket, multipart))
+	multipart.Metadata.OwnerId = "initiator"
+	bucket.OwnershipControls = ownershipControlsOf(datatype.ObjectOwnershipBucketOwnerEnforced)
+	assert.Equal(t, "owner", completedOwnerOf(bucket, multipart))
+}
+
+func TestGetObjectInfoWithAclsDisabled(t *testing.T) {
+	bucket := &types.Bucket{Name: "b", OwnerId: "owner",
+		OwnershipControls: ownershipControlsOf(datatype.ObjectOwnershipBucketOwnerEnforced)}
+	// uploaded by writer before ACLs are disabled
+	object := &types.Object{BucketName: "b", Name: "o", OwnerId: "writer",
+		ACL: datatype.Acl{CannedAcl: "public-read"}}
+	yig := newTestStorage(&fakeObjectClient{bucket: bucket, object: object})
+
+	testCases := []struct {
+		credential common.Credential
+		err        error
+	}{
+		{common.Credential{UserId: "owner"}, nil},
+		// object ACLs are ignored
+		{common.Credential{UserId: "writer"}, ErrAccessDenied},
+		{common.Credential{}, ErrAccessDenied},
+		// permitted by bucket policy
+		{common.Credential{UserId: "other", AllowOtherUserAccess: true}, nil},
+	}
+	for _, testCase := range testCases {
+		_, err := yig.GetObjectInfo("b", "o", "", testCase.credential)
+		assert.Equal(t, testCase.err, err, "GetObjectInfo by %q", testCase.credential.UserId)
+		ctx := api.RequestContext{BucketName: "b", ObjectName: "o", BucketInfo: bucket, ObjectInfo: object}
+		_, err = yig.GetObjectInfoByCtx(ctx, "", testCase.credential)
+		assert.Equal(t, testCase.err, err, "GetObjectInfoByCtx by %q", testCase.credential.UserId)
+		_, err = yig.getSubresourceObject("b", "o", "", testCase.credential)
+		assert.Equal(t, testCase.err, err, "getSubresourceObject by %q", testCase.credential.UserId)
+	}
+}
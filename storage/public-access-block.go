@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/api/datatype/policy"
+	. "github.com/journeymidnight/yig/error"
+	meta "github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/redis"
+)
+
+func (yig *YigStorage) SetBucketPublicAccessBlock(bucket *meta.Bucket,
+	config datatype.PublicAccessBlockConfiguration) error {
+
+	bucket.PublicAccessBlock = config
+	err := yig.MetaStorage.Client.PutBucket(*bucket)
+	if err != nil {
+		return err
+	}
+	yig.MetaStorage.Cache.Remove(redis.BucketTable, bucket.Name)
+	return nil
+}
+
+func (yig *YigStorage) GetBucketPublicAccessBlock(bucketName string) (
+	config datatype.PublicAccessBlockConfiguration, err error) {
+
+	bucket, err := yig.MetaStorage.GetBucket(bucketName, true)
+	if err != nil {
+		return
+	}
+	if !bucket.PublicAccessBlock.IsSet() {
+		return config, ErrNoSuchPublicAccessBlockConfiguration
+	}
+	return bucket.PublicAccessBlock, nil
+}
+
+func (yig *YigStorage) DeleteBucketPublicAccessBlock(bucket *meta.Bucket) error {
+	return yig.SetBucketPublicAccessBlock(bucket, datatype.PublicAccessBlockConfiguration{})
+}
+
+// GetEffectivePublicAccessBlock returns Block Public Access settings enforced on bucket,
+// i.e. settings of the bucket combined with settings of its owner
+func (yig *YigStorage) GetEffectivePublicAccessBlock(bucket *meta.Bucket) (
+	datatype.PublicAccessBlockConfiguration, error) {
+
+	return yig.MetaStorage.GetEffectivePublicAccessBlock(bucket)
+}
+
+// checkPublicAcl returns ErrPublicAclBlocked if acl is public and blocked by
+// BlockPublicAcls setting of bucket
+func (yig *YigStorage) checkPublicAcl(bucket *meta.Bucket, acl datatype.Acl) error {
+	if !datatype.IsPublicAcl(acl) {
+		return nil
+	}
+	config, err := yig.MetaStorage.GetEffectivePublicAccessBlock(bucket)
+	if err != nil {
+		return err
+	}
+	if config.BlockPublicAcls {
+		return ErrPublicAclBlocked
+	}
+	return nil
+}
+
+// checkPublicPolicy returns ErrPublicPolicyBlocked if bucket policy is public and
+// blocked by BlockPublicPolicy setting of bucket
+func (yig *YigStorage) checkPublicPolicy(bucket *meta.Bucket, bucketPolicy policy.Policy) error {
+	if !bucketPolicy.IsPublic() {
+		return nil
+	}
+	config, err := yig.MetaStorage.GetEffectivePublicAccessBlock(bucket)
+	if err != nil {
+		return err
+	}
+	if config.BlockPublicPolicy {
+		return ErrPublicPolicyBlocked
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/helper"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+// checkQuota returns ErrQuotaExceeded if bytes and objects to write exceed
+// quotas of bucket or its owner, bytes of unknown length are checked by
+// checkWrittenQuota once written. Nothing is reserved by the check, so
+// concurrent writes could overshoot quotas by up to their sizes.
+func (yig *YigStorage) checkQuota(bucket *meta.Bucket, bytes, objects int64) error {
+	if !helper.CONFIG.EnableQuota {
+		return nil
+	}
+	if bytes < 0 {
+		bytes = 0
+	}
+	return yig.MetaStorage.CheckQuota(bucket, bytes, objects)
+}
+
+// checkWrittenQuota checks quotas again by bytes written of data of unknown
+// length, the written data should be recycled if it returns an error
+func (yig *YigStorage) checkWrittenQuota(bucket *meta.Bucket, size, bytesWritten, objects int64) error {
+	if size >= 0 {
+		return nil
+	}
+	return yig.checkQuota(bucket, bytesWritten, objects)
+}
+
+// chargeQuota counts written bytes and objects before cached usages expire
+func (yig *YigStorage) chargeQuota(bucket *meta.Bucket, bytes, objects int64) {
+	if !helper.CONFIG.EnableQuota {
+		return
+	}
+	yig.MetaStorage.ChargeQuota(bucket, bytes, objects)
+}
+
+// dischargeQuota subtracts a removed object from cached usages, e.g. objects replaced
+// by puts, the same as usages in database. Delete markers are not counted.
+func (yig *YigStorage) dischargeQuota(object *meta.Object) {
+	if !helper.CONFIG.EnableQuota || object.DeleteMarker {
+		return
+	}
+	bucket, err := yig.MetaStorage.GetBucket(object.BucketName, true)
+	if err != nil {
+		helper.Logger.Warn("Failed to discharge quota of bucket", object.BucketName, "err:", err)
+		return
+	}
+	yig.MetaStorage.ChargeQuota(bucket, -object.Size, -1)
+}
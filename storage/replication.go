@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"github.com/journeymidnight/yig/crypto"
+	meta "github.com/journeymidnight/yig/meta/types"
+)
+
+// replicationStatusOf returns PENDING if the object should be replicated
+// by rules of bucket, objects encrypted with customer keys are never replicated
+// since their keys are not stored.
+func replicationStatusOf(bucket *meta.Bucket, object *meta.Object) string {
+	if object.SseType == crypto.SSEC.String() {
+		return ""
+	}
+	rule := bucket.Replication.MatchRule(object.Name)
+	if rule == nil {
+		return ""
+	}
+	if object.DeleteMarker && !rule.ReplicateDeleteMarker() {
+		return ""
+	}
+	return meta.ReplicationStatusPending
+}
@@ -31,6 +31,7 @@ type YigStorage struct {
 	DataCache   DataCache
 	MetaStorage *meta.Meta
 	KMS         crypto.KMS
+	Keyring     *crypto.Keyring // seals keys of SSE objects stored in metadata
 	Stopping    bool
 	WaitGroup   *sync.WaitGroup
 }
@@ -72,13 +73,39 @@ func (y *YigStorage) PingCache(interval time.Duration) {
 	}
 }
 
-func (yig *YigStorage) encryptionKeyFromSseRequest(sseRequest datatype.SseRequest, bucket, object string) (key []byte, encKey []byte, err error) {
+// kmsContextOf returns the context bound to data keys of SSE-S3 and SSE-KMS objects,
+// encryption context of SSE-KMS requests is added to it
+func kmsContextOf(bucket, object, sseContext string) (crypto.Context, error) {
+	kmsContext, err := crypto.S3KMS.ParseContext(sseContext)
+	if err != nil {
+		return nil, err
+	}
+	kmsContext[bucket] = path.Join(bucket, object)
+	return kmsContext, nil
+}
+
+// encryptionKeyFromSseRequest generates the data key of SSE requests, for SSE-KMS
+// the default key of KMS is filled into sseRequest if key ID is not specified
+func (yig *YigStorage) encryptionKeyFromSseRequest(sseRequest *datatype.SseRequest, bucket, object string) (key []byte, encKey []byte, err error) {
 	switch sseRequest.Type {
 	case "": // no encryption
 		return nil, nil, nil
-	// not implemented yet
 	case crypto.S3KMS.String():
-		return nil, nil, ErrNotImplemented
+		if yig.KMS == nil {
+			return nil, nil, ErrKMSNotConfigured
+		}
+		if sseRequest.SseAwsKmsKeyId == "" {
+			sseRequest.SseAwsKmsKeyId = yig.KMS.GetKeyID()
+		}
+		kmsContext, err := kmsContextOf(bucket, object, sseRequest.SseContext)
+		if err != nil {
+			return nil, nil, ErrInvalidSseHeader
+		}
+		key, encKey, err := yig.KMS.GenerateKey(sseRequest.SseAwsKmsKeyId, kmsContext)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key[:], encKey, nil
 	case crypto.S3.String():
 		if yig.KMS == nil {
 			return nil, nil, ErrKMSNotConfigured
@@ -171,6 +171,32 @@ func TestS3Client_GetEncryptObjectWithSSES3(t *testing.T) {
 	t.Log("GetEncryptObjectWithSSES3 Success value:", v)
 }
 
+func Test_PutEncryptObjectWithSSEKMS(t *testing.T) {
+	sc := NewS3()
+	keyId, err := sc.PutEncryptObjectWithSSEKMS(TEST_BUCKET, TEST_KEY, TEST_VALUE, "", `{"project":"yig"}`)
+	if err != nil {
+		t.Fatal("PutEncryptObjectWithSSEKMS err:", err)
+	}
+	if keyId == "" {
+		t.Fatal("PutEncryptObjectWithSSEKMS err: key id of default key is not returned")
+	}
+	headKeyId, err := sc.HeadEncryptObjectWithSSEKMS(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("HeadEncryptObjectWithSSEKMS err:", err)
+	}
+	if headKeyId != keyId {
+		t.Fatal("HeadEncryptObjectWithSSEKMS err: key id is:", headKeyId, ", but should be:", keyId)
+	}
+	v, err := sc.GetEncryptObjectWithSSES3(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("GetObject of SSE-KMS object err:", err)
+	}
+	if v != TEST_VALUE {
+		t.Fatal("GetObject of SSE-KMS object err: value is:", v, ", but should be:", TEST_VALUE)
+	}
+	t.Log("PutEncryptObjectWithSSEKMS Success key id:", keyId)
+}
+
 func Test_Encrypt_End(t *testing.T) {
 	sc := NewS3()
 	err := sc.DeleteObject(TEST_BUCKET, TEST_KEY)
@@ -48,3 +48,14 @@ func (s3client *S3Client) ListObjects(bucketName string) (objects []*s3.Object,
 	}
 	return
 }
+
+func (s3client *S3Client) PutBucketVersioning(bucketName, status string) (err error) {
+	params := &s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(status),
+		},
+	}
+	_, err = s3client.Client.PutBucketVersioning(params)
+	return err
+}
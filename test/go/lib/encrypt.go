@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/base64"
+	"errors"
 	"github.com/journeymidnight/aws-sdk-go/aws"
 	"github.com/journeymidnight/aws-sdk-go/service/s3"
 	"io/ioutil"
@@ -99,6 +100,47 @@ func (s3client *S3Client) GetEncryptObjectWithSSES3(bucketName, key string) (val
 	return string(data), err
 }
 
+// PutEncryptObjectWithSSEKMS puts an object with SSE-KMS and returns the key ID used,
+// the default key of KMS is used if keyId is empty
+func (s3client *S3Client) PutEncryptObjectWithSSEKMS(bucketName, key, value, keyId, context string) (
+	usedKeyId string, err error) {
+
+	params := &s3.PutObjectInput{
+		Bucket:               aws.String(bucketName),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader([]byte(value)),
+		ServerSideEncryption: aws.String("aws:kms"),
+	}
+	if keyId != "" {
+		params.SSEKMSKeyId = aws.String(keyId)
+	}
+	req, out := s3client.Client.PutObjectRequest(params)
+	if context != "" {
+		// encryption context is not a field of PutObjectInput in this SDK version
+		req.HTTPRequest.Header.Set("X-Amz-Server-Side-Encryption-Context",
+			base64.StdEncoding.EncodeToString([]byte(context)))
+	}
+	if err = req.Send(); err != nil {
+		return "", err
+	}
+	if aws.StringValue(out.ServerSideEncryption) != "aws:kms" {
+		return "", errors.New("unexpected server side encryption: " + aws.StringValue(out.ServerSideEncryption))
+	}
+	return aws.StringValue(out.SSEKMSKeyId), nil
+}
+
+func (s3client *S3Client) HeadEncryptObjectWithSSEKMS(bucketName, key string) (keyId string, err error) {
+	params := &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	}
+	out, err := s3client.Client.HeadObject(params)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.SSEKMSKeyId), nil
+}
+
 func (s3client *S3Client) CreateMultiPartUploadWithSSEC(bucketName, key, storageClass string) (uploadId string, err error) {
 	ssekey := "qwertyuiopasdfghjklzxcvbnmaaaaaa"
 	hash := md5.New()
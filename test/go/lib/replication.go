@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	"github.com/journeymidnight/aws-sdk-go/service/s3"
+)
+
+func (s3client *S3Client) PutBucketReplication(bucketName, id, prefix, destinationArn string) (err error) {
+	params := &s3.PutBucketReplicationInput{
+		Bucket: aws.String(bucketName),
+		ReplicationConfiguration: &s3.ReplicationConfiguration{
+			Role: aws.String(""),
+			Rules: []*s3.ReplicationRule{
+				{
+					ID:     aws.String(id),
+					Prefix: aws.String(prefix),
+					Status: aws.String(s3.ReplicationRuleStatusEnabled),
+					Destination: &s3.Destination{
+						Bucket: aws.String(destinationArn),
+					},
+				},
+			},
+		},
+	}
+	_, err = s3client.Client.PutBucketReplication(params)
+	return err
+}
+
+func (s3client *S3Client) GetBucketReplication(bucketName string) (
+	out *s3.ReplicationConfiguration, err error) {
+
+	params := &s3.GetBucketReplicationInput{
+		Bucket: aws.String(bucketName),
+	}
+	output, err := s3client.Client.GetBucketReplication(params)
+	if err != nil {
+		return nil, err
+	}
+	return output.ReplicationConfiguration, nil
+}
+
+func (s3client *S3Client) DeleteBucketReplication(bucketName string) (err error) {
+	params := &s3.DeleteBucketReplicationInput{
+		Bucket: aws.String(bucketName),
+	}
+	_, err = s3client.Client.DeleteBucketReplication(params)
+	return err
+}
@@ -0,0 +1,79 @@
+package _go
+
+import (
+	"testing"
+
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	. "github.com/journeymidnight/yig/test/go/lib"
+)
+
+// Queue name is the name of MQ plugin in yig.toml
+const TEST_NOTIFICATION_QUEUE = "arn:aws:sqs:cn-bj-1:123456789012:dummy_mq"
+
+func Test_BucketNotification(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+
+	err = sc.PutBucketNotificationWithQueue(TEST_BUCKET, "test", TEST_NOTIFICATION_QUEUE, "images/",
+		"s3:ObjectCreated:*", "s3:ObjectRemoved:Delete")
+	if err != nil {
+		t.Fatal("PutBucketNotification err:", err)
+	}
+	out, err := sc.GetBucketNotification(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("GetBucketNotification err:", err)
+	}
+	if len(out.QueueConfigurations) != 1 || aws.StringValue(out.QueueConfigurations[0].Id) != "test" ||
+		aws.StringValue(out.QueueConfigurations[0].QueueArn) != TEST_NOTIFICATION_QUEUE ||
+		len(out.QueueConfigurations[0].Events) != 2 {
+		t.Fatal("Unexpected notification configuration:", out)
+	}
+
+	// Events are sent asynchronously and should never fail object operations
+	err = sc.PutObject(TEST_BUCKET, "images/"+TEST_KEY, TEST_VALUE)
+	if err != nil {
+		t.Fatal("PutObject err:", err)
+	}
+	err = sc.DeleteObject(TEST_BUCKET, "images/"+TEST_KEY)
+	if err != nil {
+		t.Fatal("DeleteObject err:", err)
+	}
+
+	err = sc.DeleteBucketNotification(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("DeleteBucketNotification err:", err)
+	}
+	out, err = sc.GetBucketNotification(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("GetBucketNotification err:", err)
+	}
+	if len(out.QueueConfigurations) != 0 {
+		t.Fatal("Notification configuration should be empty:", out)
+	}
+}
+
+func Test_BucketNotificationInvalid(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+
+	err = sc.PutBucketNotificationWithQueue(TEST_BUCKET, "test", "arn:aws:sqs:cn-bj-1:123456789012:no_such_mq",
+		"", "s3:ObjectCreated:*")
+	if err == nil {
+		t.Fatal("PutBucketNotification should fail with unknown queue")
+	}
+	err = sc.PutBucketNotificationWithQueue(TEST_BUCKET, "test", TEST_NOTIFICATION_QUEUE,
+		"", "s3:ReducedRedundancyLostObject")
+	if err == nil {
+		t.Fatal("PutBucketNotification should fail with unsupported event")
+	}
+}
@@ -0,0 +1,84 @@
+package _go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	. "github.com/journeymidnight/yig/test/go/lib"
+)
+
+func Test_ObjectLock(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+	_, err = sc.PutObjectWithRetention(TEST_BUCKET, TEST_KEY, TEST_VALUE, "GOVERNANCE",
+		time.Now().Add(time.Hour))
+	if err == nil {
+		t.Fatal("PutObject with retention should fail without object lock enabled")
+	}
+	err = sc.DeleteBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("DeleteBucket err:", err)
+	}
+
+	err = sc.MakeBucketWithObjectLock(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucketWithObjectLock err:", err)
+		panic(err)
+	}
+	err = sc.PutBucketVersioning(TEST_BUCKET, "Suspended")
+	if err == nil {
+		t.Fatal("PutBucketVersioning should fail with object lock enabled")
+	}
+	err = sc.PutObjectLockConfiguration(TEST_BUCKET, "GOVERNANCE", 1)
+	if err != nil {
+		t.Fatal("PutObjectLockConfiguration err:", err)
+	}
+	config, err := sc.GetObjectLockConfiguration(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("GetObjectLockConfiguration err:", err)
+	}
+	if aws.StringValue(config.Rule.DefaultRetention.Mode) != "GOVERNANCE" ||
+		aws.Int64Value(config.Rule.DefaultRetention.Days) != 1 {
+		t.Fatal("Unexpected object lock configuration:", config)
+	}
+
+	versionId, err := sc.PutObjectWithRetention(TEST_BUCKET, TEST_KEY, TEST_VALUE, "GOVERNANCE",
+		time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal("PutObjectWithRetention err:", err)
+	}
+	retention, err := sc.GetObjectRetention(TEST_BUCKET, TEST_KEY, versionId)
+	if err != nil {
+		t.Fatal("GetObjectRetention err:", err)
+	}
+	if aws.StringValue(retention.Mode) != "GOVERNANCE" {
+		t.Fatal("Unexpected retention:", retention)
+	}
+
+	err = sc.DeleteObjectVersion(TEST_BUCKET, TEST_KEY, versionId, false)
+	if err == nil {
+		t.Fatal("DeleteObjectVersion should fail with retention")
+	}
+	err = sc.PutObjectLegalHold(TEST_BUCKET, TEST_KEY, versionId, "ON")
+	if err != nil {
+		t.Fatal("PutObjectLegalHold err:", err)
+	}
+	err = sc.DeleteObjectVersion(TEST_BUCKET, TEST_KEY, versionId, true)
+	if err == nil {
+		t.Fatal("DeleteObjectVersion should fail with legal hold")
+	}
+	err = sc.PutObjectLegalHold(TEST_BUCKET, TEST_KEY, versionId, "OFF")
+	if err != nil {
+		t.Fatal("PutObjectLegalHold err:", err)
+	}
+	err = sc.DeleteObjectVersion(TEST_BUCKET, TEST_KEY, versionId, true)
+	if err != nil {
+		t.Fatal("DeleteObjectVersion with bypass err:", err)
+	}
+}
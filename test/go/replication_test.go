@@ -0,0 +1,61 @@
+package _go
+
+import (
+	"testing"
+
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	. "github.com/journeymidnight/yig/test/go/lib"
+)
+
+// Region of destination is the key of replication_endpoints in yig.toml
+const TEST_REPLICATION_DESTINATION = "arn:aws:s3:cn-bj-1::" + TEST_COPY_BUCKET
+
+func Test_BucketReplication(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+
+	err = sc.PutBucketReplication(TEST_BUCKET, "test", "images/", TEST_REPLICATION_DESTINATION)
+	if err == nil {
+		t.Fatal("PutBucketReplication should fail without versioning")
+	}
+	err = sc.PutBucketVersioning(TEST_BUCKET, "Enabled")
+	if err != nil {
+		t.Fatal("PutBucketVersioning err:", err)
+	}
+	err = sc.PutBucketReplication(TEST_BUCKET, "test", "images/",
+		"arn:aws:s3:no-such-region::"+TEST_COPY_BUCKET)
+	if err == nil {
+		t.Fatal("PutBucketReplication should fail with unknown destination region")
+	}
+	err = sc.PutBucketReplication(TEST_BUCKET, "test", "images/", TEST_REPLICATION_DESTINATION)
+	if err != nil {
+		t.Fatal("PutBucketReplication err:", err)
+	}
+
+	out, err := sc.GetBucketReplication(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("GetBucketReplication err:", err)
+	}
+	if len(out.Rules) != 1 || aws.StringValue(out.Rules[0].ID) != "test" ||
+		aws.StringValue(out.Rules[0].Destination.Bucket) != TEST_REPLICATION_DESTINATION {
+		t.Fatal("Unexpected replication configuration:", out)
+	}
+	err = sc.PutBucketVersioning(TEST_BUCKET, "Suspended")
+	if err == nil {
+		t.Fatal("PutBucketVersioning should fail with replication configured")
+	}
+
+	err = sc.DeleteBucketReplication(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("DeleteBucketReplication err:", err)
+	}
+	_, err = sc.GetBucketReplication(TEST_BUCKET)
+	if err == nil {
+		t.Fatal("GetBucketReplication should fail after deleted")
+	}
+}
@@ -0,0 +1,154 @@
+package _go
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	. "github.com/journeymidnight/yig/test/go/lib"
+)
+
+const TEST_COPY_KEY = "testcopytagging"
+
+func Test_ObjectTagging(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	defer sc.DeleteObject(TEST_BUCKET, TEST_COPY_KEY)
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+
+	err = sc.PutObjectWithTagging(TEST_BUCKET, TEST_KEY, TEST_VALUE, "k1=v1&k2=v2")
+	if err != nil {
+		t.Fatal("PutObjectWithTagging err:", err)
+	}
+	out, err := sc.GetObjectOutPut(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("GetObject err:", err)
+	}
+	if aws.Int64Value(out.TagCount) != 2 {
+		t.Fatal("x-amz-tagging-count should be 2, but got:", aws.Int64Value(out.TagCount))
+	}
+	tags, err := sc.GetObjectTagging(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("GetObjectTagging err:", err)
+	}
+	if !reflect.DeepEqual(tags, map[string]string{"k1": "v1", "k2": "v2"}) {
+		t.Fatal("Unexpected tags:", tags)
+	}
+
+	newTags := map[string]string{"k3": "v3"}
+	err = sc.PutObjectTagging(TEST_BUCKET, TEST_KEY, newTags)
+	if err != nil {
+		t.Fatal("PutObjectTagging err:", err)
+	}
+	tags, err = sc.GetObjectTagging(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("GetObjectTagging err:", err)
+	}
+	if !reflect.DeepEqual(tags, newTags) {
+		t.Fatal("Unexpected tags after PutObjectTagging:", tags)
+	}
+
+	// Tags are copied by default
+	err = sc.CopyObjectWithTagging(TEST_BUCKET, TEST_COPY_KEY, TEST_KEY, "COPY", "")
+	if err != nil {
+		t.Fatal("CopyObject err:", err)
+	}
+	tags, err = sc.GetObjectTagging(TEST_BUCKET, TEST_COPY_KEY)
+	if err != nil {
+		t.Fatal("GetObjectTagging err:", err)
+	}
+	if !reflect.DeepEqual(tags, newTags) {
+		t.Fatal("Unexpected tags of copied object:", tags)
+	}
+	err = sc.CopyObjectWithTagging(TEST_BUCKET, TEST_COPY_KEY, TEST_KEY, "REPLACE", "k4=v4")
+	if err != nil {
+		t.Fatal("CopyObject err:", err)
+	}
+	tags, err = sc.GetObjectTagging(TEST_BUCKET, TEST_COPY_KEY)
+	if err != nil {
+		t.Fatal("GetObjectTagging err:", err)
+	}
+	if !reflect.DeepEqual(tags, map[string]string{"k4": "v4"}) {
+		t.Fatal("Unexpected tags of copied object with REPLACE:", tags)
+	}
+
+	err = sc.DeleteObjectTagging(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("DeleteObjectTagging err:", err)
+	}
+	tags, err = sc.GetObjectTagging(TEST_BUCKET, TEST_KEY)
+	if err != nil {
+		t.Fatal("GetObjectTagging err:", err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("Tags should be empty after DeleteObjectTagging:", tags)
+	}
+}
+
+func Test_ObjectTaggingInvalid(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+	err = sc.PutObject(TEST_BUCKET, TEST_KEY, TEST_VALUE)
+	if err != nil {
+		t.Fatal("PutObject err:", err)
+	}
+
+	tooManyTags := make(map[string]string)
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"} {
+		tooManyTags[k] = k
+	}
+	err = sc.PutObjectTagging(TEST_BUCKET, TEST_KEY, tooManyTags)
+	if err == nil {
+		t.Fatal("PutObjectTagging with more than 10 tags should fail")
+	}
+	err = sc.PutObjectWithTagging(TEST_BUCKET, TEST_KEY, TEST_VALUE, "k1=v1&k1=v2")
+	if err == nil {
+		t.Fatal("PutObject with duplicated tag keys should fail")
+	}
+}
+
+func Test_BucketTagging(t *testing.T) {
+	sc := NewS3()
+	defer sc.CleanEnv()
+	err := sc.MakeBucket(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("MakeBucket err:", err)
+		panic(err)
+	}
+
+	_, err = sc.GetBucketTagging(TEST_BUCKET)
+	if err == nil {
+		t.Fatal("GetBucketTagging should fail with NoSuchTagSet before tags are set")
+	}
+
+	tags := map[string]string{"cost-center": "rd", "project": "yig"}
+	err = sc.PutBucketTagging(TEST_BUCKET, tags)
+	if err != nil {
+		t.Fatal("PutBucketTagging err:", err)
+	}
+	out, err := sc.GetBucketTagging(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("GetBucketTagging err:", err)
+	}
+	if !reflect.DeepEqual(out, tags) {
+		t.Fatal("Unexpected bucket tags:", out)
+	}
+
+	err = sc.DeleteBucketTagging(TEST_BUCKET)
+	if err != nil {
+		t.Fatal("DeleteBucketTagging err:", err)
+	}
+	_, err = sc.GetBucketTagging(TEST_BUCKET)
+	if err == nil {
+		t.Fatal("GetBucketTagging should fail with NoSuchTagSet after tags are deleted")
+	}
+}
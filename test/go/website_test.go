@@ -89,7 +89,28 @@ var testUnits = []WebsiteTestUnit{
 		Fn: doGet,
 		Cases: []Case{
 			{"http://" + TEST_BUCKET + "." + Endpoint, 200, testIndexHTML, false},
-			{"http://" + TEST_BUCKET + "." + Endpoint + "/aaa.txt", 404, testErrorHTML, false},
+			// Anonymous users without s3:ListBucket get 403 for missing keys
+			{"http://" + TEST_BUCKET + "." + Endpoint + "/aaa.txt", 403, testErrorHTML, false},
+		},
+	},
+	// Serve the same configuration from the website endpoint
+	{
+		WebsiteConfiguration: &s3.WebsiteConfiguration{
+			IndexDocument: &s3.IndexDocument{Suffix: aws.String("index.html")},
+			ErrorDocument: &s3.ErrorDocument{Key: aws.String("error.html")},
+		},
+		Buckets: []string{TEST_BUCKET},
+		Objects: []ObjectInput{
+			{TEST_BUCKET, "index.html", testIndexHTML},
+			{TEST_BUCKET, "error.html", testErrorHTML},
+			{TEST_BUCKET, "docs/index.html", testIndexHTML},
+		},
+		Fn: doGet,
+		Cases: []Case{
+			{"http://" + TEST_BUCKET + "." + EndpointWebsite, 200, testIndexHTML, false},
+			{"http://" + TEST_BUCKET + "." + EndpointWebsite + "/docs/", 200, testIndexHTML, false},
+			{"http://" + TEST_BUCKET + "." + EndpointWebsite + "/docs", 200, testIndexHTML, true},
+			{"http://" + TEST_BUCKET + "." + EndpointWebsite + "/aaa.txt", 403, testErrorHTML, false},
 		},
 	},
 	// Configure bucket as a website but redirect all requests
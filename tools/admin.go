@@ -21,11 +21,21 @@ var config Config
 
 func printHelp() {
 	fmt.Println("Usage: admin <commands> [options...] ")
-	fmt.Println("Commands: usage|bucket|object|user|cachehit")
+	fmt.Println("Commands: usage|bucket|object|user|cachehit|quota|setquota|delquota|" +
+		"publicaccessblock|setpublicaccessblock|delpublicaccessblock|simulate")
 	fmt.Println("Options:")
 	fmt.Println(" -b, --bucket   Specify bucket to operate")
 	fmt.Println(" -u, --uid      Specify user name to operate")
 	fmt.Println(" -o, --object   Specify object to operate")
+	fmt.Println(" -max-bytes     Specify max bytes of quota, -1 for unlimited")
+	fmt.Println(" -max-objects   Specify max objects of quota, -1 for unlimited")
+	fmt.Println(" -block-public-acls        Reject public ACLs on buckets of user")
+	fmt.Println(" -ignore-public-acls       Ignore public ACLs on buckets of user")
+	fmt.Println(" -block-public-policy      Reject public bucket policies on buckets of user")
+	fmt.Println(" -restrict-public-buckets  Restrict access to buckets of user with public policies")
+	fmt.Println(" -a, --action   Specify action to simulate, e.g. s3:GetObject")
+	fmt.Println(" -c, --conditions  Specify condition context to simulate in JSON, " +
+		`e.g. {"aws:SourceIp":["10.0.0.1"]}`)
 }
 
 func isParaEmpty(p string) bool {
@@ -212,6 +222,146 @@ func getCacheHit() {
 
 }
 
+// quota gets, sets or deletes quota of bucket, or user if bucket is empty
+func quota(method string, bucket string, uid string, maxBytes int64, maxObjects int64) {
+	if bucket == "" && isParaEmpty(uid) {
+		return
+	}
+
+	claims := jwt.MapClaims{}
+	if bucket != "" {
+		claims["bucket"] = bucket
+	} else {
+		claims["uid"] = uid
+	}
+	if method == "PUT" {
+		claims["max_bytes"] = maxBytes
+		claims["max_objects"] = maxObjects
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(config.AdminKey))
+
+	if err == nil {
+		//go use token
+		fmt.Printf("\nHS256 = %v\n", tokenString)
+	} else {
+		fmt.Println("internal error", err)
+		return
+	}
+
+	url := config.RequestUrl + "/admin/quota"
+	request, _ := http.NewRequest(method, url, nil)
+	request.Header.Set("Authorization", "Bearer "+tokenString)
+	response, err := client.Do(request)
+	if err != nil {
+		fmt.Println("quota failed error:", err.Error())
+		return
+	}
+	if response.StatusCode != 200 && response.StatusCode != 204 {
+		fmt.Println("quota failed as status != 200", response.StatusCode)
+		return
+	}
+
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	fmt.Println(string(body))
+}
+
+// publicAccessBlock gets, sets or deletes account level Block Public Access settings of user
+func publicAccessBlock(method string, uid string, settings map[string]bool) {
+	if isParaEmpty(uid) {
+		return
+	}
+
+	claims := jwt.MapClaims{"uid": uid}
+	if method == "PUT" {
+		for key, value := range settings {
+			claims[key] = value
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(config.AdminKey))
+
+	if err == nil {
+		//go use token
+		fmt.Printf("\nHS256 = %v\n", tokenString)
+	} else {
+		fmt.Println("internal error", err)
+		return
+	}
+
+	url := config.RequestUrl + "/admin/publicaccessblock"
+	request, _ := http.NewRequest(method, url, nil)
+	request.Header.Set("Authorization", "Bearer "+tokenString)
+	response, err := client.Do(request)
+	if err != nil {
+		fmt.Println("publicaccessblock failed error:", err.Error())
+		return
+	}
+	if response.StatusCode != 200 && response.StatusCode != 204 {
+		fmt.Println("publicaccessblock failed as status != 200", response.StatusCode)
+		return
+	}
+
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	fmt.Println(string(body))
+}
+
+// simulate explains if user, or anonymous users if uid is empty, is allowed
+// to access bucket or object with action under condition context
+func simulate(uid string, bucket string, object string, action string, conditions string) {
+	if isParaEmpty(bucket) || isParaEmpty(action) {
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"uid":    uid,
+		"bucket": bucket,
+		"object": object,
+		"action": action,
+	}
+	if conditions != "" {
+		var conditionValues map[string]interface{}
+		err := json.Unmarshal([]byte(conditions), &conditionValues)
+		if err != nil {
+			fmt.Println("invalid conditions", err)
+			return
+		}
+		claims["conditions"] = conditionValues
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(config.AdminKey))
+
+	if err == nil {
+		//go use token
+		fmt.Printf("\nHS256 = %v\n", tokenString)
+	} else {
+		fmt.Println("internal error", err)
+		return
+	}
+
+	url := config.RequestUrl + "/admin/simulate"
+	request, _ := http.NewRequest("GET", url, nil)
+	request.Header.Set("Authorization", "Bearer "+tokenString)
+	response, err := client.Do(request)
+	if err != nil {
+		fmt.Println("simulate failed error:", err.Error())
+		return
+	}
+	if response.StatusCode != 200 {
+		fmt.Println("simulate failed as status != 200", response.StatusCode)
+		return
+	}
+
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	fmt.Println(string(body))
+}
+
 func main() {
 	f, err := os.Open("./admin.json")
 	if err != nil {
@@ -231,6 +381,14 @@ func main() {
 	bucket := mySet.String("b", "", "bucket name")
 	uid := mySet.String("u", "", "user name")
 	object := mySet.String("o", "", "object name")
+	maxBytes := mySet.Int64("max-bytes", -1, "max bytes of quota")
+	maxObjects := mySet.Int64("max-objects", -1, "max objects of quota")
+	blockPublicAcls := mySet.Bool("block-public-acls", false, "reject public ACLs")
+	ignorePublicAcls := mySet.Bool("ignore-public-acls", false, "ignore public ACLs")
+	blockPublicPolicy := mySet.Bool("block-public-policy", false, "reject public bucket policies")
+	restrictPublicBuckets := mySet.Bool("restrict-public-buckets", false, "restrict buckets with public policies")
+	action := mySet.String("a", "", "action to simulate")
+	conditions := mySet.String("c", "", "condition context to simulate in JSON")
 	mySet.Parse(os.Args[2:])
 	fmt.Println("command:", os.Args[1], "bucket:", *bucket, "user:", *uid, "object:", *object)
 	switch os.Args[1] {
@@ -244,6 +402,25 @@ func main() {
 		getObjectInfo(*bucket, *object)
 	case "cachehit":
 		getCacheHit()
+	case "quota":
+		quota("GET", *bucket, *uid, 0, 0)
+	case "setquota":
+		quota("PUT", *bucket, *uid, *maxBytes, *maxObjects)
+	case "delquota":
+		quota("DELETE", *bucket, *uid, 0, 0)
+	case "publicaccessblock":
+		publicAccessBlock("GET", *uid, nil)
+	case "setpublicaccessblock":
+		publicAccessBlock("PUT", *uid, map[string]bool{
+			"block_public_acls":       *blockPublicAcls,
+			"ignore_public_acls":      *ignorePublicAcls,
+			"block_public_policy":     *blockPublicPolicy,
+			"restrict_public_buckets": *restrictPublicBuckets,
+		})
+	case "delpublicaccessblock":
+		publicAccessBlock("DELETE", *uid, nil)
+	case "simulate":
+		simulate(*uid, *bucket, *object, *action, *conditions)
 	default:
 		printHelp()
 		return
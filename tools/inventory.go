@@ -0,0 +1,482 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/crypto"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/iam/common"
+	"github.com/journeymidnight/yig/log"
+	"github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/mods"
+	"github.com/journeymidnight/yig/redis"
+	"github.com/journeymidnight/yig/storage"
+)
+
+const (
+	SCAN_LIMIT                 = 100
+	SCAN_INTERVAL              = 10 * time.Minute
+	DEFAULT_INVENTORY_LOG_PATH = "/var/log/yig/inventory.log"
+	LEASE_TIMEOUT              = 5 * time.Minute
+	LEASE_RENEW_INTERVAL       = time.Minute
+	// rows of each data file, reports of large buckets are split into files
+	MAX_FILE_ROWS     = 1000000
+	INVENTORY_VERSION = "2016-11-30"
+	TIMESTAMP_LAYOUT  = "2006-01-02T15:04:05.000Z"
+)
+
+var (
+	yig         *storage.YigStorage
+	taskQ       chan types.Inventory
+	signalQueue chan os.Signal
+	batch       sync.WaitGroup
+	stop        int32 // set by signal handler, read by workers
+
+	errInventoryCancelled = errors.New("inventory export cancelled")
+)
+
+type manifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5checksum string `json:"MD5checksum"`
+}
+
+type manifest struct {
+	SourceBucket      string         `json:"sourceBucket"`
+	DestinationBucket string         `json:"destinationBucket"`
+	Version           string         `json:"version"`
+	CreationTimestamp string         `json:"creationTimestamp"`
+	FileFormat        string         `json:"fileFormat"`
+	FileSchema        string         `json:"fileSchema"`
+	Files             []manifestFile `json:"files"`
+}
+
+// export is a report being exported, data files are uploaded once
+// MAX_FILE_ROWS rows are written
+type export struct {
+	inventory   types.Inventory
+	owner       string
+	credential  common.Credential
+	destination string
+	keyPrefix   string // <prefix>/<source bucket>/<config id>
+	fields      []string
+	versioned   bool
+	lastRenew   time.Time
+
+	file  *os.File
+	gzip  *gzip.Writer
+	md5   hash.Hash
+	rows  int
+	files []manifestFile
+}
+
+// periodStart returns the start of the current schedule period in UTC,
+// a report is exported once in each period. Weekly reports start on Sunday.
+func periodStart(frequency string, now time.Time) time.Time {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if frequency == datatype.InventoryFrequencyWeekly {
+		start = start.AddDate(0, 0, -int(start.Weekday()))
+	}
+	return start
+}
+
+func (e *export) schema() []string {
+	columns := []string{"Bucket", "Key"}
+	if e.versioned {
+		columns = append(columns, "VersionId", "IsLatest", "IsDeleteMarker")
+	}
+	return append(columns, e.fields...)
+}
+
+// cancelled renews the lease if needed, true if the lease is lost or stopping
+func (e *export) cancelled() bool {
+	if stopping() {
+		return true
+	}
+	if time.Since(e.lastRenew) < LEASE_RENEW_INTERVAL {
+		return false
+	}
+	ok, err := yig.MetaStorage.RenewInventory(e.inventory.BucketName, e.inventory.Id, e.owner,
+		time.Now().Add(LEASE_TIMEOUT))
+	if err != nil || !ok {
+		helper.Logger.Warn("Lost lease of inventory", e.inventory.BucketName, e.inventory.Id, err)
+		return true
+	}
+	e.lastRenew = time.Now()
+	return false
+}
+
+func fieldValue(field string, object *types.Object) string {
+	if field == "LastModifiedDate" {
+		return object.LastModifiedTime.UTC().Format(TIMESTAMP_LAYOUT)
+	}
+	if object.DeleteMarker {
+		return ""
+	}
+	switch field {
+	case "Size":
+		return strconv.FormatInt(object.Size, 10)
+	case "StorageClass":
+		return object.StorageClass.ToString()
+	case "ETag":
+		return object.Etag
+	case "IsMultipartUploaded":
+		return strconv.FormatBool(object.Type == types.ObjectTypeMultipart)
+	case "ReplicationStatus":
+		return object.ReplicationStatus
+	case "EncryptionStatus":
+		if object.SseType == "" {
+			return "NOT-SSE"
+		}
+		return object.SseType
+	case "ObjectLockRetainUntilDate":
+		if object.ObjectLock.RetainUntilDate.IsZero() {
+			return ""
+		}
+		return object.ObjectLock.RetainUntilDate.UTC().Format(TIMESTAMP_LAYOUT)
+	case "ObjectLockMode":
+		return object.ObjectLock.Mode
+	case "ObjectLockLegalHoldStatus":
+		return object.ObjectLock.LegalHold
+	case "ObjectType":
+		return object.ObjectTypeToString()
+	}
+	return ""
+}
+
+// writeRow writes a CSV row with all fields quoted, keys are URL encoded as AWS does
+func (e *export) writeRow(object *types.Object, isLatest bool) error {
+	values := []string{object.BucketName, url.QueryEscape(object.Name)}
+	if e.versioned {
+		values = append(values, object.GetVersionId(), strconv.FormatBool(isLatest),
+			strconv.FormatBool(object.DeleteMarker))
+	}
+	for _, field := range e.fields {
+		values = append(values, fieldValue(field, object))
+	}
+	if e.file == nil {
+		if err := e.createFile(); err != nil {
+			return err
+		}
+	}
+	for i, value := range values {
+		values[i] = `"` + strings.Replace(value, `"`, `""`, -1) + `"`
+	}
+	if _, err := io.WriteString(e.gzip, strings.Join(values, ",")+"\n"); err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows >= MAX_FILE_ROWS {
+		return e.uploadFile()
+	}
+	return nil
+}
+
+func (e *export) createFile() (err error) {
+	e.file, err = ioutil.TempFile(helper.CONFIG.InventorySpoolDir, "inventory-")
+	if err != nil {
+		return err
+	}
+	e.md5 = md5.New()
+	e.gzip = gzip.NewWriter(io.MultiWriter(e.file, e.md5))
+	e.rows = 0
+	return nil
+}
+
+func (e *export) removeFile() {
+	if e.file == nil {
+		return
+	}
+	e.file.Close()
+	os.Remove(e.file.Name())
+	e.file = nil
+}
+
+// uploadFile uploads the current data file to destination bucket
+func (e *export) uploadFile() error {
+	defer e.removeFile()
+	if err := e.gzip.Close(); err != nil {
+		return err
+	}
+	size, err := e.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = e.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	key := e.keyPrefix + "/data/" + string(helper.GenerateRandomId()) + ".csv.gz"
+	err = e.put(key, size, ioutil.NopCloser(e.file), "application/x-gzip")
+	if err != nil {
+		return err
+	}
+	e.files = append(e.files, manifestFile{
+		Key:         key,
+		Size:        size,
+		MD5checksum: hex.EncodeToString(e.md5.Sum(nil)),
+	})
+	return nil
+}
+
+func (e *export) put(key string, size int64, data io.ReadCloser, contentType string) error {
+	metadata := map[string]string{"Content-Type": contentType}
+	_, err := yig.PutObject(e.destination, key, e.credential, size, data, metadata,
+		datatype.Acl{CannedAcl: "private"}, nil, datatype.ObjectLock{},
+		e.inventory.Config.Destination.S3BucketDestination.SseRequest(), types.ObjectStorageClassStandard,
+		datatype.WriteCondition{})
+	return err
+}
+
+// cleanup removes data files uploaded by a failed export
+func (e *export) cleanup() {
+	e.removeFile()
+	for _, file := range e.files {
+		_, err := yig.DeleteObject(e.destination, file.Key, "", false, e.credential)
+		if err != nil {
+			helper.Logger.Warn("Failed to remove inventory file", e.destination, file.Key, err)
+		}
+	}
+}
+
+// walk lists objects of source bucket, only the first version of each object is the latest
+func (e *export) walk(bucket *types.Bucket) error {
+	var request datatype.ListObjectsRequest
+	request.Versioned = e.versioned
+	request.Prefix = e.inventory.Config.GetPrefix()
+	request.MaxKeys = 1000
+	var lastName string
+	for {
+		if e.cancelled() {
+			return errInventoryCancelled
+		}
+		retObjects, _, truncated, nextMarker, nextVerIdMarker, err := yig.ListObjectsInternal(bucket.Name, request)
+		if err != nil {
+			return err
+		}
+		for _, object := range retObjects {
+			err = e.writeRow(object, object.Name != lastName)
+			if err != nil {
+				return err
+			}
+			lastName = object.Name
+		}
+		if truncated == false {
+			break
+		}
+		request.Marker = nextMarker
+		request.KeyMarker = nextMarker
+		request.VersionIdMarker = nextVerIdMarker
+	}
+	if e.file != nil {
+		return e.uploadFile()
+	}
+	return nil
+}
+
+// writeManifest uploads manifest.json listing all data files, and
+// manifest.checksum which is MD5 of manifest.json
+func (e *export) writeManifest(start time.Time) error {
+	m := manifest{
+		SourceBucket:      e.inventory.BucketName,
+		DestinationBucket: e.inventory.Config.Destination.S3BucketDestination.Bucket,
+		Version:           INVENTORY_VERSION,
+		CreationTimestamp: strconv.FormatInt(start.UnixNano()/int64(time.Millisecond), 10),
+		FileFormat:        e.inventory.Config.Destination.S3BucketDestination.Format,
+		FileSchema:        strings.Join(e.schema(), ", "),
+		Files:             e.files,
+	}
+	if m.Files == nil {
+		m.Files = []manifestFile{}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	dir := e.keyPrefix + "/" + start.UTC().Format("2006-01-02T15-04Z")
+	err = e.put(dir+"/manifest.json", int64(len(data)),
+		ioutil.NopCloser(strings.NewReader(string(data))), "application/json")
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(data)
+	checksum := hex.EncodeToString(sum[:])
+	return e.put(dir+"/manifest.checksum", int64(len(checksum)),
+		ioutil.NopCloser(strings.NewReader(checksum)), "text/plain")
+}
+
+func (e *export) run() error {
+	start := time.Now()
+	bucket, err := yig.GetBucket(e.inventory.BucketName)
+	if err != nil {
+		return err
+	}
+	// reports are written with permission of bucket owner
+	destination, err := yig.GetBucket(e.destination)
+	if err != nil {
+		return err
+	}
+	if destination.OwnerId != bucket.OwnerId {
+		return errors.New("destination bucket " + e.destination + " is not owned by bucket owner")
+	}
+	e.credential = common.Credential{UserId: bucket.OwnerId}
+	e.versioned = e.inventory.Config.IncludedObjectVersions == datatype.InventoryVersionsAll &&
+		bucket.Versioning != types.VersionDisabled
+
+	err = e.walk(bucket)
+	if err == nil {
+		err = e.writeManifest(start)
+	}
+	if err != nil {
+		e.cleanup()
+		return err
+	}
+	helper.Logger.Info("Inventory", e.inventory.Id, "of bucket", e.inventory.BucketName, "exported to",
+		e.destination, e.keyPrefix, "files:", len(e.files), "in", time.Since(start))
+	return nil
+}
+
+func exportInventory(inventory types.Inventory, owner string) {
+	config := inventory.Config
+	exportedBefore := periodStart(config.Schedule.Frequency, time.Now())
+	ok, err := yig.MetaStorage.AcquireInventory(inventory.BucketName, inventory.Id, owner,
+		time.Now().Add(LEASE_TIMEOUT), exportedBefore)
+	if err != nil {
+		helper.Logger.Error("AcquireInventory failed:", inventory.BucketName, inventory.Id, err)
+		return
+	}
+	if !ok {
+		// exported in this period, or exporting by others
+		return
+	}
+	destination := config.Destination.S3BucketDestination
+	e := &export{
+		inventory:   inventory,
+		owner:       owner,
+		destination: destination.DestinationBucket(),
+		keyPrefix:   path.Join(destination.Prefix, inventory.BucketName, inventory.Id),
+		fields:      config.Fields(),
+		lastRenew:   time.Now(),
+	}
+	err = e.run()
+	if err != nil {
+		helper.Logger.Error("Export inventory", inventory.Id, "of bucket", inventory.BucketName,
+			"failed:", err)
+	}
+	err = yig.MetaStorage.ReleaseInventory(inventory.BucketName, inventory.Id, owner, err == nil)
+	if err != nil {
+		helper.Logger.Error("ReleaseInventory failed:", inventory.BucketName, inventory.Id, err)
+	}
+}
+
+func processInventory(owner string) {
+	for inventory := range taskQ {
+		exportInventory(inventory, owner)
+		batch.Done()
+	}
+}
+
+// scan all configurations periodically, and export reports due in current period
+func scanInventory() {
+	for {
+		var marker types.Inventory
+		for !stopping() {
+			inventories, err := yig.MetaStorage.ScanInventories(marker, SCAN_LIMIT)
+			if err != nil {
+				helper.Logger.Error("ScanInventories failed:", err)
+				break
+			}
+			now := time.Now()
+			for _, inventory := range inventories {
+				if !inventory.Config.IsEnabled ||
+					!inventory.LastExport.Before(periodStart(inventory.Config.Schedule.Frequency, now)) {
+					continue
+				}
+				batch.Add(1)
+				taskQ <- inventory
+			}
+			if len(inventories) < SCAN_LIMIT {
+				break
+			}
+			marker = inventories[len(inventories)-1]
+		}
+		batch.Wait()
+		if stopping() {
+			helper.Logger.Info("Shutting down...")
+			close(taskQ)
+			return
+		}
+		time.Sleep(SCAN_INTERVAL)
+	}
+}
+
+// stopping returns if the tool is stopped by signals
+func stopping() bool {
+	return atomic.LoadInt32(&stop) == 1
+}
+
+func main() {
+
+	helper.SetupConfig()
+	logLevel := log.ParseLevel(helper.CONFIG.LogLevel)
+
+	helper.Logger = log.NewFileLogger(DEFAULT_INVENTORY_LOG_PATH, logLevel)
+	defer helper.Logger.Close()
+	if helper.CONFIG.MetaCacheType > 0 || helper.CONFIG.EnableDataCache {
+		redis.Initialize()
+		defer redis.Close()
+	}
+	if err := os.MkdirAll(helper.CONFIG.InventorySpoolDir, 0755); err != nil {
+		helper.Logger.Error("Failed to create inventory spool dir:", err)
+		return
+	}
+
+	// Read all *.so from plugins directory, and fill the variable allPlugins
+	allPluginMap := mods.InitialPlugins()
+	kms := crypto.NewKMS(allPluginMap)
+
+	yig = storage.New(helper.CONFIG.MetaCacheType, helper.CONFIG.EnableDataCache, kms)
+	taskQ = make(chan types.Inventory, SCAN_LIMIT)
+	signal.Ignore()
+	signalQueue = make(chan os.Signal)
+
+	numOfWorkers := helper.CONFIG.InventoryThread
+	helper.Logger.Info("start inventory thread:", numOfWorkers)
+	for i := 0; i < numOfWorkers; i++ {
+		go processInventory(helper.CONFIG.InstanceId + "-" + strconv.Itoa(i))
+	}
+	go scanInventory()
+	signal.Notify(signalQueue, syscall.SIGINT, syscall.SIGTERM,
+		syscall.SIGQUIT, syscall.SIGHUP)
+	for {
+		s := <-signalQueue
+		switch s {
+		case syscall.SIGHUP:
+			// reload config file
+			helper.SetupConfig()
+		default:
+			// exports in progress are cancelled and retried later
+			atomic.StoreInt32(&stop, 1)
+			batch.Wait()
+			return
+		}
+	}
+}
@@ -1,231 +1,639 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
 	"github.com/journeymidnight/yig/api/datatype"
 	"github.com/journeymidnight/yig/crypto"
+	. "github.com/journeymidnight/yig/error"
 	"github.com/journeymidnight/yig/helper"
 	"github.com/journeymidnight/yig/iam/common"
 	"github.com/journeymidnight/yig/log"
 	"github.com/journeymidnight/yig/meta/types"
 	"github.com/journeymidnight/yig/mods"
+	bus "github.com/journeymidnight/yig/mq"
 	"github.com/journeymidnight/yig/redis"
 	"github.com/journeymidnight/yig/storage"
-	"os"
-	"os/signal"
-	"strconv"
-	"strings"
-	"sync"
-	"syscall"
-	"time"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
 	SCAN_LIMIT          = 50
 	DEFAULT_LC_LOG_PATH = "/var/log/yig/lc.log"
+	// buckets are processed by instance holding the lease, leases of
+	// crashed instances expire and the buckets are resumed by others
+	LEASE_TIMEOUT         = 5 * time.Minute
+	LEASE_RENEW_INTERVAL  = time.Minute
+	WINDOW_CHECK_INTERVAL = time.Minute
 )
 
 var (
 	yig         *storage.YigStorage
-	taskQ       chan types.LifeCycle
+	taskQ       chan *lcTask
 	signalQueue chan os.Signal
-	waitgroup   sync.WaitGroup
-	empty       bool
-	stop        bool
+	batch       sync.WaitGroup
+	stop        int32 // set by signal handler, read by workers
+
+	errLcCancelled = errors.New("lifecycle processing cancelled")
 )
 
-func getLifeCycles() {
-	var marker string
-	helper.Logger.Info("all bucket lifecycle handle start")
-	waitgroup.Add(1)
-	defer waitgroup.Done()
-	for {
-		if stop {
-			helper.Logger.Info("shutting down...")
-			return
+var (
+	lcObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "yig",
+		Subsystem: "lc",
+		Name:      "objects_total",
+		Help:      "Object versions processed by lifecycle rules, by action",
+	}, []string{"action"})
+	lcBuckets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "yig",
+		Subsystem: "lc",
+		Name:      "buckets_total",
+		Help:      "Buckets processed by lifecycle rules, by result",
+	}, []string{"result"})
+	lcBucketsPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "yig",
+		Subsystem: "lc",
+		Name:      "buckets_pending",
+		Help:      "Buckets waiting to be processed in the current pass",
+	})
+	lcBucketsInProgress = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "yig",
+		Subsystem: "lc",
+		Name:      "buckets_in_progress",
+		Help:      "Buckets being processed by this instance",
+	})
+	lcLastPass = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "yig",
+		Subsystem: "lc",
+		Name:      "last_pass_timestamp_seconds",
+		Help:      "When the last pass over all buckets finished",
+	})
+)
+
+// lcTask is a bucket to process in the window, by the worker holding its lease
+type lcTask struct {
+	lc          types.LifeCycle
+	owner       string
+	windowStart time.Time
+	windowEnd   time.Time // zero if not limited
+	lost        int32     // set when the lease is taken by others or fails to renew
+	done        string    // the last object processed, saved as marker
+}
+
+// cancelled returns if processing should stop, the bucket is resumed from marker later
+func (t *lcTask) cancelled() bool {
+	if stopping() || atomic.LoadInt32(&t.lost) == 1 {
+		return true
+	}
+	return !t.windowEnd.IsZero() && time.Now().After(t.windowEnd)
+}
+
+func (t *lcTask) saveMarker() {
+	if t.done == "" || t.done == t.lc.Marker {
+		return
+	}
+	err := yig.MetaStorage.UpdateLifeCycleMarker(t.lc.BucketName, t.owner, t.done)
+	if err != nil {
+		helper.Logger.Warn("Failed to save lifecycle marker of", t.lc.BucketName, "err:", err)
+		return
+	}
+	t.lc.Marker = t.done
+}
+
+// lifecycleDays returns duration of days in lifecycle rules, days are seconds in debug mode
+func lifecycleDays(days int) time.Duration {
+	if helper.CONFIG.DebugMode == false {
+		return time.Duration(days) * 24 * time.Hour
+	} else {
+		return time.Duration(days) * time.Second
+	}
+}
+
+func checkIfExpiration(updateTime time.Time, days int) bool {
+	return time.Since(updateTime) >= lifecycleDays(days)
+}
+
+// checkIfDateReached checks date of rules, which is already validated
+func checkIfDateReached(date time.Time, err error) bool {
+	return err == nil && !time.Now().Before(date)
+}
+
+// matchRules returns enabled rules applying to an object version
+func matchRules(rules []datatype.LifecycleRule, object *types.Object) (matched []datatype.LifecycleRule) {
+	for _, rule := range rules {
+		if rule.IsEnabled() && rule.Match(object.Name, object.Size, object.Tags) {
+			matched = append(matched, rule)
 		}
+	}
+	return
+}
 
-		result, err := yig.MetaStorage.ScanLifeCycle(SCAN_LIMIT, marker)
-		if err != nil {
-			helper.Logger.Error("ScanLifeCycle failed:", err)
-			signalQueue <- syscall.SIGQUIT
-			return
+// checkIfExpired checks if current version of object is expired by any of rules
+func checkIfExpired(object *types.Object, rules []datatype.LifecycleRule) bool {
+	for _, rule := range rules {
+		if !rule.HasExpiration() {
+			continue
 		}
-		for _, entry := range result.Lcs {
-			taskQ <- entry
-			marker = entry.BucketName
+		if rule.Expiration.Days != 0 && checkIfExpiration(object.LastModifiedTime, rule.Expiration.Days) {
+			return true
 		}
+		if rule.Expiration.Date != "" && checkIfDateReached(rule.Expiration.GetDate()) {
+			return true
+		}
+	}
+	return false
+}
 
-		if result.Truncated == false {
-			empty = true
-			return
+// transitionStorageClass returns the coldest storage class object should be transited to
+// by rules, or false if none of the transitions is due
+func transitionStorageClass(object *types.Object, rules []datatype.LifecycleRule) (types.StorageClass, bool) {
+	var target string
+	rank := datatype.StorageClassRank(object.StorageClass.ToString())
+	for _, rule := range rules {
+		for _, transition := range rule.Transition {
+			if datatype.StorageClassRank(transition.StorageClass) <= rank {
+				continue
+			}
+			if transition.Date != "" {
+				if !checkIfDateReached(transition.GetDate()) {
+					continue
+				}
+			} else if !checkIfExpiration(object.LastModifiedTime, transition.Days) {
+				continue
+			}
+			rank = datatype.StorageClassRank(transition.StorageClass)
+			target = transition.StorageClass
 		}
+	}
+	return matchStorageClass(target)
+}
 
+// noncurrentTransitionStorageClass returns the coldest storage class a noncurrent version
+// should be transited to by rules, newer is the number of noncurrent versions newer than it
+func noncurrentTransitionStorageClass(object *types.Object, rules []datatype.LifecycleRule,
+	noncurrentSince time.Time, newer int) (types.StorageClass, bool) {
+
+	var target string
+	rank := datatype.StorageClassRank(object.StorageClass.ToString())
+	for _, rule := range rules {
+		for _, transition := range rule.NoncurrentVersionTransition {
+			if datatype.StorageClassRank(transition.StorageClass) <= rank {
+				continue
+			}
+			if newer < transition.NewerNoncurrentVersions ||
+				!checkIfExpiration(noncurrentSince, transition.NoncurrentDays) {
+				continue
+			}
+			rank = datatype.StorageClassRank(transition.StorageClass)
+			target = transition.StorageClass
+		}
 	}
+	return matchStorageClass(target)
+}
 
+func matchStorageClass(target string) (types.StorageClass, bool) {
+	if target == "" {
+		return 0, false
+	}
+	storageClass, err := types.MatchStorageClassIndex(target)
+	if err != nil {
+		return 0, false
+	}
+	return storageClass, true
 }
 
-func checkIfExpiration(updateTime time.Time, days int) bool {
-	if helper.CONFIG.DebugMode == false {
-		return int(time.Since(updateTime).Seconds()) >= days*24*3600
-	} else {
-		return int(time.Since(updateTime).Seconds()) >= days
+// checkIfNoncurrentExpired checks if a noncurrent version is expired by any of rules
+func checkIfNoncurrentExpired(rules []datatype.LifecycleRule, noncurrentSince time.Time, newer int) bool {
+	for _, rule := range rules {
+		expiration := rule.NoncurrentVersionExpiration
+		if expiration != nil && newer >= expiration.NewerNoncurrentVersions &&
+			checkIfExpiration(noncurrentSince, expiration.NoncurrentDays) {
+			return true
+		}
 	}
+	return false
 }
 
-// If a rule has an empty prefix, the days in it will be consider as a default days for all objects that not specified in
-// other rules. For this reason, we have two conditions to check if a object has expired and should be deleted
-//  if defaultConfig == true
-//                    for each object           check if object name has a prifix
-//  list all objects --------------->loop rules---------------------------------->
-//                                                                      |     NO
-//                                                                      |--------> days = default days ---
-//                                                                      |     YES                         |->delete object if expired
-//                                                                      |--------> days = specify days ---
-//
-//  if defaultConfig == false
-//                 for each rule get objects by prefix
-//  iterator rules ----------------------------------> loop objects-------->delete object if expired
-func retrieveBucket(lc types.LifeCycle) error {
-	defaultConfig := false
-	defaultDays := 0
-	bucket, err := yig.MetaStorage.GetBucket(lc.BucketName, false)
+func transitObject(object *types.Object, storageClass types.StorageClass) {
+	err := yig.TransitObject(object, storageClass)
 	if err != nil {
-		return err
+		helper.Logger.Error(object.BucketName, object.Name, object.VersionId, "transit to",
+			storageClass.ToString(), "failed:", err)
+		lcObjects.WithLabelValues("failed").Inc()
+		return
 	}
-	rules := bucket.Lifecycle.Rule
-	for _, rule := range rules {
-		if rule.Prefix == "" {
-			defaultConfig = true
-			defaultDays, err = strconv.Atoi(rule.Expiration)
-			if err != nil {
-				return err
-			}
+	lcObjects.WithLabelValues("transited").Inc()
+	helper.Logger.Info("Transited:", object.BucketName, object.Name, object.VersionId, "to", storageClass.ToString())
+}
+
+func expireObjectVersion(bucket *types.Bucket, object *types.Object) bool {
+	err := yig.ExpireObjectVersion(bucket, object)
+	if err == ErrObjectLocked {
+		// versions protected by Object Lock are never expired
+		helper.Logger.Info("Skipped locked:", object.BucketName, object.Name, object.GetVersionId())
+		return false
+	} else if err != nil {
+		helper.Logger.Error(object.BucketName, object.Name, object.GetVersionId(), "failed:", err)
+		lcObjects.WithLabelValues("failed").Inc()
+		return false
+	}
+	if object.DeleteMarker {
+		lcObjects.WithLabelValues("delete_marker_expired").Inc()
+	} else {
+		lcObjects.WithLabelValues("version_expired").Inc()
+	}
+	helper.Logger.Info("Deleted:", object.BucketName, object.Name, object.GetVersionId())
+	return true
+}
+
+// lifecycleObject expires current version of object if it's expired by rules,
+// or transits it to colder storage class
+func lifecycleObject(object *types.Object, rules []datatype.LifecycleRule) {
+	if checkIfExpired(object, rules) {
+		// the same as DELETE without versionId, which adds a delete marker in versioned buckets
+		_, err := yig.DeleteObject(object.BucketName, object.Name, "", false, common.Credential{})
+		if err == ErrObjectLocked {
+			helper.Logger.Info("Skipped locked:", object.BucketName, object.Name)
+			return
+		} else if err != nil {
+			helper.Logger.Error(object.BucketName, object.Name, "failed:", err)
+			lcObjects.WithLabelValues("failed").Inc()
+			return
+		}
+		lcObjects.WithLabelValues("expired").Inc()
+		helper.Logger.Info("Deleted:", object.BucketName, object.Name)
+		return
+	}
+	if object.DeleteMarker {
+		return
+	}
+	storageClass, ok := transitionStorageClass(object, rules)
+	if ok {
+		transitObject(object, storageClass)
+	}
+}
+
+// objectVersions tracks versions of the object being walked, versions are listed from the newest
+type objectVersions struct {
+	name      string
+	marker    *types.Object            // current version if it's a delete marker
+	rules     []datatype.LifecycleRule // rules applying to marker
+	newer     int                      // noncurrent versions walked
+	remained  int                      // noncurrent versions not expired
+	successor time.Time                // when the last walked version became noncurrent
+}
+
+func (v *objectVersions) next(bucket *types.Bucket, object *types.Object, rules []datatype.LifecycleRule) {
+	if object.Name != v.name {
+		v.finish(bucket)
+		*v = objectVersions{
+			name:      object.Name,
+			successor: object.LastModifiedTime,
+		}
+		if object.DeleteMarker {
+			v.marker, v.rules = object, rules
+			return
+		}
+		lifecycleObject(object, rules)
+		return
+	}
+
+	noncurrentSince, newer := v.successor, v.newer
+	v.successor = object.LastModifiedTime
+	v.newer++
+	if checkIfNoncurrentExpired(rules, noncurrentSince, newer) {
+		if expireObjectVersion(bucket, object) {
+			return
+		}
+	} else if storageClass, ok := noncurrentTransitionStorageClass(object, rules, noncurrentSince, newer); ok {
+		transitObject(object, storageClass)
+	}
+	v.remained++
+}
+
+// finish removes delete marker of the object if no noncurrent versions remained
+func (v *objectVersions) finish(bucket *types.Bucket) {
+	if v.marker == nil || v.remained > 0 {
+		return
+	}
+	for _, rule := range v.rules {
+		if rule.ExpiresDeleteMarker() || rule.HasExpiration() {
+			expireObjectVersion(bucket, v.marker)
+			return
 		}
 	}
+}
+
+// walkBucket applies rules to objects with prefix, all versions are walked in versioned buckets.
+// Objects are walked from marker of task, and the marker is saved after each page.
+func walkBucket(task *lcTask, bucket *types.Bucket, prefix string, rules []datatype.LifecycleRule) error {
 	var request datatype.ListObjectsRequest
-	request.Versioned = false
+	request.Versioned = bucket.Versioning != types.VersionDisabled
+	request.Prefix = prefix
 	request.MaxKeys = 1000
-	if defaultConfig == true {
+	if marker := task.lc.Marker; marker > prefix {
+		if !strings.HasPrefix(marker, prefix) {
+			// all objects with prefix are before marker
+			return nil
+		}
+		request.Marker = marker
+		request.KeyMarker = marker
+	}
+	versions := new(objectVersions)
+	for {
+		if task.cancelled() {
+			return errLcCancelled
+		}
+		retObjects, _, truncated, nextMarker, nextVerIdMarker, err := yig.ListObjectsInternal(bucket.Name, request)
+		if err != nil {
+			return err
+		}
+		for _, object := range retObjects {
+			lcObjects.WithLabelValues("scanned").Inc()
+			matched := matchRules(rules, object)
+			if request.Versioned {
+				if versions.name != "" && versions.name != object.Name {
+					task.done = versions.name
+				}
+				versions.next(bucket, object, matched)
+			} else {
+				lifecycleObject(object, matched)
+				task.done = object.Name
+			}
+		}
+		if truncated == false {
+			break
+		}
+		task.saveMarker()
+		request.Marker = nextMarker
+		request.KeyMarker = nextMarker
+		request.VersionIdMarker = nextVerIdMarker
+	}
+	versions.finish(bucket)
+	if versions.name != "" {
+		task.done = versions.name
+	}
+	task.saveMarker()
+	return nil
+}
+
+// walkPrefixes returns prefixes to list objects of rules, objects
+// with the same prefix are walked only once and matched with all rules
+func walkPrefixes(rules []datatype.LifecycleRule) (prefixes []string) {
+	var all []string
+	for _, rule := range rules {
+		if rule.IsEnabled() {
+			all = append(all, rule.GetPrefix())
+		}
+	}
+	sort.Strings(all)
+	for _, prefix := range all {
+		if len(prefixes) > 0 && strings.HasPrefix(prefix, prefixes[len(prefixes)-1]) {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return
+}
+
+// abortMultipartUploads aborts multipart uploads by AbortIncompleteMultipartUpload of rules
+func abortMultipartUploads(bucket *types.Bucket, rules []datatype.LifecycleRule) error {
+	for _, rule := range rules {
+		if !rule.IsEnabled() || rule.AbortIncompleteMultipartUpload == nil {
+			continue
+		}
+		initiatedBefore := time.Now().Add(-lifecycleDays(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+		var marker types.Multipart
 		for {
-			retObjects, _, truncated, nextMarker, nextVerIdMarker, err := yig.ListObjectsInternal(bucket.Name, request)
+			multiparts, err := yig.MetaStorage.ScanStaleMultiparts(bucket.Name, rule.GetPrefix(),
+				initiatedBefore, marker, SCAN_LIMIT)
 			if err != nil {
 				return err
 			}
-
-			for _, object := range retObjects {
-				prefixMatch := false
-				matchDays := 0
-				for _, rule := range rules {
-					if rule.Prefix == "" {
-						continue
-					}
-					if strings.HasPrefix(object.Name, rule.Prefix) == false {
-						continue
-					}
-					prefixMatch = true
-					matchDays, err = strconv.Atoi(rule.Expiration)
-					if err != nil {
-						return err
-					}
-				}
-				days := 0
-				if prefixMatch == true {
-					days = matchDays
-				} else {
-					days = defaultDays
-				}
-				helper.Logger.Info("inteval:", time.Since(object.LastModifiedTime).Seconds())
-				if checkIfExpiration(object.LastModifiedTime, days) {
-					helper.Logger.Info("come here")
-					if object.NullVersion {
-						object.VersionId = ""
-					}
-					_, err = yig.DeleteObject(object.BucketName, object.Name, object.VersionId, common.Credential{})
-					if err != nil {
-						helper.Logger.Error(object.BucketName, object.Name, object.VersionId, err)
-						continue
-					}
-					helper.Logger.Info("Deleted:", object.BucketName, object.Name, object.VersionId)
+			for _, multipart := range multiparts {
+				marker = multipart
+				err = yig.AbortMultipartUpload(common.Credential{UserId: bucket.OwnerId},
+					bucket.Name, multipart.ObjectName, multipart.UploadId)
+				if err == ErrNoSuchUpload {
+					// aborted by other rules
+					continue
+				} else if err != nil {
+					helper.Logger.Error("Abort", bucket.Name, multipart.ObjectName, multipart.UploadId, "failed:", err)
+					lcObjects.WithLabelValues("failed").Inc()
+					continue
 				}
+				lcObjects.WithLabelValues("upload_aborted").Inc()
+				helper.Logger.Info("Aborted:", bucket.Name, multipart.ObjectName, multipart.UploadId)
 			}
-			if truncated == true {
-				request.KeyMarker = nextMarker
-				request.VersionIdMarker = nextVerIdMarker
-			} else {
+			if len(multiparts) < SCAN_LIMIT {
 				break
 			}
 		}
-	} else {
-		for _, rule := range rules {
-			if rule.Prefix == "" {
-				continue
-			}
-			days, _ := strconv.Atoi(rule.Expiration)
-			if err != nil {
-				return err
-			}
-			request.Prefix = rule.Prefix
-			for {
+	}
+	return nil
+}
 
-				retObjects, _, truncated, nextMarker, nextVerIdMarker, err := yig.ListObjectsInternal(bucket.Name, request)
-				if err != nil {
-					return err
-				}
-				for _, object := range retObjects {
-					if checkIfExpiration(object.LastModifiedTime, days) {
-						_, err = yig.DeleteObject(object.BucketName, object.Name, object.VersionId, common.Credential{})
-						if err != nil {
-							helper.Logger.Error(object.BucketName, object.Name, object.VersionId, "failed:", err)
-							continue
-						}
-						helper.Logger.Info("Deleted:", object.BucketName, object.Name, object.VersionId)
-					}
-				}
-				if truncated == true {
-					request.KeyMarker = nextMarker
-					request.VersionIdMarker = nextVerIdMarker
-				} else {
-					break
-				}
+// retrieveBucket applies lifecycle rules of bucket. Objects are listed by prefixes of
+// enabled rules, and each object version is matched with all enabled rules by Filter.
+// Expiration takes precedence over transitions when multiple rules apply, and the
+// coldest storage class is picked among due transitions.
+//
+// In versioned buckets, all versions of objects are walked, and noncurrent versions are
+// expired or transited by NoncurrentVersionExpiration and NoncurrentVersionTransition.
+func retrieveBucket(task *lcTask) error {
+	bucket, err := yig.MetaStorage.GetBucket(task.lc.BucketName, false)
+	if err != nil {
+		return err
+	}
+	rules := bucket.Lifecycle.Rule
+	for _, prefix := range walkPrefixes(rules) {
+		err = walkBucket(task, bucket, prefix, rules)
+		if err != nil {
+			return err
+		}
+	}
+	if task.cancelled() {
+		return errLcCancelled
+	}
+	return abortMultipartUploads(bucket, rules)
+}
 
+// renewLease keeps the lease of task until done is closed, the task is stopped
+// if the lease is not renewed, since it may expire and be taken by others
+func renewLease(task *lcTask, done chan struct{}) {
+	ticker := time.NewTicker(LEASE_RENEW_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			renewed, err := yig.MetaStorage.RenewLifeCycle(task.lc.BucketName, task.owner,
+				time.Now().Add(LEASE_TIMEOUT))
+			if err != nil {
+				helper.Logger.Error("Failed to renew lease of", task.lc.BucketName, "err:", err)
+				atomic.StoreInt32(&task.lost, 1)
+				return
+			}
+			if !renewed {
+				helper.Logger.Warn("Lease of", task.lc.BucketName, "is taken by others")
+				atomic.StoreInt32(&task.lost, 1)
+				return
 			}
 		}
+	}
+}
 
+// processBucket applies lifecycle rules of bucket if its lease is acquired,
+// the bucket is marked finished in the window if all objects are processed
+func processBucket(task *lcTask) {
+	acquired, err := yig.MetaStorage.AcquireLifeCycle(task.lc.BucketName, task.owner,
+		time.Now().Add(LEASE_TIMEOUT), task.windowStart)
+	if err != nil {
+		helper.Logger.Error("Failed to acquire lease of", task.lc.BucketName, "err:", err)
+		return
+	}
+	if !acquired {
+		// processed by others
+		return
+	}
+	// marker might be saved by others after scanned
+	lc, err := yig.MetaStorage.GetLifeCycle(task.lc.BucketName)
+	if err != nil {
+		helper.Logger.Error("Failed to get lifecycle of", task.lc.BucketName, "err:", err)
+		yig.MetaStorage.ReleaseLifeCycle(task.lc.BucketName, task.owner, false)
+		return
+	}
+	task.lc = lc
+	lcBucketsInProgress.Inc()
+	defer lcBucketsInProgress.Dec()
+	if task.lc.Marker != "" {
+		helper.Logger.Info("Resume bucket lifecycle:", task.lc.BucketName, "from", task.lc.Marker)
+	}
+
+	done := make(chan struct{})
+	go renewLease(task, done)
+	err = retrieveBucket(task)
+	close(done)
+	if atomic.LoadInt32(&task.lost) == 1 {
+		lcBuckets.WithLabelValues("cancelled").Inc()
+		// in case the lease is still held but failed to renew
+		yig.MetaStorage.ReleaseLifeCycle(task.lc.BucketName, task.owner, false)
+		return
+	}
+	switch err {
+	case nil:
+		lcBuckets.WithLabelValues("finished").Inc()
+		helper.Logger.Info("Bucket lifecycle done:", task.lc.BucketName)
+	case errLcCancelled:
+		lcBuckets.WithLabelValues("cancelled").Inc()
+		helper.Logger.Info("Bucket lifecycle paused:", task.lc.BucketName, "marker:", task.lc.Marker)
+	default:
+		lcBuckets.WithLabelValues("failed").Inc()
+		helper.Logger.Error("Bucket", task.lc.BucketName, "retrieve error:", err)
+	}
+	err = yig.MetaStorage.ReleaseLifeCycle(task.lc.BucketName, task.owner, err == nil)
+	if err != nil {
+		helper.Logger.Warn("Failed to release lease of", task.lc.BucketName, "err:", err)
 	}
-	return nil
 }
 
-func processLifecycle() {
-	time.Sleep(time.Second * 1)
+// each worker holds leases with its own owner, so buckets are not processed twice
+func processLifecycle(owner string) {
+	for task := range taskQ {
+		task.owner = owner
+		processBucket(task)
+		lcBucketsPending.Dec()
+		batch.Done()
+	}
+}
+
+// getLifeCycles runs a pass over buckets with lifecycle rules, buckets finished
+// after windowStart or being processed by others are skipped
+func getLifeCycles(windowStart, windowEnd time.Time) {
+	var marker string
+	helper.Logger.Info("all bucket lifecycle handle start")
 	for {
-		if stop {
-			helper.Logger.Info("Shutting down...")
-			return
+		if stopping() || (!windowEnd.IsZero() && time.Now().After(windowEnd)) {
+			break
 		}
-		waitgroup.Add(1)
-		select {
-		case item := <-taskQ:
-			err := retrieveBucket(item)
-			if err != nil {
-				helper.Logger.Error("Bucket", item.BucketName, "retrieve error:", err)
-				waitgroup.Done()
+		result, err := yig.MetaStorage.ScanLifeCycle(SCAN_LIMIT, marker)
+		if err != nil {
+			helper.Logger.Error("ScanLifeCycle failed:", err)
+			break
+		}
+		now := time.Now()
+		for _, entry := range result.Lcs {
+			marker = entry.BucketName
+			if !entry.LastFinished.Before(windowStart) {
 				continue
 			}
-			helper.Logger.Info("Bucket lifecycle done:", item.BucketName)
-		default:
-			if empty == true {
-				helper.Logger.Info("All bucket lifecycle handle complete. QUIT")
-				signalQueue <- syscall.SIGQUIT
-				waitgroup.Done()
-				return
+			if entry.Owner != "" && entry.LeaseExpire.After(now) {
+				continue
+			}
+			batch.Add(1)
+			lcBucketsPending.Inc()
+			taskQ <- &lcTask{
+				lc:          entry,
+				windowStart: windowStart,
+				windowEnd:   windowEnd,
 			}
 		}
-		waitgroup.Done()
+		if result.Truncated == false {
+			break
+		}
+	}
+	batch.Wait()
+	lcLastPass.SetToCurrentTime()
+	helper.Logger.Info("All bucket lifecycle handle complete.")
+}
+
+// stopping returns if the tool is stopped by signals
+func stopping() bool {
+	return atomic.LoadInt32(&stop) == 1
+}
+
+// runDaemon runs passes in daily windows, until stopped
+func runDaemon() {
+	for !stopping() {
+		now := time.Now()
+		start, end := types.LifeCycleWindow(now, helper.CONFIG.LcWindowStart, helper.CONFIG.LcWindowEnd)
+		if !now.Before(start) {
+			// buckets failed or released by others are retried in the window
+			getLifeCycles(start, end)
+		}
+		for i := 0; i < int(WINDOW_CHECK_INTERVAL/time.Second) && !stopping(); i++ {
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func serveMetrics(address string) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(lcObjects, lcBuckets, lcBucketsPending, lcBucketsInProgress, lcLastPass)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	err := http.ListenAndServe(address, mux)
+	if err != nil {
+		helper.Logger.Error("Failed to serve metrics on", address, "err:", err)
 	}
 }
 
 func main() {
-	stop = false
+	daemon := flag.Bool("daemon", false, "keep running and process buckets in the daily window")
+	flag.Parse()
 
 	helper.SetupConfig()
 	logLevel := log.ParseLevel(helper.CONFIG.LogLevel)
@@ -242,31 +650,54 @@ func main() {
 	kms := crypto.NewKMS(allPluginMap)
 
 	yig = storage.New(helper.CONFIG.MetaCacheType, helper.CONFIG.EnableDataCache, kms)
-	taskQ = make(chan types.LifeCycle, SCAN_LIMIT)
+
+	// expirations are notified to MQ plugins subscribed by buckets, if any is configured
+	if err := bus.InitNotificationSenders(allPluginMap); err != nil {
+		helper.Logger.Error("Failed to create message queue senders, err:", err)
+		panic("failed to create message bus senders")
+	}
+	taskQ = make(chan *lcTask, SCAN_LIMIT)
 	signal.Ignore()
 	signalQueue = make(chan os.Signal)
 
 	numOfWorkers := helper.CONFIG.LcThread
 	helper.Logger.Info("start lc thread:", numOfWorkers)
-	empty = false
 	for i := 0; i < numOfWorkers; i++ {
-		go processLifecycle()
+		go processLifecycle(helper.CONFIG.InstanceId + "-" + strconv.Itoa(i))
+	}
+	finished := make(chan struct{})
+	if *daemon {
+		if helper.CONFIG.LcMetricsListener != "" {
+			go serveMetrics(helper.CONFIG.LcMetricsListener)
+		}
+		go func() {
+			runDaemon()
+			close(finished)
+		}()
+	} else {
+		// process all buckets once, regardless of the window
+		go func() {
+			getLifeCycles(time.Now(), time.Time{})
+			close(finished)
+		}()
 	}
-	go getLifeCycles()
 	signal.Notify(signalQueue, syscall.SIGINT, syscall.SIGTERM,
 		syscall.SIGQUIT, syscall.SIGHUP)
 	for {
-		s := <-signalQueue
-		switch s {
-		case syscall.SIGHUP:
-			// reload config file
-			helper.SetupConfig()
-		default:
-			// stop YIG server, order matters
-			stop = true
-			waitgroup.Wait()
+		select {
+		case <-finished:
 			return
+		case s := <-signalQueue:
+			switch s {
+			case syscall.SIGHUP:
+				// reload config file
+				helper.SetupConfig()
+			default:
+				// buckets being processed are paused and resumed from markers later
+				atomic.StoreInt32(&stop, 1)
+				<-finished
+				return
+			}
 		}
 	}
-
 }
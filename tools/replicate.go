@@ -0,0 +1,324 @@
+package main
+
+import (
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/journeymidnight/aws-sdk-go/aws"
+	"github.com/journeymidnight/aws-sdk-go/aws/credentials"
+	"github.com/journeymidnight/aws-sdk-go/aws/session"
+	"github.com/journeymidnight/aws-sdk-go/service/s3"
+	"github.com/journeymidnight/aws-sdk-go/service/s3/s3manager"
+	"github.com/journeymidnight/yig/api/datatype"
+	"github.com/journeymidnight/yig/crypto"
+	. "github.com/journeymidnight/yig/error"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/log"
+	"github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/mods"
+	"github.com/journeymidnight/yig/redis"
+	"github.com/journeymidnight/yig/storage"
+)
+
+const (
+	SCAN_LIMIT                 = 100
+	DEFAULT_REPLICATE_LOG_PATH = "/var/log/yig/replicate.log"
+	// wait longer after each failure, at most MAX_RETRY_INTERVAL
+	RETRY_INTERVAL     = 30 * time.Second
+	MAX_RETRY_INTERVAL = time.Hour
+)
+
+var (
+	yig         *storage.YigStorage
+	taskQ       chan types.Replication
+	signalQueue chan os.Signal
+	batch       sync.WaitGroup
+	stop        int32 // set to 1 atomically when shutting down
+
+	// S3 clients of destinations keyed by region, reset when config reloaded
+	clients     map[string]*s3.S3
+	clientsLock sync.Mutex
+)
+
+func getClient(region string) (*s3.S3, error) {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	if client, ok := clients[region]; ok {
+		return client, nil
+	}
+	endpoint, ok := helper.CONFIG.ReplicationEndpoints[region]
+	if !ok {
+		return nil, ErrInvalidReplicationDestination
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(endpoint.AccessKey, endpoint.SecretKey, ""),
+		Endpoint:         aws.String(endpoint.Endpoint),
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	clients[region] = s3.New(sess)
+	return clients[region], nil
+}
+
+func resetClients() {
+	clientsLock.Lock()
+	defer clientsLock.Unlock()
+	clients = make(map[string]*s3.S3)
+}
+
+// copy object, or delete marker, to destination of rule with S3 API
+func copyObject(rule *datatype.ReplicationRule, object *types.Object) error {
+	client, err := getClient(rule.Destination.DestinationRegion())
+	if err != nil {
+		return err
+	}
+	destBucket := rule.Destination.DestinationBucket()
+	if object.DeleteMarker {
+		_, err = client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(destBucket),
+			Key:    aws.String(object.Name),
+		})
+		return err
+	}
+
+	input := &s3manager.UploadInput{
+		Bucket:   aws.String(destBucket),
+		Key:      aws.String(object.Name),
+		Metadata: make(map[string]*string),
+	}
+	for k, v := range object.CustomAttributes {
+		switch strings.ToLower(k) {
+		case "content-type":
+			input.ContentType = aws.String(v)
+		case "cache-control":
+			input.CacheControl = aws.String(v)
+		case "content-disposition":
+			input.ContentDisposition = aws.String(v)
+		case "content-encoding":
+			input.ContentEncoding = aws.String(v)
+		case "content-language":
+			input.ContentLanguage = aws.String(v)
+		case "website-redirect-location":
+			input.WebsiteRedirectLocation = aws.String(v)
+		default:
+			if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+				input.Metadata[k[len("x-amz-meta-"):]] = aws.String(v)
+			}
+		}
+	}
+	if object.ContentType != "" {
+		input.ContentType = aws.String(object.ContentType)
+	}
+	if len(object.Tags) > 0 {
+		tagging := url.Values{}
+		for k, v := range object.Tags {
+			tagging.Set(k, v)
+		}
+		input.Tagging = aws.String(tagging.Encode())
+	}
+	input.StorageClass = aws.String(object.StorageClass.ToString())
+	if rule.Destination.StorageClass != "" {
+		input.StorageClass = aws.String(rule.Destination.StorageClass)
+	}
+	switch object.SseType {
+	case crypto.S3.String():
+		input.ServerSideEncryption = aws.String(crypto.SSEAlgorithmAES256)
+	case crypto.S3KMS.String():
+		// keys of KMS are not shared across deployments, the default key of destination is used
+		input.ServerSideEncryption = aws.String(crypto.SSEAlgorithmKMS)
+	}
+
+	// SSE-S3 and SSE-KMS objects are decrypted when read and encrypted again by destination
+	reader, writer := io.Pipe()
+	go func() {
+		err := yig.GetObject(object, 0, object.Size, writer, datatype.SseRequest{})
+		writer.CloseWithError(err)
+	}()
+	input.Body = reader
+	_, err = s3manager.NewUploaderWithClient(client).Upload(input)
+	reader.Close()
+	return err
+}
+
+func finish(entry types.Replication, object *types.Object, status string) {
+	object.ReplicationStatus = status
+	err := yig.MetaStorage.UpdateObjectReplicationStatus(object)
+	if err != nil {
+		helper.Logger.Error("Failed to update replication status of", entry.BucketName, entry.ObjectName,
+			entry.Version, "err:", err)
+		return
+	}
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":")
+	yig.MetaStorage.Cache.Remove(redis.ObjectTable, object.BucketName+":"+object.Name+":"+object.GetVersionId())
+	err = yig.MetaStorage.RemoveReplication(entry)
+	if err != nil {
+		helper.Logger.Error("Failed to remove replication", entry.BucketName, entry.ObjectName,
+			entry.Version, "err:", err)
+	}
+}
+
+// objectOf returns the object version of entry with only keys set,
+// used to update replication status if the object can't be read
+func objectOf(entry types.Replication) *types.Object {
+	return &types.Object{
+		BucketName:       entry.BucketName,
+		Name:             entry.ObjectName,
+		LastModifiedTime: time.Unix(0, int64(math.MaxUint64-entry.Version)),
+	}
+}
+
+// retry replicates entry later, object is nil if it failed to read
+func retry(entry types.Replication, object *types.Object, cause error) {
+	helper.Logger.Warn("Replicate", entry.BucketName, entry.ObjectName, entry.Version,
+		"failed:", cause, "tried times:", entry.TriedTimes)
+	entry.TriedTimes += 1
+	if entry.TriedTimes >= helper.CONFIG.ReplicationMaxRetries {
+		if object == nil {
+			object = objectOf(entry)
+		}
+		finish(entry, object, types.ReplicationStatusFailed)
+		return
+	}
+	interval := RETRY_INTERVAL * time.Duration(entry.TriedTimes)
+	if interval > MAX_RETRY_INTERVAL {
+		interval = MAX_RETRY_INTERVAL
+	}
+	entry.NextTime = time.Now().Add(interval)
+	err := yig.MetaStorage.UpdateReplication(entry)
+	if err != nil {
+		helper.Logger.Error("Failed to update replication", entry.BucketName, entry.ObjectName,
+			entry.Version, "err:", err)
+	}
+}
+
+func replicate(entry types.Replication) {
+	object, err := yig.MetaStorage.Client.GetObject(entry.BucketName, entry.ObjectName,
+		strconv.FormatUint(entry.Version, 10))
+	if err == ErrNoSuchKey {
+		// the version has been removed before replicated
+		yig.MetaStorage.RemoveReplication(entry)
+		return
+	} else if err != nil {
+		retry(entry, nil, err)
+		return
+	}
+	// version id from GetObject is only a cache, calculate it again
+	object.VersionId = ""
+
+	bucket, err := yig.MetaStorage.GetBucket(entry.BucketName, false)
+	if err == ErrNoSuchBucket {
+		yig.MetaStorage.RemoveReplication(entry)
+		return
+	} else if err != nil {
+		retry(entry, object, err)
+		return
+	}
+	rule := bucket.Replication.MatchRule(object.Name)
+	if rule == nil {
+		helper.Logger.Warn("No replication rule matches", entry.BucketName, entry.ObjectName, entry.Version)
+		finish(entry, object, types.ReplicationStatusFailed)
+		return
+	}
+
+	err = copyObject(rule, object)
+	if err != nil {
+		retry(entry, object, err)
+		return
+	}
+	helper.Logger.Info("Replicated", entry.BucketName, entry.ObjectName, entry.Version,
+		"to", rule.Destination.Bucket)
+	finish(entry, object, types.ReplicationStatusCompleted)
+}
+
+func processReplication() {
+	for entry := range taskQ {
+		replicate(entry)
+		batch.Done()
+	}
+}
+
+// scan due entries batch by batch, an entry is only scanned again
+// after the previous batch is done, so it's never replicated concurrently
+func scanReplication() {
+	for {
+		if atomic.LoadInt32(&stop) == 1 {
+			helper.Logger.Info("Shutting down...")
+			close(taskQ)
+			return
+		}
+		entries, err := yig.MetaStorage.ScanReplication(SCAN_LIMIT)
+		if err != nil {
+			helper.Logger.Error("ScanReplication failed:", err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		if len(entries) == 0 {
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		batch.Add(len(entries))
+		for _, entry := range entries {
+			taskQ <- entry
+		}
+		batch.Wait()
+	}
+}
+
+func main() {
+	atomic.StoreInt32(&stop, 0)
+
+	helper.SetupConfig()
+	logLevel := log.ParseLevel(helper.CONFIG.LogLevel)
+
+	helper.Logger = log.NewFileLogger(DEFAULT_REPLICATE_LOG_PATH, logLevel)
+	defer helper.Logger.Close()
+	if helper.CONFIG.MetaCacheType > 0 || helper.CONFIG.EnableDataCache {
+		redis.Initialize()
+		defer redis.Close()
+	}
+
+	// Read all *.so from plugins directory, and fill the variable allPlugins
+	allPluginMap := mods.InitialPlugins()
+	kms := crypto.NewKMS(allPluginMap)
+
+	yig = storage.New(helper.CONFIG.MetaCacheType, helper.CONFIG.EnableDataCache, kms)
+	resetClients()
+	taskQ = make(chan types.Replication, SCAN_LIMIT)
+	signal.Ignore()
+	signalQueue = make(chan os.Signal)
+
+	numOfWorkers := helper.CONFIG.ReplicationThread
+	helper.Logger.Info("start replication thread:", numOfWorkers)
+	for i := 0; i < numOfWorkers; i++ {
+		go processReplication()
+	}
+	go scanReplication()
+	signal.Notify(signalQueue, syscall.SIGINT, syscall.SIGTERM,
+		syscall.SIGQUIT, syscall.SIGHUP)
+	for {
+		s := <-signalQueue
+		switch s {
+		case syscall.SIGHUP:
+			// reload config file, including replication endpoints
+			helper.SetupConfig()
+			resetClients()
+		default:
+			// stop after the current batch is done
+			atomic.StoreInt32(&stop, 1)
+			batch.Wait()
+			return
+		}
+	}
+}
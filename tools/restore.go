@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/journeymidnight/yig/crypto"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/log"
+	"github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/mods"
+	bus "github.com/journeymidnight/yig/mq"
+	"github.com/journeymidnight/yig/redis"
+	"github.com/journeymidnight/yig/storage"
+)
+
+const (
+	SCAN_LIMIT               = 100
+	DEFAULT_RESTORE_LOG_PATH = "/var/log/yig/restore.log"
+	// RESTORING requests not finished in time are restarted, e.g. the worker is killed
+	RESTORE_TIMEOUT = 24 * time.Hour
+	EXPIRE_INTERVAL = 10 * time.Minute
+)
+
+var (
+	yig         *storage.YigStorage
+	taskQ       chan types.Freezer
+	signalQueue chan os.Signal
+	batch       sync.WaitGroup
+	stop        int32 // set by signal handler, read by workers
+)
+
+func restore(freezer types.Freezer) {
+	err := yig.RestoreFreezer(&freezer)
+	if err != nil {
+		helper.Logger.Error("Failed to restore", freezer.BucketName, freezer.Name, "err:", err)
+		return
+	}
+	helper.Logger.Info("Restored", freezer.BucketName, freezer.Name, "status:", freezer.Status.ToString())
+}
+
+func processRestore() {
+	for freezer := range taskQ {
+		restore(freezer)
+		batch.Done()
+	}
+}
+
+// scan requests batch by batch, requests of Expedited tier are scanned
+// before Standard and Bulk ones, and earlier requests first in the same tier
+func scanRestore() {
+	for {
+		if stopping() {
+			helper.Logger.Info("Shutting down...")
+			close(taskQ)
+			return
+		}
+		freezers, err := yig.MetaStorage.ScanFreezers(SCAN_LIMIT, time.Now().Add(-RESTORE_TIMEOUT))
+		if err != nil {
+			helper.Logger.Error("ScanFreezers failed:", err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		if len(freezers) == 0 {
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		batch.Add(len(freezers))
+		for _, freezer := range freezers {
+			taskQ <- freezer
+		}
+		batch.Wait()
+	}
+}
+
+// remove restored copies after their life time, data is removed by tools/delete
+func expireRestored() {
+	for {
+		if stopping() {
+			return
+		}
+		freezers, err := yig.MetaStorage.ScanExpiredFreezers(SCAN_LIMIT)
+		if err != nil {
+			helper.Logger.Error("ScanExpiredFreezers failed:", err)
+		}
+		for _, freezer := range freezers {
+			err = yig.ExpireFreezer(&freezer)
+			if err != nil {
+				helper.Logger.Error("Failed to expire restored", freezer.BucketName, freezer.Name, "err:", err)
+				continue
+			}
+			helper.Logger.Info("Expired restored", freezer.BucketName, freezer.Name)
+		}
+		if len(freezers) < SCAN_LIMIT {
+			time.Sleep(EXPIRE_INTERVAL)
+		}
+	}
+}
+
+// stopping returns if the tool is stopped by signals
+func stopping() bool {
+	return atomic.LoadInt32(&stop) == 1
+}
+
+func main() {
+
+	helper.SetupConfig()
+	logLevel := log.ParseLevel(helper.CONFIG.LogLevel)
+
+	helper.Logger = log.NewFileLogger(DEFAULT_RESTORE_LOG_PATH, logLevel)
+	defer helper.Logger.Close()
+	if helper.CONFIG.MetaCacheType > 0 || helper.CONFIG.EnableDataCache {
+		redis.Initialize()
+		defer redis.Close()
+	}
+
+	// Read all *.so from plugins directory, and fill the variable allPlugins
+	allPluginMap := mods.InitialPlugins()
+	kms := crypto.NewKMS(allPluginMap)
+
+	yig = storage.New(helper.CONFIG.MetaCacheType, helper.CONFIG.EnableDataCache, kms)
+
+	// completed restorations are notified to MQ plugins subscribed by buckets, if any is configured
+	if err := bus.InitNotificationSenders(allPluginMap); err != nil {
+		helper.Logger.Error("Failed to create message queue senders, err:", err)
+		panic("failed to create message bus senders")
+	}
+	taskQ = make(chan types.Freezer, SCAN_LIMIT)
+	signal.Ignore()
+	signalQueue = make(chan os.Signal)
+
+	numOfWorkers := helper.CONFIG.RestoreThread
+	helper.Logger.Info("start restore thread:", numOfWorkers)
+	for i := 0; i < numOfWorkers; i++ {
+		go processRestore()
+	}
+	go scanRestore()
+	go expireRestored()
+	signal.Notify(signalQueue, syscall.SIGINT, syscall.SIGTERM,
+		syscall.SIGQUIT, syscall.SIGHUP)
+	for {
+		s := <-signalQueue
+		switch s {
+		case syscall.SIGHUP:
+			// reload config file
+			helper.SetupConfig()
+		default:
+			// stop after the current batch is done
+			atomic.StoreInt32(&stop, 1)
+			batch.Wait()
+			return
+		}
+	}
+}
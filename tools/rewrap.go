@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"math"
+	"time"
+
+	"github.com/journeymidnight/yig/crypto"
+	"github.com/journeymidnight/yig/helper"
+	"github.com/journeymidnight/yig/log"
+	"github.com/journeymidnight/yig/meta"
+	"github.com/journeymidnight/yig/meta/types"
+	"github.com/journeymidnight/yig/mods"
+	"github.com/journeymidnight/yig/redis"
+)
+
+const (
+	SCAN_LIMIT              = 100
+	DEFAULT_REWRAP_LOG_PATH = "/var/log/yig/rewrap.log"
+)
+
+var (
+	metaStorage *meta.Meta
+	keyring     *crypto.Keyring
+)
+
+// rewrap unseals key with its master key version, and seals it again with target,
+// data of objects are never touched since data keys are not changed
+func rewrap(key []byte, keyVersion, target int) ([]byte, error) {
+	unsealed, err := keyring.Unseal(keyVersion, key)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.SealWith(target, unsealed)
+}
+
+func rewrapObjects(target int) (done, failed int) {
+	var marker types.SealedKey
+	for {
+		keys, err := metaStorage.ScanObjectSealedKeys(target, marker, SCAN_LIMIT)
+		if err != nil {
+			helper.Logger.Error("ScanObjectSealedKeys failed:", err)
+			return
+		}
+		if len(keys) == 0 {
+			return
+		}
+		for _, key := range keys {
+			marker = key
+			sealed, err := rewrap(key.EncryptionKey, key.KeyVersion, target)
+			if err != nil {
+				helper.Logger.Error("Failed to rewrap key of object", key.BucketName, key.ObjectName,
+					key.Version, "key version:", key.KeyVersion, "err:", err)
+				failed++
+				continue
+			}
+			rewrapped := key
+			rewrapped.EncryptionKey, rewrapped.KeyVersion = sealed, target
+			updated, err := metaStorage.UpdateObjectSealedKey(key, rewrapped)
+			if err != nil {
+				helper.Logger.Error("Failed to update key of object", key.BucketName, key.ObjectName,
+					key.Version, "err:", err)
+				failed++
+				continue
+			}
+			if !updated {
+				// rewritten since scanned, the new key is sealed with the current version
+				helper.Logger.Info("Key of object", key.BucketName, key.ObjectName, key.Version,
+					"is changed since scanned, skipped")
+				continue
+			}
+			// cached objects keep keys sealed with old version, which are still valid
+			// as long as the old master key is in keyring
+			object := types.Object{
+				LastModifiedTime: time.Unix(0, int64(math.MaxUint64-key.Version)),
+			}
+			metaStorage.Cache.Remove(redis.ObjectTable, key.BucketName+":"+key.ObjectName+":")
+			metaStorage.Cache.Remove(redis.ObjectTable, key.BucketName+":"+key.ObjectName+":"+object.GetVersionId())
+			done++
+		}
+	}
+}
+
+func rewrapMultiparts(target int) (done, failed int) {
+	var marker types.SealedKey
+	for {
+		keys, err := metaStorage.ScanMultipartSealedKeys(target, marker, SCAN_LIMIT)
+		if err != nil {
+			helper.Logger.Error("ScanMultipartSealedKeys failed:", err)
+			return
+		}
+		if len(keys) == 0 {
+			return
+		}
+		for _, key := range keys {
+			marker = key
+			encryptionKey, err := rewrap(key.EncryptionKey, key.KeyVersion, target)
+			if err != nil {
+				helper.Logger.Error("Failed to rewrap key of multipart upload", key.BucketName, key.ObjectName,
+					key.Version, "key version:", key.KeyVersion, "err:", err)
+				failed++
+				continue
+			}
+			cipherKey, err := rewrap(key.CipherKey, key.KeyVersion, target)
+			if err != nil {
+				helper.Logger.Error("Failed to rewrap cipher key of multipart upload", key.BucketName, key.ObjectName,
+					key.Version, "key version:", key.KeyVersion, "err:", err)
+				failed++
+				continue
+			}
+			rewrapped := key
+			rewrapped.EncryptionKey, rewrapped.CipherKey, rewrapped.KeyVersion = encryptionKey, cipherKey, target
+			updated, err := metaStorage.UpdateMultipartSealedKey(key, rewrapped)
+			if err != nil {
+				helper.Logger.Error("Failed to update key of multipart upload", key.BucketName, key.ObjectName,
+					key.Version, "err:", err)
+				failed++
+				continue
+			}
+			if !updated {
+				helper.Logger.Info("Key of multipart upload", key.BucketName, key.ObjectName, key.Version,
+					"is changed since scanned, skipped")
+				continue
+			}
+			done++
+		}
+	}
+}
+
+// re-seal keys of SSE objects and multipart uploads with a master key version,
+// so master keys of other versions could be removed from keyring afterwards
+func main() {
+	helper.SetupConfig()
+	logLevel := log.ParseLevel(helper.CONFIG.LogLevel)
+
+	helper.Logger = log.NewFileLogger(DEFAULT_REWRAP_LOG_PATH, logLevel)
+	defer helper.Logger.Close()
+	if helper.CONFIG.MetaCacheType > 0 || helper.CONFIG.EnableDataCache {
+		redis.Initialize()
+		defer redis.Close()
+	}
+
+	// Read all *.so from plugins directory, and fill the variable allPlugins
+	allPluginMap := mods.InitialPlugins()
+	kms := crypto.NewKMS(allPluginMap)
+	var err error
+	keyring, err = crypto.LoadKeyring(kms)
+	if err != nil {
+		panic("Failed to load SSE master keys: " + err.Error())
+	}
+
+	target := flag.Int("version", keyring.CurrentVersion(), "master key version to seal keys with")
+	flag.Parse()
+	if _, err = keyring.SealWith(*target, make([]byte, 32)); err != nil {
+		helper.Logger.Error("Invalid master key version", *target, "err:", err)
+		return
+	}
+	metaStorage = meta.New(meta.CacheType(helper.CONFIG.MetaCacheType))
+
+	helper.Logger.Info("Rewrap keys with master key version", *target)
+	done, failed := rewrapObjects(*target)
+	helper.Logger.Info("Objects rewrapped:", done, "failed:", failed)
+	done, failed = rewrapMultiparts(*target)
+	helper.Logger.Info("Multipart uploads rewrapped:", done, "failed:", failed)
+}